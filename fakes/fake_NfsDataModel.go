@@ -0,0 +1,416 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/IBM/ubiquity/local/nfs"
+	"github.com/IBM/ubiquity/resources"
+)
+
+type FakeNfsDataModel struct {
+	CreateVolumeTableStub        func() error
+	createVolumeTableMutex       sync.RWMutex
+	createVolumeTableArgsForCall []struct{}
+	createVolumeTableReturns     struct {
+		result1 error
+	}
+	createVolumeTableReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteVolumeStub        func(name string) error
+	deleteVolumeMutex       sync.RWMutex
+	deleteVolumeArgsForCall []struct {
+		name string
+	}
+	deleteVolumeReturns struct {
+		result1 error
+	}
+	deleteVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InsertVolumeStub        func(volumeName string, directory string, nfsShare string, quota string, projectId uint32, opts map[string]interface{}) error
+	insertVolumeMutex       sync.RWMutex
+	insertVolumeArgsForCall []struct {
+		volumeName string
+		directory  string
+		nfsShare   string
+		quota      string
+		projectId  uint32
+		opts       map[string]interface{}
+	}
+	insertVolumeReturns struct {
+		result1 error
+	}
+	insertVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetVolumeStub        func(name string) (nfs.NfsVolume, bool, error)
+	getVolumeMutex       sync.RWMutex
+	getVolumeArgsForCall []struct {
+		name string
+	}
+	getVolumeReturns struct {
+		result1 nfs.NfsVolume
+		result2 bool
+		result3 error
+	}
+	getVolumeReturnsOnCall map[int]struct {
+		result1 nfs.NfsVolume
+		result2 bool
+		result3 error
+	}
+	ListVolumesStub        func() ([]resources.Volume, error)
+	listVolumesMutex       sync.RWMutex
+	listVolumesArgsForCall []struct{}
+	listVolumesReturns     struct {
+		result1 []resources.Volume
+		result2 error
+	}
+	listVolumesReturnsOnCall map[int]struct {
+		result1 []resources.Volume
+		result2 error
+	}
+	GetMaxProjectIdStub        func() (uint32, error)
+	getMaxProjectIdMutex       sync.RWMutex
+	getMaxProjectIdArgsForCall []struct{}
+	getMaxProjectIdReturns     struct {
+		result1 uint32
+		result2 error
+	}
+	getMaxProjectIdReturnsOnCall map[int]struct {
+		result1 uint32
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeNfsDataModel) CreateVolumeTable() error {
+	fake.createVolumeTableMutex.Lock()
+	ret, specificReturn := fake.createVolumeTableReturnsOnCall[len(fake.createVolumeTableArgsForCall)]
+	fake.createVolumeTableArgsForCall = append(fake.createVolumeTableArgsForCall, struct{}{})
+	fake.recordInvocation("CreateVolumeTable", []interface{}{})
+	fake.createVolumeTableMutex.Unlock()
+	if fake.CreateVolumeTableStub != nil {
+		return fake.CreateVolumeTableStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.createVolumeTableReturns.result1
+}
+
+func (fake *FakeNfsDataModel) CreateVolumeTableCallCount() int {
+	fake.createVolumeTableMutex.RLock()
+	defer fake.createVolumeTableMutex.RUnlock()
+	return len(fake.createVolumeTableArgsForCall)
+}
+
+func (fake *FakeNfsDataModel) CreateVolumeTableReturns(result1 error) {
+	fake.CreateVolumeTableStub = nil
+	fake.createVolumeTableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNfsDataModel) CreateVolumeTableReturnsOnCall(i int, result1 error) {
+	fake.CreateVolumeTableStub = nil
+	if fake.createVolumeTableReturnsOnCall == nil {
+		fake.createVolumeTableReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createVolumeTableReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNfsDataModel) DeleteVolume(name string) error {
+	fake.deleteVolumeMutex.Lock()
+	ret, specificReturn := fake.deleteVolumeReturnsOnCall[len(fake.deleteVolumeArgsForCall)]
+	fake.deleteVolumeArgsForCall = append(fake.deleteVolumeArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("DeleteVolume", []interface{}{name})
+	fake.deleteVolumeMutex.Unlock()
+	if fake.DeleteVolumeStub != nil {
+		return fake.DeleteVolumeStub(name)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.deleteVolumeReturns.result1
+}
+
+func (fake *FakeNfsDataModel) DeleteVolumeCallCount() int {
+	fake.deleteVolumeMutex.RLock()
+	defer fake.deleteVolumeMutex.RUnlock()
+	return len(fake.deleteVolumeArgsForCall)
+}
+
+func (fake *FakeNfsDataModel) DeleteVolumeArgsForCall(i int) string {
+	fake.deleteVolumeMutex.RLock()
+	defer fake.deleteVolumeMutex.RUnlock()
+	return fake.deleteVolumeArgsForCall[i].name
+}
+
+func (fake *FakeNfsDataModel) DeleteVolumeReturns(result1 error) {
+	fake.DeleteVolumeStub = nil
+	fake.deleteVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNfsDataModel) DeleteVolumeReturnsOnCall(i int, result1 error) {
+	fake.DeleteVolumeStub = nil
+	if fake.deleteVolumeReturnsOnCall == nil {
+		fake.deleteVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNfsDataModel) InsertVolume(volumeName string, directory string, nfsShare string, quota string, projectId uint32, opts map[string]interface{}) error {
+	fake.insertVolumeMutex.Lock()
+	ret, specificReturn := fake.insertVolumeReturnsOnCall[len(fake.insertVolumeArgsForCall)]
+	fake.insertVolumeArgsForCall = append(fake.insertVolumeArgsForCall, struct {
+		volumeName string
+		directory  string
+		nfsShare   string
+		quota      string
+		projectId  uint32
+		opts       map[string]interface{}
+	}{volumeName, directory, nfsShare, quota, projectId, opts})
+	fake.recordInvocation("InsertVolume", []interface{}{volumeName, directory, nfsShare, quota, projectId, opts})
+	fake.insertVolumeMutex.Unlock()
+	if fake.InsertVolumeStub != nil {
+		return fake.InsertVolumeStub(volumeName, directory, nfsShare, quota, projectId, opts)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.insertVolumeReturns.result1
+}
+
+func (fake *FakeNfsDataModel) InsertVolumeCallCount() int {
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	return len(fake.insertVolumeArgsForCall)
+}
+
+func (fake *FakeNfsDataModel) InsertVolumeArgsForCall(i int) (string, string, string, string, uint32, map[string]interface{}) {
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	return fake.insertVolumeArgsForCall[i].volumeName, fake.insertVolumeArgsForCall[i].directory, fake.insertVolumeArgsForCall[i].nfsShare, fake.insertVolumeArgsForCall[i].quota, fake.insertVolumeArgsForCall[i].projectId, fake.insertVolumeArgsForCall[i].opts
+}
+
+func (fake *FakeNfsDataModel) InsertVolumeReturns(result1 error) {
+	fake.InsertVolumeStub = nil
+	fake.insertVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNfsDataModel) InsertVolumeReturnsOnCall(i int, result1 error) {
+	fake.InsertVolumeStub = nil
+	if fake.insertVolumeReturnsOnCall == nil {
+		fake.insertVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.insertVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNfsDataModel) GetVolume(name string) (nfs.NfsVolume, bool, error) {
+	fake.getVolumeMutex.Lock()
+	ret, specificReturn := fake.getVolumeReturnsOnCall[len(fake.getVolumeArgsForCall)]
+	fake.getVolumeArgsForCall = append(fake.getVolumeArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("GetVolume", []interface{}{name})
+	fake.getVolumeMutex.Unlock()
+	if fake.GetVolumeStub != nil {
+		return fake.GetVolumeStub(name)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.getVolumeReturns.result1, fake.getVolumeReturns.result2, fake.getVolumeReturns.result3
+}
+
+func (fake *FakeNfsDataModel) GetVolumeCallCount() int {
+	fake.getVolumeMutex.RLock()
+	defer fake.getVolumeMutex.RUnlock()
+	return len(fake.getVolumeArgsForCall)
+}
+
+func (fake *FakeNfsDataModel) GetVolumeArgsForCall(i int) string {
+	fake.getVolumeMutex.RLock()
+	defer fake.getVolumeMutex.RUnlock()
+	return fake.getVolumeArgsForCall[i].name
+}
+
+func (fake *FakeNfsDataModel) GetVolumeReturns(result1 nfs.NfsVolume, result2 bool, result3 error) {
+	fake.GetVolumeStub = nil
+	fake.getVolumeReturns = struct {
+		result1 nfs.NfsVolume
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeNfsDataModel) GetVolumeReturnsOnCall(i int, result1 nfs.NfsVolume, result2 bool, result3 error) {
+	fake.GetVolumeStub = nil
+	if fake.getVolumeReturnsOnCall == nil {
+		fake.getVolumeReturnsOnCall = make(map[int]struct {
+			result1 nfs.NfsVolume
+			result2 bool
+			result3 error
+		})
+	}
+	fake.getVolumeReturnsOnCall[i] = struct {
+		result1 nfs.NfsVolume
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeNfsDataModel) ListVolumes() ([]resources.Volume, error) {
+	fake.listVolumesMutex.Lock()
+	ret, specificReturn := fake.listVolumesReturnsOnCall[len(fake.listVolumesArgsForCall)]
+	fake.listVolumesArgsForCall = append(fake.listVolumesArgsForCall, struct{}{})
+	fake.recordInvocation("ListVolumes", []interface{}{})
+	fake.listVolumesMutex.Unlock()
+	if fake.ListVolumesStub != nil {
+		return fake.ListVolumesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listVolumesReturns.result1, fake.listVolumesReturns.result2
+}
+
+func (fake *FakeNfsDataModel) ListVolumesCallCount() int {
+	fake.listVolumesMutex.RLock()
+	defer fake.listVolumesMutex.RUnlock()
+	return len(fake.listVolumesArgsForCall)
+}
+
+func (fake *FakeNfsDataModel) ListVolumesReturns(result1 []resources.Volume, result2 error) {
+	fake.ListVolumesStub = nil
+	fake.listVolumesReturns = struct {
+		result1 []resources.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNfsDataModel) ListVolumesReturnsOnCall(i int, result1 []resources.Volume, result2 error) {
+	fake.ListVolumesStub = nil
+	if fake.listVolumesReturnsOnCall == nil {
+		fake.listVolumesReturnsOnCall = make(map[int]struct {
+			result1 []resources.Volume
+			result2 error
+		})
+	}
+	fake.listVolumesReturnsOnCall[i] = struct {
+		result1 []resources.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNfsDataModel) GetMaxProjectId() (uint32, error) {
+	fake.getMaxProjectIdMutex.Lock()
+	ret, specificReturn := fake.getMaxProjectIdReturnsOnCall[len(fake.getMaxProjectIdArgsForCall)]
+	fake.getMaxProjectIdArgsForCall = append(fake.getMaxProjectIdArgsForCall, struct{}{})
+	fake.recordInvocation("GetMaxProjectId", []interface{}{})
+	fake.getMaxProjectIdMutex.Unlock()
+	if fake.GetMaxProjectIdStub != nil {
+		return fake.GetMaxProjectIdStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getMaxProjectIdReturns.result1, fake.getMaxProjectIdReturns.result2
+}
+
+func (fake *FakeNfsDataModel) GetMaxProjectIdCallCount() int {
+	fake.getMaxProjectIdMutex.RLock()
+	defer fake.getMaxProjectIdMutex.RUnlock()
+	return len(fake.getMaxProjectIdArgsForCall)
+}
+
+func (fake *FakeNfsDataModel) GetMaxProjectIdReturns(result1 uint32, result2 error) {
+	fake.GetMaxProjectIdStub = nil
+	fake.getMaxProjectIdReturns = struct {
+		result1 uint32
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNfsDataModel) GetMaxProjectIdReturnsOnCall(i int, result1 uint32, result2 error) {
+	fake.GetMaxProjectIdStub = nil
+	if fake.getMaxProjectIdReturnsOnCall == nil {
+		fake.getMaxProjectIdReturnsOnCall = make(map[int]struct {
+			result1 uint32
+			result2 error
+		})
+	}
+	fake.getMaxProjectIdReturnsOnCall[i] = struct {
+		result1 uint32
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNfsDataModel) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createVolumeTableMutex.RLock()
+	defer fake.createVolumeTableMutex.RUnlock()
+	fake.deleteVolumeMutex.RLock()
+	defer fake.deleteVolumeMutex.RUnlock()
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	fake.getVolumeMutex.RLock()
+	defer fake.getVolumeMutex.RUnlock()
+	fake.listVolumesMutex.RLock()
+	defer fake.listVolumesMutex.RUnlock()
+	fake.getMaxProjectIdMutex.RLock()
+	defer fake.getMaxProjectIdMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeNfsDataModel) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ nfs.NfsDataModel = new(FakeNfsDataModel)
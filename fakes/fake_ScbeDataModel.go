@@ -19,19 +19,6 @@ type FakeScbeDataModel struct {
 	deleteVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
-	InsertVolumeStub        func(volumeName string, wwn string, fstype string) error
-	insertVolumeMutex       sync.RWMutex
-	insertVolumeArgsForCall []struct {
-		volumeName string
-		wwn        string
-		fstype     string
-	}
-	insertVolumeReturns struct {
-		result1 error
-	}
-	insertVolumeReturnsOnCall map[int]struct {
-		result1 error
-	}
 	GetVolumeStub        func(name string) (scbe.ScbeVolume, bool, error)
 	getVolumeMutex       sync.RWMutex
 	getVolumeArgsForCall []struct {
@@ -58,6 +45,52 @@ type FakeScbeDataModel struct {
 		result1 []scbe.ScbeVolume
 		result2 error
 	}
+	InsertVolumeStub        func(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options scbe.ScbeVolumeOptions) error
+	insertVolumeMutex       sync.RWMutex
+	insertVolumeArgsForCall []struct {
+		volumeName    string
+		wwn           string
+		fstype        string
+		size          int
+		requestedSize string
+		accessMode    string
+		isPreexisting bool
+		options       scbe.ScbeVolumeOptions
+	}
+	insertVolumeReturns struct {
+		result1 error
+	}
+	insertVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateHostReportStub        func(host string, volumes []string, wwns []string) error
+	updateHostReportMutex       sync.RWMutex
+	updateHostReportArgsForCall []struct {
+		host    string
+		volumes []string
+		wwns    []string
+	}
+	updateHostReportReturns struct {
+		result1 error
+	}
+	updateHostReportReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetHostReportStub        func(host string) (scbe.ScbeHostReport, bool, error)
+	getHostReportMutex       sync.RWMutex
+	getHostReportArgsForCall []struct {
+		host string
+	}
+	getHostReportReturns struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}
+	getHostReportReturnsOnCall map[int]struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -110,56 +143,6 @@ func (fake *FakeScbeDataModel) DeleteVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeScbeDataModel) InsertVolume(volumeName string, wwn string, fstype string) error {
-	fake.insertVolumeMutex.Lock()
-	ret, specificReturn := fake.insertVolumeReturnsOnCall[len(fake.insertVolumeArgsForCall)]
-	fake.insertVolumeArgsForCall = append(fake.insertVolumeArgsForCall, struct {
-		volumeName string
-		wwn        string
-		fstype     string
-	}{volumeName, wwn, fstype})
-	fake.recordInvocation("InsertVolume", []interface{}{volumeName, wwn, fstype})
-	fake.insertVolumeMutex.Unlock()
-	if fake.InsertVolumeStub != nil {
-		return fake.InsertVolumeStub(volumeName, wwn, fstype)
-	}
-	if specificReturn {
-		return ret.result1
-	}
-	return fake.insertVolumeReturns.result1
-}
-
-func (fake *FakeScbeDataModel) InsertVolumeCallCount() int {
-	fake.insertVolumeMutex.RLock()
-	defer fake.insertVolumeMutex.RUnlock()
-	return len(fake.insertVolumeArgsForCall)
-}
-
-func (fake *FakeScbeDataModel) InsertVolumeArgsForCall(i int) (string, string, string) {
-	fake.insertVolumeMutex.RLock()
-	defer fake.insertVolumeMutex.RUnlock()
-	return fake.insertVolumeArgsForCall[i].volumeName, fake.insertVolumeArgsForCall[i].wwn, fake.insertVolumeArgsForCall[i].fstype
-}
-
-func (fake *FakeScbeDataModel) InsertVolumeReturns(result1 error) {
-	fake.InsertVolumeStub = nil
-	fake.insertVolumeReturns = struct {
-		result1 error
-	}{result1}
-}
-
-func (fake *FakeScbeDataModel) InsertVolumeReturnsOnCall(i int, result1 error) {
-	fake.InsertVolumeStub = nil
-	if fake.insertVolumeReturnsOnCall == nil {
-		fake.insertVolumeReturnsOnCall = make(map[int]struct {
-			result1 error
-		})
-	}
-	fake.insertVolumeReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
-}
-
 func (fake *FakeScbeDataModel) GetVolume(name string) (scbe.ScbeVolume, bool, error) {
 	fake.getVolumeMutex.Lock()
 	ret, specificReturn := fake.getVolumeReturnsOnCall[len(fake.getVolumeArgsForCall)]
@@ -257,17 +240,190 @@ func (fake *FakeScbeDataModel) ListVolumesReturnsOnCall(i int, result1 []scbe.Sc
 	}{result1, result2}
 }
 
+func (fake *FakeScbeDataModel) InsertVolume(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options scbe.ScbeVolumeOptions) error {
+	fake.insertVolumeMutex.Lock()
+	ret, specificReturn := fake.insertVolumeReturnsOnCall[len(fake.insertVolumeArgsForCall)]
+	fake.insertVolumeArgsForCall = append(fake.insertVolumeArgsForCall, struct {
+		volumeName    string
+		wwn           string
+		fstype        string
+		size          int
+		requestedSize string
+		accessMode    string
+		isPreexisting bool
+		options       scbe.ScbeVolumeOptions
+	}{volumeName, wwn, fstype, size, requestedSize, accessMode, isPreexisting, options})
+	fake.recordInvocation("InsertVolume", []interface{}{volumeName, wwn, fstype, size, requestedSize, accessMode, isPreexisting, options})
+	fake.insertVolumeMutex.Unlock()
+	if fake.InsertVolumeStub != nil {
+		return fake.InsertVolumeStub(volumeName, wwn, fstype, size, requestedSize, accessMode, isPreexisting, options)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.insertVolumeReturns.result1
+}
+
+func (fake *FakeScbeDataModel) InsertVolumeCallCount() int {
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	return len(fake.insertVolumeArgsForCall)
+}
+
+func (fake *FakeScbeDataModel) InsertVolumeArgsForCall(i int) (string, string, string, int, string, string, bool, scbe.ScbeVolumeOptions) {
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	return fake.insertVolumeArgsForCall[i].volumeName, fake.insertVolumeArgsForCall[i].wwn, fake.insertVolumeArgsForCall[i].fstype, fake.insertVolumeArgsForCall[i].size, fake.insertVolumeArgsForCall[i].requestedSize, fake.insertVolumeArgsForCall[i].accessMode, fake.insertVolumeArgsForCall[i].isPreexisting, fake.insertVolumeArgsForCall[i].options
+}
+
+func (fake *FakeScbeDataModel) InsertVolumeReturns(result1 error) {
+	fake.InsertVolumeStub = nil
+	fake.insertVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModel) InsertVolumeReturnsOnCall(i int, result1 error) {
+	fake.InsertVolumeStub = nil
+	if fake.insertVolumeReturnsOnCall == nil {
+		fake.insertVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.insertVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModel) UpdateHostReport(host string, volumes []string, wwns []string) error {
+	var volumesCopy []string
+	if volumes != nil {
+		volumesCopy = make([]string, len(volumes))
+		copy(volumesCopy, volumes)
+	}
+	var wwnsCopy []string
+	if wwns != nil {
+		wwnsCopy = make([]string, len(wwns))
+		copy(wwnsCopy, wwns)
+	}
+	fake.updateHostReportMutex.Lock()
+	ret, specificReturn := fake.updateHostReportReturnsOnCall[len(fake.updateHostReportArgsForCall)]
+	fake.updateHostReportArgsForCall = append(fake.updateHostReportArgsForCall, struct {
+		host    string
+		volumes []string
+		wwns    []string
+	}{host, volumesCopy, wwnsCopy})
+	fake.recordInvocation("UpdateHostReport", []interface{}{host, volumesCopy, wwnsCopy})
+	fake.updateHostReportMutex.Unlock()
+	if fake.UpdateHostReportStub != nil {
+		return fake.UpdateHostReportStub(host, volumes, wwns)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateHostReportReturns.result1
+}
+
+func (fake *FakeScbeDataModel) UpdateHostReportCallCount() int {
+	fake.updateHostReportMutex.RLock()
+	defer fake.updateHostReportMutex.RUnlock()
+	return len(fake.updateHostReportArgsForCall)
+}
+
+func (fake *FakeScbeDataModel) UpdateHostReportArgsForCall(i int) (string, []string, []string) {
+	fake.updateHostReportMutex.RLock()
+	defer fake.updateHostReportMutex.RUnlock()
+	return fake.updateHostReportArgsForCall[i].host, fake.updateHostReportArgsForCall[i].volumes, fake.updateHostReportArgsForCall[i].wwns
+}
+
+func (fake *FakeScbeDataModel) UpdateHostReportReturns(result1 error) {
+	fake.UpdateHostReportStub = nil
+	fake.updateHostReportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModel) UpdateHostReportReturnsOnCall(i int, result1 error) {
+	fake.UpdateHostReportStub = nil
+	if fake.updateHostReportReturnsOnCall == nil {
+		fake.updateHostReportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateHostReportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModel) GetHostReport(host string) (scbe.ScbeHostReport, bool, error) {
+	fake.getHostReportMutex.Lock()
+	ret, specificReturn := fake.getHostReportReturnsOnCall[len(fake.getHostReportArgsForCall)]
+	fake.getHostReportArgsForCall = append(fake.getHostReportArgsForCall, struct {
+		host string
+	}{host})
+	fake.recordInvocation("GetHostReport", []interface{}{host})
+	fake.getHostReportMutex.Unlock()
+	if fake.GetHostReportStub != nil {
+		return fake.GetHostReportStub(host)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.getHostReportReturns.result1, fake.getHostReportReturns.result2, fake.getHostReportReturns.result3
+}
+
+func (fake *FakeScbeDataModel) GetHostReportCallCount() int {
+	fake.getHostReportMutex.RLock()
+	defer fake.getHostReportMutex.RUnlock()
+	return len(fake.getHostReportArgsForCall)
+}
+
+func (fake *FakeScbeDataModel) GetHostReportArgsForCall(i int) string {
+	fake.getHostReportMutex.RLock()
+	defer fake.getHostReportMutex.RUnlock()
+	return fake.getHostReportArgsForCall[i].host
+}
+
+func (fake *FakeScbeDataModel) GetHostReportReturns(result1 scbe.ScbeHostReport, result2 bool, result3 error) {
+	fake.GetHostReportStub = nil
+	fake.getHostReportReturns = struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeScbeDataModel) GetHostReportReturnsOnCall(i int, result1 scbe.ScbeHostReport, result2 bool, result3 error) {
+	fake.GetHostReportStub = nil
+	if fake.getHostReportReturnsOnCall == nil {
+		fake.getHostReportReturnsOnCall = make(map[int]struct {
+			result1 scbe.ScbeHostReport
+			result2 bool
+			result3 error
+		})
+	}
+	fake.getHostReportReturnsOnCall[i] = struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeScbeDataModel) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.deleteVolumeMutex.RLock()
 	defer fake.deleteVolumeMutex.RUnlock()
-	fake.insertVolumeMutex.RLock()
-	defer fake.insertVolumeMutex.RUnlock()
 	fake.getVolumeMutex.RLock()
 	defer fake.getVolumeMutex.RUnlock()
 	fake.listVolumesMutex.RLock()
 	defer fake.listVolumesMutex.RUnlock()
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	fake.updateHostReportMutex.RLock()
+	defer fake.updateHostReportMutex.RUnlock()
+	fake.getHostReportMutex.RLock()
+	defer fake.getHostReportMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
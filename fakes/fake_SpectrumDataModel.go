@@ -144,6 +144,113 @@ type FakeSpectrumDataModel struct {
 	updateVolumeMountpointReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UpdateVolumeQuotaStub        func(name string, quota string) error
+	updateVolumeQuotaMutex       sync.RWMutex
+	updateVolumeQuotaArgsForCall []struct {
+		name  string
+		quota string
+	}
+	updateVolumeQuotaReturns struct {
+		result1 error
+	}
+	updateVolumeQuotaReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InsertLightweightQuotaVolumeStub        func(fileset string, directory string, quotaFileset string, quota string, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error
+	insertLightweightQuotaVolumeMutex       sync.RWMutex
+	insertLightweightQuotaVolumeArgsForCall []struct {
+		fileset       string
+		directory     string
+		quotaFileset  string
+		quota         string
+		volumeName    string
+		filesystem    string
+		isPreexisting bool
+		opts          map[string]interface{}
+	}
+	insertLightweightQuotaVolumeReturns struct {
+		result1 error
+	}
+	insertLightweightQuotaVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InsertAttachmentStub        func(name string, host string) error
+	insertAttachmentMutex       sync.RWMutex
+	insertAttachmentArgsForCall []struct {
+		name string
+		host string
+	}
+	insertAttachmentReturns struct {
+		result1 error
+	}
+	insertAttachmentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteAttachmentStub        func(name string, host string) error
+	deleteAttachmentMutex       sync.RWMutex
+	deleteAttachmentArgsForCall []struct {
+		name string
+		host string
+	}
+	deleteAttachmentReturns struct {
+		result1 error
+	}
+	deleteAttachmentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListAttachmentsStub        func(name string) ([]spectrumscale.SpectrumScaleAttachment, error)
+	listAttachmentsMutex       sync.RWMutex
+	listAttachmentsArgsForCall []struct {
+		name string
+	}
+	listAttachmentsReturns struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}
+	listAttachmentsReturnsOnCall map[int]struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}
+	ListFilesetAttachmentsStub        func(filesystem string, fileset string) ([]spectrumscale.SpectrumScaleAttachment, error)
+	listFilesetAttachmentsMutex       sync.RWMutex
+	listFilesetAttachmentsArgsForCall []struct {
+		filesystem string
+		fileset    string
+	}
+	listFilesetAttachmentsReturns struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}
+	listFilesetAttachmentsReturnsOnCall map[int]struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}
+	UpdateVolumeNfsClientConfigStub        func(name string, nfsClientConfig string) error
+	updateVolumeNfsClientConfigMutex       sync.RWMutex
+	updateVolumeNfsClientConfigArgsForCall []struct {
+		name            string
+		nfsClientConfig string
+	}
+	updateVolumeNfsClientConfigReturns struct {
+		result1 error
+	}
+	updateVolumeNfsClientConfigReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListFilesetVolumesStub        func(filesystem string, fileset string) ([]spectrumscale.SpectrumScaleVolume, error)
+	listFilesetVolumesMutex       sync.RWMutex
+	listFilesetVolumesArgsForCall []struct {
+		filesystem string
+		fileset    string
+	}
+	listFilesetVolumesReturns struct {
+		result1 []spectrumscale.SpectrumScaleVolume
+		result2 error
+	}
+	listFilesetVolumesReturnsOnCall map[int]struct {
+		result1 []spectrumscale.SpectrumScaleVolume
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -604,6 +711,412 @@ func (fake *FakeSpectrumDataModel) UpdateVolumeMountpointReturnsOnCall(i int, re
 	}{result1}
 }
 
+func (fake *FakeSpectrumDataModel) UpdateVolumeQuota(name string, quota string) error {
+	fake.updateVolumeQuotaMutex.Lock()
+	ret, specificReturn := fake.updateVolumeQuotaReturnsOnCall[len(fake.updateVolumeQuotaArgsForCall)]
+	fake.updateVolumeQuotaArgsForCall = append(fake.updateVolumeQuotaArgsForCall, struct {
+		name  string
+		quota string
+	}{name, quota})
+	fake.recordInvocation("UpdateVolumeQuota", []interface{}{name, quota})
+	fake.updateVolumeQuotaMutex.Unlock()
+	if fake.UpdateVolumeQuotaStub != nil {
+		return fake.UpdateVolumeQuotaStub(name, quota)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateVolumeQuotaReturns.result1
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeQuotaCallCount() int {
+	fake.updateVolumeQuotaMutex.RLock()
+	defer fake.updateVolumeQuotaMutex.RUnlock()
+	return len(fake.updateVolumeQuotaArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeQuotaArgsForCall(i int) (string, string) {
+	fake.updateVolumeQuotaMutex.RLock()
+	defer fake.updateVolumeQuotaMutex.RUnlock()
+	return fake.updateVolumeQuotaArgsForCall[i].name, fake.updateVolumeQuotaArgsForCall[i].quota
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeQuotaReturns(result1 error) {
+	fake.UpdateVolumeQuotaStub = nil
+	fake.updateVolumeQuotaReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeQuotaReturnsOnCall(i int, result1 error) {
+	fake.UpdateVolumeQuotaStub = nil
+	if fake.updateVolumeQuotaReturnsOnCall == nil {
+		fake.updateVolumeQuotaReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateVolumeQuotaReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) InsertLightweightQuotaVolume(fileset string, directory string, quotaFileset string, quota string, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error {
+	fake.insertLightweightQuotaVolumeMutex.Lock()
+	ret, specificReturn := fake.insertLightweightQuotaVolumeReturnsOnCall[len(fake.insertLightweightQuotaVolumeArgsForCall)]
+	fake.insertLightweightQuotaVolumeArgsForCall = append(fake.insertLightweightQuotaVolumeArgsForCall, struct {
+		fileset       string
+		directory     string
+		quotaFileset  string
+		quota         string
+		volumeName    string
+		filesystem    string
+		isPreexisting bool
+		opts          map[string]interface{}
+	}{fileset, directory, quotaFileset, quota, volumeName, filesystem, isPreexisting, opts})
+	fake.recordInvocation("InsertLightweightQuotaVolume", []interface{}{fileset, directory, quotaFileset, quota, volumeName, filesystem, isPreexisting, opts})
+	fake.insertLightweightQuotaVolumeMutex.Unlock()
+	if fake.InsertLightweightQuotaVolumeStub != nil {
+		return fake.InsertLightweightQuotaVolumeStub(fileset, directory, quotaFileset, quota, volumeName, filesystem, isPreexisting, opts)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.insertLightweightQuotaVolumeReturns.result1
+}
+
+func (fake *FakeSpectrumDataModel) InsertLightweightQuotaVolumeCallCount() int {
+	fake.insertLightweightQuotaVolumeMutex.RLock()
+	defer fake.insertLightweightQuotaVolumeMutex.RUnlock()
+	return len(fake.insertLightweightQuotaVolumeArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) InsertLightweightQuotaVolumeArgsForCall(i int) (string, string, string, string, string, string, bool, map[string]interface{}) {
+	fake.insertLightweightQuotaVolumeMutex.RLock()
+	defer fake.insertLightweightQuotaVolumeMutex.RUnlock()
+	return fake.insertLightweightQuotaVolumeArgsForCall[i].fileset, fake.insertLightweightQuotaVolumeArgsForCall[i].directory, fake.insertLightweightQuotaVolumeArgsForCall[i].quotaFileset, fake.insertLightweightQuotaVolumeArgsForCall[i].quota, fake.insertLightweightQuotaVolumeArgsForCall[i].volumeName, fake.insertLightweightQuotaVolumeArgsForCall[i].filesystem, fake.insertLightweightQuotaVolumeArgsForCall[i].isPreexisting, fake.insertLightweightQuotaVolumeArgsForCall[i].opts
+}
+
+func (fake *FakeSpectrumDataModel) InsertLightweightQuotaVolumeReturns(result1 error) {
+	fake.InsertLightweightQuotaVolumeStub = nil
+	fake.insertLightweightQuotaVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) InsertLightweightQuotaVolumeReturnsOnCall(i int, result1 error) {
+	fake.InsertLightweightQuotaVolumeStub = nil
+	if fake.insertLightweightQuotaVolumeReturnsOnCall == nil {
+		fake.insertLightweightQuotaVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.insertLightweightQuotaVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) InsertAttachment(name string, host string) error {
+	fake.insertAttachmentMutex.Lock()
+	ret, specificReturn := fake.insertAttachmentReturnsOnCall[len(fake.insertAttachmentArgsForCall)]
+	fake.insertAttachmentArgsForCall = append(fake.insertAttachmentArgsForCall, struct {
+		name string
+		host string
+	}{name, host})
+	fake.recordInvocation("InsertAttachment", []interface{}{name, host})
+	fake.insertAttachmentMutex.Unlock()
+	if fake.InsertAttachmentStub != nil {
+		return fake.InsertAttachmentStub(name, host)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.insertAttachmentReturns.result1
+}
+
+func (fake *FakeSpectrumDataModel) InsertAttachmentCallCount() int {
+	fake.insertAttachmentMutex.RLock()
+	defer fake.insertAttachmentMutex.RUnlock()
+	return len(fake.insertAttachmentArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) InsertAttachmentArgsForCall(i int) (string, string) {
+	fake.insertAttachmentMutex.RLock()
+	defer fake.insertAttachmentMutex.RUnlock()
+	return fake.insertAttachmentArgsForCall[i].name, fake.insertAttachmentArgsForCall[i].host
+}
+
+func (fake *FakeSpectrumDataModel) InsertAttachmentReturns(result1 error) {
+	fake.InsertAttachmentStub = nil
+	fake.insertAttachmentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) InsertAttachmentReturnsOnCall(i int, result1 error) {
+	fake.InsertAttachmentStub = nil
+	if fake.insertAttachmentReturnsOnCall == nil {
+		fake.insertAttachmentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.insertAttachmentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) DeleteAttachment(name string, host string) error {
+	fake.deleteAttachmentMutex.Lock()
+	ret, specificReturn := fake.deleteAttachmentReturnsOnCall[len(fake.deleteAttachmentArgsForCall)]
+	fake.deleteAttachmentArgsForCall = append(fake.deleteAttachmentArgsForCall, struct {
+		name string
+		host string
+	}{name, host})
+	fake.recordInvocation("DeleteAttachment", []interface{}{name, host})
+	fake.deleteAttachmentMutex.Unlock()
+	if fake.DeleteAttachmentStub != nil {
+		return fake.DeleteAttachmentStub(name, host)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.deleteAttachmentReturns.result1
+}
+
+func (fake *FakeSpectrumDataModel) DeleteAttachmentCallCount() int {
+	fake.deleteAttachmentMutex.RLock()
+	defer fake.deleteAttachmentMutex.RUnlock()
+	return len(fake.deleteAttachmentArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) DeleteAttachmentArgsForCall(i int) (string, string) {
+	fake.deleteAttachmentMutex.RLock()
+	defer fake.deleteAttachmentMutex.RUnlock()
+	return fake.deleteAttachmentArgsForCall[i].name, fake.deleteAttachmentArgsForCall[i].host
+}
+
+func (fake *FakeSpectrumDataModel) DeleteAttachmentReturns(result1 error) {
+	fake.DeleteAttachmentStub = nil
+	fake.deleteAttachmentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) DeleteAttachmentReturnsOnCall(i int, result1 error) {
+	fake.DeleteAttachmentStub = nil
+	if fake.deleteAttachmentReturnsOnCall == nil {
+		fake.deleteAttachmentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteAttachmentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) ListAttachments(name string) ([]spectrumscale.SpectrumScaleAttachment, error) {
+	fake.listAttachmentsMutex.Lock()
+	ret, specificReturn := fake.listAttachmentsReturnsOnCall[len(fake.listAttachmentsArgsForCall)]
+	fake.listAttachmentsArgsForCall = append(fake.listAttachmentsArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("ListAttachments", []interface{}{name})
+	fake.listAttachmentsMutex.Unlock()
+	if fake.ListAttachmentsStub != nil {
+		return fake.ListAttachmentsStub(name)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listAttachmentsReturns.result1, fake.listAttachmentsReturns.result2
+}
+
+func (fake *FakeSpectrumDataModel) ListAttachmentsCallCount() int {
+	fake.listAttachmentsMutex.RLock()
+	defer fake.listAttachmentsMutex.RUnlock()
+	return len(fake.listAttachmentsArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) ListAttachmentsArgsForCall(i int) string {
+	fake.listAttachmentsMutex.RLock()
+	defer fake.listAttachmentsMutex.RUnlock()
+	return fake.listAttachmentsArgsForCall[i].name
+}
+
+func (fake *FakeSpectrumDataModel) ListAttachmentsReturns(result1 []spectrumscale.SpectrumScaleAttachment, result2 error) {
+	fake.ListAttachmentsStub = nil
+	fake.listAttachmentsReturns = struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumDataModel) ListAttachmentsReturnsOnCall(i int, result1 []spectrumscale.SpectrumScaleAttachment, result2 error) {
+	fake.ListAttachmentsStub = nil
+	if fake.listAttachmentsReturnsOnCall == nil {
+		fake.listAttachmentsReturnsOnCall = make(map[int]struct {
+			result1 []spectrumscale.SpectrumScaleAttachment
+			result2 error
+		})
+	}
+	fake.listAttachmentsReturnsOnCall[i] = struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetAttachments(filesystem string, fileset string) ([]spectrumscale.SpectrumScaleAttachment, error) {
+	fake.listFilesetAttachmentsMutex.Lock()
+	ret, specificReturn := fake.listFilesetAttachmentsReturnsOnCall[len(fake.listFilesetAttachmentsArgsForCall)]
+	fake.listFilesetAttachmentsArgsForCall = append(fake.listFilesetAttachmentsArgsForCall, struct {
+		filesystem string
+		fileset    string
+	}{filesystem, fileset})
+	fake.recordInvocation("ListFilesetAttachments", []interface{}{filesystem, fileset})
+	fake.listFilesetAttachmentsMutex.Unlock()
+	if fake.ListFilesetAttachmentsStub != nil {
+		return fake.ListFilesetAttachmentsStub(filesystem, fileset)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listFilesetAttachmentsReturns.result1, fake.listFilesetAttachmentsReturns.result2
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetAttachmentsCallCount() int {
+	fake.listFilesetAttachmentsMutex.RLock()
+	defer fake.listFilesetAttachmentsMutex.RUnlock()
+	return len(fake.listFilesetAttachmentsArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetAttachmentsArgsForCall(i int) (string, string) {
+	fake.listFilesetAttachmentsMutex.RLock()
+	defer fake.listFilesetAttachmentsMutex.RUnlock()
+	return fake.listFilesetAttachmentsArgsForCall[i].filesystem, fake.listFilesetAttachmentsArgsForCall[i].fileset
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetAttachmentsReturns(result1 []spectrumscale.SpectrumScaleAttachment, result2 error) {
+	fake.ListFilesetAttachmentsStub = nil
+	fake.listFilesetAttachmentsReturns = struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetAttachmentsReturnsOnCall(i int, result1 []spectrumscale.SpectrumScaleAttachment, result2 error) {
+	fake.ListFilesetAttachmentsStub = nil
+	if fake.listFilesetAttachmentsReturnsOnCall == nil {
+		fake.listFilesetAttachmentsReturnsOnCall = make(map[int]struct {
+			result1 []spectrumscale.SpectrumScaleAttachment
+			result2 error
+		})
+	}
+	fake.listFilesetAttachmentsReturnsOnCall[i] = struct {
+		result1 []spectrumscale.SpectrumScaleAttachment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeNfsClientConfig(name string, nfsClientConfig string) error {
+	fake.updateVolumeNfsClientConfigMutex.Lock()
+	ret, specificReturn := fake.updateVolumeNfsClientConfigReturnsOnCall[len(fake.updateVolumeNfsClientConfigArgsForCall)]
+	fake.updateVolumeNfsClientConfigArgsForCall = append(fake.updateVolumeNfsClientConfigArgsForCall, struct {
+		name            string
+		nfsClientConfig string
+	}{name, nfsClientConfig})
+	fake.recordInvocation("UpdateVolumeNfsClientConfig", []interface{}{name, nfsClientConfig})
+	fake.updateVolumeNfsClientConfigMutex.Unlock()
+	if fake.UpdateVolumeNfsClientConfigStub != nil {
+		return fake.UpdateVolumeNfsClientConfigStub(name, nfsClientConfig)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateVolumeNfsClientConfigReturns.result1
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeNfsClientConfigCallCount() int {
+	fake.updateVolumeNfsClientConfigMutex.RLock()
+	defer fake.updateVolumeNfsClientConfigMutex.RUnlock()
+	return len(fake.updateVolumeNfsClientConfigArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeNfsClientConfigArgsForCall(i int) (string, string) {
+	fake.updateVolumeNfsClientConfigMutex.RLock()
+	defer fake.updateVolumeNfsClientConfigMutex.RUnlock()
+	return fake.updateVolumeNfsClientConfigArgsForCall[i].name, fake.updateVolumeNfsClientConfigArgsForCall[i].nfsClientConfig
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeNfsClientConfigReturns(result1 error) {
+	fake.UpdateVolumeNfsClientConfigStub = nil
+	fake.updateVolumeNfsClientConfigReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) UpdateVolumeNfsClientConfigReturnsOnCall(i int, result1 error) {
+	fake.UpdateVolumeNfsClientConfigStub = nil
+	if fake.updateVolumeNfsClientConfigReturnsOnCall == nil {
+		fake.updateVolumeNfsClientConfigReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateVolumeNfsClientConfigReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetVolumes(filesystem string, fileset string) ([]spectrumscale.SpectrumScaleVolume, error) {
+	fake.listFilesetVolumesMutex.Lock()
+	ret, specificReturn := fake.listFilesetVolumesReturnsOnCall[len(fake.listFilesetVolumesArgsForCall)]
+	fake.listFilesetVolumesArgsForCall = append(fake.listFilesetVolumesArgsForCall, struct {
+		filesystem string
+		fileset    string
+	}{filesystem, fileset})
+	fake.recordInvocation("ListFilesetVolumes", []interface{}{filesystem, fileset})
+	fake.listFilesetVolumesMutex.Unlock()
+	if fake.ListFilesetVolumesStub != nil {
+		return fake.ListFilesetVolumesStub(filesystem, fileset)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listFilesetVolumesReturns.result1, fake.listFilesetVolumesReturns.result2
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetVolumesCallCount() int {
+	fake.listFilesetVolumesMutex.RLock()
+	defer fake.listFilesetVolumesMutex.RUnlock()
+	return len(fake.listFilesetVolumesArgsForCall)
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetVolumesArgsForCall(i int) (string, string) {
+	fake.listFilesetVolumesMutex.RLock()
+	defer fake.listFilesetVolumesMutex.RUnlock()
+	return fake.listFilesetVolumesArgsForCall[i].filesystem, fake.listFilesetVolumesArgsForCall[i].fileset
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetVolumesReturns(result1 []spectrumscale.SpectrumScaleVolume, result2 error) {
+	fake.ListFilesetVolumesStub = nil
+	fake.listFilesetVolumesReturns = struct {
+		result1 []spectrumscale.SpectrumScaleVolume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumDataModel) ListFilesetVolumesReturnsOnCall(i int, result1 []spectrumscale.SpectrumScaleVolume, result2 error) {
+	fake.ListFilesetVolumesStub = nil
+	if fake.listFilesetVolumesReturnsOnCall == nil {
+		fake.listFilesetVolumesReturnsOnCall = make(map[int]struct {
+			result1 []spectrumscale.SpectrumScaleVolume
+			result2 error
+		})
+	}
+	fake.listFilesetVolumesReturnsOnCall[i] = struct {
+		result1 []spectrumscale.SpectrumScaleVolume
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeSpectrumDataModel) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -627,6 +1140,22 @@ func (fake *FakeSpectrumDataModel) Invocations() map[string][][]interface{} {
 	defer fake.listVolumesMutex.RUnlock()
 	fake.updateVolumeMountpointMutex.RLock()
 	defer fake.updateVolumeMountpointMutex.RUnlock()
+	fake.updateVolumeQuotaMutex.RLock()
+	defer fake.updateVolumeQuotaMutex.RUnlock()
+	fake.insertLightweightQuotaVolumeMutex.RLock()
+	defer fake.insertLightweightQuotaVolumeMutex.RUnlock()
+	fake.insertAttachmentMutex.RLock()
+	defer fake.insertAttachmentMutex.RUnlock()
+	fake.deleteAttachmentMutex.RLock()
+	defer fake.deleteAttachmentMutex.RUnlock()
+	fake.listAttachmentsMutex.RLock()
+	defer fake.listAttachmentsMutex.RUnlock()
+	fake.listFilesetAttachmentsMutex.RLock()
+	defer fake.listFilesetAttachmentsMutex.RUnlock()
+	fake.updateVolumeNfsClientConfigMutex.RLock()
+	defer fake.updateVolumeNfsClientConfigMutex.RUnlock()
+	fake.listFilesetVolumesMutex.RLock()
+	defer fake.listFilesetVolumesMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -33,19 +33,6 @@ type FakeScbeDataModelWrapper struct {
 	deleteVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
-	InsertVolumeStub        func(volumeName string, wwn string, fstype string) error
-	insertVolumeMutex       sync.RWMutex
-	insertVolumeArgsForCall []struct {
-		volumeName string
-		wwn        string
-		fstype     string
-	}
-	insertVolumeReturns struct {
-		result1 error
-	}
-	insertVolumeReturnsOnCall map[int]struct {
-		result1 error
-	}
 	ListVolumesStub        func() ([]scbe.ScbeVolume, error)
 	listVolumesMutex       sync.RWMutex
 	listVolumesArgsForCall []struct{}
@@ -62,6 +49,52 @@ type FakeScbeDataModelWrapper struct {
 	updateDatabaseVolumeArgsForCall []struct {
 		newVolume *scbe.ScbeVolume
 	}
+	InsertVolumeStub        func(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options scbe.ScbeVolumeOptions) error
+	insertVolumeMutex       sync.RWMutex
+	insertVolumeArgsForCall []struct {
+		volumeName    string
+		wwn           string
+		fstype        string
+		size          int
+		requestedSize string
+		accessMode    string
+		isPreexisting bool
+		options       scbe.ScbeVolumeOptions
+	}
+	insertVolumeReturns struct {
+		result1 error
+	}
+	insertVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateHostReportStub        func(host string, volumes []string, wwns []string) error
+	updateHostReportMutex       sync.RWMutex
+	updateHostReportArgsForCall []struct {
+		host    string
+		volumes []string
+		wwns    []string
+	}
+	updateHostReportReturns struct {
+		result1 error
+	}
+	updateHostReportReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetHostReportStub        func(host string) (scbe.ScbeHostReport, bool, error)
+	getHostReportMutex       sync.RWMutex
+	getHostReportArgsForCall []struct {
+		host string
+	}
+	getHostReportReturns struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}
+	getHostReportReturnsOnCall map[int]struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -166,56 +199,6 @@ func (fake *FakeScbeDataModelWrapper) DeleteVolumeReturnsOnCall(i int, result1 e
 	}{result1}
 }
 
-func (fake *FakeScbeDataModelWrapper) InsertVolume(volumeName string, wwn string, fstype string) error {
-	fake.insertVolumeMutex.Lock()
-	ret, specificReturn := fake.insertVolumeReturnsOnCall[len(fake.insertVolumeArgsForCall)]
-	fake.insertVolumeArgsForCall = append(fake.insertVolumeArgsForCall, struct {
-		volumeName string
-		wwn        string
-		fstype     string
-	}{volumeName, wwn, fstype})
-	fake.recordInvocation("InsertVolume", []interface{}{volumeName, wwn, fstype})
-	fake.insertVolumeMutex.Unlock()
-	if fake.InsertVolumeStub != nil {
-		return fake.InsertVolumeStub(volumeName, wwn, fstype)
-	}
-	if specificReturn {
-		return ret.result1
-	}
-	return fake.insertVolumeReturns.result1
-}
-
-func (fake *FakeScbeDataModelWrapper) InsertVolumeCallCount() int {
-	fake.insertVolumeMutex.RLock()
-	defer fake.insertVolumeMutex.RUnlock()
-	return len(fake.insertVolumeArgsForCall)
-}
-
-func (fake *FakeScbeDataModelWrapper) InsertVolumeArgsForCall(i int) (string, string, string) {
-	fake.insertVolumeMutex.RLock()
-	defer fake.insertVolumeMutex.RUnlock()
-	return fake.insertVolumeArgsForCall[i].volumeName, fake.insertVolumeArgsForCall[i].wwn, fake.insertVolumeArgsForCall[i].fstype
-}
-
-func (fake *FakeScbeDataModelWrapper) InsertVolumeReturns(result1 error) {
-	fake.InsertVolumeStub = nil
-	fake.insertVolumeReturns = struct {
-		result1 error
-	}{result1}
-}
-
-func (fake *FakeScbeDataModelWrapper) InsertVolumeReturnsOnCall(i int, result1 error) {
-	fake.InsertVolumeStub = nil
-	if fake.insertVolumeReturnsOnCall == nil {
-		fake.insertVolumeReturnsOnCall = make(map[int]struct {
-			result1 error
-		})
-	}
-	fake.insertVolumeReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
-}
-
 func (fake *FakeScbeDataModelWrapper) ListVolumes() ([]scbe.ScbeVolume, error) {
 	fake.listVolumesMutex.Lock()
 	ret, specificReturn := fake.listVolumesReturnsOnCall[len(fake.listVolumesArgsForCall)]
@@ -283,6 +266,175 @@ func (fake *FakeScbeDataModelWrapper) UpdateDatabaseVolumeArgsForCall(i int) *sc
 	return fake.updateDatabaseVolumeArgsForCall[i].newVolume
 }
 
+func (fake *FakeScbeDataModelWrapper) InsertVolume(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options scbe.ScbeVolumeOptions) error {
+	fake.insertVolumeMutex.Lock()
+	ret, specificReturn := fake.insertVolumeReturnsOnCall[len(fake.insertVolumeArgsForCall)]
+	fake.insertVolumeArgsForCall = append(fake.insertVolumeArgsForCall, struct {
+		volumeName    string
+		wwn           string
+		fstype        string
+		size          int
+		requestedSize string
+		accessMode    string
+		isPreexisting bool
+		options       scbe.ScbeVolumeOptions
+	}{volumeName, wwn, fstype, size, requestedSize, accessMode, isPreexisting, options})
+	fake.recordInvocation("InsertVolume", []interface{}{volumeName, wwn, fstype, size, requestedSize, accessMode, isPreexisting, options})
+	fake.insertVolumeMutex.Unlock()
+	if fake.InsertVolumeStub != nil {
+		return fake.InsertVolumeStub(volumeName, wwn, fstype, size, requestedSize, accessMode, isPreexisting, options)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.insertVolumeReturns.result1
+}
+
+func (fake *FakeScbeDataModelWrapper) InsertVolumeCallCount() int {
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	return len(fake.insertVolumeArgsForCall)
+}
+
+func (fake *FakeScbeDataModelWrapper) InsertVolumeArgsForCall(i int) (string, string, string, int, string, string, bool, scbe.ScbeVolumeOptions) {
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	return fake.insertVolumeArgsForCall[i].volumeName, fake.insertVolumeArgsForCall[i].wwn, fake.insertVolumeArgsForCall[i].fstype, fake.insertVolumeArgsForCall[i].size, fake.insertVolumeArgsForCall[i].requestedSize, fake.insertVolumeArgsForCall[i].accessMode, fake.insertVolumeArgsForCall[i].isPreexisting, fake.insertVolumeArgsForCall[i].options
+}
+
+func (fake *FakeScbeDataModelWrapper) InsertVolumeReturns(result1 error) {
+	fake.InsertVolumeStub = nil
+	fake.insertVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModelWrapper) InsertVolumeReturnsOnCall(i int, result1 error) {
+	fake.InsertVolumeStub = nil
+	if fake.insertVolumeReturnsOnCall == nil {
+		fake.insertVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.insertVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModelWrapper) UpdateHostReport(host string, volumes []string, wwns []string) error {
+	var volumesCopy []string
+	if volumes != nil {
+		volumesCopy = make([]string, len(volumes))
+		copy(volumesCopy, volumes)
+	}
+	var wwnsCopy []string
+	if wwns != nil {
+		wwnsCopy = make([]string, len(wwns))
+		copy(wwnsCopy, wwns)
+	}
+	fake.updateHostReportMutex.Lock()
+	ret, specificReturn := fake.updateHostReportReturnsOnCall[len(fake.updateHostReportArgsForCall)]
+	fake.updateHostReportArgsForCall = append(fake.updateHostReportArgsForCall, struct {
+		host    string
+		volumes []string
+		wwns    []string
+	}{host, volumesCopy, wwnsCopy})
+	fake.recordInvocation("UpdateHostReport", []interface{}{host, volumesCopy, wwnsCopy})
+	fake.updateHostReportMutex.Unlock()
+	if fake.UpdateHostReportStub != nil {
+		return fake.UpdateHostReportStub(host, volumes, wwns)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateHostReportReturns.result1
+}
+
+func (fake *FakeScbeDataModelWrapper) UpdateHostReportCallCount() int {
+	fake.updateHostReportMutex.RLock()
+	defer fake.updateHostReportMutex.RUnlock()
+	return len(fake.updateHostReportArgsForCall)
+}
+
+func (fake *FakeScbeDataModelWrapper) UpdateHostReportArgsForCall(i int) (string, []string, []string) {
+	fake.updateHostReportMutex.RLock()
+	defer fake.updateHostReportMutex.RUnlock()
+	return fake.updateHostReportArgsForCall[i].host, fake.updateHostReportArgsForCall[i].volumes, fake.updateHostReportArgsForCall[i].wwns
+}
+
+func (fake *FakeScbeDataModelWrapper) UpdateHostReportReturns(result1 error) {
+	fake.UpdateHostReportStub = nil
+	fake.updateHostReportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModelWrapper) UpdateHostReportReturnsOnCall(i int, result1 error) {
+	fake.UpdateHostReportStub = nil
+	if fake.updateHostReportReturnsOnCall == nil {
+		fake.updateHostReportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateHostReportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeDataModelWrapper) GetHostReport(host string) (scbe.ScbeHostReport, bool, error) {
+	fake.getHostReportMutex.Lock()
+	ret, specificReturn := fake.getHostReportReturnsOnCall[len(fake.getHostReportArgsForCall)]
+	fake.getHostReportArgsForCall = append(fake.getHostReportArgsForCall, struct {
+		host string
+	}{host})
+	fake.recordInvocation("GetHostReport", []interface{}{host})
+	fake.getHostReportMutex.Unlock()
+	if fake.GetHostReportStub != nil {
+		return fake.GetHostReportStub(host)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.getHostReportReturns.result1, fake.getHostReportReturns.result2, fake.getHostReportReturns.result3
+}
+
+func (fake *FakeScbeDataModelWrapper) GetHostReportCallCount() int {
+	fake.getHostReportMutex.RLock()
+	defer fake.getHostReportMutex.RUnlock()
+	return len(fake.getHostReportArgsForCall)
+}
+
+func (fake *FakeScbeDataModelWrapper) GetHostReportArgsForCall(i int) string {
+	fake.getHostReportMutex.RLock()
+	defer fake.getHostReportMutex.RUnlock()
+	return fake.getHostReportArgsForCall[i].host
+}
+
+func (fake *FakeScbeDataModelWrapper) GetHostReportReturns(result1 scbe.ScbeHostReport, result2 bool, result3 error) {
+	fake.GetHostReportStub = nil
+	fake.getHostReportReturns = struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeScbeDataModelWrapper) GetHostReportReturnsOnCall(i int, result1 scbe.ScbeHostReport, result2 bool, result3 error) {
+	fake.GetHostReportStub = nil
+	if fake.getHostReportReturnsOnCall == nil {
+		fake.getHostReportReturnsOnCall = make(map[int]struct {
+			result1 scbe.ScbeHostReport
+			result2 bool
+			result3 error
+		})
+	}
+	fake.getHostReportReturnsOnCall[i] = struct {
+		result1 scbe.ScbeHostReport
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeScbeDataModelWrapper) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -290,12 +442,16 @@ func (fake *FakeScbeDataModelWrapper) Invocations() map[string][][]interface{} {
 	defer fake.getVolumeMutex.RUnlock()
 	fake.deleteVolumeMutex.RLock()
 	defer fake.deleteVolumeMutex.RUnlock()
-	fake.insertVolumeMutex.RLock()
-	defer fake.insertVolumeMutex.RUnlock()
 	fake.listVolumesMutex.RLock()
 	defer fake.listVolumesMutex.RUnlock()
 	fake.updateDatabaseVolumeMutex.RLock()
 	defer fake.updateDatabaseVolumeMutex.RUnlock()
+	fake.insertVolumeMutex.RLock()
+	defer fake.insertVolumeMutex.RUnlock()
+	fake.updateHostReportMutex.RLock()
+	defer fake.updateHostReportMutex.RUnlock()
+	fake.getHostReportMutex.RLock()
+	defer fake.getHostReportMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
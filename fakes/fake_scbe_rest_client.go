@@ -21,6 +21,7 @@ import (
 	"sync"
 
 	"github.com/IBM/ubiquity/local/scbe"
+	"github.com/IBM/ubiquity/resources"
 )
 
 type FakeScbeRestClient struct {
@@ -33,12 +34,13 @@ type FakeScbeRestClient struct {
 	loginReturnsOnCall map[int]struct {
 		result1 error
 	}
-	CreateVolumeStub        func(volName string, serviceName string, size int) (scbe.ScbeVolumeInfo, error)
+	CreateVolumeStub        func(volName string, serviceName string, sizeBytes int, options scbe.ScbeVolumeOptions) (scbe.ScbeVolumeInfo, error)
 	createVolumeMutex       sync.RWMutex
 	createVolumeArgsForCall []struct {
 		volName     string
 		serviceName string
-		size        int
+		sizeBytes   int
+		options     scbe.ScbeVolumeOptions
 	}
 	createVolumeReturns struct {
 		result1 scbe.ScbeVolumeInfo
@@ -98,17 +100,17 @@ type FakeScbeRestClient struct {
 	unmapVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
-	GetVolMappingStub        func(wwn string) (string, error)
+	GetVolMappingStub        func(wwn string) ([]string, error)
 	getVolMappingMutex       sync.RWMutex
 	getVolMappingArgsForCall []struct {
 		wwn string
 	}
 	getVolMappingReturns struct {
-		result1 string
+		result1 []string
 		result2 error
 	}
 	getVolMappingReturnsOnCall map[int]struct {
-		result1 string
+		result1 []string
 		result2 error
 	}
 	ServiceExistStub        func(serviceName string) (bool, error)
@@ -124,6 +126,30 @@ type FakeScbeRestClient struct {
 		result1 bool
 		result2 error
 	}
+	GetServicesStub        func() ([]scbe.ScbeStorageService, error)
+	getServicesMutex       sync.RWMutex
+	getServicesArgsForCall []struct{}
+	getServicesReturns     struct {
+		result1 []scbe.ScbeStorageService
+		result2 error
+	}
+	getServicesReturnsOnCall map[int]struct {
+		result1 []scbe.ScbeStorageService
+		result2 error
+	}
+	CreateHostStub        func(wwn string, host string, initiators resources.HostInitiators) error
+	createHostMutex       sync.RWMutex
+	createHostArgsForCall []struct {
+		wwn        string
+		host       string
+		initiators resources.HostInitiators
+	}
+	createHostReturns struct {
+		result1 error
+	}
+	createHostReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -168,18 +194,19 @@ func (fake *FakeScbeRestClient) LoginReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeScbeRestClient) CreateVolume(volName string, serviceName string, size int) (scbe.ScbeVolumeInfo, error) {
+func (fake *FakeScbeRestClient) CreateVolume(volName string, serviceName string, sizeBytes int, options scbe.ScbeVolumeOptions) (scbe.ScbeVolumeInfo, error) {
 	fake.createVolumeMutex.Lock()
 	ret, specificReturn := fake.createVolumeReturnsOnCall[len(fake.createVolumeArgsForCall)]
 	fake.createVolumeArgsForCall = append(fake.createVolumeArgsForCall, struct {
 		volName     string
 		serviceName string
-		size        int
-	}{volName, serviceName, size})
-	fake.recordInvocation("CreateVolume", []interface{}{volName, serviceName, size})
+		sizeBytes   int
+		options     scbe.ScbeVolumeOptions
+	}{volName, serviceName, sizeBytes, options})
+	fake.recordInvocation("CreateVolume", []interface{}{volName, serviceName, sizeBytes, options})
 	fake.createVolumeMutex.Unlock()
 	if fake.CreateVolumeStub != nil {
-		return fake.CreateVolumeStub(volName, serviceName, size)
+		return fake.CreateVolumeStub(volName, serviceName, sizeBytes, options)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -193,10 +220,10 @@ func (fake *FakeScbeRestClient) CreateVolumeCallCount() int {
 	return len(fake.createVolumeArgsForCall)
 }
 
-func (fake *FakeScbeRestClient) CreateVolumeArgsForCall(i int) (string, string, int) {
+func (fake *FakeScbeRestClient) CreateVolumeArgsForCall(i int) (string, string, int, scbe.ScbeVolumeOptions) {
 	fake.createVolumeMutex.RLock()
 	defer fake.createVolumeMutex.RUnlock()
-	return fake.createVolumeArgsForCall[i].volName, fake.createVolumeArgsForCall[i].serviceName, fake.createVolumeArgsForCall[i].size
+	return fake.createVolumeArgsForCall[i].volName, fake.createVolumeArgsForCall[i].serviceName, fake.createVolumeArgsForCall[i].sizeBytes, fake.createVolumeArgsForCall[i].options
 }
 
 func (fake *FakeScbeRestClient) CreateVolumeReturns(result1 scbe.ScbeVolumeInfo, result2 error) {
@@ -421,7 +448,7 @@ func (fake *FakeScbeRestClient) UnmapVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeScbeRestClient) GetVolMapping(wwn string) (string, error) {
+func (fake *FakeScbeRestClient) GetVolMapping(wwn string) ([]string, error) {
 	fake.getVolMappingMutex.Lock()
 	ret, specificReturn := fake.getVolMappingReturnsOnCall[len(fake.getVolMappingArgsForCall)]
 	fake.getVolMappingArgsForCall = append(fake.getVolMappingArgsForCall, struct {
@@ -450,24 +477,24 @@ func (fake *FakeScbeRestClient) GetVolMappingArgsForCall(i int) string {
 	return fake.getVolMappingArgsForCall[i].wwn
 }
 
-func (fake *FakeScbeRestClient) GetVolMappingReturns(result1 string, result2 error) {
+func (fake *FakeScbeRestClient) GetVolMappingReturns(result1 []string, result2 error) {
 	fake.GetVolMappingStub = nil
 	fake.getVolMappingReturns = struct {
-		result1 string
+		result1 []string
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeScbeRestClient) GetVolMappingReturnsOnCall(i int, result1 string, result2 error) {
+func (fake *FakeScbeRestClient) GetVolMappingReturnsOnCall(i int, result1 []string, result2 error) {
 	fake.GetVolMappingStub = nil
 	if fake.getVolMappingReturnsOnCall == nil {
 		fake.getVolMappingReturnsOnCall = make(map[int]struct {
-			result1 string
+			result1 []string
 			result2 error
 		})
 	}
 	fake.getVolMappingReturnsOnCall[i] = struct {
-		result1 string
+		result1 []string
 		result2 error
 	}{result1, result2}
 }
@@ -523,6 +550,99 @@ func (fake *FakeScbeRestClient) ServiceExistReturnsOnCall(i int, result1 bool, r
 	}{result1, result2}
 }
 
+func (fake *FakeScbeRestClient) GetServices() ([]scbe.ScbeStorageService, error) {
+	fake.getServicesMutex.Lock()
+	ret, specificReturn := fake.getServicesReturnsOnCall[len(fake.getServicesArgsForCall)]
+	fake.getServicesArgsForCall = append(fake.getServicesArgsForCall, struct{}{})
+	fake.recordInvocation("GetServices", []interface{}{})
+	fake.getServicesMutex.Unlock()
+	if fake.GetServicesStub != nil {
+		return fake.GetServicesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getServicesReturns.result1, fake.getServicesReturns.result2
+}
+
+func (fake *FakeScbeRestClient) GetServicesCallCount() int {
+	fake.getServicesMutex.RLock()
+	defer fake.getServicesMutex.RUnlock()
+	return len(fake.getServicesArgsForCall)
+}
+
+func (fake *FakeScbeRestClient) GetServicesReturns(result1 []scbe.ScbeStorageService, result2 error) {
+	fake.GetServicesStub = nil
+	fake.getServicesReturns = struct {
+		result1 []scbe.ScbeStorageService
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScbeRestClient) GetServicesReturnsOnCall(i int, result1 []scbe.ScbeStorageService, result2 error) {
+	fake.GetServicesStub = nil
+	if fake.getServicesReturnsOnCall == nil {
+		fake.getServicesReturnsOnCall = make(map[int]struct {
+			result1 []scbe.ScbeStorageService
+			result2 error
+		})
+	}
+	fake.getServicesReturnsOnCall[i] = struct {
+		result1 []scbe.ScbeStorageService
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeScbeRestClient) CreateHost(wwn string, host string, initiators resources.HostInitiators) error {
+	fake.createHostMutex.Lock()
+	ret, specificReturn := fake.createHostReturnsOnCall[len(fake.createHostArgsForCall)]
+	fake.createHostArgsForCall = append(fake.createHostArgsForCall, struct {
+		wwn        string
+		host       string
+		initiators resources.HostInitiators
+	}{wwn, host, initiators})
+	fake.recordInvocation("CreateHost", []interface{}{wwn, host, initiators})
+	fake.createHostMutex.Unlock()
+	if fake.CreateHostStub != nil {
+		return fake.CreateHostStub(wwn, host, initiators)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.createHostReturns.result1
+}
+
+func (fake *FakeScbeRestClient) CreateHostCallCount() int {
+	fake.createHostMutex.RLock()
+	defer fake.createHostMutex.RUnlock()
+	return len(fake.createHostArgsForCall)
+}
+
+func (fake *FakeScbeRestClient) CreateHostArgsForCall(i int) (string, string, resources.HostInitiators) {
+	fake.createHostMutex.RLock()
+	defer fake.createHostMutex.RUnlock()
+	return fake.createHostArgsForCall[i].wwn, fake.createHostArgsForCall[i].host, fake.createHostArgsForCall[i].initiators
+}
+
+func (fake *FakeScbeRestClient) CreateHostReturns(result1 error) {
+	fake.CreateHostStub = nil
+	fake.createHostReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScbeRestClient) CreateHostReturnsOnCall(i int, result1 error) {
+	fake.CreateHostStub = nil
+	if fake.createHostReturnsOnCall == nil {
+		fake.createHostReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createHostReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeScbeRestClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -542,6 +662,10 @@ func (fake *FakeScbeRestClient) Invocations() map[string][][]interface{} {
 	defer fake.getVolMappingMutex.RUnlock()
 	fake.serviceExistMutex.RLock()
 	defer fake.serviceExistMutex.RUnlock()
+	fake.getServicesMutex.RLock()
+	defer fake.getServicesMutex.RUnlock()
+	fake.createHostMutex.RLock()
+	defer fake.createHostMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -201,11 +201,138 @@ type FakeSpectrumScaleConnector struct {
 	setFilesetQuotaReturnsOnCall map[int]struct {
 		result1 error
 	}
-	ExportNfsStub        func(volumeMountpoint string, clientConfig string) error
+	UnexportNfsStub        func(volumeMountpoint string) error
+	unexportNfsMutex       sync.RWMutex
+	unexportNfsArgsForCall []struct {
+		volumeMountpoint string
+	}
+	unexportNfsReturns struct {
+		result1 error
+	}
+	unexportNfsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LinkFilesetAtStub        func(filesystemName string, filesetName string, junctionPath string) error
+	linkFilesetAtMutex       sync.RWMutex
+	linkFilesetAtArgsForCall []struct {
+		filesystemName string
+		filesetName    string
+		junctionPath   string
+	}
+	linkFilesetAtReturns struct {
+		result1 error
+	}
+	linkFilesetAtReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListFilesetQuotaUsageStub        func(filesystemName string, filesetName string) (connectors.FilesetQuotaUsage, error)
+	listFilesetQuotaUsageMutex       sync.RWMutex
+	listFilesetQuotaUsageArgsForCall []struct {
+		filesystemName string
+		filesetName    string
+	}
+	listFilesetQuotaUsageReturns struct {
+		result1 connectors.FilesetQuotaUsage
+		result2 error
+	}
+	listFilesetQuotaUsageReturnsOnCall map[int]struct {
+		result1 connectors.FilesetQuotaUsage
+		result2 error
+	}
+	GetFilesystemCapacityStub        func(filesystemName string) (connectors.FilesystemCapacity, error)
+	getFilesystemCapacityMutex       sync.RWMutex
+	getFilesystemCapacityArgsForCall []struct {
+		filesystemName string
+	}
+	getFilesystemCapacityReturns struct {
+		result1 connectors.FilesystemCapacity
+		result2 error
+	}
+	getFilesystemCapacityReturnsOnCall map[int]struct {
+		result1 connectors.FilesystemCapacity
+		result2 error
+	}
+	ListFilesystemPoolsStub        func(filesystemName string) ([]string, error)
+	listFilesystemPoolsMutex       sync.RWMutex
+	listFilesystemPoolsArgsForCall []struct {
+		filesystemName string
+	}
+	listFilesystemPoolsReturns struct {
+		result1 []string
+		result2 error
+	}
+	listFilesystemPoolsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	GetPolicyStub        func(filesystemName string) (string, error)
+	getPolicyMutex       sync.RWMutex
+	getPolicyArgsForCall []struct {
+		filesystemName string
+	}
+	getPolicyReturns struct {
+		result1 string
+		result2 error
+	}
+	getPolicyReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ChangePolicyStub        func(filesystemName string, policy string) error
+	changePolicyMutex       sync.RWMutex
+	changePolicyArgsForCall []struct {
+		filesystemName string
+		policy         string
+	}
+	changePolicyReturns struct {
+		result1 error
+	}
+	changePolicyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetFilesetAfmStateStub        func(filesystemName string, filesetName string) (string, error)
+	getFilesetAfmStateMutex       sync.RWMutex
+	getFilesetAfmStateArgsForCall []struct {
+		filesystemName string
+		filesetName    string
+	}
+	getFilesetAfmStateReturns struct {
+		result1 string
+		result2 error
+	}
+	getFilesetAfmStateReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ExportSmbStub        func(shareName string, volumeMountpoint string, smbOptions map[string]string) error
+	exportSmbMutex       sync.RWMutex
+	exportSmbArgsForCall []struct {
+		shareName        string
+		volumeMountpoint string
+		smbOptions       map[string]string
+	}
+	exportSmbReturns struct {
+		result1 error
+	}
+	exportSmbReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UnexportSmbStub        func(shareName string) error
+	unexportSmbMutex       sync.RWMutex
+	unexportSmbArgsForCall []struct {
+		shareName string
+	}
+	unexportSmbReturns struct {
+		result1 error
+	}
+	unexportSmbReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ExportNfsStub        func(volumeMountpoint string, clients []connectors.NfsClient) error
 	exportNfsMutex       sync.RWMutex
 	exportNfsArgsForCall []struct {
 		volumeMountpoint string
-		clientConfig     string
+		clients          []connectors.NfsClient
 	}
 	exportNfsReturns struct {
 		result1 error
@@ -213,17 +340,33 @@ type FakeSpectrumScaleConnector struct {
 	exportNfsReturnsOnCall map[int]struct {
 		result1 error
 	}
-	UnexportNfsStub        func(volumeMountpoint string) error
-	unexportNfsMutex       sync.RWMutex
-	unexportNfsArgsForCall []struct {
+	UpdateNfsExportStub        func(volumeMountpoint string, addClients []connectors.NfsClient, changeClients []connectors.NfsClient, removeClients []connectors.NfsClient) error
+	updateNfsExportMutex       sync.RWMutex
+	updateNfsExportArgsForCall []struct {
 		volumeMountpoint string
+		addClients       []connectors.NfsClient
+		changeClients    []connectors.NfsClient
+		removeClients    []connectors.NfsClient
 	}
-	unexportNfsReturns struct {
+	updateNfsExportReturns struct {
 		result1 error
 	}
-	unexportNfsReturnsOnCall map[int]struct {
+	updateNfsExportReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ListNfsExportClientsStub        func(volumeMountpoint string) ([]connectors.NfsClient, error)
+	listNfsExportClientsMutex       sync.RWMutex
+	listNfsExportClientsArgsForCall []struct {
+		volumeMountpoint string
+	}
+	listNfsExportClientsReturns struct {
+		result1 []connectors.NfsClient
+		result2 error
+	}
+	listNfsExportClientsReturnsOnCall map[int]struct {
+		result1 []connectors.NfsClient
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -918,17 +1061,524 @@ func (fake *FakeSpectrumScaleConnector) SetFilesetQuotaReturnsOnCall(i int, resu
 	}{result1}
 }
 
-func (fake *FakeSpectrumScaleConnector) ExportNfs(volumeMountpoint string, clientConfig string) error {
+func (fake *FakeSpectrumScaleConnector) UnexportNfs(volumeMountpoint string) error {
+	fake.unexportNfsMutex.Lock()
+	ret, specificReturn := fake.unexportNfsReturnsOnCall[len(fake.unexportNfsArgsForCall)]
+	fake.unexportNfsArgsForCall = append(fake.unexportNfsArgsForCall, struct {
+		volumeMountpoint string
+	}{volumeMountpoint})
+	fake.recordInvocation("UnexportNfs", []interface{}{volumeMountpoint})
+	fake.unexportNfsMutex.Unlock()
+	if fake.UnexportNfsStub != nil {
+		return fake.UnexportNfsStub(volumeMountpoint)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.unexportNfsReturns.result1
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportNfsCallCount() int {
+	fake.unexportNfsMutex.RLock()
+	defer fake.unexportNfsMutex.RUnlock()
+	return len(fake.unexportNfsArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportNfsArgsForCall(i int) string {
+	fake.unexportNfsMutex.RLock()
+	defer fake.unexportNfsMutex.RUnlock()
+	return fake.unexportNfsArgsForCall[i].volumeMountpoint
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportNfsReturns(result1 error) {
+	fake.UnexportNfsStub = nil
+	fake.unexportNfsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportNfsReturnsOnCall(i int, result1 error) {
+	fake.UnexportNfsStub = nil
+	if fake.unexportNfsReturnsOnCall == nil {
+		fake.unexportNfsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unexportNfsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) LinkFilesetAt(filesystemName string, filesetName string, junctionPath string) error {
+	fake.linkFilesetAtMutex.Lock()
+	ret, specificReturn := fake.linkFilesetAtReturnsOnCall[len(fake.linkFilesetAtArgsForCall)]
+	fake.linkFilesetAtArgsForCall = append(fake.linkFilesetAtArgsForCall, struct {
+		filesystemName string
+		filesetName    string
+		junctionPath   string
+	}{filesystemName, filesetName, junctionPath})
+	fake.recordInvocation("LinkFilesetAt", []interface{}{filesystemName, filesetName, junctionPath})
+	fake.linkFilesetAtMutex.Unlock()
+	if fake.LinkFilesetAtStub != nil {
+		return fake.LinkFilesetAtStub(filesystemName, filesetName, junctionPath)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.linkFilesetAtReturns.result1
+}
+
+func (fake *FakeSpectrumScaleConnector) LinkFilesetAtCallCount() int {
+	fake.linkFilesetAtMutex.RLock()
+	defer fake.linkFilesetAtMutex.RUnlock()
+	return len(fake.linkFilesetAtArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) LinkFilesetAtArgsForCall(i int) (string, string, string) {
+	fake.linkFilesetAtMutex.RLock()
+	defer fake.linkFilesetAtMutex.RUnlock()
+	return fake.linkFilesetAtArgsForCall[i].filesystemName, fake.linkFilesetAtArgsForCall[i].filesetName, fake.linkFilesetAtArgsForCall[i].junctionPath
+}
+
+func (fake *FakeSpectrumScaleConnector) LinkFilesetAtReturns(result1 error) {
+	fake.LinkFilesetAtStub = nil
+	fake.linkFilesetAtReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) LinkFilesetAtReturnsOnCall(i int, result1 error) {
+	fake.LinkFilesetAtStub = nil
+	if fake.linkFilesetAtReturnsOnCall == nil {
+		fake.linkFilesetAtReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.linkFilesetAtReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesetQuotaUsage(filesystemName string, filesetName string) (connectors.FilesetQuotaUsage, error) {
+	fake.listFilesetQuotaUsageMutex.Lock()
+	ret, specificReturn := fake.listFilesetQuotaUsageReturnsOnCall[len(fake.listFilesetQuotaUsageArgsForCall)]
+	fake.listFilesetQuotaUsageArgsForCall = append(fake.listFilesetQuotaUsageArgsForCall, struct {
+		filesystemName string
+		filesetName    string
+	}{filesystemName, filesetName})
+	fake.recordInvocation("ListFilesetQuotaUsage", []interface{}{filesystemName, filesetName})
+	fake.listFilesetQuotaUsageMutex.Unlock()
+	if fake.ListFilesetQuotaUsageStub != nil {
+		return fake.ListFilesetQuotaUsageStub(filesystemName, filesetName)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listFilesetQuotaUsageReturns.result1, fake.listFilesetQuotaUsageReturns.result2
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesetQuotaUsageCallCount() int {
+	fake.listFilesetQuotaUsageMutex.RLock()
+	defer fake.listFilesetQuotaUsageMutex.RUnlock()
+	return len(fake.listFilesetQuotaUsageArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesetQuotaUsageArgsForCall(i int) (string, string) {
+	fake.listFilesetQuotaUsageMutex.RLock()
+	defer fake.listFilesetQuotaUsageMutex.RUnlock()
+	return fake.listFilesetQuotaUsageArgsForCall[i].filesystemName, fake.listFilesetQuotaUsageArgsForCall[i].filesetName
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesetQuotaUsageReturns(result1 connectors.FilesetQuotaUsage, result2 error) {
+	fake.ListFilesetQuotaUsageStub = nil
+	fake.listFilesetQuotaUsageReturns = struct {
+		result1 connectors.FilesetQuotaUsage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesetQuotaUsageReturnsOnCall(i int, result1 connectors.FilesetQuotaUsage, result2 error) {
+	fake.ListFilesetQuotaUsageStub = nil
+	if fake.listFilesetQuotaUsageReturnsOnCall == nil {
+		fake.listFilesetQuotaUsageReturnsOnCall = make(map[int]struct {
+			result1 connectors.FilesetQuotaUsage
+			result2 error
+		})
+	}
+	fake.listFilesetQuotaUsageReturnsOnCall[i] = struct {
+		result1 connectors.FilesetQuotaUsage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesystemCapacity(filesystemName string) (connectors.FilesystemCapacity, error) {
+	fake.getFilesystemCapacityMutex.Lock()
+	ret, specificReturn := fake.getFilesystemCapacityReturnsOnCall[len(fake.getFilesystemCapacityArgsForCall)]
+	fake.getFilesystemCapacityArgsForCall = append(fake.getFilesystemCapacityArgsForCall, struct {
+		filesystemName string
+	}{filesystemName})
+	fake.recordInvocation("GetFilesystemCapacity", []interface{}{filesystemName})
+	fake.getFilesystemCapacityMutex.Unlock()
+	if fake.GetFilesystemCapacityStub != nil {
+		return fake.GetFilesystemCapacityStub(filesystemName)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getFilesystemCapacityReturns.result1, fake.getFilesystemCapacityReturns.result2
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesystemCapacityCallCount() int {
+	fake.getFilesystemCapacityMutex.RLock()
+	defer fake.getFilesystemCapacityMutex.RUnlock()
+	return len(fake.getFilesystemCapacityArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesystemCapacityArgsForCall(i int) string {
+	fake.getFilesystemCapacityMutex.RLock()
+	defer fake.getFilesystemCapacityMutex.RUnlock()
+	return fake.getFilesystemCapacityArgsForCall[i].filesystemName
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesystemCapacityReturns(result1 connectors.FilesystemCapacity, result2 error) {
+	fake.GetFilesystemCapacityStub = nil
+	fake.getFilesystemCapacityReturns = struct {
+		result1 connectors.FilesystemCapacity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesystemCapacityReturnsOnCall(i int, result1 connectors.FilesystemCapacity, result2 error) {
+	fake.GetFilesystemCapacityStub = nil
+	if fake.getFilesystemCapacityReturnsOnCall == nil {
+		fake.getFilesystemCapacityReturnsOnCall = make(map[int]struct {
+			result1 connectors.FilesystemCapacity
+			result2 error
+		})
+	}
+	fake.getFilesystemCapacityReturnsOnCall[i] = struct {
+		result1 connectors.FilesystemCapacity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesystemPools(filesystemName string) ([]string, error) {
+	fake.listFilesystemPoolsMutex.Lock()
+	ret, specificReturn := fake.listFilesystemPoolsReturnsOnCall[len(fake.listFilesystemPoolsArgsForCall)]
+	fake.listFilesystemPoolsArgsForCall = append(fake.listFilesystemPoolsArgsForCall, struct {
+		filesystemName string
+	}{filesystemName})
+	fake.recordInvocation("ListFilesystemPools", []interface{}{filesystemName})
+	fake.listFilesystemPoolsMutex.Unlock()
+	if fake.ListFilesystemPoolsStub != nil {
+		return fake.ListFilesystemPoolsStub(filesystemName)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listFilesystemPoolsReturns.result1, fake.listFilesystemPoolsReturns.result2
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesystemPoolsCallCount() int {
+	fake.listFilesystemPoolsMutex.RLock()
+	defer fake.listFilesystemPoolsMutex.RUnlock()
+	return len(fake.listFilesystemPoolsArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesystemPoolsArgsForCall(i int) string {
+	fake.listFilesystemPoolsMutex.RLock()
+	defer fake.listFilesystemPoolsMutex.RUnlock()
+	return fake.listFilesystemPoolsArgsForCall[i].filesystemName
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesystemPoolsReturns(result1 []string, result2 error) {
+	fake.ListFilesystemPoolsStub = nil
+	fake.listFilesystemPoolsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) ListFilesystemPoolsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.ListFilesystemPoolsStub = nil
+	if fake.listFilesystemPoolsReturnsOnCall == nil {
+		fake.listFilesystemPoolsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.listFilesystemPoolsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) GetPolicy(filesystemName string) (string, error) {
+	fake.getPolicyMutex.Lock()
+	ret, specificReturn := fake.getPolicyReturnsOnCall[len(fake.getPolicyArgsForCall)]
+	fake.getPolicyArgsForCall = append(fake.getPolicyArgsForCall, struct {
+		filesystemName string
+	}{filesystemName})
+	fake.recordInvocation("GetPolicy", []interface{}{filesystemName})
+	fake.getPolicyMutex.Unlock()
+	if fake.GetPolicyStub != nil {
+		return fake.GetPolicyStub(filesystemName)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getPolicyReturns.result1, fake.getPolicyReturns.result2
+}
+
+func (fake *FakeSpectrumScaleConnector) GetPolicyCallCount() int {
+	fake.getPolicyMutex.RLock()
+	defer fake.getPolicyMutex.RUnlock()
+	return len(fake.getPolicyArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) GetPolicyArgsForCall(i int) string {
+	fake.getPolicyMutex.RLock()
+	defer fake.getPolicyMutex.RUnlock()
+	return fake.getPolicyArgsForCall[i].filesystemName
+}
+
+func (fake *FakeSpectrumScaleConnector) GetPolicyReturns(result1 string, result2 error) {
+	fake.GetPolicyStub = nil
+	fake.getPolicyReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) GetPolicyReturnsOnCall(i int, result1 string, result2 error) {
+	fake.GetPolicyStub = nil
+	if fake.getPolicyReturnsOnCall == nil {
+		fake.getPolicyReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getPolicyReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) ChangePolicy(filesystemName string, policy string) error {
+	fake.changePolicyMutex.Lock()
+	ret, specificReturn := fake.changePolicyReturnsOnCall[len(fake.changePolicyArgsForCall)]
+	fake.changePolicyArgsForCall = append(fake.changePolicyArgsForCall, struct {
+		filesystemName string
+		policy         string
+	}{filesystemName, policy})
+	fake.recordInvocation("ChangePolicy", []interface{}{filesystemName, policy})
+	fake.changePolicyMutex.Unlock()
+	if fake.ChangePolicyStub != nil {
+		return fake.ChangePolicyStub(filesystemName, policy)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.changePolicyReturns.result1
+}
+
+func (fake *FakeSpectrumScaleConnector) ChangePolicyCallCount() int {
+	fake.changePolicyMutex.RLock()
+	defer fake.changePolicyMutex.RUnlock()
+	return len(fake.changePolicyArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) ChangePolicyArgsForCall(i int) (string, string) {
+	fake.changePolicyMutex.RLock()
+	defer fake.changePolicyMutex.RUnlock()
+	return fake.changePolicyArgsForCall[i].filesystemName, fake.changePolicyArgsForCall[i].policy
+}
+
+func (fake *FakeSpectrumScaleConnector) ChangePolicyReturns(result1 error) {
+	fake.ChangePolicyStub = nil
+	fake.changePolicyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) ChangePolicyReturnsOnCall(i int, result1 error) {
+	fake.ChangePolicyStub = nil
+	if fake.changePolicyReturnsOnCall == nil {
+		fake.changePolicyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.changePolicyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesetAfmState(filesystemName string, filesetName string) (string, error) {
+	fake.getFilesetAfmStateMutex.Lock()
+	ret, specificReturn := fake.getFilesetAfmStateReturnsOnCall[len(fake.getFilesetAfmStateArgsForCall)]
+	fake.getFilesetAfmStateArgsForCall = append(fake.getFilesetAfmStateArgsForCall, struct {
+		filesystemName string
+		filesetName    string
+	}{filesystemName, filesetName})
+	fake.recordInvocation("GetFilesetAfmState", []interface{}{filesystemName, filesetName})
+	fake.getFilesetAfmStateMutex.Unlock()
+	if fake.GetFilesetAfmStateStub != nil {
+		return fake.GetFilesetAfmStateStub(filesystemName, filesetName)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getFilesetAfmStateReturns.result1, fake.getFilesetAfmStateReturns.result2
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesetAfmStateCallCount() int {
+	fake.getFilesetAfmStateMutex.RLock()
+	defer fake.getFilesetAfmStateMutex.RUnlock()
+	return len(fake.getFilesetAfmStateArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesetAfmStateArgsForCall(i int) (string, string) {
+	fake.getFilesetAfmStateMutex.RLock()
+	defer fake.getFilesetAfmStateMutex.RUnlock()
+	return fake.getFilesetAfmStateArgsForCall[i].filesystemName, fake.getFilesetAfmStateArgsForCall[i].filesetName
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesetAfmStateReturns(result1 string, result2 error) {
+	fake.GetFilesetAfmStateStub = nil
+	fake.getFilesetAfmStateReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) GetFilesetAfmStateReturnsOnCall(i int, result1 string, result2 error) {
+	fake.GetFilesetAfmStateStub = nil
+	if fake.getFilesetAfmStateReturnsOnCall == nil {
+		fake.getFilesetAfmStateReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getFilesetAfmStateReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) ExportSmb(shareName string, volumeMountpoint string, smbOptions map[string]string) error {
+	fake.exportSmbMutex.Lock()
+	ret, specificReturn := fake.exportSmbReturnsOnCall[len(fake.exportSmbArgsForCall)]
+	fake.exportSmbArgsForCall = append(fake.exportSmbArgsForCall, struct {
+		shareName        string
+		volumeMountpoint string
+		smbOptions       map[string]string
+	}{shareName, volumeMountpoint, smbOptions})
+	fake.recordInvocation("ExportSmb", []interface{}{shareName, volumeMountpoint, smbOptions})
+	fake.exportSmbMutex.Unlock()
+	if fake.ExportSmbStub != nil {
+		return fake.ExportSmbStub(shareName, volumeMountpoint, smbOptions)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.exportSmbReturns.result1
+}
+
+func (fake *FakeSpectrumScaleConnector) ExportSmbCallCount() int {
+	fake.exportSmbMutex.RLock()
+	defer fake.exportSmbMutex.RUnlock()
+	return len(fake.exportSmbArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) ExportSmbArgsForCall(i int) (string, string, map[string]string) {
+	fake.exportSmbMutex.RLock()
+	defer fake.exportSmbMutex.RUnlock()
+	return fake.exportSmbArgsForCall[i].shareName, fake.exportSmbArgsForCall[i].volumeMountpoint, fake.exportSmbArgsForCall[i].smbOptions
+}
+
+func (fake *FakeSpectrumScaleConnector) ExportSmbReturns(result1 error) {
+	fake.ExportSmbStub = nil
+	fake.exportSmbReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) ExportSmbReturnsOnCall(i int, result1 error) {
+	fake.ExportSmbStub = nil
+	if fake.exportSmbReturnsOnCall == nil {
+		fake.exportSmbReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportSmbReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportSmb(shareName string) error {
+	fake.unexportSmbMutex.Lock()
+	ret, specificReturn := fake.unexportSmbReturnsOnCall[len(fake.unexportSmbArgsForCall)]
+	fake.unexportSmbArgsForCall = append(fake.unexportSmbArgsForCall, struct {
+		shareName string
+	}{shareName})
+	fake.recordInvocation("UnexportSmb", []interface{}{shareName})
+	fake.unexportSmbMutex.Unlock()
+	if fake.UnexportSmbStub != nil {
+		return fake.UnexportSmbStub(shareName)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.unexportSmbReturns.result1
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportSmbCallCount() int {
+	fake.unexportSmbMutex.RLock()
+	defer fake.unexportSmbMutex.RUnlock()
+	return len(fake.unexportSmbArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportSmbArgsForCall(i int) string {
+	fake.unexportSmbMutex.RLock()
+	defer fake.unexportSmbMutex.RUnlock()
+	return fake.unexportSmbArgsForCall[i].shareName
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportSmbReturns(result1 error) {
+	fake.UnexportSmbStub = nil
+	fake.unexportSmbReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) UnexportSmbReturnsOnCall(i int, result1 error) {
+	fake.UnexportSmbStub = nil
+	if fake.unexportSmbReturnsOnCall == nil {
+		fake.unexportSmbReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unexportSmbReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpectrumScaleConnector) ExportNfs(volumeMountpoint string, clients []connectors.NfsClient) error {
+	var clientsCopy []connectors.NfsClient
+	if clients != nil {
+		clientsCopy = make([]connectors.NfsClient, len(clients))
+		copy(clientsCopy, clients)
+	}
 	fake.exportNfsMutex.Lock()
 	ret, specificReturn := fake.exportNfsReturnsOnCall[len(fake.exportNfsArgsForCall)]
 	fake.exportNfsArgsForCall = append(fake.exportNfsArgsForCall, struct {
 		volumeMountpoint string
-		clientConfig     string
-	}{volumeMountpoint, clientConfig})
-	fake.recordInvocation("ExportNfs", []interface{}{volumeMountpoint, clientConfig})
+		clients          []connectors.NfsClient
+	}{volumeMountpoint, clientsCopy})
+	fake.recordInvocation("ExportNfs", []interface{}{volumeMountpoint, clientsCopy})
 	fake.exportNfsMutex.Unlock()
 	if fake.ExportNfsStub != nil {
-		return fake.ExportNfsStub(volumeMountpoint, clientConfig)
+		return fake.ExportNfsStub(volumeMountpoint, clients)
 	}
 	if specificReturn {
 		return ret.result1
@@ -942,10 +1592,10 @@ func (fake *FakeSpectrumScaleConnector) ExportNfsCallCount() int {
 	return len(fake.exportNfsArgsForCall)
 }
 
-func (fake *FakeSpectrumScaleConnector) ExportNfsArgsForCall(i int) (string, string) {
+func (fake *FakeSpectrumScaleConnector) ExportNfsArgsForCall(i int) (string, []connectors.NfsClient) {
 	fake.exportNfsMutex.RLock()
 	defer fake.exportNfsMutex.RUnlock()
-	return fake.exportNfsArgsForCall[i].volumeMountpoint, fake.exportNfsArgsForCall[i].clientConfig
+	return fake.exportNfsArgsForCall[i].volumeMountpoint, fake.exportNfsArgsForCall[i].clients
 }
 
 func (fake *FakeSpectrumScaleConnector) ExportNfsReturns(result1 error) {
@@ -967,54 +1617,123 @@ func (fake *FakeSpectrumScaleConnector) ExportNfsReturnsOnCall(i int, result1 er
 	}{result1}
 }
 
-func (fake *FakeSpectrumScaleConnector) UnexportNfs(volumeMountpoint string) error {
-	fake.unexportNfsMutex.Lock()
-	ret, specificReturn := fake.unexportNfsReturnsOnCall[len(fake.unexportNfsArgsForCall)]
-	fake.unexportNfsArgsForCall = append(fake.unexportNfsArgsForCall, struct {
+func (fake *FakeSpectrumScaleConnector) UpdateNfsExport(volumeMountpoint string, addClients []connectors.NfsClient, changeClients []connectors.NfsClient, removeClients []connectors.NfsClient) error {
+	var addClientsCopy []connectors.NfsClient
+	if addClients != nil {
+		addClientsCopy = make([]connectors.NfsClient, len(addClients))
+		copy(addClientsCopy, addClients)
+	}
+	var changeClientsCopy []connectors.NfsClient
+	if changeClients != nil {
+		changeClientsCopy = make([]connectors.NfsClient, len(changeClients))
+		copy(changeClientsCopy, changeClients)
+	}
+	var removeClientsCopy []connectors.NfsClient
+	if removeClients != nil {
+		removeClientsCopy = make([]connectors.NfsClient, len(removeClients))
+		copy(removeClientsCopy, removeClients)
+	}
+	fake.updateNfsExportMutex.Lock()
+	ret, specificReturn := fake.updateNfsExportReturnsOnCall[len(fake.updateNfsExportArgsForCall)]
+	fake.updateNfsExportArgsForCall = append(fake.updateNfsExportArgsForCall, struct {
 		volumeMountpoint string
-	}{volumeMountpoint})
-	fake.recordInvocation("UnexportNfs", []interface{}{volumeMountpoint})
-	fake.unexportNfsMutex.Unlock()
-	if fake.UnexportNfsStub != nil {
-		return fake.UnexportNfsStub(volumeMountpoint)
+		addClients       []connectors.NfsClient
+		changeClients    []connectors.NfsClient
+		removeClients    []connectors.NfsClient
+	}{volumeMountpoint, addClientsCopy, changeClientsCopy, removeClientsCopy})
+	fake.recordInvocation("UpdateNfsExport", []interface{}{volumeMountpoint, addClientsCopy, changeClientsCopy, removeClientsCopy})
+	fake.updateNfsExportMutex.Unlock()
+	if fake.UpdateNfsExportStub != nil {
+		return fake.UpdateNfsExportStub(volumeMountpoint, addClients, changeClients, removeClients)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.unexportNfsReturns.result1
+	return fake.updateNfsExportReturns.result1
 }
 
-func (fake *FakeSpectrumScaleConnector) UnexportNfsCallCount() int {
-	fake.unexportNfsMutex.RLock()
-	defer fake.unexportNfsMutex.RUnlock()
-	return len(fake.unexportNfsArgsForCall)
+func (fake *FakeSpectrumScaleConnector) UpdateNfsExportCallCount() int {
+	fake.updateNfsExportMutex.RLock()
+	defer fake.updateNfsExportMutex.RUnlock()
+	return len(fake.updateNfsExportArgsForCall)
 }
 
-func (fake *FakeSpectrumScaleConnector) UnexportNfsArgsForCall(i int) string {
-	fake.unexportNfsMutex.RLock()
-	defer fake.unexportNfsMutex.RUnlock()
-	return fake.unexportNfsArgsForCall[i].volumeMountpoint
+func (fake *FakeSpectrumScaleConnector) UpdateNfsExportArgsForCall(i int) (string, []connectors.NfsClient, []connectors.NfsClient, []connectors.NfsClient) {
+	fake.updateNfsExportMutex.RLock()
+	defer fake.updateNfsExportMutex.RUnlock()
+	return fake.updateNfsExportArgsForCall[i].volumeMountpoint, fake.updateNfsExportArgsForCall[i].addClients, fake.updateNfsExportArgsForCall[i].changeClients, fake.updateNfsExportArgsForCall[i].removeClients
 }
 
-func (fake *FakeSpectrumScaleConnector) UnexportNfsReturns(result1 error) {
-	fake.UnexportNfsStub = nil
-	fake.unexportNfsReturns = struct {
+func (fake *FakeSpectrumScaleConnector) UpdateNfsExportReturns(result1 error) {
+	fake.UpdateNfsExportStub = nil
+	fake.updateNfsExportReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeSpectrumScaleConnector) UnexportNfsReturnsOnCall(i int, result1 error) {
-	fake.UnexportNfsStub = nil
-	if fake.unexportNfsReturnsOnCall == nil {
-		fake.unexportNfsReturnsOnCall = make(map[int]struct {
+func (fake *FakeSpectrumScaleConnector) UpdateNfsExportReturnsOnCall(i int, result1 error) {
+	fake.UpdateNfsExportStub = nil
+	if fake.updateNfsExportReturnsOnCall == nil {
+		fake.updateNfsExportReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.unexportNfsReturnsOnCall[i] = struct {
+	fake.updateNfsExportReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
+func (fake *FakeSpectrumScaleConnector) ListNfsExportClients(volumeMountpoint string) ([]connectors.NfsClient, error) {
+	fake.listNfsExportClientsMutex.Lock()
+	ret, specificReturn := fake.listNfsExportClientsReturnsOnCall[len(fake.listNfsExportClientsArgsForCall)]
+	fake.listNfsExportClientsArgsForCall = append(fake.listNfsExportClientsArgsForCall, struct {
+		volumeMountpoint string
+	}{volumeMountpoint})
+	fake.recordInvocation("ListNfsExportClients", []interface{}{volumeMountpoint})
+	fake.listNfsExportClientsMutex.Unlock()
+	if fake.ListNfsExportClientsStub != nil {
+		return fake.ListNfsExportClientsStub(volumeMountpoint)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listNfsExportClientsReturns.result1, fake.listNfsExportClientsReturns.result2
+}
+
+func (fake *FakeSpectrumScaleConnector) ListNfsExportClientsCallCount() int {
+	fake.listNfsExportClientsMutex.RLock()
+	defer fake.listNfsExportClientsMutex.RUnlock()
+	return len(fake.listNfsExportClientsArgsForCall)
+}
+
+func (fake *FakeSpectrumScaleConnector) ListNfsExportClientsArgsForCall(i int) string {
+	fake.listNfsExportClientsMutex.RLock()
+	defer fake.listNfsExportClientsMutex.RUnlock()
+	return fake.listNfsExportClientsArgsForCall[i].volumeMountpoint
+}
+
+func (fake *FakeSpectrumScaleConnector) ListNfsExportClientsReturns(result1 []connectors.NfsClient, result2 error) {
+	fake.ListNfsExportClientsStub = nil
+	fake.listNfsExportClientsReturns = struct {
+		result1 []connectors.NfsClient
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpectrumScaleConnector) ListNfsExportClientsReturnsOnCall(i int, result1 []connectors.NfsClient, result2 error) {
+	fake.ListNfsExportClientsStub = nil
+	if fake.listNfsExportClientsReturnsOnCall == nil {
+		fake.listNfsExportClientsReturnsOnCall = make(map[int]struct {
+			result1 []connectors.NfsClient
+			result2 error
+		})
+	}
+	fake.listNfsExportClientsReturnsOnCall[i] = struct {
+		result1 []connectors.NfsClient
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeSpectrumScaleConnector) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -1046,10 +1765,32 @@ func (fake *FakeSpectrumScaleConnector) Invocations() map[string][][]interface{}
 	defer fake.listFilesetQuotaMutex.RUnlock()
 	fake.setFilesetQuotaMutex.RLock()
 	defer fake.setFilesetQuotaMutex.RUnlock()
-	fake.exportNfsMutex.RLock()
-	defer fake.exportNfsMutex.RUnlock()
 	fake.unexportNfsMutex.RLock()
 	defer fake.unexportNfsMutex.RUnlock()
+	fake.linkFilesetAtMutex.RLock()
+	defer fake.linkFilesetAtMutex.RUnlock()
+	fake.listFilesetQuotaUsageMutex.RLock()
+	defer fake.listFilesetQuotaUsageMutex.RUnlock()
+	fake.getFilesystemCapacityMutex.RLock()
+	defer fake.getFilesystemCapacityMutex.RUnlock()
+	fake.listFilesystemPoolsMutex.RLock()
+	defer fake.listFilesystemPoolsMutex.RUnlock()
+	fake.getPolicyMutex.RLock()
+	defer fake.getPolicyMutex.RUnlock()
+	fake.changePolicyMutex.RLock()
+	defer fake.changePolicyMutex.RUnlock()
+	fake.getFilesetAfmStateMutex.RLock()
+	defer fake.getFilesetAfmStateMutex.RUnlock()
+	fake.exportSmbMutex.RLock()
+	defer fake.exportSmbMutex.RUnlock()
+	fake.unexportSmbMutex.RLock()
+	defer fake.unexportSmbMutex.RUnlock()
+	fake.exportNfsMutex.RLock()
+	defer fake.exportNfsMutex.RUnlock()
+	fake.updateNfsExportMutex.RLock()
+	defer fake.updateNfsExportMutex.RUnlock()
+	fake.listNfsExportClientsMutex.RLock()
+	defer fake.listNfsExportClientsMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -18,6 +18,8 @@ package local
 
 import (
 	"fmt"
+	"github.com/IBM/ubiquity/database"
+	"github.com/IBM/ubiquity/local/nfs"
 	"github.com/IBM/ubiquity/local/scbe"
 	"github.com/IBM/ubiquity/resources"
 	"log"
@@ -33,9 +35,32 @@ func GetLocalClients(logger *log.Logger, config resources.UbiquityServerConfig)
 		clients[resources.SCBE] = ScbeClient
 	}
 
+	if config.NfsConfig.ServerAddr != "" {
+		nfsClient, err := newNfsLocalClient(logger, config)
+		if err != nil {
+			logger.Printf("Failed to initialize '%s' client: %s", resources.SoftlayerNFS, err.Error())
+		} else {
+			clients[resources.SoftlayerNFS] = nfsClient
+		}
+	}
+
 	if len(clients) == 0 {
 		log.Fatal("No client can be initialized....please check config file")
 		return nil, fmt.Errorf("No client can be initialized....please check config file")
 	}
 	return clients, nil
 }
+
+// newNfsLocalClient opens the database connection of the NFS client, which keeps it open for the life of the server
+func newNfsLocalClient(logger *log.Logger, config resources.UbiquityServerConfig) (resources.StorageClient, error) {
+	dbConnection := database.NewConnection()
+	if err := dbConnection.Open(); err != nil {
+		return nil, err
+	}
+	nfsClient, err := nfs.NewNfsLocalClient(logger, config, dbConnection.GetDb())
+	if err != nil {
+		dbConnection.Close()
+		return nil, err
+	}
+	return nfsClient, nil
+}
@@ -0,0 +1,168 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/IBM/ubiquity/model"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/jinzhu/gorm"
+)
+
+//go:generate counterfeiter -o ../../fakes/fake_NfsDataModel.go . NfsDataModel
+type NfsDataModel interface {
+	CreateVolumeTable() error
+	DeleteVolume(name string) error
+	InsertVolume(volumeName, directory, nfsShare, quota string, projectId uint32, opts map[string]interface{}) error
+	GetVolume(name string) (NfsVolume, bool, error)
+	ListVolumes() ([]resources.Volume, error)
+	GetMaxProjectId() (uint32, error)
+}
+
+type nfsDataModel struct {
+	log      *log.Logger
+	database *gorm.DB
+	backend  string
+}
+
+const (
+	UserSpecifiedUID   string = "uid"
+	UserSpecifiedGID   string = "gid"
+	UserSpecifiedQuota string = "quota"
+)
+
+// NfsVolume is a volume in a directory of the export root
+type NfsVolume struct {
+	ID        uint
+	Volume    resources.Volume
+	VolumeID  uint
+	Directory string // relative to the export root
+	NfsShare  string // <server>:<export path>/<directory>, which the hosts mount
+	Quota     string
+	ProjectId uint32 // XFS project of the directory, 0 if the volume has no quota
+	UID       string
+	GID       string
+}
+
+func NewNfsDataModel(log *log.Logger, db *gorm.DB, backend string) NfsDataModel {
+	return &nfsDataModel{log: log, database: db, backend: backend}
+}
+
+func (d *nfsDataModel) CreateVolumeTable() error {
+	d.log.Println("NfsDataModel: Create Volumes Table start")
+	defer d.log.Println("NfsDataModel: Create Volumes Table end")
+
+	if err := d.database.AutoMigrate(&resources.Volume{}, &NfsVolume{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *nfsDataModel) DeleteVolume(name string) error {
+	d.log.Println("NfsDataModel: DeleteVolume start")
+	defer d.log.Println("NfsDataModel: DeleteVolume end")
+
+	volume, exists, err := d.GetVolume(name)
+	if err != nil {
+		return err
+	}
+	if exists == false {
+		return fmt.Errorf("Volume : %s not found", name)
+	}
+
+	if err := d.database.Delete(&volume).Error; err != nil {
+		return err
+	}
+	if err := model.DeleteVolume(d.database, &volume.Volume).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *nfsDataModel) InsertVolume(volumeName, directory, nfsShare, quota string, projectId uint32, opts map[string]interface{}) error {
+	d.log.Println("NfsDataModel: InsertVolume start")
+	defer d.log.Println("NfsDataModel: InsertVolume end")
+
+	volume := NfsVolume{Volume: resources.Volume{Name: volumeName, Backend: d.backend}, Directory: directory, NfsShare: nfsShare,
+		Quota: quota, ProjectId: projectId}
+	if uid, exists := opts[UserSpecifiedUID]; exists {
+		volume.UID = uid.(string)
+	}
+	if gid, exists := opts[UserSpecifiedGID]; exists {
+		volume.GID = gid.(string)
+	}
+
+	if err := d.database.Create(&volume).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *nfsDataModel) GetVolume(name string) (NfsVolume, bool, error) {
+	d.log.Println("NfsDataModel: GetVolume start")
+	defer d.log.Println("NfsDataModel: GetVolume end")
+
+	volume, err := model.GetVolume(d.database, name, d.backend)
+	if err != nil {
+		if err.Error() == "record not found" {
+			return NfsVolume{}, false, nil
+		}
+		return NfsVolume{}, false, err
+	}
+
+	var nfsVolume NfsVolume
+	if err := d.database.Where("volume_id = ?", volume.ID).Preload("Volume").First(&nfsVolume).Error; err != nil {
+		if err.Error() == "record not found" {
+			return NfsVolume{}, false, nil
+		}
+		return NfsVolume{}, false, err
+	}
+	return nfsVolume, true, nil
+}
+
+func (d *nfsDataModel) ListVolumes() ([]resources.Volume, error) {
+	d.log.Println("NfsDataModel: ListVolumes start")
+	defer d.log.Println("NfsDataModel: ListVolumes end")
+
+	var volumesInDb []NfsVolume
+	if err := d.database.Preload("Volume").Find(&volumesInDb).Error; err != nil {
+		return nil, err
+	}
+	var volumes []resources.Volume
+	for _, volume := range volumesInDb {
+		volumes = append(volumes, volume.Volume)
+	}
+	return volumes, nil
+}
+
+// GetMaxProjectId return the highest XFS project id of the volumes, 0 if no volume has a quota
+func (d *nfsDataModel) GetMaxProjectId() (uint32, error) {
+	d.log.Println("NfsDataModel: GetMaxProjectId start")
+	defer d.log.Println("NfsDataModel: GetMaxProjectId end")
+
+	var maxProjectId sql.NullInt64
+	if err := d.database.Model(&NfsVolume{}).Select("max(project_id)").Row().Scan(&maxProjectId); err != nil {
+		return 0, err
+	}
+	if !maxProjectId.Valid {
+		return 0, nil
+	}
+	return uint32(maxProjectId.Int64), nil
+}
@@ -0,0 +1,463 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	"github.com/jinzhu/gorm"
+)
+
+/*
+The generic NFS backend keeps every volume in a directory of the export root of an NFS server. The ubiquity server
+mounts the export root at the mount path to create and delete the directories, and the hosts mount the directory
+of a volume, <server>:<export path>/<volume>, with the nfs mounter.
+
+A volume with a quota is an XFS project: when the ubiquity server runs on the NFS server and the export root is on an
+XFS filesystem mounted with prjquota, the directory of the volume is assigned the next free project id and the quota
+is set as the block hard limit of the project. The directory is removed from the project when the volume is removed,
+so the project id of a removed volume can be given to a new volume.
+*/
+
+const (
+	NfsShare      string = "nfs_share"
+	Directory     string = "directory"
+	ProjectId     string = "projectId"
+	IsPreexisting string = "isPreexisting"
+
+	firstProjectId uint32 = 1000 // project ids below are left to the administrator of the NFS server
+)
+
+type nfsLocalClient struct {
+	logger         *log.Logger
+	config         resources.NfsConfig
+	executor       utils.Executor
+	dataModel      NfsDataModel
+	isActivated    bool
+	activationLock *sync.RWMutex
+	projectLock    *sync.Mutex
+}
+
+func NewNfsLocalClient(logger *log.Logger, config resources.UbiquityServerConfig, db *gorm.DB) (resources.StorageClient, error) {
+	logger.Println("nfsLocalClient: init start")
+	defer logger.Println("nfsLocalClient: init end")
+
+	datamodel := NewNfsDataModel(logger, db, resources.SoftlayerNFS)
+	return NewNfsLocalClientWithDataModel(logger, config.NfsConfig, utils.NewExecutor(), datamodel)
+}
+
+func NewNfsLocalClientWithDataModel(logger *log.Logger, config resources.NfsConfig, executor utils.Executor, datamodel NfsDataModel) (resources.StorageClient, error) {
+	if config.ServerAddr == "" {
+		return nil, fmt.Errorf("nfsLocalClient: init: missing required parameter 'nfsServerAddr'")
+	}
+	if config.ExportPath == "" {
+		return nil, fmt.Errorf("nfsLocalClient: init: missing required parameter 'nfsExportPath'")
+	}
+	if config.MountPath == "" {
+		return nil, fmt.Errorf("nfsLocalClient: init: missing required parameter 'nfsMountPath'")
+	}
+	err := datamodel.CreateVolumeTable()
+	if err != nil {
+		return nil, err
+	}
+	return &nfsLocalClient{logger: logger, config: config, executor: executor, dataModel: datamodel, activationLock: &sync.RWMutex{}, projectLock: &sync.Mutex{}}, nil
+}
+
+// Activate mounts the export root at the mount path
+func (s *nfsLocalClient) Activate(activateRequest resources.ActivateRequest) error {
+	s.logger.Println("nfsLocalClient: Activate start")
+	defer s.logger.Println("nfsLocalClient: Activate end")
+
+	s.activationLock.RLock()
+	if s.isActivated {
+		s.activationLock.RUnlock()
+		return nil
+	}
+	s.activationLock.RUnlock()
+
+	s.activationLock.Lock() //get a write lock to prevent others from repeating these actions
+	defer s.activationLock.Unlock()
+
+	exportRoot := fmt.Sprintf("%s:%s", s.config.ServerAddr, s.config.ExportPath)
+	if !s.isMounted(exportRoot) {
+		err := s.executor.MkdirAll(s.config.MountPath, 0755)
+		if err != nil {
+			s.logger.Printf("Failed to create mount path %s: %s", s.config.MountPath, err.Error())
+			return err
+		}
+
+		args := []string{"-t", "nfs"}
+		if s.config.MountOptions != "" {
+			args = append(args, "-o", s.config.MountOptions)
+		}
+		args = append(args, exportRoot, s.config.MountPath)
+		output, err := s.executor.Execute("mount", args)
+		if err != nil {
+			err = fmt.Errorf("Failed to mount export root %s at %s (error '%s', output '%s')", exportRoot, s.config.MountPath, err.Error(), output)
+			s.logger.Println(err.Error())
+			return err
+		}
+	}
+
+	s.isActivated = true
+	return nil
+}
+
+func (s *nfsLocalClient) CreateVolume(createVolumeRequest resources.CreateVolumeRequest) error {
+	s.logger.Println("nfsLocalClient: create start")
+	defer s.logger.Println("nfsLocalClient: create end")
+
+	s.logger.Printf("Opts for create: %#v\n", createVolumeRequest.Opts)
+
+	quota, quotaBytes, err := validateParams(createVolumeRequest.Name, createVolumeRequest.Opts)
+	if err != nil {
+		s.logger.Printf("Error in validate params: %s\n", err.Error())
+		return err
+	}
+	if quota != "" && s.config.QuotaFilesystem == "" {
+		err = fmt.Errorf("Cannot create volume %s with quota, the quota filesystem of the NFS server is not configured", createVolumeRequest.Name)
+		s.logger.Println(err.Error())
+		return err
+	}
+
+	_, volExists, err := s.dataModel.GetVolume(createVolumeRequest.Name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	if volExists {
+		return &resources.VolAlreadyExistsError{VolName: createVolumeRequest.Name}
+	}
+
+	// without the export root the directory would be created on the local disk, and its nfs share would not exist
+	exportRoot := fmt.Sprintf("%s:%s", s.config.ServerAddr, s.config.ExportPath)
+	if !s.isMounted(exportRoot) {
+		err = fmt.Errorf("Cannot create volume %s, the export root %s is not mounted at %s (the backend is not activated)", createVolumeRequest.Name, exportRoot, s.config.MountPath)
+		s.logger.Println(err.Error())
+		return err
+	}
+
+	volumePath := path.Join(s.config.MountPath, createVolumeRequest.Name)
+	if _, err := s.executor.Stat(volumePath); err == nil {
+		return fmt.Errorf("Cannot create volume %s, directory %s already exists", createVolumeRequest.Name, volumePath)
+	}
+	s.logger.Printf("creating directory %s\n", volumePath)
+	err = s.executor.MkdirAll(volumePath, 0755)
+	if err != nil {
+		s.logger.Printf("Failed to create directory %s: %s", volumePath, err.Error())
+		return err
+	}
+
+	err = s.updatePermissions(volumePath, createVolumeRequest.Opts)
+	if err != nil {
+		s.removeDirectory(volumePath)
+		return err
+	}
+
+	// the project id is taken from the db, no other volume gets it until the volume is inserted
+	s.projectLock.Lock()
+	defer s.projectLock.Unlock()
+
+	var projectId uint32
+	if quota != "" {
+		projectId, err = s.setQuota(createVolumeRequest.Name, quotaBytes)
+		if err != nil {
+			s.removeDirectory(volumePath)
+			return err
+		}
+	}
+
+	nfsShare := fmt.Sprintf("%s:%s", s.config.ServerAddr, path.Join(s.config.ExportPath, createVolumeRequest.Name))
+	err = s.dataModel.InsertVolume(createVolumeRequest.Name, createVolumeRequest.Name, nfsShare, quota, projectId, createVolumeRequest.Opts)
+	if err != nil {
+		s.logger.Printf("Error inserting volume %v\n", err)
+		if projectId != 0 {
+			s.clearQuota(createVolumeRequest.Name, projectId)
+		}
+		s.removeDirectory(volumePath)
+		return err
+	}
+
+	s.logger.Printf("Created volume %s in directory %s\n", createVolumeRequest.Name, volumePath)
+	return nil
+}
+
+func (s *nfsLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveVolumeRequest) error {
+	s.logger.Println("nfsLocalClient: remove start")
+	defer s.logger.Println("nfsLocalClient: remove end")
+
+	existingVolume, volExists, err := s.dataModel.GetVolume(removeVolumeRequest.Name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	if volExists == false {
+		return &resources.VolumeNotFoundError{VolName: removeVolumeRequest.Name}
+	}
+
+	if existingVolume.ProjectId != 0 {
+		err = s.clearQuota(existingVolume.Directory, existingVolume.ProjectId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.dataModel.DeleteVolume(removeVolumeRequest.Name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+
+	if s.config.ForceDelete == true {
+		err = s.executor.RemoveAll(path.Join(s.config.MountPath, existingVolume.Directory))
+		if err != nil {
+			s.logger.Println(err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nfsLocalClient) ListVolumes(listVolumesRequest resources.ListVolumesRequest) ([]resources.Volume, error) {
+	s.logger.Println("nfsLocalClient: list start")
+	defer s.logger.Println("nfsLocalClient: list end")
+
+	volumesInDb, err := s.dataModel.ListVolumes()
+	if err != nil {
+		s.logger.Printf("error retrieving volumes from db %#v\n", err)
+		return nil, err
+	}
+	return volumesInDb, nil
+}
+
+func (s *nfsLocalClient) GetVolume(getVolumeRequest resources.GetVolumeRequest) (resources.Volume, error) {
+	s.logger.Println("nfsLocalClient: GetVolume start")
+	defer s.logger.Println("nfsLocalClient: GetVolume finish")
+
+	existingVolume, volExists, err := s.dataModel.GetVolume(getVolumeRequest.Name)
+	if err != nil {
+		return resources.Volume{}, err
+	}
+	if volExists == false {
+		return resources.Volume{}, &resources.VolumeNotFoundError{VolName: getVolumeRequest.Name}
+	}
+	return resources.Volume{Name: existingVolume.Volume.Name, Backend: existingVolume.Volume.Backend}, nil
+}
+
+func (s *nfsLocalClient) GetVolumeConfig(getVolumeConfigRequest resources.GetVolumeConfigRequest) (map[string]interface{}, error) {
+	s.logger.Println("nfsLocalClient: GetVolumeConfig start")
+	defer s.logger.Println("nfsLocalClient: GetVolumeConfig finish")
+
+	existingVolume, volExists, err := s.dataModel.GetVolume(getVolumeConfigRequest.Name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return nil, err
+	}
+	if volExists == false {
+		return nil, &resources.VolumeNotFoundError{VolName: getVolumeConfigRequest.Name}
+	}
+
+	volumeConfigDetails := make(map[string]interface{})
+	volumeConfigDetails[NfsShare] = existingVolume.NfsShare
+	volumeConfigDetails[Directory] = existingVolume.Directory
+	// the volumes are created by ubiquity, the nfs mounter sets the permissions of their mountpoints
+	volumeConfigDetails[IsPreexisting] = false
+	if existingVolume.UID != "" {
+		volumeConfigDetails[UserSpecifiedUID] = existingVolume.UID
+	}
+	if existingVolume.GID != "" {
+		volumeConfigDetails[UserSpecifiedGID] = existingVolume.GID
+	}
+	if existingVolume.Quota != "" {
+		volumeConfigDetails[UserSpecifiedQuota] = existingVolume.Quota
+		volumeConfigDetails[ProjectId] = existingVolume.ProjectId
+	}
+	return volumeConfigDetails, nil
+}
+
+func (s *nfsLocalClient) Attach(attachRequest resources.AttachRequest) (string, error) {
+	s.logger.Println("nfsLocalClient: attach start")
+	defer s.logger.Println("nfsLocalClient: attach end")
+
+	existingVolume, volExists, err := s.dataModel.GetVolume(attachRequest.Name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return "", err
+	}
+	if volExists == false {
+		return "", &resources.VolumeNotFoundError{VolName: attachRequest.Name}
+	}
+	return existingVolume.NfsShare, nil
+}
+
+func (s *nfsLocalClient) Detach(detachRequest resources.DetachRequest) error {
+	s.logger.Println("nfsLocalClient: detach start")
+	defer s.logger.Println("nfsLocalClient: detach end")
+
+	_, volExists, err := s.dataModel.GetVolume(detachRequest.Name)
+	if err != nil {
+		s.logger.Printf("nfsLocalClient: error in no-op detach for volume %s: %#v\n", detachRequest.Name, err)
+		return err
+	}
+	if volExists == false {
+		return &resources.VolumeNotFoundError{VolName: detachRequest.Name}
+	}
+	return nil
+}
+
+// validateParams checks the name and the opts of a new volume and return its quota and the quota in bytes,
+// "" for a volume without quota
+func validateParams(name string, opts map[string]interface{}) (string, uint64, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\x00") {
+		return "", 0, fmt.Errorf("Invalid volume name '%s', the name of an NFS volume is the name of its directory", name)
+	}
+	quota := ""
+	var quotaBytes uint64
+	for k, v := range opts {
+		value, ok := v.(string)
+		if !ok {
+			return "", 0, fmt.Errorf("Invalid '%s' = %v specified", k, v)
+		}
+		switch k {
+		case UserSpecifiedQuota:
+			bytes, err := utils.ParseQuantity(value)
+			if err != nil {
+				return "", 0, fmt.Errorf("Invalid quota '%s' specified: %s", value, err.Error())
+			}
+			if bytes == 0 {
+				return "", 0, fmt.Errorf("Invalid quota '%s' specified, the quota must be greater than 0", value)
+			}
+			quota, quotaBytes = strings.TrimSpace(value), bytes
+		case UserSpecifiedUID, UserSpecifiedGID:
+			if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+				return "", 0, fmt.Errorf("Invalid '%s' = %s specified, expected a numeric id", k, value)
+			}
+		default:
+			return "", 0, fmt.Errorf("Invalid option '%s' specified, the NFS volume options are %s, %s and %s", k, UserSpecifiedQuota, UserSpecifiedUID, UserSpecifiedGID)
+		}
+	}
+	return quota, quotaBytes, nil
+}
+
+// updatePermissions gives the directory of a volume with uid or gid to its owner, and opens it to all the users otherwise
+func (s *nfsLocalClient) updatePermissions(volumePath string, opts map[string]interface{}) error {
+	s.logger.Println("nfsLocalClient: updatePermissions-start")
+	defer s.logger.Println("nfsLocalClient: updatePermissions-end")
+
+	uid, uidSpecified := opts[UserSpecifiedUID]
+	gid, gidSpecified := opts[UserSpecifiedGID]
+	if uidSpecified || gidSpecified {
+		owner := ""
+		if uidSpecified {
+			owner = uid.(string)
+		}
+		if gidSpecified {
+			owner = fmt.Sprintf("%s:%s", owner, gid.(string))
+		}
+		args := []string{owner, volumePath}
+		_, err := s.executor.Execute("chown", args)
+		if err != nil {
+			s.logger.Printf("Failed to change owner of directory %s: %s", volumePath, err.Error())
+			return err
+		}
+		args = []string{"og-rw", volumePath}
+		_, err = s.executor.Execute("chmod", args)
+		if err != nil {
+			s.logger.Printf("Failed to set user permissions of directory %s: %s", volumePath, err.Error())
+			return err
+		}
+		return nil
+	}
+	//chmod 777 directory
+	args := []string{"777", volumePath}
+	_, err := s.executor.Execute("chmod", args)
+	if err != nil {
+		s.logger.Printf("Failed to change permissions of directory %s: %s", volumePath, err.Error())
+		return err
+	}
+	return nil
+}
+
+// setQuota makes the directory of a volume the next XFS project and limits the blocks of the project to the quota
+func (s *nfsLocalClient) setQuota(name string, quotaBytes uint64) (uint32, error) {
+	s.logger.Println("nfsLocalClient: setQuota-start")
+	defer s.logger.Println("nfsLocalClient: setQuota-end")
+
+	maxProjectId, err := s.dataModel.GetMaxProjectId()
+	if err != nil {
+		s.logger.Println(err.Error())
+		return 0, err
+	}
+	projectId := firstProjectId
+	if maxProjectId >= firstProjectId {
+		projectId = maxProjectId + 1
+	}
+
+	directory := path.Join(s.config.ExportPath, name)
+	err = s.xfsQuota(fmt.Sprintf("project -s -p %s %d", directory, projectId))
+	if err != nil {
+		return 0, err
+	}
+	err = s.xfsQuota(fmt.Sprintf("limit -p bhard=%d %d", quotaBytes, projectId))
+	if err != nil {
+		s.xfsQuota(fmt.Sprintf("project -C -p %s %d", directory, projectId))
+		return 0, err
+	}
+	return projectId, nil
+}
+
+// clearQuota removes the block limit of the project of a volume and removes the directory of the volume from the project
+func (s *nfsLocalClient) clearQuota(directory string, projectId uint32) error {
+	if err := s.xfsQuota(fmt.Sprintf("limit -p bhard=0 %d", projectId)); err != nil {
+		return err
+	}
+	return s.xfsQuota(fmt.Sprintf("project -C -p %s %d", path.Join(s.config.ExportPath, directory), projectId))
+}
+
+func (s *nfsLocalClient) xfsQuota(command string) error {
+	args := []string{"-x", "-c", command, s.config.QuotaFilesystem}
+	output, err := s.executor.Execute("xfs_quota", args)
+	if err != nil {
+		err = fmt.Errorf("Failed to run xfs_quota command '%s' on %s (error '%s', output '%s')", command, s.config.QuotaFilesystem, err.Error(), output)
+		s.logger.Println(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (s *nfsLocalClient) removeDirectory(volumePath string) {
+	if err := s.executor.RemoveAll(volumePath); err != nil {
+		s.logger.Printf("Failed to remove directory %s: %s", volumePath, err.Error())
+	}
+}
+
+func (s *nfsLocalClient) isMounted(exportRoot string) bool {
+	args := []string{"-qs", fmt.Sprintf("%s\\s%s", exportRoot, s.config.MountPath), "/proc/mounts"}
+	output, err := s.executor.Execute("grep", args)
+	if err != nil {
+		s.logger.Printf("nfsLocalClient: export root %s is not mounted at %s (error '%s', output '%s')\n", exportRoot, s.config.MountPath, err.Error(), output)
+		return false
+	}
+	return true
+}
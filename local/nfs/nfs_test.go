@@ -0,0 +1,274 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/local/nfs"
+	"github.com/IBM/ubiquity/resources"
+)
+
+var _ = Describe("nfs-local-client", func() {
+	var (
+		client        resources.StorageClient
+		logger        *log.Logger
+		fakeDataModel *fakes.FakeNfsDataModel
+		fakeExec      *fakes.FakeExecutor
+		fakeConfig    resources.NfsConfig
+		err           error
+	)
+	BeforeEach(func() {
+		logger = log.New(os.Stdout, "ubiquity: ", log.Lshortfile|log.LstdFlags)
+		fakeExec = new(fakes.FakeExecutor)
+		fakeExec.StatReturns(nil, fmt.Errorf("no such file or directory"))
+		fakeDataModel = new(fakes.FakeNfsDataModel)
+		fakeConfig = resources.NfsConfig{ServerAddr: "nfs.example.com", ExportPath: "/exports/ubiquity", MountPath: "/mnt/ubiquity-nfs",
+			QuotaFilesystem: "/exports"}
+		client, err = nfs.NewNfsLocalClientWithDataModel(logger, fakeConfig, fakeExec, fakeDataModel)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context(".Init", func() {
+		It("should fail without the export path", func() {
+			fakeConfig.ExportPath = ""
+			_, err = nfs.NewNfsLocalClientWithDataModel(logger, fakeConfig, fakeExec, fakeDataModel)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("nfsLocalClient: init: missing required parameter 'nfsExportPath'"))
+		})
+		It("should fail when the volume table cannot be created", func() {
+			fakeDataModel.CreateVolumeTableReturns(fmt.Errorf("error creating table"))
+			_, err = nfs.NewNfsLocalClientWithDataModel(logger, fakeConfig, fakeExec, fakeDataModel)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".Activate", func() {
+		It("should mount the export root at the mount path once", func() {
+			fakeExec.ExecuteReturnsOnCall(0, nil, fmt.Errorf("not mounted"))
+			fakeConfig.MountOptions = "vers=4.1"
+			client, err = nfs.NewNfsLocalClientWithDataModel(logger, fakeConfig, fakeExec, fakeDataModel)
+			Expect(err).ToNot(HaveOccurred())
+			err = client.Activate(resources.ActivateRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeExec.MkdirAllCallCount()).To(Equal(1))
+			command, args := fakeExec.ExecuteArgsForCall(1)
+			Expect(command).To(Equal("mount"))
+			Expect(args).To(Equal([]string{"-t", "nfs", "-o", "vers=4.1", "nfs.example.com:/exports/ubiquity", "/mnt/ubiquity-nfs"}))
+
+			err = client.Activate(resources.ActivateRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(2))
+		})
+		It("should not mount an export root which is already mounted", func() {
+			err = client.Activate(resources.ActivateRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(1))
+			Expect(fakeExec.MkdirAllCallCount()).To(Equal(0))
+		})
+		It("should fail when the mount fails", func() {
+			fakeExec.ExecuteReturnsOnCall(0, nil, fmt.Errorf("not mounted"))
+			fakeExec.ExecuteReturnsOnCall(1, nil, fmt.Errorf("access denied by server"))
+			err = client.Activate(resources.ActivateRequest{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".CreateVolume", func() {
+		It("should create the directory of the volume open to all the users", func() {
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{}})
+			Expect(err).ToNot(HaveOccurred())
+			path, _ := fakeExec.MkdirAllArgsForCall(0)
+			Expect(path).To(Equal("/mnt/ubiquity-nfs/vol1"))
+			command, args := fakeExec.ExecuteArgsForCall(1)
+			Expect(command).To(Equal("chmod"))
+			Expect(args).To(Equal([]string{"777", "/mnt/ubiquity-nfs/vol1"}))
+			Expect(fakeDataModel.InsertVolumeCallCount()).To(Equal(1))
+			name, directory, nfsShare, quota, projectId, _ := fakeDataModel.InsertVolumeArgsForCall(0)
+			Expect(name).To(Equal("vol1"))
+			Expect(directory).To(Equal("vol1"))
+			Expect(nfsShare).To(Equal("nfs.example.com:/exports/ubiquity/vol1"))
+			Expect(quota).To(Equal(""))
+			Expect(projectId).To(Equal(uint32(0)))
+		})
+		It("should give the directory of the volume to its uid and gid", func() {
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"uid": "1000", "gid": "100"}})
+			Expect(err).ToNot(HaveOccurred())
+			command, args := fakeExec.ExecuteArgsForCall(1)
+			Expect(command).To(Equal("chown"))
+			Expect(args).To(Equal([]string{"1000:100", "/mnt/ubiquity-nfs/vol1"}))
+		})
+		It("should set the quota of the volume on the next free project", func() {
+			fakeDataModel.GetMaxProjectIdReturns(1041, nil)
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "10G"}})
+			Expect(err).ToNot(HaveOccurred())
+			command, args := fakeExec.ExecuteArgsForCall(2)
+			Expect(command).To(Equal("xfs_quota"))
+			Expect(args).To(Equal([]string{"-x", "-c", "project -s -p /exports/ubiquity/vol1 1042", "/exports"}))
+			_, args = fakeExec.ExecuteArgsForCall(3)
+			Expect(args).To(Equal([]string{"-x", "-c", "limit -p bhard=10737418240 1042", "/exports"}))
+			_, _, _, quota, projectId, _ := fakeDataModel.InsertVolumeArgsForCall(0)
+			Expect(quota).To(Equal("10G"))
+			Expect(projectId).To(Equal(uint32(1042)))
+		})
+		It("should set a fractional quota in bytes", func() {
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "1.5Gi"}})
+			Expect(err).ToNot(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(3)
+			Expect(args).To(Equal([]string{"-x", "-c", "limit -p bhard=1610612736 1000", "/exports"}))
+			_, _, _, quota, _, _ := fakeDataModel.InsertVolumeArgsForCall(0)
+			Expect(quota).To(Equal("1.5Gi"))
+		})
+		It("should start the projects of the volumes at the first project id", func() {
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "500Mi"}})
+			Expect(err).ToNot(HaveOccurred())
+			_, _, _, _, projectId, _ := fakeDataModel.InsertVolumeArgsForCall(0)
+			Expect(projectId).To(Equal(uint32(1000)))
+		})
+		It("should remove the directory when the quota cannot be set", func() {
+			fakeExec.ExecuteReturnsOnCall(3, nil, fmt.Errorf("project quota not enabled"))
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "10g"}})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeExec.RemoveAllCallCount()).To(Equal(1))
+			Expect(fakeDataModel.InsertVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail without creating the directory when the export root is not mounted", func() {
+			fakeExec.ExecuteReturnsOnCall(0, nil, fmt.Errorf("not mounted"))
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not mounted at /mnt/ubiquity-nfs"))
+			command, _ := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("grep"))
+			Expect(fakeExec.MkdirAllCallCount()).To(Equal(0))
+			Expect(fakeDataModel.InsertVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail for a quota without a configured quota filesystem", func() {
+			fakeConfig.QuotaFilesystem = ""
+			client, err = nfs.NewNfsLocalClientWithDataModel(logger, fakeConfig, fakeExec, fakeDataModel)
+			Expect(err).ToNot(HaveOccurred())
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "10g"}})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeExec.MkdirAllCallCount()).To(Equal(0))
+		})
+		It("should fail for invalid names and opts", func() {
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "../vol1", Opts: map[string]interface{}{}})
+			Expect(err).To(HaveOccurred())
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "ten gigabytes"}})
+			Expect(err).To(HaveOccurred())
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "0"}})
+			Expect(err).To(HaveOccurred())
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"uid": "root"}})
+			Expect(err).To(HaveOccurred())
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"filesystem": "gold"}})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeExec.MkdirAllCallCount()).To(Equal(0))
+		})
+		It("should fail for an existing volume", func() {
+			fakeDataModel.GetVolumeReturns(nfs.NfsVolume{}, true, nil)
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{}})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&resources.VolAlreadyExistsError{}))
+		})
+		It("should fail for an existing directory", func() {
+			fakeExec.StatReturns(nil, nil)
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{}})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeExec.MkdirAllCallCount()).To(Equal(0))
+		})
+		It("should clear the quota and remove the directory when the volume cannot be inserted", func() {
+			fakeDataModel.InsertVolumeReturns(fmt.Errorf("error inserting volume"))
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Opts: map[string]interface{}{"quota": "10g"}})
+			Expect(err).To(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(4)
+			Expect(args).To(Equal([]string{"-x", "-c", "limit -p bhard=0 1000", "/exports"}))
+			_, args = fakeExec.ExecuteArgsForCall(5)
+			Expect(args).To(Equal([]string{"-x", "-c", "project -C -p /exports/ubiquity/vol1 1000", "/exports"}))
+			Expect(fakeExec.RemoveAllCallCount()).To(Equal(1))
+		})
+	})
+
+	Context(".RemoveVolume", func() {
+		It("should clear the quota and the project and keep the directory of the volume", func() {
+			fakeDataModel.GetVolumeReturns(nfs.NfsVolume{Directory: "vol1", Quota: "10g", ProjectId: 1000}, true, nil)
+			err = client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})
+			Expect(err).ToNot(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{"-x", "-c", "limit -p bhard=0 1000", "/exports"}))
+			_, args = fakeExec.ExecuteArgsForCall(1)
+			Expect(args).To(Equal([]string{"-x", "-c", "project -C -p /exports/ubiquity/vol1 1000", "/exports"}))
+			Expect(fakeDataModel.DeleteVolumeCallCount()).To(Equal(1))
+			Expect(fakeExec.RemoveAllCallCount()).To(Equal(0))
+		})
+		It("should keep the volume when its project cannot be cleared", func() {
+			fakeDataModel.GetVolumeReturns(nfs.NfsVolume{Directory: "vol1", Quota: "10g", ProjectId: 1000}, true, nil)
+			fakeExec.ExecuteReturnsOnCall(1, nil, fmt.Errorf("project not found"))
+			err = client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeDataModel.DeleteVolumeCallCount()).To(Equal(0))
+		})
+		It("should remove the directory of the volume with force delete", func() {
+			fakeConfig.ForceDelete = true
+			client, err = nfs.NewNfsLocalClientWithDataModel(logger, fakeConfig, fakeExec, fakeDataModel)
+			Expect(err).ToNot(HaveOccurred())
+			fakeDataModel.GetVolumeReturns(nfs.NfsVolume{Directory: "vol1"}, true, nil)
+			err = client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(0))
+			Expect(fakeExec.RemoveAllArgsForCall(0)).To(Equal("/mnt/ubiquity-nfs/vol1"))
+		})
+		It("should fail for a volume which does not exist", func() {
+			err = client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})
+			Expect(err).To(BeAssignableToTypeOf(&resources.VolumeNotFoundError{}))
+			Expect(fakeDataModel.DeleteVolumeCallCount()).To(Equal(0))
+		})
+	})
+
+	Context(".GetVolumeConfig", func() {
+		It("should return the nfs share of the volume", func() {
+			volume := nfs.NfsVolume{Volume: resources.Volume{Name: "vol1"}, Directory: "vol1", NfsShare: "nfs.example.com:/exports/ubiquity/vol1",
+				Quota: "10g", ProjectId: 1000, UID: "1000", GID: "100"}
+			fakeDataModel.GetVolumeReturns(volume, true, nil)
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(volumeConfig).To(Equal(map[string]interface{}{"nfs_share": "nfs.example.com:/exports/ubiquity/vol1", "directory": "vol1",
+				"isPreexisting": false, "uid": "1000", "gid": "100", "quota": "10g", "projectId": uint32(1000)}))
+		})
+		It("should fail for a volume which does not exist", func() {
+			_, err = client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".Attach", func() {
+		It("should return the nfs share of the volume", func() {
+			fakeDataModel.GetVolumeReturns(nfs.NfsVolume{Volume: resources.Volume{Name: "vol1"}, NfsShare: "nfs.example.com:/exports/ubiquity/vol1"}, true, nil)
+			nfsShare, err := client.Attach(resources.AttachRequest{Name: "vol1", Host: "host1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nfsShare).To(Equal("nfs.example.com:/exports/ubiquity/vol1"))
+		})
+		It("should fail for a volume which does not exist", func() {
+			_, err = client.Attach(resources.AttachRequest{Name: "vol1", Host: "host1"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
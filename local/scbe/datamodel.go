@@ -22,14 +22,18 @@ import (
 	"github.com/IBM/ubiquity/resources"
 	"github.com/IBM/ubiquity/utils/logs"
 	"github.com/jinzhu/gorm"
+	"strings"
+	"time"
 )
 
 //go:generate counterfeiter -o ../../fakes/fake_ScbeDataModel.go . ScbeDataModel
 type ScbeDataModel interface {
 	DeleteVolume(name string) error
-	InsertVolume(volumeName string, wwn string, fstype string) error
+	InsertVolume(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options ScbeVolumeOptions) error
 	GetVolume(name string) (ScbeVolume, bool, error)
 	ListVolumes() ([]ScbeVolume, error)
+	UpdateHostReport(host string, volumes []string, wwns []string) error
+	GetHostReport(host string) (ScbeHostReport, bool, error)
 }
 
 type scbeDataModel struct {
@@ -39,11 +43,62 @@ type scbeDataModel struct {
 }
 
 type ScbeVolume struct {
-	ID       uint
-	Volume   resources.Volume
-	VolumeID uint
-	WWN      string
-	FSType   string
+	ID            uint
+	Volume        resources.Volume
+	VolumeID      uint
+	WWN           string
+	FSType        string
+	Size          int               // the requested size in bytes
+	RequestedSize string            // the size option as requested, e.g. 1.5Gi, empty for an imported volume
+	AccessMode    string            // single-writer or multi-attach
+	IsPreexisting bool              // imported array volume, ubiquity never deletes it from the array
+	Options       ScbeVolumeOptions `gorm:"embedded"`
+}
+
+// ScbeVolumeOptions are the QoS and provisioning options requested for the volume (zero value means the service default)
+type ScbeVolumeOptions struct {
+	MaxIops     int
+	MaxMbps     int
+	Thin        string // "true", "false" or "" for the service default
+	Compression bool
+}
+
+// ScbeHostReport is the last list of volumes a host reported as attached, for the mapping reconciliation.
+// The reports are kept in the DB, so that the mappings of the hosts stay known after the server restarts.
+type ScbeHostReport struct {
+	ID         uint
+	Host       string `gorm:"unique_index"`
+	Volumes    string // the reported volume names, separated by commas
+	WWNs       string // the reported volume WWNs, separated by commas
+	ReportedAt time.Time
+}
+
+// ReportedVolumes return the reported volume names
+func (r ScbeHostReport) ReportedVolumes() []string {
+	return splitReportedList(r.Volumes)
+}
+
+// ReportedWWNs return the reported volume WWNs
+func (r ScbeHostReport) ReportedWWNs() []string {
+	return splitReportedList(r.WWNs)
+}
+
+// IsWWNReported return whether the host reported the WWN. The case of the WWN differs between the multipath devices of
+// the host and SCBE.
+func (r ScbeHostReport) IsWWNReported(wwn string) bool {
+	for _, reportedWWN := range r.ReportedWWNs() {
+		if strings.EqualFold(reportedWWN, wwn) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitReportedList(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	return strings.Split(list, ",")
 }
 
 func NewScbeDataModel(db *gorm.DB) ScbeDataModel {
@@ -74,14 +129,19 @@ func (d *scbeDataModel) DeleteVolume(name string) error {
 }
 
 // InsertVolume volume name and its details given in opts
-func (d *scbeDataModel) InsertVolume(volumeName string, wwn string, fstype string) error {
+func (d *scbeDataModel) InsertVolume(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options ScbeVolumeOptions) error {
 	defer d.logger.Trace(logs.DEBUG)()
 
 	volume := ScbeVolume{
 		Volume: resources.Volume{Name: volumeName,
 			Backend: fmt.Sprintf("%s", d.backend)},
-		WWN:    wwn,
-		FSType: fstype,
+		WWN:           wwn,
+		FSType:        fstype,
+		Size:          size,
+		RequestedSize: requestedSize,
+		AccessMode:    accessMode,
+		IsPreexisting: isPreexisting,
+		Options:       options,
 	}
 
 	if err := d.database.Create(&volume).Error; err != nil {
@@ -131,3 +191,40 @@ func (d *scbeDataModel) ListVolumes() ([]ScbeVolume, error) {
 
 	return volumes, nil
 }
+
+// UpdateHostReport saves the volumes the host reported, replacing its previous report
+func (d *scbeDataModel) UpdateHostReport(host string, volumes []string, wwns []string) error {
+	defer d.logger.Trace(logs.DEBUG)()
+
+	report, exists, err := d.GetHostReport(host)
+	if err != nil {
+		return d.logger.ErrorRet(err, "GetHostReport failed")
+	}
+	report.Host = host
+	report.Volumes = strings.Join(volumes, ",")
+	report.WWNs = strings.Join(wwns, ",")
+	report.ReportedAt = time.Now()
+	if exists {
+		err = d.database.Save(&report).Error
+	} else {
+		err = d.database.Create(&report).Error
+	}
+	if err != nil {
+		return d.logger.ErrorRet(err, "failed to save the host report", logs.Args{{"host", host}})
+	}
+	return nil
+}
+
+// GetHostReport return the last report of the host, or false if the host never reported
+func (d *scbeDataModel) GetHostReport(host string) (ScbeHostReport, bool, error) {
+	defer d.logger.Trace(logs.DEBUG)()
+
+	var report ScbeHostReport
+	if err := d.database.Where("host = ?", host).First(&report).Error; err != nil {
+		if err.Error() == "record not found" {
+			return ScbeHostReport{}, false, nil
+		}
+		return ScbeHostReport{}, false, d.logger.ErrorRet(err, "failed")
+	}
+	return report, true, nil
+}
@@ -26,9 +26,11 @@ import (
 type ScbeDataModelWrapper interface {
 	GetVolume(name string, mustExist bool) (ScbeVolume, error)
 	DeleteVolume(name string) error
-	InsertVolume(volumeName string, wwn string, fstype string) error
+	InsertVolume(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options ScbeVolumeOptions) error
 	ListVolumes() ([]ScbeVolume, error)
 	UpdateDatabaseVolume(newVolume *ScbeVolume)
+	UpdateHostReport(host string, volumes []string, wwns []string) error
+	GetHostReport(host string) (ScbeHostReport, bool, error)
 }
 
 type scbeDataModelWrapper struct {
@@ -39,6 +41,7 @@ type scbeDataModelWrapper struct {
 func NewScbeDataModelWrapper() ScbeDataModelWrapper {
 	database.RegisterMigration(resources.Volume{})
 	database.RegisterMigration(&ScbeVolume{})
+	database.RegisterMigration(&ScbeHostReport{})
 	return &scbeDataModelWrapper{logger: logs.GetLogger()}
 }
 
@@ -125,7 +128,7 @@ func (d *scbeDataModelWrapper) DeleteVolume(name string) error {
 	return nil
 }
 
-func (d *scbeDataModelWrapper) InsertVolume(volumeName string, wwn string, fstype string) error {
+func (d *scbeDataModelWrapper) InsertVolume(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options ScbeVolumeOptions) error {
 	defer d.logger.Trace(logs.DEBUG)()
 	var err error
 
@@ -137,7 +140,7 @@ func (d *scbeDataModelWrapper) InsertVolume(volumeName string, wwn string, fstyp
 		}
 
 		// work with memory object
-		d.UpdateDatabaseVolume(&ScbeVolume{Volume: resources.Volume{Name: volumeName, Backend: resources.SCBE}, WWN: wwn, FSType: fstype})
+		d.UpdateDatabaseVolume(&ScbeVolume{Volume: resources.Volume{Name: volumeName, Backend: resources.SCBE}, WWN: wwn, FSType: fstype, Size: size, RequestedSize: requestedSize, AccessMode: accessMode, IsPreexisting: isPreexisting, Options: options})
 
 	} else {
 
@@ -150,7 +153,7 @@ func (d *scbeDataModelWrapper) InsertVolume(volumeName string, wwn string, fstyp
 
 		// insert volume
 		dataModel := NewScbeDataModel(dbConnection.GetDb())
-		if err = dataModel.InsertVolume(volumeName, wwn, fstype); err != nil {
+		if err = dataModel.InsertVolume(volumeName, wwn, fstype, size, requestedSize, accessMode, isPreexisting, options); err != nil {
 			return d.logger.ErrorRet(err, "dataModel.InsertVolume failed")
 		}
 	}
@@ -182,3 +185,38 @@ func (d *scbeDataModelWrapper) ListVolumes() ([]ScbeVolume, error) {
 
 	return volumes, nil
 }
+
+func (d *scbeDataModelWrapper) UpdateHostReport(host string, volumes []string, wwns []string) error {
+	defer d.logger.Trace(logs.DEBUG)()
+
+	// open db connection
+	dbConnection := database.NewConnection()
+	if err := dbConnection.Open(); err != nil {
+		return d.logger.ErrorRet(err, "dbConnection.Open failed")
+	}
+	defer dbConnection.Close()
+
+	dataModel := NewScbeDataModel(dbConnection.GetDb())
+	if err := dataModel.UpdateHostReport(host, volumes, wwns); err != nil {
+		return d.logger.ErrorRet(err, "dataModel.UpdateHostReport failed")
+	}
+	return nil
+}
+
+func (d *scbeDataModelWrapper) GetHostReport(host string) (ScbeHostReport, bool, error) {
+	defer d.logger.Trace(logs.DEBUG)()
+
+	// open db connection
+	dbConnection := database.NewConnection()
+	if err := dbConnection.Open(); err != nil {
+		return ScbeHostReport{}, false, d.logger.ErrorRet(err, "dbConnection.Open failed")
+	}
+	defer dbConnection.Close()
+
+	dataModel := NewScbeDataModel(dbConnection.GetDb())
+	report, exists, err := dataModel.GetHostReport(host)
+	if err != nil {
+		return ScbeHostReport{}, false, d.logger.ErrorRet(err, "dataModel.GetHostReport failed")
+	}
+	return report, exists, nil
+}
@@ -48,14 +48,14 @@ var _ = Describe("ScbeDataModelWrapper test", func() {
         Context("InsertVolume", func() {
             It("succeed for db volume", func() {
                 defer database.InitTestError()()
-                err = dataModelWrapper.InsertVolume(volumeNameDb, volumeWwnDb, volumeFsTypeDb)
+                err = dataModelWrapper.InsertVolume(volumeNameDb, volumeWwnDb, volumeFsTypeDb, 0, "", "", false, scbe.ScbeVolumeOptions{})
                 Expect(err).To(Not(HaveOccurred()))
                 scbeVolume, err = dataModelWrapper.GetVolume(volumeNameDb, true)
                 Expect(err).To(Not(HaveOccurred()))
             })
             It("fail for non db volume", func() {
                 defer database.InitTestError()()
-                err = dataModelWrapper.InsertVolume(volumeName, volumeWwn, volumeFsType)
+                err = dataModelWrapper.InsertVolume(volumeName, volumeWwn, volumeFsType, 0, "", "", false, scbe.ScbeVolumeOptions{})
                 Expect(err).To(HaveOccurred())
                 scbeVolume, err = dataModelWrapper.GetVolume(volumeName, true)
                 Expect(err).To(HaveOccurred())
@@ -64,7 +64,7 @@ var _ = Describe("ScbeDataModelWrapper test", func() {
         Context("DeleteVolume", func() {
             It("succeed for db volume", func() {
                 defer database.InitTestError()()
-                err = dataModelWrapper.InsertVolume(volumeNameDb, volumeWwnDb, volumeFsTypeDb)
+                err = dataModelWrapper.InsertVolume(volumeNameDb, volumeWwnDb, volumeFsTypeDb, 0, "", "", false, scbe.ScbeVolumeOptions{})
                 Expect(err).To(Not(HaveOccurred()))
                 scbeVolume, err = dataModelWrapper.GetVolume(volumeNameDb, true)
                 Expect(err).To(Not(HaveOccurred()))
@@ -77,7 +77,7 @@ var _ = Describe("ScbeDataModelWrapper test", func() {
         Context("UpdateDatabaseVolume", func() {
             It("succeed", func() {
                 defer database.InitTestError()()
-                err = dataModelWrapper.InsertVolume(volumeNameDb, volumeWwnDb, volumeFsTypeDb)
+                err = dataModelWrapper.InsertVolume(volumeNameDb, volumeWwnDb, volumeFsTypeDb, 0, "", "", false, scbe.ScbeVolumeOptions{})
                 Expect(err).To(Not(HaveOccurred()))
                 scbeVolume, err = dataModelWrapper.GetVolume(volumeNameDb, true)
                 Expect(err).To(Not(HaveOccurred()))
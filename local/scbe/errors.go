@@ -32,6 +32,53 @@ func (e *serviceDoesntExistError) Error() string {
 		e.volName, ScName, e.serviceName, resources.ScbeInterfaceName, e.scbeName)
 }
 
+type serviceNotEnoughCapacityError struct {
+	volName     string
+	serviceName string
+	size        int
+	freeSize    int
+}
+
+func (e *serviceNotEnoughCapacityError) Error() string {
+	return fmt.Sprintf("Cannot create volume [%s] with size [%d] bytes on %s service [%s]. The service can provision up to [%d] bytes",
+		e.volName, e.size, ScName, e.serviceName, e.freeSize)
+}
+
+type serviceQosLimitExceededError struct {
+	volName     string
+	serviceName string
+	option      string
+	value       int
+	maxValue    int
+}
+
+func (e *serviceQosLimitExceededError) Error() string {
+	return fmt.Sprintf("Cannot create volume [%s] with [%s=%d] on %s service [%s]. The service allows up to [%d]",
+		e.volName, e.option, e.value, ScName, e.serviceName, e.maxValue)
+}
+
+type serviceCapabilityNotSupportedError struct {
+	volName     string
+	serviceName string
+	capability  string
+}
+
+func (e *serviceCapabilityNotSupportedError) Error() string {
+	return fmt.Sprintf("Cannot create volume [%s] on %s service [%s]. The service does not support [%s]",
+		e.volName, ScName, e.serviceName, e.capability)
+}
+
+type noServiceWithEnoughCapacityError struct {
+	volName  string
+	size     int
+	services string
+}
+
+func (e *noServiceWithEnoughCapacityError) Error() string {
+	return fmt.Sprintf("Cannot create volume [%s] with size [%d] bytes. None of the allowed %s services [%s] has enough free capacity",
+		e.volName, e.size, ScName, e.services)
+}
+
 type mappingResponseError struct {
 	mapping ScbeResponseMappings
 }
@@ -91,6 +138,101 @@ func (e *provisionParamIsNotNumberError) Error() string {
 		e.volName, e.param)
 }
 
+type provisionParamIsNotBooleanError struct {
+	volName string
+	param   string
+}
+
+func (e *provisionParamIsNotBooleanError) Error() string {
+	return fmt.Sprintf("Volume [%s] provisioning failure due to a non-boolean value set for option [%s]",
+		e.volName, e.param)
+}
+
+type provisionParamsConflictError struct {
+	volName string
+	param1  string
+	param2  string
+}
+
+func (e *provisionParamsConflictError) Error() string {
+	return fmt.Sprintf("Volume [%s] provisioning failure due to conflicting options [%s] and [%s]",
+		e.volName, e.param1, e.param2)
+}
+
+type AccessModeNotSupportedError struct {
+	volName        string
+	accessMode     string
+	supportedModes string
+}
+
+func (e *AccessModeNotSupportedError) Error() string {
+	return fmt.Sprintf("Volume [%s] provisioning failure due to unsupported access mode [%s], supported modes are [%s]",
+		e.volName, e.accessMode, e.supportedModes)
+}
+
+type volumeSizeTooSmallError struct {
+	volName string
+	size    uint64
+	minSize uint64
+}
+
+func (e *volumeSizeTooSmallError) Error() string {
+	return fmt.Sprintf("Volume [%s] provisioning failure due to size [%d] bytes smaller than the minimum [%d] bytes",
+		e.volName, e.size, e.minSize)
+}
+
+type volumeSizeGranularityError struct {
+	volName     string
+	size        uint64
+	granularity uint64
+}
+
+func (e *volumeSizeGranularityError) Error() string {
+	return fmt.Sprintf("Volume [%s] provisioning failure due to size [%d] bytes not being a multiple of [%d] bytes",
+		e.volName, e.size, e.granularity)
+}
+
+type importVolumeNotFoundError struct {
+	volName  string
+	importId string
+	found    int
+}
+
+func (e *importVolumeNotFoundError) Error() string {
+	return fmt.Sprintf("Volume [%s] import failure, expected one array volume with WWN or name [%s] but found [%d]",
+		e.volName, e.importId, e.found)
+}
+
+type importIdEmptyError struct {
+	volName string
+}
+
+func (e *importIdEmptyError) Error() string {
+	return fmt.Sprintf("Volume [%s] import failure, option [%s] must be the WWN or the name of an array volume",
+		e.volName, OptionNameForImport)
+}
+
+type importVolumeAlreadyManagedError struct {
+	volName      string
+	importId     string
+	managedAsVol string
+}
+
+func (e *importVolumeAlreadyManagedError) Error() string {
+	return fmt.Sprintf("Volume [%s] import failure, array volume [%s] is already managed as volume [%s]",
+		e.volName, e.importId, e.managedAsVol)
+}
+
+type importOptionConflictError struct {
+	volName string
+	option  string
+}
+
+func (e *importOptionConflictError) Error() string {
+	return fmt.Sprintf("Volume [%s] import failure, option [%s] cannot be used with option [%s]",
+		e.volName, e.option, OptionNameForImport)
+}
+
 type volAlreadyAttachedError struct {
 	volName  string
 	hostName string
@@ -127,6 +269,18 @@ func (e *ConfigDefaultSizeNotNumError) Error() string {
 		"ScbeConfig.DefaultVolumeSize")
 }
 
+type ConfigDefaultSizeInvalidError struct {
+	size          string
+	sizeInBytes   uint64
+	plainSizeUnit string
+}
+
+func (e *ConfigDefaultSizeInvalidError) Error() string {
+	return fmt.Sprintf("Configuration file error. The [%s] parameter value [%s] is [%d] bytes, it must be at least [%d] bytes and a multiple of [%d] bytes. "+
+		"A size without unit is in [%s], set SCBE_PLAIN_SIZE_IN_GB=true for GB or add a unit such as Gi",
+		"ScbeConfig.DefaultVolumeSize", e.size, e.sizeInBytes, MinVolumeSize, VolumeSizeGranularity, e.plainSizeUnit)
+}
+
 type ConfigDefaultFilesystemTypeNotSupported struct {
 	wrongFStype    string
 	supportedTypes string
@@ -225,3 +379,19 @@ func (e *BadHttpStatusCodeError) Error() string {
 		e.httpUrl,
 	)
 }
+
+type mappingMayBeInUseError struct {
+	volName string
+	host    string
+	status  string
+	reason  string
+}
+
+func (e *mappingMayBeInUseError) Error() string {
+	if e.status == "" {
+		e.status = "in use"
+		e.reason = "host reports the volume as attached"
+	}
+	return fmt.Sprintf("Cannot unmap volume [%s] from host [%s], the mapping is %s (%s). Force the unmap if the host does not use the volume",
+		e.volName, e.host, e.status, e.reason)
+}
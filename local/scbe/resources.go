@@ -176,10 +176,21 @@ Example of SCBE response on A9000 volume
 */
 
 type ScbeCreateVolumePostParams struct {
-	Service  string `json:"service"`
-	Name     string `json:"name"`
-	Size     int    `json:"size"`
-	SizeUnit string `json:"size_unit"`
+	Service          string `json:"service"`
+	Name             string `json:"name"`
+	Size             int    `json:"size"`
+	SizeUnit         string `json:"size_unit"`
+	MaxIops          int    `json:"max_iops,omitempty"`
+	MaxMbps          int    `json:"max_mbps,omitempty"`
+	ProvisioningType string `json:"provisioning_type,omitempty"` // thin or thick, the service default if empty
+	Compression      bool   `json:"compression,omitempty"`
+}
+
+type ScbeCreateHostPostParams struct {
+	ArrayId    string   `json:"array_id"`
+	Name       string   `json:"name"`
+	IscsiPorts []string `json:"iscsi_ports,omitempty"`
+	FcPorts    []string `json:"fc_ports,omitempty"`
 }
 
 type ScbeMapVolumePostParams struct {
@@ -26,6 +26,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type scbeLocalClient struct {
@@ -36,22 +37,39 @@ type scbeLocalClient struct {
 	activationLock *sync.RWMutex
 	locker         utils.Locker
 	restClients    *sync.Map
+	startedAt      time.Time // hosts may not have reported since the server started, see getMappingStatus
 }
 
 const (
-	OptionNameForServiceName = "profile"
-	OptionNameForVolumeSize  = "size"
-	volumeNamePrefix         = "u_"
-	AttachedToNothing        = "" // during provisioning the volume is not attached to any host
-	EmptyHost                = ""
-	ComposeVolumeName        = volumeNamePrefix + "%s_%s" // e.g u_instance1_volName
-	MaxVolumeNameLength      = 63                         // IBM block storage max volume name cannot exceed this length
-
-	GetVolumeConfigExtraParams = 2 // number of extra params added to the VolumeConfig beyond the scbe volume struct
+	OptionNameForServiceName  = "profile"
+	OptionNameForVolumeSize   = "size"
+	OptionValueForAutoService = "auto" // let ubiquity choose the allowed service with the most free capacity
+	OptionNameForAccessMode   = "access-mode"
+	AccessModeSingleWriter    = "single-writer" // the volume can be mapped to one host at a time (default)
+	AccessModeMultiAttach     = "multi-attach"  // the volume can be mapped to several hosts, e.g for clustered filesystems
+	OptionNameForImport       = "import"        // WWN or name of an existing array volume to manage instead of provisioning a new one
+	IsPreexisting             = "isPreexisting"
+	OptionNameForMaxIops      = "max-iops"    // per volume IOPS limit
+	OptionNameForMaxMbps      = "max-mbps"    // per volume bandwidth limit in MB/s
+	OptionNameForThin         = "thin"        // true for thin provisioning, false for thick (the service default if not given)
+	OptionNameForCompression  = "compression" // true to compress the volume, requires thin provisioning
+	volumeNamePrefix          = "u_"
+	AttachedToNothing         = "" // during provisioning the volume is not attached to any host
+	EmptyHost                 = ""
+	ComposeVolumeName         = volumeNamePrefix + "%s_%s" // e.g u_instance1_volName
+	MaxVolumeNameLength       = 63                         // IBM block storage max volume name cannot exceed this length
+
+	GetVolumeConfigExtraParams = 8 // number of extra params added to the VolumeConfig beyond the scbe volume struct
+
+	MinVolumeSize         = utils.MiB // smallest volume size in bytes that can be provisioned on IBM block storage
+	VolumeSizeGranularity = utils.MiB // volume size in bytes must be a multiple of this value (the smallest unit SCBE accepts)
+
+	HostReportTimeout = 10 * time.Minute // a host that did not report its attachments for this long is considered dead
 )
 
 var (
-	SupportedFSTypes = []string{"ext4", "xfs"}
+	SupportedFSTypes     = []string{"ext4", "xfs"}
+	SupportedAccessModes = []string{AccessModeSingleWriter, AccessModeMultiAttach}
 )
 
 func NewScbeLocalClient(config resources.ScbeConfig) (resources.StorageClient, error) {
@@ -75,6 +93,7 @@ func NewScbeLocalClientWithNewScbeRestClientAndDataModel(config resources.ScbeCo
 		activationLock: &sync.RWMutex{},
 		locker:         utils.NewLocker(),
 		restClients:    new(sync.Map),
+		startedAt:      time.Now(),
 	}
 
 	if err := client.basicScbeLocalClientStartupAndValidation(scbeRestClient); err != nil {
@@ -157,10 +176,17 @@ func validateScbeConfig(config *resources.ScbeConfig) error {
 		config.DefaultVolumeSize = resources.DefaultForScbeConfigParamDefaultVolumeSize
 		logger.Debug("No DefaultVolumeSize defined in conf file, so set the DefaultVolumeSize to value " + resources.DefaultForScbeConfigParamDefaultVolumeSize)
 	}
-	_, err := strconv.Atoi(config.DefaultVolumeSize)
+	defaultSize, err := parseVolumeSize(config.DefaultVolumeSize, config.PlainSizeInGB)
 	if err != nil {
 		return logger.ErrorRet(&ConfigDefaultSizeNotNumError{}, "failed")
 	}
+	if defaultSize < MinVolumeSize || defaultSize%VolumeSizeGranularity != 0 {
+		plainSizeUnit := "bytes"
+		if config.PlainSizeInGB {
+			plainSizeUnit = DefaultSizeUnit
+		}
+		return logger.ErrorRet(&ConfigDefaultSizeInvalidError{config.DefaultVolumeSize, defaultSize, plainSizeUnit}, "failed")
+	}
 
 	if config.DefaultFilesystemType == "" {
 		// means customer didn't configure the default
@@ -219,20 +245,6 @@ func (s *scbeLocalClient) CreateVolume(createVolumeRequest resources.CreateVolum
 		return s.logger.ErrorRet(err, "dataModel.GetVolume failed", logs.Args{{"name", createVolumeRequest.Name}})
 	}
 
-	// validate size option given
-	sizeStr, ok := createVolumeRequest.Opts[OptionNameForVolumeSize]
-	if !ok {
-		sizeStr = s.config.DefaultVolumeSize
-		s.logger.Debug("No size given to create volume, so using the default_size",
-			logs.Args{{"volume", createVolumeRequest.Name}, {"default_size", sizeStr}})
-	}
-
-	// validate size is a number
-	size, err := strconv.Atoi(sizeStr.(string))
-	if err != nil {
-		return s.logger.ErrorRet(&provisionParamIsNotNumberError{createVolumeRequest.Name, OptionNameForVolumeSize}, "failed")
-	}
-
 	// validate fstype option given
 	fstypeInt, ok := createVolumeRequest.Opts[resources.OptionNameForVolumeFsType]
 	var fstype string
@@ -248,12 +260,60 @@ func (s *scbeLocalClient) CreateVolume(createVolumeRequest resources.CreateVolum
 			&FsTypeNotSupportedError{createVolumeRequest.Name, fstype, strings.Join(SupportedFSTypes, ",")}, "failed")
 	}
 
+	// validate access mode option given
+	accessMode := AccessModeSingleWriter
+	if accessModeInt, ok := createVolumeRequest.Opts[OptionNameForAccessMode]; ok {
+		accessMode = accessModeInt.(string)
+	}
+	if !utils.StringInSlice(accessMode, SupportedAccessModes) {
+		return s.logger.ErrorRet(
+			&AccessModeNotSupportedError{createVolumeRequest.Name, accessMode, strings.Join(SupportedAccessModes, ",")}, "failed")
+	}
+
+	// validate QoS and provisioning options given
+	volumeOptions, err := parseVolumeOptions(createVolumeRequest.Name, createVolumeRequest.Opts)
+	if err != nil {
+		return s.logger.ErrorRet(err, "parseVolumeOptions failed")
+	}
+
+	// import an existing array volume instead of provisioning a new one
+	if importInt, ok := createVolumeRequest.Opts[OptionNameForImport]; ok {
+		importId, _ := importInt.(string)
+		return s.importVolume(scbeRestClient, createVolumeRequest, importId, fstype, accessMode)
+	}
+
+	// validate size option given
+	sizeStr, ok := createVolumeRequest.Opts[OptionNameForVolumeSize]
+	if !ok {
+		sizeStr = s.config.DefaultVolumeSize
+		s.logger.Debug("No size given to create volume, so using the default_size",
+			logs.Args{{"volume", createVolumeRequest.Name}, {"default_size", sizeStr}})
+	}
+
+	// validate size is a valid quantity that the storage can provision
+	size, err := parseVolumeSize(sizeStr.(string), s.config.PlainSizeInGB)
+	if err != nil {
+		return s.logger.ErrorRet(&provisionParamIsNotNumberError{createVolumeRequest.Name, OptionNameForVolumeSize}, "failed", logs.Args{{"error", err}})
+	}
+	if size < MinVolumeSize {
+		return s.logger.ErrorRet(&volumeSizeTooSmallError{createVolumeRequest.Name, size, MinVolumeSize}, "failed")
+	}
+	if size%VolumeSizeGranularity != 0 {
+		return s.logger.ErrorRet(&volumeSizeGranularityError{createVolumeRequest.Name, size, VolumeSizeGranularity}, "failed")
+	}
+
 	// Get the profile option
 	profile := s.config.DefaultService
 	if createVolumeRequest.Opts[OptionNameForServiceName] != "" && createVolumeRequest.Opts[OptionNameForServiceName] != nil {
 		profile = createVolumeRequest.Opts[OptionNameForServiceName].(string)
 	}
 
+	if profile == OptionValueForAutoService {
+		if profile, err = s.selectServiceByCapacity(scbeRestClient, createVolumeRequest.Name, int(size)); err != nil {
+			return s.logger.ErrorRet(err, "selectServiceByCapacity failed")
+		}
+	}
+
 	// Generate the designated volume name by template
 	volNameToCreate := fmt.Sprintf(ComposeVolumeName, s.config.UbiquityInstanceName, createVolumeRequest.Name)
 
@@ -267,20 +327,190 @@ func (s *scbeLocalClient) CreateVolume(createVolumeRequest resources.CreateVolum
 
 	// Provision the volume on SCBE service
 	volInfo := ScbeVolumeInfo{}
-	volInfo, err = scbeRestClient.CreateVolume(volNameToCreate, profile, size)
+	volInfo, err = scbeRestClient.CreateVolume(volNameToCreate, profile, int(size), volumeOptions)
 	if err != nil {
 		return s.logger.ErrorRet(err, "scbeRestClient.CreateVolume failed")
 	}
 
-	err = s.dataModel.InsertVolume(createVolumeRequest.Name, volInfo.Wwn, fstype)
+	err = s.dataModel.InsertVolume(createVolumeRequest.Name, volInfo.Wwn, fstype, int(size), sizeStr.(string), accessMode, false, volumeOptions)
+	if err != nil {
+		return s.logger.ErrorRet(err, "dataModel.InsertVolume failed")
+	}
+
+	s.logger.Info("succeeded", logs.Args{{"volume", createVolumeRequest.Name}, {"profile", profile}, {"size", size}})
+	return nil
+}
+
+// importVolume adds an existing array volume, given by WWN or name, to ubiquity as a preexisting volume
+func (s *scbeLocalClient) importVolume(scbeRestClient ScbeRestClient, createVolumeRequest resources.CreateVolumeRequest, importId string, fstype string, accessMode string) error {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	// an empty WWN lists all the array volumes, so findArrayVolume could pick an unrelated volume
+	importId = strings.TrimSpace(importId)
+	if importId == "" {
+		return s.logger.ErrorRet(&importIdEmptyError{createVolumeRequest.Name}, "failed")
+	}
+
+	// the array volume already has a size, a service and provisioning options
+	for _, option := range []string{OptionNameForVolumeSize, OptionNameForServiceName, OptionNameForMaxIops, OptionNameForMaxMbps, OptionNameForThin, OptionNameForCompression} {
+		if _, ok := createVolumeRequest.Opts[option]; ok {
+			return s.logger.ErrorRet(&importOptionConflictError{createVolumeRequest.Name, option}, "failed")
+		}
+	}
+
+	volInfo, err := s.findArrayVolume(scbeRestClient, createVolumeRequest.Name, importId)
+	if err != nil {
+		return s.logger.ErrorRet(err, "findArrayVolume failed")
+	}
+
+	// verify the array volume is not managed already (by this or another ubiquity volume)
+	volumesInDb, err := s.dataModel.ListVolumes()
+	if err != nil {
+		return s.logger.ErrorRet(err, "dataModel.ListVolumes failed")
+	}
+	for _, volume := range volumesInDb {
+		if volume.WWN == volInfo.Wwn {
+			return s.logger.ErrorRet(&importVolumeAlreadyManagedError{createVolumeRequest.Name, importId, volume.Volume.Name}, "failed")
+		}
+	}
+	if database.IsDatabaseVolume(volInfo.Name) {
+		return s.logger.ErrorRet(&importVolumeAlreadyManagedError{createVolumeRequest.Name, importId, database.VolumeNameSuffix}, "failed")
+	}
+
+	size, err := strconv.Atoi(volInfo.LogicalCapacity)
+	if err != nil {
+		s.logger.Warning("Cannot parse the array volume capacity", logs.Args{{"volume", volInfo.Name}, {"capacity", volInfo.LogicalCapacity}})
+		size = 0
+	}
+
+	err = s.dataModel.InsertVolume(createVolumeRequest.Name, volInfo.Wwn, fstype, size, "", accessMode, true, ScbeVolumeOptions{})
 	if err != nil {
 		return s.logger.ErrorRet(err, "dataModel.InsertVolume failed")
 	}
 
-	s.logger.Info("succeeded", logs.Args{{"volume", createVolumeRequest.Name}, {"profile", profile}})
+	s.logger.Info("succeeded", logs.Args{{"volume", createVolumeRequest.Name}, {"imported", volInfo.Name}, {"wwn", volInfo.Wwn}})
 	return nil
 }
 
+// findArrayVolume return the only array volume with the given WWN, or else with the given name
+func (s *scbeLocalClient) findArrayVolume(scbeRestClient ScbeRestClient, volName string, importId string) (ScbeVolumeInfo, error) {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	volumes, err := scbeRestClient.GetVolumes(importId)
+	if err != nil {
+		return ScbeVolumeInfo{}, s.logger.ErrorRet(err, "scbeRestClient.GetVolumes failed", logs.Args{{"wwn", importId}})
+	}
+	if len(volumes) == 1 {
+		return volumes[0], nil
+	}
+
+	// not a WWN, look for the array volume by name
+	volumes, err = scbeRestClient.GetVolumes("")
+	if err != nil {
+		return ScbeVolumeInfo{}, s.logger.ErrorRet(err, "scbeRestClient.GetVolumes failed")
+	}
+	var found []ScbeVolumeInfo
+	for _, volume := range volumes {
+		if volume.Name == importId {
+			found = append(found, volume)
+		}
+	}
+	if len(found) != 1 {
+		return ScbeVolumeInfo{}, s.logger.ErrorRet(&importVolumeNotFoundError{volName, importId, len(found)}, "failed")
+	}
+	return found[0], nil
+}
+
+// parseVolumeOptions return the QoS and provisioning options given in opts
+func parseVolumeOptions(volName string, opts map[string]interface{}) (ScbeVolumeOptions, error) {
+	options := ScbeVolumeOptions{}
+	for option, value := range map[string]*int{OptionNameForMaxIops: &options.MaxIops, OptionNameForMaxMbps: &options.MaxMbps} {
+		valueInt, ok := opts[option]
+		if !ok {
+			continue
+		}
+		number, err := strconv.Atoi(fmt.Sprintf("%v", valueInt))
+		if err != nil || number <= 0 {
+			return ScbeVolumeOptions{}, &provisionParamIsNotNumberError{volName, option}
+		}
+		*value = number
+	}
+
+	if thinInt, ok := opts[OptionNameForThin]; ok {
+		thin, err := strconv.ParseBool(fmt.Sprintf("%v", thinInt))
+		if err != nil {
+			return ScbeVolumeOptions{}, &provisionParamIsNotBooleanError{volName, OptionNameForThin}
+		}
+		options.Thin = strconv.FormatBool(thin)
+	}
+
+	if compressionInt, ok := opts[OptionNameForCompression]; ok {
+		compression, err := strconv.ParseBool(fmt.Sprintf("%v", compressionInt))
+		if err != nil {
+			return ScbeVolumeOptions{}, &provisionParamIsNotBooleanError{volName, OptionNameForCompression}
+		}
+		options.Compression = compression
+	}
+
+	if options.Compression && options.Thin == "false" {
+		return ScbeVolumeOptions{}, &provisionParamsConflictError{volName, OptionNameForCompression, OptionNameForThin}
+	}
+	return options, nil
+}
+
+// parseVolumeSize return the size in bytes of a size option such as 500Mi, 1.5Gi, 2T or 1073741824 (bytes).
+// With plainSizeInGB a plain number is in DefaultSizeUnit, as it was before units were supported.
+func parseVolumeSize(sizeStr string, plainSizeInGB bool) (uint64, error) {
+	if size, err := strconv.ParseUint(sizeStr, 10, 64); err == nil && plainSizeInGB {
+		sizeStr = fmt.Sprintf("%d%s", size, DefaultSizeUnit)
+	}
+	return utils.ParseQuantity(sizeStr)
+}
+
+// selectServiceByCapacity return the allowed service with the most headroom that can provision the given size (in bytes),
+// or the first allowed service that does not report its capacity if no service that reports it has enough headroom
+func (s *scbeLocalClient) selectServiceByCapacity(scbeRestClient ScbeRestClient, volName string, size int) (string, error) {
+	defer s.logger.Trace(logs.DEBUG)()
+	services, err := scbeRestClient.GetServices()
+	if err != nil {
+		return "", s.logger.ErrorRet(err, "scbeRestClient.GetServices failed")
+	}
+
+	selected := ""
+	selectedHeadroom := 0
+	unreported := ""
+	var candidates []string
+	for _, service := range services {
+		if len(s.config.AllowedServices) > 0 && !utils.StringInSlice(service.Name, s.config.AllowedServices) {
+			continue
+		}
+		candidates = append(candidates, service.Name)
+		if !IsServiceCapacityReported(service) {
+			s.logger.Debug("service candidate without reported capacity", logs.Args{{"service", service.Name}})
+			if unreported == "" {
+				unreported = service.Name
+			}
+			continue
+		}
+		headroom := GetServiceHeadroom(service)
+		s.logger.Debug("service candidate", logs.Args{{"service", service.Name}, {"headroom", headroom}})
+		if headroom >= size && headroom > selectedHeadroom {
+			selected = service.Name
+			selectedHeadroom = headroom
+		}
+	}
+	if selected == "" && unreported != "" {
+		s.logger.Info("selected service without reported capacity", logs.Args{{"volume", volName}, {"service", unreported}})
+		return unreported, nil
+	}
+	if selected == "" {
+		return "", s.logger.ErrorRet(&noServiceWithEnoughCapacityError{volName, size, strings.Join(candidates, ",")}, "failed")
+	}
+
+	s.logger.Info("selected service by capacity", logs.Args{{"volume", volName}, {"service", selected}, {"headroom", selectedHeadroom}})
+	return selected, nil
+}
+
 func (s *scbeLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveVolumeRequest) (err error) {
 	defer s.logger.Trace(logs.DEBUG)()
 
@@ -295,16 +525,18 @@ func (s *scbeLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveVolum
 		return s.logger.ErrorRet(err, "dataModel.GetVolume failed")
 	}
 
-	hostAttach, err := scbeRestClient.GetVolMapping(existingVolume.WWN)
+	hostsAttach, err := scbeRestClient.GetVolMapping(existingVolume.WWN)
 	if err != nil {
 		return s.logger.ErrorRet(err, "scbeRestClient.GetVolMapping failed")
 	}
 
-	if hostAttach != EmptyHost {
-		return s.logger.ErrorRet(&CannotDeleteVolWhichAttachedToHostError{removeVolumeRequest.Name, hostAttach}, "failed")
+	if len(hostsAttach) > 0 {
+		return s.logger.ErrorRet(&CannotDeleteVolWhichAttachedToHostError{removeVolumeRequest.Name, strings.Join(hostsAttach, ",")}, "failed")
 	}
 
-	if err = scbeRestClient.DeleteVolume(existingVolume.WWN); err != nil {
+	if existingVolume.IsPreexisting {
+		s.logger.Info("Volume was imported, so it stays on the array", logs.Args{{"volume", removeVolumeRequest.Name}, {"wwn", existingVolume.WWN}})
+	} else if err = scbeRestClient.DeleteVolume(existingVolume.WWN); err != nil {
 		return s.logger.ErrorRet(err, "scbeRestClient.DeleteVolume failed")
 	}
 
@@ -376,13 +608,19 @@ func (s *scbeLocalClient) GetVolumeConfig(getVolumeConfigRequest resources.GetVo
 	// The ubiquity remote will use this extra info to determine the fstype needed to be created on this volume while attaching
 	volConfig[resources.OptionNameForVolumeFsType] = scbeVolume.FSType
 
-	// The ubiquity remote will use this extra info to determine is-attached
-	hostAttach, err := scbeRestClient.GetVolMapping(scbeVolume.WWN)
+	// The ubiquity remote will use this extra info to determine is-attached (comma separated list for multi-attach volumes)
+	hostsAttach, err := scbeRestClient.GetVolMapping(scbeVolume.WWN)
 	if err != nil {
 		return nil, s.logger.ErrorRet(err, "scbeRestClient.GetVolMapping failed")
 	}
 
-	volConfig[resources.ScbeKeyVolAttachToHost] = hostAttach
+	volConfig[resources.ScbeKeyVolAttachToHost] = strings.Join(hostsAttach, ",")
+	volConfig[OptionNameForAccessMode] = getAccessMode(scbeVolume)
+	volConfig[IsPreexisting] = scbeVolume.IsPreexisting
+	volConfig[OptionNameForMaxIops] = scbeVolume.Options.MaxIops
+	volConfig[OptionNameForMaxMbps] = scbeVolume.Options.MaxMbps
+	volConfig[OptionNameForThin] = scbeVolume.Options.Thin
+	volConfig[OptionNameForCompression] = scbeVolume.Options.Compression
 
 	return volConfig, nil
 }
@@ -410,22 +648,28 @@ func (s *scbeLocalClient) Attach(attachRequest resources.AttachRequest) (string,
 		return "", s.logger.ErrorRet(err, "dataModel.GetVolume failed")
 	}
 
-	hostAttach, err := scbeRestClient.GetVolMapping(existingVolume.WWN)
+	hostsAttach, err := scbeRestClient.GetVolMapping(existingVolume.WWN)
 	if err != nil {
 		return "", s.logger.ErrorRet(err, "scbeRestClient.GetVolMapping failed")
 	}
 
-	if hostAttach == attachRequest.Host {
+	if utils.StringInSlice(attachRequest.Host, hostsAttach) {
 		// if already map to the given host then just ignore and succeed to attach
 		s.logger.Info("Volume already attached, skip backend attach", logs.Args{{"volume", attachRequest.Name}, {"host", attachRequest.Host}})
 		volumeMountpoint := fmt.Sprintf(resources.PathToMountUbiquityBlockDevices, existingVolume.WWN)
 		return volumeMountpoint, nil
-	} else if hostAttach != "" {
-		return "", s.logger.ErrorRet(&volAlreadyAttachedError{attachRequest.Name, hostAttach}, "failed")
+	} else if len(hostsAttach) > 0 && getAccessMode(existingVolume) != AccessModeMultiAttach {
+		return "", s.logger.ErrorRet(&volAlreadyAttachedError{attachRequest.Name, strings.Join(hostsAttach, ",")}, "failed")
 	}
 
 	// Lock will ensure no other caller attach a volume from the same host concurrently, Prevent SCBE race condition on get next available lun ID
 	s.locker.WriteLock(attachRequest.Host)
+	if s.config.CreateHosts && (attachRequest.Initiators.Iqn != "" || len(attachRequest.Initiators.Wwpns) > 0) {
+		if err = scbeRestClient.CreateHost(existingVolume.WWN, attachRequest.Host, attachRequest.Initiators); err != nil {
+			s.locker.WriteUnlock(attachRequest.Host)
+			return "", s.logger.ErrorRet(err, "scbeRestClient.CreateHost failed")
+		}
+	}
 	s.logger.Debug("Attaching", logs.Args{{"volume", existingVolume}})
 	if _, err = scbeRestClient.MapVolume(existingVolume.WWN, attachRequest.Host); err != nil {
 		s.locker.WriteUnlock(attachRequest.Host)
@@ -452,17 +696,16 @@ func (s *scbeLocalClient) Detach(detachRequest resources.DetachRequest) (err err
 		return s.logger.ErrorRet(err, "dataModel.GetVolume failed")
 	}
 
-	hostAttach, err := scbeRestClient.GetVolMapping(existingVolume.WWN)
+	hostsAttach, err := scbeRestClient.GetVolMapping(existingVolume.WWN)
 	if err != nil {
 		return s.logger.ErrorRet(err, "scbeRestClient.GetVolMapping failed")
 	}
 
-	if hostAttach == EmptyHost {
-		s.logger.Warning("Volume is already detached from host.", logs.Args{{"volume", existingVolume.WWN}})
+	if !utils.StringInSlice(host2detach, hostsAttach) {
+		s.logger.Warning("Volume is already detached from host.", logs.Args{{"volume", existingVolume.WWN}, {"host", host2detach}, {"mapped-hosts", hostsAttach}})
 		return nil
 	}
 
-	// TODO idempotent, if volume attach to different host, then we should also return succeed.
 	s.logger.Debug("Detaching", logs.Args{{"volume", existingVolume}})
 	if err = scbeRestClient.UnmapVolume(existingVolume.WWN, host2detach); err != nil {
 		return s.logger.ErrorRet(err, "scbeRestClient.UnmapVolume failed")
@@ -496,9 +739,157 @@ func (s *scbeLocalClient) ListVolumes(listVolumesRequest resources.ListVolumesRe
 	return volumes, nil
 }
 
+// getAccessMode return the access mode of the volume, volumes created before access modes were supported are single-writer
+func getAccessMode(volume ScbeVolume) string {
+	if volume.AccessMode == "" {
+		return AccessModeSingleWriter
+	}
+	return volume.AccessMode
+}
+
 func (s *scbeLocalClient) getVolumeMountPoint(volume ScbeVolume) (string, error) {
 	defer s.logger.Trace(logs.DEBUG)()
 
 	//TODO return mountpoint
 	return "some mount point", nil
 }
+
+// GetCapacity return the capacity of the storage services that are delegated to the user
+func (s *scbeLocalClient) GetCapacity(getCapacityRequest resources.GetCapacityRequest) ([]resources.ServiceCapacity, error) {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	// authenticate
+	scbeRestClient, err := s.getAuthenticatedScbeRestClient(getCapacityRequest.CredentialInfo)
+	if err != nil {
+		return nil, s.logger.ErrorRet(err, "getAuthenticatedScbeRestClient failed")
+	}
+
+	services, err := scbeRestClient.GetServices()
+	if err != nil {
+		return nil, s.logger.ErrorRet(err, "scbeRestClient.GetServices failed")
+	}
+
+	capacity := []resources.ServiceCapacity{}
+	for _, service := range services {
+		capacity = append(capacity, resources.ServiceCapacity{
+			Name:                   service.Name,
+			TotalCapacity:          service.TotalCapacity,
+			UsedCapacity:           service.UsedCapacity,
+			FreeCapacity:           service.LogicalFree,
+			MaxFreeForProvisioning: service.MaxResourceFreeSizeForProvisioning,
+			QosMaxIops:             service.QosMaxIops,
+			QosMaxMbps:             service.QosMaxMbps,
+		})
+	}
+	return capacity, nil
+}
+
+// ReportAttachments saves the volumes a host currently uses, for the mapping reconciliation
+func (s *scbeLocalClient) ReportAttachments(reportAttachmentsRequest resources.ReportAttachmentsRequest) error {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	if reportAttachmentsRequest.Host == EmptyHost {
+		return s.logger.ErrorRet(&InValidRequestError{"reportAttachmentsRequest", "Host", reportAttachmentsRequest.Host, "none empty string"}, "failed")
+	}
+
+	s.logger.Debug("Host reported attachments", logs.Args{{"host", reportAttachmentsRequest.Host}, {"volumes", reportAttachmentsRequest.Volumes}, {"wwns", reportAttachmentsRequest.WWNs}})
+	if err := s.dataModel.UpdateHostReport(reportAttachmentsRequest.Host, reportAttachmentsRequest.Volumes, reportAttachmentsRequest.WWNs); err != nil {
+		return s.logger.ErrorRet(err, "dataModel.UpdateHostReport failed")
+	}
+	return nil
+}
+
+// GetStaleMappings compares the SCBE mappings of the ubiquity volumes with the host reports and returns the
+// mappings that no live host uses (stale), and the mappings of the hosts that did not report (unknown).
+func (s *scbeLocalClient) GetStaleMappings(getStaleMappingsRequest resources.GetStaleMappingsRequest) ([]resources.StaleMapping, error) {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	// authenticate
+	scbeRestClient, err := s.getAuthenticatedScbeRestClient(getStaleMappingsRequest.CredentialInfo)
+	if err != nil {
+		return nil, s.logger.ErrorRet(err, "getAuthenticatedScbeRestClient failed")
+	}
+
+	volumesInDb, err := s.dataModel.ListVolumes()
+	if err != nil {
+		return nil, s.logger.ErrorRet(err, "dataModel.ListVolumes failed")
+	}
+
+	staleMappings := []resources.StaleMapping{}
+	for _, volume := range volumesInDb {
+		hosts, err := scbeRestClient.GetVolMapping(volume.WWN)
+		if err != nil {
+			return nil, s.logger.ErrorRet(err, "scbeRestClient.GetVolMapping failed", logs.Args{{"volume", volume.Volume.Name}})
+		}
+		for _, host := range hosts {
+			status, reason, err := s.getMappingStatus(volume, host)
+			if err != nil {
+				return nil, s.logger.ErrorRet(err, "getMappingStatus failed", logs.Args{{"volume", volume.Volume.Name}, {"host", host}})
+			}
+			if status != "" {
+				staleMappings = append(staleMappings, resources.StaleMapping{Name: volume.Volume.Name, Host: host, Status: status, Reason: reason})
+			}
+		}
+	}
+
+	s.logger.Debug("Stale mappings", logs.Args{{"stale-mappings", staleMappings}})
+	return staleMappings, nil
+}
+
+// getMappingStatus return whether the mapping of the volume to the host is stale or unknown and why,
+// or "" if the host reports the volume as attached.
+// A host that never reported is unknown, it may use the volume, e.g. if it runs a plugin that does not report.
+// The last report of a host may be older than HostReportTimeout when the server restarts, so a host that stopped
+// reporting is unknown until it had the time to report to the restarted server.
+func (s *scbeLocalClient) getMappingStatus(volume ScbeVolume, host string) (string, string, error) {
+	report, exists, err := s.dataModel.GetHostReport(host)
+	if err != nil {
+		return "", "", s.logger.ErrorRet(err, "dataModel.GetHostReport failed")
+	}
+	if !exists {
+		return resources.MappingStatusUnknown, resources.StaleMappingReasonHostNeverReported, nil
+	}
+	if time.Since(report.ReportedAt) > HostReportTimeout {
+		if time.Since(s.startedAt) < HostReportTimeout {
+			return resources.MappingStatusUnknown, resources.StaleMappingReasonHostReportPending, nil
+		}
+		return resources.MappingStatusStale, resources.StaleMappingReasonHostStopped, nil
+	}
+	if !utils.StringInSlice(volume.Volume.Name, report.ReportedVolumes()) && !report.IsWWNReported(volume.WWN) {
+		return resources.MappingStatusStale, resources.StaleMappingReasonVolumeNotInUse, nil
+	}
+	return "", "", nil
+}
+
+// ForceUnmap unmaps the volume from the host without asking the host, e.g for a stale mapping of a dead node.
+// A mapping that is not stale, because the host uses the volume or did not report, is unmapped only with Force.
+func (s *scbeLocalClient) ForceUnmap(forceUnmapRequest resources.ForceUnmapRequest) error {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	if forceUnmapRequest.Host == EmptyHost {
+		return s.logger.ErrorRet(&InValidRequestError{"forceUnmapRequest", "Host", forceUnmapRequest.Host, "none empty string"}, "failed")
+	}
+
+	existingVolume, err := s.dataModel.GetVolume(forceUnmapRequest.Name, true)
+	if err != nil {
+		return s.logger.ErrorRet(err, "dataModel.GetVolume failed")
+	}
+	status, reason, err := s.getMappingStatus(existingVolume, forceUnmapRequest.Host)
+	if err != nil {
+		return s.logger.ErrorRet(err, "getMappingStatus failed")
+	}
+	if status != resources.MappingStatusStale {
+		if !forceUnmapRequest.Force {
+			return s.logger.ErrorRet(&mappingMayBeInUseError{forceUnmapRequest.Name, forceUnmapRequest.Host, status, reason}, "failed")
+		}
+		s.logger.Warning("Force unmapping a volume the host may use", logs.Args{{"volume", forceUnmapRequest.Name}, {"host", forceUnmapRequest.Host}, {"status", status}, {"reason", reason}})
+	}
+
+	s.logger.Warning("Force unmapping volume", logs.Args{{"volume", forceUnmapRequest.Name}, {"host", forceUnmapRequest.Host}})
+	return s.Detach(resources.DetachRequest{
+		CredentialInfo: forceUnmapRequest.CredentialInfo,
+		Name:           forceUnmapRequest.Name,
+		Host:           forceUnmapRequest.Host,
+		Context:        forceUnmapRequest.Context,
+	})
+}
@@ -20,20 +20,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
 	"github.com/IBM/ubiquity/utils/logs"
 	"strconv"
+	"strings"
 )
 
 //go:generate counterfeiter -o ../fakes/fake_scbe_rest_client.go . ScbeRestClient
 type ScbeRestClient interface {
 	Login() error
-	CreateVolume(volName string, serviceName string, size int) (ScbeVolumeInfo, error)
+	CreateVolume(volName string, serviceName string, sizeBytes int, options ScbeVolumeOptions) (ScbeVolumeInfo, error)
 	GetVolumes(wwn string) ([]ScbeVolumeInfo, error)
 	DeleteVolume(wwn string) error
 	MapVolume(wwn string, host string) (ScbeResponseMapping, error)
+	CreateHost(wwn string, host string, initiators resources.HostInitiators) error
 	UnmapVolume(wwn string, host string) error
-	GetVolMapping(wwn string) (string, error)
+	GetVolMapping(wwn string) ([]string, error)
 	ServiceExist(serviceName string) (bool, error)
+	GetServices() ([]ScbeStorageService, error)
 }
 
 type scbeRestClient struct {
@@ -53,6 +57,15 @@ const (
 	UrlScbeResourceMapping   = "mappings"
 	UrlScbeResourceHost      = "hosts"
 	DefaultSizeUnit          = "gb"
+
+	// capabilities listed in the capability_values of a service
+	ServiceCapabilityThin        = "thin"
+	ServiceCapabilityThick       = "thick"
+	ServiceCapabilityCompression = "compression"
+)
+
+var (
+	SupportedSizeUnits = []string{"tb", "gb", "mb"} // size units accepted by SCBE, from the largest to the smallest
 )
 
 func NewScbeRestClient(conInfo resources.ConnectionInfo) (ScbeRestClient, error) {
@@ -89,11 +102,15 @@ func (s *scbeRestClient) Login() error {
 }
 
 // CreateVolume provision new volume on SCBE storage service.
+// The size is given in bytes and sent in the largest unit that SCBE accepts.
 // Return ScbeVolumeInfo of the new volume that was created
 // Errors:
 //	if service don't exist
+//	if size cannot be expressed in the units SCBE accepts
+//	if service has not enough free capacity for the volume
+//	if service does not support the requested QoS or provisioning options
 //	if fail to create the volume
-func (s *scbeRestClient) CreateVolume(volName string, serviceName string, size int) (ScbeVolumeInfo, error) {
+func (s *scbeRestClient) CreateVolume(volName string, serviceName string, sizeBytes int, options ScbeVolumeOptions) (ScbeVolumeInfo, error) {
 	defer s.logger.Trace(logs.DEBUG)()
 	// find the service in order to validate and also to get the service id
 	services, err := s.serviceList(serviceName)
@@ -105,11 +122,35 @@ func (s *scbeRestClient) CreateVolume(volName string, serviceName string, size i
 		return ScbeVolumeInfo{}, s.logger.ErrorRet(&serviceDoesntExistError{volName, serviceName, s.connectionInfo.ManagementIP}, "failed")
 	}
 
+	// pre-flight check, so the request will not fail late on the storage system
+	if IsServiceCapacityReported(services[0]) && sizeBytes > GetServiceHeadroom(services[0]) {
+		return ScbeVolumeInfo{}, s.logger.ErrorRet(
+			&serviceNotEnoughCapacityError{volName, serviceName, sizeBytes, GetServiceHeadroom(services[0])}, "failed")
+	}
+
+	if err = validateServiceSupportsOptions(volName, services[0], options); err != nil {
+		return ScbeVolumeInfo{}, s.logger.ErrorRet(err, "validateServiceSupportsOptions failed")
+	}
+
+	size, sizeUnit, err := utils.BestQuantityUnit(uint64(sizeBytes), SupportedSizeUnits)
+	if err != nil {
+		return ScbeVolumeInfo{}, s.logger.ErrorRet(err, "utils.BestQuantityUnit failed", logs.Args{{"sizeBytes", sizeBytes}})
+	}
+
 	payload := ScbeCreateVolumePostParams{
-		services[0].Id,
-		volName,
-		size,
-		DefaultSizeUnit, // TODO lets support different type of unit size, for now only gb
+		Service:     services[0].Id,
+		Name:        volName,
+		Size:        int(size),
+		SizeUnit:    sizeUnit,
+		MaxIops:     options.MaxIops,
+		MaxMbps:     options.MaxMbps,
+		Compression: options.Compression,
+	}
+	switch options.Thin {
+	case "true":
+		payload.ProvisioningType = ServiceCapabilityThin
+	case "false":
+		payload.ProvisioningType = ServiceCapabilityThick
 	}
 
 	payloadMarshaled, err := json.Marshal(payload)
@@ -168,6 +209,43 @@ func (s *scbeRestClient) MapVolume(wwn string, host string) (ScbeResponseMapping
 	return mappingsResponse.Mappings[0], nil
 }
 
+// CreateHost define the host with the given initiators on the storage system of the volume.
+// It does nothing if the host is already defined there.
+func (s *scbeRestClient) CreateHost(wwn string, host string, initiators resources.HostInitiators) error {
+	defer s.logger.Trace(logs.DEBUG)()
+	vols, err := s.volumeList(wwn)
+	if err != nil {
+		return s.logger.ErrorRet(err, "volumeList failed", logs.Args{{"wwn", wwn}})
+	}
+	if len(vols) == 0 {
+		return s.logger.ErrorRet(&VolumeNotFoundOnArrayError{VolName: wwn}, "failed")
+	}
+
+	hosts, err := s.hostList(map[string]string{"array_id": vols[0].Array, "name": host})
+	if err != nil {
+		return s.logger.ErrorRet(err, "hostList failed")
+	}
+	if len(hosts) > 0 {
+		s.logger.Debug("Host already defined on the storage system", logs.Args{{"host", host}, {"array", vols[0].Array}})
+		return nil
+	}
+
+	payload := ScbeCreateHostPostParams{ArrayId: vols[0].Array, Name: host, FcPorts: initiators.Wwpns}
+	if initiators.Iqn != "" {
+		payload.IscsiPorts = []string{initiators.Iqn}
+	}
+	payloadMarshal, err := json.Marshal(payload)
+	if err != nil {
+		return s.logger.ErrorRet(err, "json.Marshal failed", logs.Args{{"payload", payload}})
+	}
+	hostResponse := ScbeResponseHost{}
+	if err = s.client.Post(UrlScbeResourceHost, payloadMarshal, HTTP_SUCCEED_POST, &hostResponse); err != nil {
+		return s.logger.ErrorRet(err, "client.Post failed", logs.Args{{"payload", payload}})
+	}
+	s.logger.Info("Host defined on the storage system", logs.Args{{"host", host}, {"array", vols[0].Array}, {"id", hostResponse.Id}})
+	return nil
+}
+
 func (s *scbeRestClient) UnmapVolume(wwn string, host string) error {
 	defer s.logger.Trace(logs.DEBUG)()
 	// TODO consider to return the unmap SCBE response
@@ -186,10 +264,10 @@ func (s *scbeRestClient) UnmapVolume(wwn string, host string) error {
 	return nil
 }
 
-func (s *scbeRestClient) GetVolMapping(wwn string) (string, error) {
+// GetVolMapping return the names of all the hosts that the volume is mapped to
+func (s *scbeRestClient) GetVolMapping(wwn string) ([]string, error) {
 	defer s.logger.Trace(logs.DEBUG)()
 	var err error
-	var host string
 
 	payload := map[string]string{}
 	payload["volume"] = wwn
@@ -197,28 +275,25 @@ func (s *scbeRestClient) GetVolMapping(wwn string) (string, error) {
 
 	var mappings []ScbeResponseMapping
 	if err = s.client.Get(UrlScbeResourceMapping, payload, HTTP_SUCCEED, &mappings); err != nil {
-		return "", s.logger.ErrorRet(err, "client.Get failed")
+		return nil, s.logger.ErrorRet(err, "client.Get failed")
 	}
 	s.logger.Debug("", logs.Args{{"mappings", mappings}})
 
-	if len(mappings) > 1 {
-		return "", s.logger.ErrorRet(&InvalidMappingsForVolume{wwn}, "failed")
-	}
-
-	if len(mappings) == 1 {
+	hosts := []string{}
+	for _, mapping := range mappings {
 		var hostResponse ScbeResponseHost
-		hostUrl := fmt.Sprintf("%s/%s", UrlScbeResourceHost, strconv.Itoa(mappings[0].Host))
+		hostUrl := fmt.Sprintf("%s/%s", UrlScbeResourceHost, strconv.Itoa(mapping.Host))
 		err := s.client.Get(hostUrl, nil, -1, &hostResponse)
 		if err != nil {
-			return "", s.logger.ErrorRet(err, "client.Get failed")
+			return nil, s.logger.ErrorRet(err, "client.Get failed")
 		}
 
 		s.logger.Debug("", logs.Args{{"hostResponse", hostResponse}})
-		host = hostResponse.Name
+		hosts = append(hosts, hostResponse.Name)
 	}
 
-	s.logger.Debug("volume is mapped", logs.Args{{"host", host}})
-	return host, nil
+	s.logger.Debug("volume is mapped", logs.Args{{"hosts", hosts}})
+	return hosts, nil
 }
 
 func (s *scbeRestClient) ServiceExist(serviceName string) (exist bool, err error) {
@@ -231,6 +306,76 @@ func (s *scbeRestClient) ServiceExist(serviceName string) (exist bool, err error
 	return false, err
 }
 
+// GetServices return all the storage services that are delegated to the user
+func (s *scbeRestClient) GetServices() ([]ScbeStorageService, error) {
+	defer s.logger.Trace(logs.DEBUG)()
+	services, err := s.serviceList("")
+	if err != nil {
+		return nil, s.logger.ErrorRet(err, "serviceList failed")
+	}
+	return services, nil
+}
+
+// IsServiceCapacityReported return false if SCBE did not report any capacity for the service
+func IsServiceCapacityReported(service ScbeStorageService) bool {
+	return service.TotalCapacity != 0 || service.LogicalFree != 0 || service.MaxResourceFreeSizeForProvisioning != 0
+}
+
+// GetServiceHeadroom return the max size in bytes of a new volume that the service can provision,
+// a free size that SCBE did not report (0) does not limit the headroom
+func GetServiceHeadroom(service ScbeStorageService) int {
+	if service.MaxResourceFreeSizeForProvisioning == 0 {
+		return service.LogicalFree
+	}
+	if service.LogicalFree == 0 || service.MaxResourceFreeSizeForProvisioning < service.LogicalFree {
+		return service.MaxResourceFreeSizeForProvisioning
+	}
+	return service.LogicalFree
+}
+
+// GetServiceCapabilities return the capabilities of the service (lower case), or nil if SCBE did not report any
+func GetServiceCapabilities(service ScbeStorageService) []string {
+	var capabilities []string
+	for _, capability := range strings.Split(service.CapabilityValues, ",") {
+		if capability = strings.ToLower(strings.TrimSpace(capability)); capability != "" {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	return capabilities
+}
+
+// validateServiceSupportsOptions verify the service can provision a volume with the given options.
+// Capabilities and QoS limits the service does not report are not validated.
+func validateServiceSupportsOptions(volName string, service ScbeStorageService, options ScbeVolumeOptions) error {
+	if service.QosMaxIops > 0 && options.MaxIops > service.QosMaxIops {
+		return &serviceQosLimitExceededError{volName, service.Name, OptionNameForMaxIops, options.MaxIops, service.QosMaxIops}
+	}
+	if service.QosMaxMbps > 0 && options.MaxMbps > service.QosMaxMbps {
+		return &serviceQosLimitExceededError{volName, service.Name, OptionNameForMaxMbps, options.MaxMbps, service.QosMaxMbps}
+	}
+
+	capabilities := GetServiceCapabilities(service)
+	if len(capabilities) == 0 {
+		return nil
+	}
+	required := []string{}
+	switch options.Thin {
+	case "true":
+		required = append(required, ServiceCapabilityThin)
+	case "false":
+		required = append(required, ServiceCapabilityThick)
+	}
+	if options.Compression {
+		required = append(required, ServiceCapabilityCompression)
+	}
+	for _, capability := range required {
+		if !utils.StringInSlice(capability, capabilities) {
+			return &serviceCapabilityNotSupportedError{volName, service.Name, capability}
+		}
+	}
+	return nil
+}
+
 func (s *scbeRestClient) serviceList(serviceName string) ([]ScbeStorageService, error) {
 	defer s.logger.Trace(logs.DEBUG)()
 	payload := map[string]string{}
@@ -21,6 +21,7 @@ import (
 	"github.com/IBM/ubiquity/database"
 	"github.com/IBM/ubiquity/local/scbe"
 	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
 	"github.com/IBM/ubiquity/utils/logs"
 	"github.com/jinzhu/gorm"
 	. "github.com/onsi/ginkgo"
@@ -141,7 +142,7 @@ var _ = Describe("ScbeRestClient volume operations integration testing with exis
 	Context(".CreateVolume", func() {
 		It(fmt.Sprintf("Should succeed if vol was created and deleted on %s service", profile), func() {
 			fakeName := "fakevol_ubiquity"
-			volInfo, err := scbeRestClient.CreateVolume(fakeName, profile, 10)
+			volInfo, err := scbeRestClient.CreateVolume(fakeName, profile, 10*int(utils.GiB), scbe.ScbeVolumeOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(volInfo.Name).To(Equal(fakeName))
 			Expect(volInfo.Profile).To(Equal(profile))
@@ -151,7 +152,7 @@ var _ = Describe("ScbeRestClient volume operations integration testing with exis
 		})
 		It(fmt.Sprintf("Should succeed if vol map and unmap works", profile), func() {
 			fakeName := "fakevol_ubiquity"
-			volInfo, err := scbeRestClient.CreateVolume(fakeName, profile, 10)
+			volInfo, err := scbeRestClient.CreateVolume(fakeName, profile, 10*int(utils.GiB), scbe.ScbeVolumeOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			mapInfo, err := scbeRestClient.MapVolume(volInfo.Wwn, host)
 			Expect(err).NotTo(HaveOccurred())
@@ -200,7 +201,7 @@ var _ = Describe("datamodel integration testing with live DB", func() {
 	Context(".table", func() {
 		It("Should to succeed to insert new volume raw and find it in DB", func() {
 			fakeVolName := "volname1"
-			err := datamodel.InsertVolume(fakeVolName, "www1", "ext4")
+			err := datamodel.InsertVolume(fakeVolName, "www1", "ext4", 0, "", "", false, scbe.ScbeVolumeOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			ScbeVolume, exist, err := datamodel.GetVolume(fakeVolName)
 			Expect(err).NotTo(HaveOccurred())
@@ -210,7 +211,7 @@ var _ = Describe("datamodel integration testing with live DB", func() {
 		})
 		It("Should to succeed to insert new volume and delete it", func() {
 			fakeVolName := "volname1"
-			err := datamodel.InsertVolume(fakeVolName, "www1", "ext4")
+			err := datamodel.InsertVolume(fakeVolName, "www1", "ext4", 0, "", "", false, scbe.ScbeVolumeOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			_, exist, err := datamodel.GetVolume(fakeVolName)
 			Expect(err).NotTo(HaveOccurred())
@@ -224,7 +225,7 @@ var _ = Describe("datamodel integration testing with live DB", func() {
 			num := 10
 			for i := 0; i < num; i++ {
 				volname = fmt.Sprintf("fakevol %d", i)
-				Expect(datamodel.InsertVolume(volname, "www1", "ext4")).NotTo(HaveOccurred())
+				Expect(datamodel.InsertVolume(volname, "www1", "ext4", 0, "", "", false, scbe.ScbeVolumeOptions{})).NotTo(HaveOccurred())
 			}
 			vols, err := datamodel.ListVolumes()
 			Expect(err).NotTo(HaveOccurred())
@@ -232,7 +233,7 @@ var _ = Describe("datamodel integration testing with live DB", func() {
 		})
 		It("Should to succeed to insert and then update the attach of the volume", func() {
 			fakeVolName := "volname1"
-			err := datamodel.InsertVolume(fakeVolName, "www1", "ext4")
+			err := datamodel.InsertVolume(fakeVolName, "www1", "ext4", 0, "", "", false, scbe.ScbeVolumeOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			_, exist, err := datamodel.GetVolume(fakeVolName)
 			Expect(err).NotTo(HaveOccurred())
@@ -23,6 +23,7 @@ import (
 	"github.com/IBM/ubiquity/fakes"
 	"github.com/IBM/ubiquity/local/scbe"
 	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"strconv"
@@ -37,7 +38,7 @@ var _ = Describe("ScbeRestClient", func() {
 		profileName          string = "fake-profile"
 		volName              string = "fake-volume"
 		volIdentifier        string = "fake-volume-identifier"
-		volSize              int    = 10
+		volSize              int    = 10 * int(utils.GiB)
 		restErr              error  = errors.New("rest error")
 	)
 	BeforeEach(func() {
@@ -78,15 +79,36 @@ var _ = Describe("ScbeRestClient", func() {
 			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
 			volResponse := scbe.ScbeResponseVolume{Name: volName, ScsiIdentifier: volIdentifier, ServiceName: profileName}
 			fakeSimpleRestClient.PostStub = OverridePostStub(volResponse)
-			scbeVolumeInfo, err := scbeRestClient.CreateVolume(volName, profileName, volSize)
+			scbeVolumeInfo, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(scbeVolumeInfo.Name).To(Equal(volName))
 			Expect(scbeVolumeInfo.Wwn).To(Equal(volIdentifier))
 			Expect(scbeVolumeInfo.Profile).To(Equal(profileName))
 		})
+		It("send the size in the largest unit that fits", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			fakeSimpleRestClient.PostStub = OverridePostStub(scbe.ScbeResponseVolume{Name: volName})
+			_, err := scbeRestClient.CreateVolume(volName, profileName, 1536*int(utils.MiB), scbe.ScbeVolumeOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, payload, _, _ := fakeSimpleRestClient.PostArgsForCall(0)
+			var params scbe.ScbeCreateVolumePostParams
+			Expect(json.Unmarshal(payload, &params)).To(Succeed())
+			Expect(params.Size).To(Equal(1536))
+			Expect(params.SizeUnit).To(Equal("mb"))
+		})
+		It("fail if the size is not a whole number of mb", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, 1000, scbe.ScbeVolumeOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeSimpleRestClient.PostCallCount()).To(Equal(0))
+		})
 		It("fail upon service list error", func() {
 			fakeSimpleRestClient.GetReturns(restErr)
-			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{})
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(restErr))
 		})
@@ -94,18 +116,105 @@ var _ = Describe("ScbeRestClient", func() {
 			services := make([]scbe.ScbeStorageService, 1)
 			services[0].Name = "fakeProfileName"
 			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
-			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{})
+			Expect(err).To(HaveOccurred())
+		})
+		It("fail if the service has not enough free capacity", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			services[0].LogicalFree = volSize - int(utils.MiB)
+			services[0].MaxResourceFreeSizeForProvisioning = volSize - int(utils.MiB)
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("can provision up to"))
+			Expect(fakeSimpleRestClient.PostCallCount()).To(Equal(0))
+		})
+		It("succeed if the service has enough free capacity", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			services[0].LogicalFree = volSize
+			services[0].MaxResourceFreeSizeForProvisioning = volSize
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			volResponse := scbe.ScbeResponseVolume{Name: volName, ScsiIdentifier: volIdentifier, ServiceName: profileName}
+			fakeSimpleRestClient.PostStub = OverridePostStub(volResponse)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("succeed if the service reports only its logical free capacity", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			services[0].LogicalFree = volSize
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			volResponse := scbe.ScbeResponseVolume{Name: volName, ScsiIdentifier: volIdentifier, ServiceName: profileName}
+			fakeSimpleRestClient.PostStub = OverridePostStub(volResponse)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("send the QoS and provisioning options the service supports", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			services[0].CapabilityValues = "Thin, Compression"
+			services[0].QosMaxIops = 10000
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			fakeSimpleRestClient.PostStub = OverridePostStub(scbe.ScbeResponseVolume{Name: volName})
+			options := scbe.ScbeVolumeOptions{MaxIops: 5000, MaxMbps: 200, Thin: "true", Compression: true}
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, options)
+			Expect(err).NotTo(HaveOccurred())
+			_, payload, _, _ := fakeSimpleRestClient.PostArgsForCall(0)
+			var params scbe.ScbeCreateVolumePostParams
+			Expect(json.Unmarshal(payload, &params)).To(Succeed())
+			Expect(params.MaxIops).To(Equal(5000))
+			Expect(params.MaxMbps).To(Equal(200))
+			Expect(params.ProvisioningType).To(Equal(scbe.ServiceCapabilityThin))
+			Expect(params.Compression).To(Equal(true))
+		})
+		It("fail if the service does not support the provisioning options", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			services[0].CapabilityValues = "thick"
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{Thin: "true"})
 			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not support [thin]"))
+			Expect(fakeSimpleRestClient.PostCallCount()).To(Equal(0))
+		})
+		It("fail if the QoS limit exceeds the service limit", func() {
+			services := make([]scbe.ScbeStorageService, 1)
+			services[0].Name = profileName
+			services[0].QosMaxMbps = 100
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{MaxMbps: 200})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(scbe.OptionNameForMaxMbps))
+			Expect(fakeSimpleRestClient.PostCallCount()).To(Equal(0))
 		})
 		It("fail upon provision volume error", func() {
 			services := make([]scbe.ScbeStorageService, 1)
 			services[0].Name = profileName
 			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
 			fakeSimpleRestClient.PostReturns(restErr)
-			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize)
+			_, err := scbeRestClient.CreateVolume(volName, profileName, volSize, scbe.ScbeVolumeOptions{})
 			Expect(err).To(HaveOccurred())
 		})
 	})
+	Context(".GetServices", func() {
+		It("succeed and return all the services", func() {
+			services := []scbe.ScbeStorageService{{Name: "gold"}, {Name: "silver"}}
+			fakeSimpleRestClient.GetStub = OverrideGetStub(services)
+			result, err := scbeRestClient.GetServices()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(services))
+			url, params, _, _ := fakeSimpleRestClient.GetArgsForCall(0)
+			Expect(url).To(Equal(scbe.UrlScbeResourceService))
+			Expect(params).To(BeEmpty())
+		})
+		It("fail upon rest call error", func() {
+			fakeSimpleRestClient.GetReturns(restErr)
+			_, err := scbeRestClient.GetServices()
+			Expect(err).To(MatchError(restErr))
+		})
+	})
 	Context(".Login", func() {
 		It("succeed upon simple rest client success", func() {
 			err = scbeRestClient.Login()
@@ -177,23 +286,67 @@ var _ = Describe("ScbeRestClient", func() {
 			Expect(err).To(MatchError(restErr))
 		})
 	})
+	Context(".CreateHost", func() {
+		var hostsOnArray []scbe.ScbeResponseHost
+		BeforeEach(func() {
+			hostsOnArray = []scbe.ScbeResponseHost{}
+			fakeSimpleRestClient.GetStub = func(resource_url string, params map[string]string, exitStatus int, v interface{}) error {
+				var response interface{} = []scbe.ScbeResponseVolume{{ScsiIdentifier: "fakeWwn1", Array: "array1"}}
+				if resource_url == scbe.UrlScbeResourceHost {
+					Expect(params).To(Equal(map[string]string{"array_id": "array1", "name": fakeHost}))
+					response = hostsOnArray
+				}
+				data, err := json.Marshal(response)
+				Expect(err).NotTo(HaveOccurred())
+				return json.Unmarshal(data, v)
+			}
+		})
+		It("define the host with its initiators on the array of the volume", func() {
+			initiators := resources.HostInitiators{Iqn: "iqn.1994-05.com.redhat:node1", Wwpns: []string{"10000000c9a1b2c3"}}
+			err := scbeRestClient.CreateHost("fakeWwn1", fakeHost, initiators)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSimpleRestClient.PostCallCount()).To(Equal(1))
+			url, payload, _, _ := fakeSimpleRestClient.PostArgsForCall(0)
+			Expect(url).To(Equal(scbe.UrlScbeResourceHost))
+			var params scbe.ScbeCreateHostPostParams
+			Expect(json.Unmarshal(payload, &params)).To(Succeed())
+			Expect(params).To(Equal(scbe.ScbeCreateHostPostParams{
+				ArrayId:    "array1",
+				Name:       fakeHost,
+				IscsiPorts: []string{initiators.Iqn},
+				FcPorts:    initiators.Wwpns,
+			}))
+		})
+		It("do nothing if the host is already defined", func() {
+			hostsOnArray = []scbe.ScbeResponseHost{{Id: 1, Name: fakeHost}}
+			err := scbeRestClient.CreateHost("fakeWwn1", fakeHost, resources.HostInitiators{Iqn: "iqn1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSimpleRestClient.PostCallCount()).To(Equal(0))
+		})
+		It("fail if the host creation failed", func() {
+			fakeSimpleRestClient.PostReturns(restErr)
+			err := scbeRestClient.CreateHost("fakeWwn1", fakeHost, resources.HostInitiators{Iqn: "iqn1"})
+			Expect(err).To(MatchError(restErr))
+		})
+	})
 	Context(".GetVolMapping", func() {
 		It("succeed with 1 mapping found", func() {
 			fakeSimpleRestClient.GetStub = GetVolMappingStubSuccess()
-			host, err := scbeRestClient.GetVolMapping("fakeWwn1")
+			hosts, err := scbeRestClient.GetVolMapping("fakeWwn1")
 			Expect(err).NotTo(HaveOccurred())
-			Expect(host).To(Equal(fakeHost))
+			Expect(hosts).To(Equal([]string{fakeHost}))
 		})
 		It("succeed with 0 mapping found", func() {
 			fakeSimpleRestClient.GetStub = GetVolMappingStubSuccess()
-			host, err := scbeRestClient.GetVolMapping("fakeWwn0")
+			hosts, err := scbeRestClient.GetVolMapping("fakeWwn0")
 			Expect(err).NotTo(HaveOccurred())
-			Expect(host).To(Equal(""))
+			Expect(hosts).To(BeEmpty())
 		})
-		It("fail with 2 mapping found", func() {
+		It("succeed with 2 mapping found", func() {
 			fakeSimpleRestClient.GetStub = GetVolMappingStubSuccess()
-			_, err := scbeRestClient.GetVolMapping("fakeWwn2")
-			Expect(err).To(HaveOccurred())
+			hosts, err := scbeRestClient.GetVolMapping("fakeWwn2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hosts).To(Equal([]string{fakeHost, fakeHost}))
 		})
 		It("fail if get mapping failed", func() {
 			fakeSimpleRestClient.GetStub = GetVolMappingStubSuccess()
@@ -22,10 +22,12 @@ import (
 	"github.com/IBM/ubiquity/fakes"
 	"github.com/IBM/ubiquity/local/scbe"
 	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"reflect"
 	"strings"
+	"time"
 )
 
 const (
@@ -67,6 +69,35 @@ var _ = Describe("scbeLocalClient init", func() {
 			_, ok := err.(*scbe.ConfigDefaultSizeNotNumError)
 			Expect(ok).To(Equal(true))
 		})
+		It("should keep a DefaultVolumeSize without unit from before the size units in GB", func() {
+			fakeConfig = resources.ScbeConfig{DefaultVolumeSize: "5", PlainSizeInGB: true}
+			fakeScbeRestClient.LoginReturns(nil)
+			fakeScbeRestClient.ServiceExistReturns(true, nil)
+			client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(fakeConfig, fakeScbeDataModel, fakeScbeRestClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1"}, nil)
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: map[string]interface{}{}})
+			Expect(err).NotTo(HaveOccurred())
+			_, _, size, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			Expect(size).To(Equal(5 * int(utils.GiB)))
+		})
+		It("should fail because DefaultVolumeSize without unit is too small in bytes", func() {
+			fakeConfig = resources.ScbeConfig{DefaultVolumeSize: "5"}
+			client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(fakeConfig, fakeScbeDataModel, fakeScbeRestClient)
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*scbe.ConfigDefaultSizeInvalidError)
+			Expect(ok).To(Equal(true))
+			Expect(err.Error()).To(ContainSubstring("A size without unit is in [bytes]"))
+		})
+		It("should fail because DefaultVolumeSize is not a multiple of the granularity", func() {
+			fakeConfig = resources.ScbeConfig{DefaultVolumeSize: "1.5Mi"}
+			client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(fakeConfig, fakeScbeDataModel, fakeScbeRestClient)
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*scbe.ConfigDefaultSizeInvalidError)
+			Expect(ok).To(Equal(true))
+		})
 		It("should fail because DefaultFilesystemType is not supported", func() {
 			fakeConfig = resources.ScbeConfig{
 				DefaultFilesystemType: "bad fstype",
@@ -83,6 +114,7 @@ var _ = Describe("scbeLocalClient init", func() {
 			fakeConfig = resources.ScbeConfig{
 				UbiquityInstanceName: "1234567890123456",
 				DefaultVolumeSize:    "1",
+				PlainSizeInGB:        true,
 			}
 			client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(
 				fakeConfig,
@@ -96,6 +128,7 @@ var _ = Describe("scbeLocalClient init", func() {
 			fakeConfig = resources.ScbeConfig{
 				UbiquityInstanceName: "123456789012345",
 				DefaultVolumeSize:    "1",
+				PlainSizeInGB:        true,
 			}
 			fakeScbeRestClient.LoginReturns(nil)
 			fakeScbeRestClient.ServiceExistReturns(true, nil)
@@ -111,6 +144,7 @@ var _ = Describe("scbeLocalClient init", func() {
 				UbiquityInstanceName:  "123456789012345",
 				DefaultVolumeSize:     "1",
 				DefaultFilesystemType: "ext4",
+				PlainSizeInGB:         true,
 			}
 			fakeScbeRestClient.LoginReturns(nil)
 			fakeScbeRestClient.ServiceExistReturns(true, nil)
@@ -279,10 +313,193 @@ var _ = Describe("scbeLocalClient", func() {
 			Expect(ok).To(Equal(true))
 		})
 
+		It("should create volume with the size in bytes if vol size has a unit", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1"}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "1.5Gi"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			_, _, size, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			Expect(size).To(Equal(1536 * int(utils.MiB)))
+			_, _, _, dbSize, requestedSize, _, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			Expect(dbSize).To(Equal(1536 * int(utils.MiB)))
+			Expect(requestedSize).To(Equal("1.5Gi"))
+		})
+		It("should create volume with a size in bytes if vol size has no unit", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1"}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "1073741824"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			_, _, size, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			Expect(size).To(Equal(int(utils.GiB)))
+		})
+		It("should create volume with a size in GB if vol size has no unit and plain sizes are in GB", func() {
+			fakeConfig.PlainSizeInGB = true
+			client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(fakeConfig, fakeScbeDataModel, fakeScbeRestClient)
+			Expect(err).ToNot(HaveOccurred())
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1"}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "2"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			_, _, size, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			Expect(size).To(Equal(2 * int(utils.GiB)))
+			_, _, _, _, requestedSize, _, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			Expect(requestedSize).To(Equal("2"))
+		})
+		It("should fail create volume if vol size is smaller than the minimum", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "512Ki"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("smaller than the minimum"))
+			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail create volume if vol size does not match the granularity", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "1025Ki"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not being a multiple"))
+			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(0))
+		})
+		It("should store the access mode given", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1"}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForAccessMode] = scbe.AccessModeMultiAttach
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			_, _, _, _, _, accessMode, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			Expect(accessMode).To(Equal(scbe.AccessModeMultiAttach))
+		})
+		It("should fail create volume if access mode is not supported", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForAccessMode] = "many-writers"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*scbe.AccessModeNotSupportedError)
+			Expect(ok).To(Equal(true))
+			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(0))
+		})
+		It("should import an existing array volume by WWN as preexisting", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetVolumesReturns([]scbe.ScbeVolumeInfo{{Name: "legacy1", Wwn: "wwn1", LogicalCapacity: "1073741824"}}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForImport] = "wwn1"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(0))
+			Expect(fakeScbeRestClient.GetVolumesArgsForCall(1)).To(Equal("wwn1")) // call 0 is the startup db volume lookup
+			name, wwn, _, dbSize, _, _, isPreexisting, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			Expect(name).To(Equal("fakevol"))
+			Expect(wwn).To(Equal("wwn1"))
+			Expect(dbSize).To(Equal(int(utils.GiB)))
+			Expect(isPreexisting).To(Equal(true))
+		})
+		It("should import an existing array volume by name", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetVolumesReturnsOnCall(1, []scbe.ScbeVolumeInfo{}, nil)
+			fakeScbeRestClient.GetVolumesReturnsOnCall(2, []scbe.ScbeVolumeInfo{{Name: "other", Wwn: "wwn2"}, {Name: "legacy1", Wwn: "wwn1"}}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForImport] = "legacy1"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			_, wwn, _, _, _, _, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			Expect(wwn).To(Equal("wwn1"))
+		})
+		It("should fail to import an array volume that does not exist", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetVolumesReturns([]scbe.ScbeVolumeInfo{}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForImport] = "legacy1"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeScbeDataModel.InsertVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail to import an array volume with an empty import value", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetVolumesReturns([]scbe.ScbeVolumeInfo{{Name: "legacy1", Wwn: "wwn1"}}, nil)
+			for _, importId := range []string{"", "  "} {
+				opts := make(map[string]interface{})
+				opts[scbe.OptionNameForImport] = importId
+				err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(scbe.OptionNameForImport))
+			}
+			Expect(fakeScbeRestClient.GetVolumesCallCount()).To(Equal(1)) // only the startup call
+			Expect(fakeScbeDataModel.InsertVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail to import an array volume that is already managed", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeDataModel.ListVolumesReturns([]scbe.ScbeVolume{{Volume: resources.Volume{Name: "vol1"}, WWN: "wwn1"}}, nil)
+			fakeScbeRestClient.GetVolumesReturns([]scbe.ScbeVolumeInfo{{Name: "legacy1", Wwn: "wwn1"}}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForImport] = "wwn1"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("vol1"))
+			Expect(fakeScbeDataModel.InsertVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail to import an array volume with a size option", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForImport] = "wwn1"
+			opts[scbe.OptionNameForVolumeSize] = "10Gi"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeScbeRestClient.GetVolumesCallCount()).To(Equal(1)) // only the startup call
+		})
+		It("should create volume with the QoS and provisioning options given", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1"}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForMaxIops] = "5000"
+			opts[scbe.OptionNameForMaxMbps] = 200
+			opts[scbe.OptionNameForThin] = "yes"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("non-boolean"))
+
+			opts[scbe.OptionNameForThin] = "true"
+			opts[scbe.OptionNameForCompression] = true
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			expectedOptions := scbe.ScbeVolumeOptions{MaxIops: 5000, MaxMbps: 200, Thin: "true", Compression: true}
+			_, _, _, options := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			Expect(options).To(Equal(expectedOptions))
+			_, _, _, _, _, _, _, dbOptions := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			Expect(dbOptions).To(Equal(expectedOptions))
+		})
+		It("should fail create volume if max-iops is not a positive number", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForMaxIops] = "-1"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail create volume if compression is requested with thick provisioning", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForThin] = "false"
+			opts[scbe.OptionNameForCompression] = "true"
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("conflicting options"))
+			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(0))
+		})
 		It("should fail create volume if vol len exeeded", func() {
 			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
 			opts := make(map[string]interface{})
-			opts[scbe.OptionNameForVolumeSize] = "100"
+			opts[scbe.OptionNameForVolumeSize] = "100Gi"
 			maxVolNameCapable := scbe.MaxVolumeNameLength - (len(fakeConfig.UbiquityInstanceName) + 3)
 			volname := strings.Repeat("x", maxVolNameCapable+1)
 			req := resources.CreateVolumeRequest{Name: volname, Backend: resources.SCBE, Opts: opts}
@@ -297,7 +514,7 @@ var _ = Describe("scbeLocalClient", func() {
 			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{}, fmt.Errorf("error"))
 
 			opts := make(map[string]interface{})
-			opts[scbe.OptionNameForVolumeSize] = "100"
+			opts[scbe.OptionNameForVolumeSize] = "100Gi"
 			maxVolNameCapable := scbe.MaxVolumeNameLength - (len(fakeConfig.UbiquityInstanceName) + 3)
 
 			volName := strings.Repeat("x", maxVolNameCapable)
@@ -314,7 +531,7 @@ var _ = Describe("scbeLocalClient", func() {
 			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
 			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{}, fmt.Errorf("error"))
 			opts := make(map[string]interface{})
-			opts[scbe.OptionNameForVolumeSize] = "100"
+			opts[scbe.OptionNameForVolumeSize] = "100Gi"
 
 			volFake := "fakevol"
 			req := resources.CreateVolumeRequest{Name: volFake, Backend: resources.SCBE, Opts: opts}
@@ -322,9 +539,9 @@ var _ = Describe("scbeLocalClient", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("error"))
 			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(1))
-			volname, profile, size := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			volname, profile, size, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
 			Expect(profile).To(Equal(fakeDefaultProfile))
-			Expect(size).To(Equal(100))
+			Expect(size).To(Equal(100 * int(utils.GiB)))
 			expectedVolName := fmt.Sprintf(scbe.ComposeVolumeName, fakeConfig.UbiquityInstanceName, volFake)
 			Expect(volname).To(Equal(expectedVolName))
 		})
@@ -332,7 +549,7 @@ var _ = Describe("scbeLocalClient", func() {
 			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
 			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{}, fmt.Errorf("error"))
 			opts := make(map[string]interface{})
-			opts[scbe.OptionNameForVolumeSize] = "100"
+			opts[scbe.OptionNameForVolumeSize] = "100Gi"
 			opts[scbe.OptionNameForServiceName] = "gold"
 
 			volFake := "fakevol"
@@ -341,9 +558,9 @@ var _ = Describe("scbeLocalClient", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("error"))
 			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(1))
-			volname, profile, size := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			volname, profile, size, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
 			Expect(profile).To(Equal("gold"))
-			Expect(size).To(Equal(100))
+			Expect(size).To(Equal(100 * int(utils.GiB)))
 			expectedVolName := fmt.Sprintf(scbe.ComposeVolumeName, fakeConfig.UbiquityInstanceName, volFake)
 			Expect(volname).To(Equal(expectedVolName))
 		})
@@ -354,7 +571,7 @@ var _ = Describe("scbeLocalClient", func() {
 				Name: "v1", Wwn: "wwn1", Profile: "gold"}, nil)
 			fakeScbeDataModel.InsertVolumeReturns(fmt.Errorf("error"))
 			opts := make(map[string]interface{})
-			opts[scbe.OptionNameForVolumeSize] = "100"
+			opts[scbe.OptionNameForVolumeSize] = "100Gi"
 			opts[scbe.OptionNameForServiceName] = "gold"
 
 			volFake := "fakevol"
@@ -363,7 +580,7 @@ var _ = Describe("scbeLocalClient", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("error"))
 			Expect(fakeScbeDataModel.InsertVolumeCallCount()).To(Equal(1))
-			name, wwn, fstype := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			name, wwn, fstype, _, _, _, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
 			Expect(name).To(Equal(volFake))
 			Expect(wwn).To(Equal("wwn1"))
 			Expect(fstype).To(Equal("ext4"))
@@ -374,7 +591,7 @@ var _ = Describe("scbeLocalClient", func() {
 			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1", Profile: "gold"}, nil)
 			fakeScbeDataModel.InsertVolumeReturns(nil)
 			opts := make(map[string]interface{})
-			opts[scbe.OptionNameForVolumeSize] = "100"
+			opts[scbe.OptionNameForVolumeSize] = "100Gi"
 			opts[scbe.OptionNameForServiceName] = "gold"
 
 			volFake := "fakevol"
@@ -382,7 +599,7 @@ var _ = Describe("scbeLocalClient", func() {
 			err = client.CreateVolume(req)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakeScbeDataModel.InsertVolumeCallCount()).To(Equal(1))
-			name, wwn, fstype := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			name, wwn, fstype, _, _, _, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
 			Expect(name).To(Equal(volFake))
 			Expect(wwn).To(Equal("wwn1"))
 			Expect(fstype).To(Equal("ext4"))
@@ -392,7 +609,7 @@ var _ = Describe("scbeLocalClient", func() {
 			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1", Profile: "gold"}, nil)
 			fakeScbeDataModel.InsertVolumeReturns(nil)
 			opts := make(map[string]interface{})
-			//opts[scbe.OptionNameForVolumeSize] = "10"
+			//opts[scbe.OptionNameForVolumeSize] = "10Gi"
 			opts[scbe.OptionNameForServiceName] = "gold"
 
 			volFake := "fakevol"
@@ -400,7 +617,7 @@ var _ = Describe("scbeLocalClient", func() {
 			err = client.CreateVolume(req)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakeScbeDataModel.InsertVolumeCallCount()).To(Equal(1))
-			name, wwn, fstype := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			name, wwn, fstype, _, _, _, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
 			Expect(name).To(Equal(volFake))
 			Expect(wwn).To(Equal("wwn1"))
 			Expect(fstype).To(Equal("ext4"))
@@ -418,12 +635,84 @@ var _ = Describe("scbeLocalClient", func() {
 			err = client.CreateVolume(req)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakeScbeDataModel.InsertVolumeCallCount()).To(Equal(1))
-			name, wwn, fstype := fakeScbeDataModel.InsertVolumeArgsForCall(0)
+			name, wwn, fstype, _, _, _, _, _ := fakeScbeDataModel.InsertVolumeArgsForCall(0)
 			Expect(name).To(Equal(volFake))
 			Expect(wwn).To(Equal("wwn1"))
 			Expect(fstype).To(Equal("xfs"))
 		})
+		It("should create volume on the service with the most free capacity if profile is auto", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetServicesReturns([]scbe.ScbeStorageService{
+				{Name: "bronze", LogicalFree: 20 * int(utils.GiB), MaxResourceFreeSizeForProvisioning: 20 * int(utils.GiB)},
+				{Name: "gold", LogicalFree: 50 * int(utils.GiB), MaxResourceFreeSizeForProvisioning: 40 * int(utils.GiB)},
+			}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1", Profile: "gold"}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "10Gi"
+			opts[scbe.OptionNameForServiceName] = scbe.OptionValueForAutoService
+
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			_, profile, size, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			Expect(profile).To(Equal("gold"))
+			Expect(size).To(Equal(10 * int(utils.GiB)))
+		})
+		It("should fail create volume if profile is auto and no service has enough free capacity", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetServicesReturns([]scbe.ScbeStorageService{
+				{Name: "gold", LogicalFree: 5 * int(utils.GiB), MaxResourceFreeSizeForProvisioning: 5 * int(utils.GiB)},
+			}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "10Gi"
+			opts[scbe.OptionNameForServiceName] = scbe.OptionValueForAutoService
+
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("enough free capacity"))
+			Expect(fakeScbeRestClient.CreateVolumeCallCount()).To(Equal(0))
+		})
+		It("should create volume on a service that does not report its capacity if profile is auto and no other service has enough free capacity", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetServicesReturns([]scbe.ScbeStorageService{
+				{Name: "gold", LogicalFree: 5 * int(utils.GiB), MaxResourceFreeSizeForProvisioning: 5 * int(utils.GiB)},
+				{Name: "silver"},
+			}, nil)
+			fakeScbeRestClient.CreateVolumeReturns(scbe.ScbeVolumeInfo{Name: "v1", Wwn: "wwn1", Profile: "silver"}, nil)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForVolumeSize] = "10Gi"
+			opts[scbe.OptionNameForServiceName] = scbe.OptionValueForAutoService
 
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).NotTo(HaveOccurred())
+			_, profile, _, _ := fakeScbeRestClient.CreateVolumeArgsForCall(0)
+			Expect(profile).To(Equal("silver"))
+		})
+		It("should fail create volume if profile is auto and GetServices failed", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
+			fakeScbeRestClient.GetServicesReturns(nil, fakeErr)
+			opts := make(map[string]interface{})
+			opts[scbe.OptionNameForServiceName] = scbe.OptionValueForAutoService
+
+			err = client.CreateVolume(resources.CreateVolumeRequest{Name: "fakevol", Backend: resources.SCBE, Opts: opts})
+			Expect(err).To(Equal(fakeErr))
+		})
+	})
+	Context(".GetCapacity", func() {
+		It("should return the capacity of all the services", func() {
+			fakeScbeRestClient.GetServicesReturns([]scbe.ScbeStorageService{
+				{Name: "gold", TotalCapacity: 100, UsedCapacity: 40, LogicalFree: 60, MaxResourceFreeSizeForProvisioning: 50, QosMaxIops: 1000},
+			}, nil)
+			capacity, err := client.(resources.CapacityReporter).GetCapacity(resources.GetCapacityRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(capacity).To(Equal([]resources.ServiceCapacity{
+				{Name: "gold", TotalCapacity: 100, UsedCapacity: 40, FreeCapacity: 60, MaxFreeForProvisioning: 50, QosMaxIops: 1000},
+			}))
+		})
+		It("should fail if GetServices failed", func() {
+			fakeScbeRestClient.GetServicesReturns(nil, fakeErr)
+			_, err := client.(resources.CapacityReporter).GetCapacity(resources.GetCapacityRequest{})
+			Expect(err).To(Equal(fakeErr))
+		})
 	})
 })
 
@@ -490,8 +779,84 @@ var _ = Describe("scbeLocalClient", func() {
 			Expect(err).To(MatchError(fakeErr))
 			Expect(fakeScbeRestClient.MapVolumeCallCount()).To(Equal(1))
 		})
+		It("should succeed without mapping if the volume is already mapped to the host", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn1"}, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost2, fakeHost}, nil)
+			_, err := client.Attach(fakeAttachRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.MapVolumeCallCount()).To(Equal(0))
+		})
+		It("should fail to attach a single-writer volume that is mapped to another host", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn1"}, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost2}, nil)
+			_, err := client.Attach(fakeAttachRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fakeHost2))
+			Expect(fakeScbeRestClient.MapVolumeCallCount()).To(Equal(0))
+		})
+		It("should map a multi-attach volume that is mapped to another host", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn1", AccessMode: scbe.AccessModeMultiAttach}, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost2}, nil)
+			_, err := client.Attach(fakeAttachRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.MapVolumeCallCount()).To(Equal(1))
+			wwn, host := fakeScbeRestClient.MapVolumeArgsForCall(0)
+			Expect(wwn).To(Equal("wwn1"))
+			Expect(host).To(Equal(fakeHost))
+		})
+	})
+	Context(".Attach with CreateHosts", func() {
+		BeforeEach(func() {
+			fakeConfig.CreateHosts = true
+			client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(
+				fakeConfig,
+				fakeScbeDataModel,
+				fakeScbeRestClient)
+			Expect(err).ToNot(HaveOccurred())
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn1"}, nil)
+		})
+		It("should define the host before mapping", func() {
+			initiators := resources.HostInitiators{Iqn: "iqn1"}
+			_, err := client.Attach(resources.AttachRequest{Name: fakeVol, Host: fakeHost, Initiators: initiators})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.CreateHostCallCount()).To(Equal(1))
+			wwn, host, hostInitiators := fakeScbeRestClient.CreateHostArgsForCall(0)
+			Expect(wwn).To(Equal("wwn1"))
+			Expect(host).To(Equal(fakeHost))
+			Expect(hostInitiators).To(Equal(initiators))
+			Expect(fakeScbeRestClient.MapVolumeCallCount()).To(Equal(1))
+		})
+		It("should not define the host if the node sent no initiators", func() {
+			_, err := client.Attach(fakeAttachRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.CreateHostCallCount()).To(Equal(0))
+			Expect(fakeScbeRestClient.MapVolumeCallCount()).To(Equal(1))
+		})
+		It("should fail without mapping if the host definition failed", func() {
+			fakeScbeRestClient.CreateHostReturns(fakeErr)
+			_, err := client.Attach(resources.AttachRequest{Name: fakeVol, Host: fakeHost, Initiators: resources.HostInitiators{Wwpns: []string{"wwpn1"}}})
+			Expect(err).To(MatchError(fakeErr))
+			Expect(fakeScbeRestClient.MapVolumeCallCount()).To(Equal(0))
+		})
 	})
 	Context(".Detach", func() {
+		It("should unmap only the given host of a multi-attach volume", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn1", AccessMode: scbe.AccessModeMultiAttach}, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost2, fakeHost}, nil)
+			err := client.Detach(fakeDetachRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(1))
+			wwn, host := fakeScbeRestClient.UnmapVolumeArgsForCall(0)
+			Expect(wwn).To(Equal("wwn1"))
+			Expect(host).To(Equal(fakeHost))
+		})
+		It("should succeed without unmapping if the volume is mapped only to another host", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn1"}, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost2}, nil)
+			err := client.Detach(fakeDetachRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(0))
+		})
 		It("should fail to detach the volume if GetVolume failed", func() {
 			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, fakeErr)
 			err := client.Detach(fakeDetachRequest)
@@ -507,7 +872,7 @@ var _ = Describe("scbeLocalClient", func() {
 		})
 		It("should fail to detach the volume if MapVolume failed", func() {
 			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
-			fakeScbeRestClient.GetVolMappingReturns(fakeHost, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost}, nil)
 			fakeScbeRestClient.UnmapVolumeReturns(fakeErr)
 			err := client.Detach(fakeDetachRequest)
 			Expect(err).To(HaveOccurred())
@@ -517,6 +882,122 @@ var _ = Describe("scbeLocalClient", func() {
 			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(1))
 		})
 	})
+	Context(".GetStaleMappings", func() {
+		var mappingReconciler resources.MappingReconciler
+		BeforeEach(func() {
+			mappingReconciler = client.(resources.MappingReconciler)
+			fakeScbeDataModel.ListVolumesReturns([]scbe.ScbeVolume{
+				{Volume: resources.Volume{Name: "vol1"}, WWN: "wwn1"},
+				{Volume: resources.Volume{Name: "vol2"}, WWN: "wwn2"},
+			}, nil)
+		})
+		It("should save the host reports in the DB", func() {
+			err := mappingReconciler.ReportAttachments(resources.ReportAttachmentsRequest{Host: fakeHost, Volumes: []string{"vol1"}, WWNs: []string{"wwn1"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeDataModel.UpdateHostReportCallCount()).To(Equal(1))
+			host, volumes, wwns := fakeScbeDataModel.UpdateHostReportArgsForCall(0)
+			Expect(host).To(Equal(fakeHost))
+			Expect(volumes).To(Equal([]string{"vol1"}))
+			Expect(wwns).To(Equal([]string{"wwn1"}))
+		})
+		It("should return the mappings that the hosts do not report, and the mappings of the hosts that never reported as unknown", func() {
+			fakeScbeRestClient.GetVolMappingStub = func(wwn string) ([]string, error) {
+				if wwn == "wwn1" {
+					return []string{fakeHost, fakeHost2}, nil
+				}
+				return []string{fakeHost}, nil
+			}
+			fakeScbeDataModel.GetHostReportStub = func(host string) (scbe.ScbeHostReport, bool, error) {
+				if host == fakeHost {
+					return scbe.ScbeHostReport{Host: fakeHost, Volumes: "vol1", ReportedAt: time.Now()}, true, nil
+				}
+				return scbe.ScbeHostReport{}, false, nil
+			}
+			staleMappings, err := mappingReconciler.GetStaleMappings(resources.GetStaleMappingsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(staleMappings).To(ConsistOf(
+				resources.StaleMapping{Name: "vol1", Host: fakeHost2, Status: resources.MappingStatusUnknown, Reason: resources.StaleMappingReasonHostNeverReported},
+				resources.StaleMapping{Name: "vol2", Host: fakeHost, Status: resources.MappingStatusStale, Reason: resources.StaleMappingReasonVolumeNotInUse},
+			))
+		})
+		It("should return no stale mappings if the hosts report all the mapped volumes by name or WWN", func() {
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost}, nil)
+			fakeScbeDataModel.GetHostReportReturns(scbe.ScbeHostReport{Host: fakeHost, Volumes: "vol1", WWNs: "wwn2", ReportedAt: time.Now()}, true, nil)
+			staleMappings, err := mappingReconciler.GetStaleMappings(resources.GetStaleMappingsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(staleMappings).To(BeEmpty())
+		})
+		It("should return the mappings of a host that stopped reporting before the server started as unknown", func() {
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost}, nil)
+			fakeScbeDataModel.GetHostReportReturns(scbe.ScbeHostReport{Host: fakeHost, Volumes: "vol1,vol2", ReportedAt: time.Now().Add(-2 * scbe.HostReportTimeout)}, true, nil)
+			staleMappings, err := mappingReconciler.GetStaleMappings(resources.GetStaleMappingsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(staleMappings).To(HaveLen(2))
+			Expect(staleMappings[0].Status).To(Equal(resources.MappingStatusUnknown))
+			Expect(staleMappings[0].Reason).To(Equal(resources.StaleMappingReasonHostReportPending))
+		})
+		It("should fail if GetVolMapping failed", func() {
+			fakeScbeRestClient.GetVolMappingReturns(nil, fakeErr)
+			_, err := mappingReconciler.GetStaleMappings(resources.GetStaleMappingsRequest{})
+			Expect(err).To(MatchError(fakeErr))
+		})
+		It("should fail if GetHostReport failed", func() {
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost}, nil)
+			fakeScbeDataModel.GetHostReportReturns(scbe.ScbeHostReport{}, false, fakeErr)
+			_, err := mappingReconciler.GetStaleMappings(resources.GetStaleMappingsRequest{})
+			Expect(err).To(MatchError(fakeErr))
+		})
+		It("should fail to report attachments without host", func() {
+			err := mappingReconciler.ReportAttachments(resources.ReportAttachmentsRequest{Volumes: []string{"vol1"}})
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*scbe.InValidRequestError)
+			Expect(ok).To(Equal(true))
+			Expect(fakeScbeDataModel.UpdateHostReportCallCount()).To(Equal(0))
+		})
+	})
+	Context(".ForceUnmap", func() {
+		BeforeEach(func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{Volume: resources.Volume{Name: fakeVol}, WWN: "wwn1"}, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost2}, nil)
+		})
+		It("should unmap a stale mapping of the volume from the given host", func() {
+			fakeScbeDataModel.GetHostReportReturns(scbe.ScbeHostReport{Host: fakeHost2, ReportedAt: time.Now()}, true, nil)
+			err := client.(resources.MappingReconciler).ForceUnmap(resources.ForceUnmapRequest{Name: fakeVol, Host: fakeHost2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(1))
+			wwn, host := fakeScbeRestClient.UnmapVolumeArgsForCall(0)
+			Expect(wwn).To(Equal("wwn1"))
+			Expect(host).To(Equal(fakeHost2))
+		})
+		It("should refuse to unmap the volume from a host that never reported", func() {
+			err := client.(resources.MappingReconciler).ForceUnmap(resources.ForceUnmapRequest{Name: fakeVol, Host: fakeHost2})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(resources.MappingStatusUnknown))
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(0))
+		})
+		It("should refuse to unmap the volume from a host that uses it", func() {
+			fakeScbeDataModel.GetHostReportReturns(scbe.ScbeHostReport{Host: fakeHost2, WWNs: "wwn1", ReportedAt: time.Now()}, true, nil)
+			err := client.(resources.MappingReconciler).ForceUnmap(resources.ForceUnmapRequest{Name: fakeVol, Host: fakeHost2})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(0))
+		})
+		It("should refuse to unmap the volume from a host that reports its WWN in another case", func() {
+			fakeScbeDataModel.GetHostReportReturns(scbe.ScbeHostReport{Host: fakeHost2, WWNs: "WWN1", ReportedAt: time.Now()}, true, nil)
+			err := client.(resources.MappingReconciler).ForceUnmap(resources.ForceUnmapRequest{Name: fakeVol, Host: fakeHost2})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(0))
+		})
+		It("should unmap the volume from a host that never reported with force", func() {
+			err := client.(resources.MappingReconciler).ForceUnmap(resources.ForceUnmapRequest{Name: fakeVol, Host: fakeHost2, Force: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(1))
+		})
+		It("should fail without host", func() {
+			err := client.(resources.MappingReconciler).ForceUnmap(resources.ForceUnmapRequest{Name: fakeVol})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeScbeRestClient.UnmapVolumeCallCount()).To(Equal(0))
+		})
+	})
 	Context(".GetVolumeConfig", func() {
 		It("succeed and return volume info", func() {
 			volumes := make([]scbe.ScbeVolumeInfo, 1)
@@ -525,21 +1006,27 @@ var _ = Describe("scbeLocalClient", func() {
 			for i := 0; i < val.Type().NumField(); i++ {
 				reflect.ValueOf(vol).Elem().Field(i).SetString(val.Type().Field(i).Name)
 			}
-			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn", FSType: "ext4"}, nil)
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn", FSType: "ext4", Options: scbe.ScbeVolumeOptions{MaxIops: 1000, Thin: "true"}}, nil)
 			fakeScbeRestClient.GetVolumesReturns(volumes, nil)
-			fakeScbeRestClient.GetVolMappingReturns(fakeHost, nil)
+			fakeScbeRestClient.GetVolMappingReturns([]string{fakeHost}, nil)
 			volConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "name"})
 			Expect(err).To(Not(HaveOccurred()))
 			Expect(len(volConfig)).To(Equal(val.Type().NumField() + scbe.GetVolumeConfigExtraParams))
-			fstype, ok := volConfig[resources.OptionNameForVolumeFsType]
-			Expect(ok).To(Equal(true))
-			Expect(fstype).To(Equal("ext4"))
-			attachTo, ok := volConfig[resources.ScbeKeyVolAttachToHost]
-			Expect(ok).To(Equal(true))
-			Expect(attachTo).To(Equal(fakeHost))
+			extraParams := map[string]interface{}{
+				resources.OptionNameForVolumeFsType: "ext4",
+				resources.ScbeKeyVolAttachToHost:    fakeHost,
+				scbe.OptionNameForAccessMode:        scbe.AccessModeSingleWriter,
+				scbe.IsPreexisting:                  false,
+				scbe.OptionNameForMaxIops:           1000,
+				scbe.OptionNameForMaxMbps:           0,
+				scbe.OptionNameForThin:              "true",
+				scbe.OptionNameForCompression:       false,
+			}
+			Expect(len(extraParams)).To(Equal(scbe.GetVolumeConfigExtraParams))
 
 			for k, v := range volConfig {
-				if k == resources.OptionNameForVolumeFsType || k == resources.ScbeKeyVolAttachToHost {
+				if expected, ok := extraParams[k]; ok {
+					Expect(v).To(Equal(expected), k)
 					continue
 				}
 				Expect(k).To(Not(Equal("")))
@@ -607,6 +1094,13 @@ var _ = Describe("scbeLocalClient", func() {
 			Expect(fakeScbeRestClient.DeleteVolumeCallCount()).To(Equal(1))
 			Expect(fakeScbeDataModel.DeleteVolumeCallCount()).To(Equal(1))
 		})
+		It("should remove an imported volume from ubiquity without deleting it from the array", func() {
+			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{WWN: "wwn1", IsPreexisting: true}, nil)
+			err := client.RemoveVolume(fakeRemoveRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeScbeRestClient.DeleteVolumeCallCount()).To(Equal(0))
+			Expect(fakeScbeDataModel.DeleteVolumeCallCount()).To(Equal(1))
+		})
 		It("should succeed to remove the volume if all is cool", func() {
 			fakeScbeDataModel.GetVolumeReturns(scbe.ScbeVolume{}, nil)
 			fakeScbeRestClient.DeleteVolumeReturns(nil)
@@ -0,0 +1,282 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulator_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ubiquity/local/scbe"
+	"github.com/IBM/ubiquity/local/scbe/simulator"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// memDataModel keeps the volumes in memory instead of the ubiquity database
+type memDataModel struct {
+	lock        sync.Mutex
+	volumes     map[string]scbe.ScbeVolume
+	hostReports map[string]scbe.ScbeHostReport
+}
+
+func newMemDataModel() *memDataModel {
+	return &memDataModel{volumes: make(map[string]scbe.ScbeVolume), hostReports: make(map[string]scbe.ScbeHostReport)}
+}
+
+func (d *memDataModel) GetVolume(name string, mustExist bool) (scbe.ScbeVolume, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	volume, exists := d.volumes[name]
+	if mustExist && !exists {
+		return scbe.ScbeVolume{}, &resources.VolumeNotFoundError{VolName: name}
+	}
+	if !mustExist && exists {
+		return scbe.ScbeVolume{}, &resources.VolAlreadyExistsError{VolName: name}
+	}
+	return volume, nil
+}
+
+func (d *memDataModel) DeleteVolume(name string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, exists := d.volumes[name]; !exists {
+		return &resources.VolumeNotFoundError{VolName: name}
+	}
+	delete(d.volumes, name)
+	return nil
+}
+
+func (d *memDataModel) InsertVolume(volumeName string, wwn string, fstype string, size int, requestedSize string, accessMode string, isPreexisting bool, options scbe.ScbeVolumeOptions) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, exists := d.volumes[volumeName]; exists {
+		return &resources.VolAlreadyExistsError{VolName: volumeName}
+	}
+	d.volumes[volumeName] = scbe.ScbeVolume{
+		Volume: resources.Volume{Name: volumeName, Backend: resources.SCBE},
+		WWN:    wwn, FSType: fstype, Size: size, RequestedSize: requestedSize, AccessMode: accessMode, IsPreexisting: isPreexisting, Options: options}
+	return nil
+}
+
+func (d *memDataModel) ListVolumes() ([]scbe.ScbeVolume, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	volumes := []scbe.ScbeVolume{}
+	for _, volume := range d.volumes {
+		volumes = append(volumes, volume)
+	}
+	return volumes, nil
+}
+
+func (d *memDataModel) UpdateDatabaseVolume(newVolume *scbe.ScbeVolume) {}
+
+func (d *memDataModel) UpdateHostReport(host string, volumes []string, wwns []string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.hostReports[host] = scbe.ScbeHostReport{Host: host, Volumes: strings.Join(volumes, ","), WWNs: strings.Join(wwns, ","), ReportedAt: time.Now()}
+	return nil
+}
+
+func (d *memDataModel) GetHostReport(host string) (scbe.ScbeHostReport, bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	report, exists := d.hostReports[host]
+	return report, exists, nil
+}
+
+var _ = Describe("scbeLocalClient end to end with the SCBE simulator", func() {
+	const (
+		arrayId   = "array1"
+		hostName  = "node1"
+		hostName2 = "node2"
+	)
+	var (
+		server         *simulator.Server
+		client         resources.StorageClient
+		credentialInfo resources.CredentialInfo
+		config         resources.ScbeConfig
+		err            error
+	)
+
+	BeforeEach(func() {
+		os.Setenv(resources.KeyScbeSslMode, resources.SslModeRequire)
+		credentialInfo = resources.CredentialInfo{UserName: "user", Password: "password", Group: "flocker"}
+		server = simulator.NewServer(credentialInfo.UserName, credentialInfo.Password)
+		server.AddService(scbe.ScbeStorageService{Name: "gold", LogicalFree: 10 * int(utils.GiB), MaxResourceFreeSizeForProvisioning: 10 * int(utils.GiB), TotalCapacity: 10 * int(utils.GiB)}, arrayId)
+		server.AddService(scbe.ScbeStorageService{Name: "silver", LogicalFree: 20 * int(utils.GiB), MaxResourceFreeSizeForProvisioning: 20 * int(utils.GiB), TotalCapacity: 20 * int(utils.GiB)}, arrayId)
+		server.AddHost(arrayId, hostName)
+
+		config = resources.ScbeConfig{
+			ConnectionInfo:       server.ConnectionInfo(credentialInfo),
+			DefaultService:       "gold",
+			UbiquityInstanceName: "e2e",
+		}
+		restClient, err := scbe.NewScbeRestClient(config.ConnectionInfo)
+		Expect(err).NotTo(HaveOccurred())
+		client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(config, newMemDataModel(), restClient)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.Unsetenv(resources.KeyScbeSslMode)
+	})
+
+	createVolume := func(name string, opts map[string]interface{}) error {
+		return client.CreateVolume(resources.CreateVolumeRequest{CredentialInfo: credentialInfo, Name: name, Backend: resources.SCBE, Opts: opts})
+	}
+
+	Context("volume lifecycle", func() {
+		It("should create, attach, detach and remove a volume", func() {
+			err = createVolume("vol1", map[string]interface{}{scbe.OptionNameForServiceName: "gold", scbe.OptionNameForVolumeSize: "2Gi"})
+			Expect(err).NotTo(HaveOccurred())
+			volumes := server.Volumes()
+			Expect(len(volumes)).To(Equal(1))
+			Expect(volumes[0].Name).To(Equal("u_e2e_vol1"))
+			Expect(volumes[0].ServiceName).To(Equal("gold"))
+			Expect(volumes[0].LogicalCapacity).To(Equal(2 * int(utils.GiB)))
+			wwn := volumes[0].ScsiIdentifier
+
+			mountpoint, err := client.Attach(resources.AttachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: hostName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mountpoint).To(Equal(fmt.Sprintf(resources.PathToMountUbiquityBlockDevices, wwn)))
+			Expect(server.MappedHosts(wwn)).To(Equal([]string{hostName}))
+
+			// attach to the same host again is idempotent
+			_, err = client.Attach(resources.AttachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: hostName})
+			Expect(err).NotTo(HaveOccurred())
+
+			config, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{CredentialInfo: credentialInfo, Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config["attach-to"]).To(Equal(hostName))
+
+			err = client.Detach(resources.DetachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: hostName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.MappedHosts(wwn)).To(BeEmpty())
+
+			err = client.RemoveVolume(resources.RemoveVolumeRequest{CredentialInfo: credentialInfo, Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.Volumes()).To(BeEmpty())
+		})
+		It("should fail to attach a volume to a host the array does not know", func() {
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.Attach(resources.AttachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: hostName2})
+			Expect(err).To(HaveOccurred())
+		})
+		It("should choose the service with the most free capacity for profile=auto", func() {
+			err = createVolume("vol1", map[string]interface{}{scbe.OptionNameForServiceName: scbe.OptionValueForAutoService})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.Volumes()[0].ServiceName).To(Equal("silver"))
+		})
+		It("should fail to create a volume bigger than the service free capacity", func() {
+			err = createVolume("vol1", map[string]interface{}{scbe.OptionNameForServiceName: "gold", scbe.OptionNameForVolumeSize: "11Gi"})
+			Expect(err).To(HaveOccurred())
+			Expect(server.Volumes()).To(BeEmpty())
+		})
+		It("should import an existing array volume and keep it on the array when removed", func() {
+			existing, err := server.AddVolume("legacy", "gold", int(utils.GiB))
+			Expect(err).NotTo(HaveOccurred())
+			err = createVolume("vol1", map[string]interface{}{scbe.OptionNameForImport: existing.ScsiIdentifier})
+			Expect(err).NotTo(HaveOccurred())
+			err = client.RemoveVolume(resources.RemoveVolumeRequest{CredentialInfo: credentialInfo, Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(server.Volumes())).To(Equal(1))
+		})
+	})
+
+	Context("multi-attach and host creation", func() {
+		It("should map a multi-attach volume to several hosts", func() {
+			server.AddHost(arrayId, hostName2)
+			err = createVolume("vol1", map[string]interface{}{scbe.OptionNameForAccessMode: scbe.AccessModeMultiAttach})
+			Expect(err).NotTo(HaveOccurred())
+			for _, host := range []string{hostName, hostName2} {
+				_, err = client.Attach(resources.AttachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: host})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(server.MappedHosts(server.Volumes()[0].ScsiIdentifier)).To(ConsistOf(hostName, hostName2))
+		})
+		It("should define an unknown host on the array if CreateHosts is set", func() {
+			config.CreateHosts = true
+			restClient, err := scbe.NewScbeRestClient(config.ConnectionInfo)
+			Expect(err).NotTo(HaveOccurred())
+			client, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(config, newMemDataModel(), restClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.Attach(resources.AttachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: hostName2,
+				Initiators: resources.HostInitiators{Iqn: "iqn.1994-05.com.redhat:node2"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(server.Hosts())).To(Equal(2))
+			Expect(server.MappedHosts(server.Volumes()[0].ScsiIdentifier)).To(Equal([]string{hostName2}))
+		})
+	})
+
+	Context("authentication", func() {
+		It("should login again after the token expired", func() {
+			server.ExpireTokens()
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.Requests()).To(ContainElement("POST " + scbe.UrlScbeResourceGetAuth))
+			Expect(len(server.Volumes())).To(Equal(1))
+		})
+		It("should fail to create the client with wrong credentials", func() {
+			config.ConnectionInfo.CredentialInfo.Password = "wrong"
+			restClient, err := scbe.NewScbeRestClient(config.ConnectionInfo)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = scbe.NewScbeLocalClientWithNewScbeRestClientAndDataModel(config, newMemDataModel(), restClient)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("fault injection", func() {
+		It("should fail to create a volume if the array fails", func() {
+			server.InjectFault(simulator.Fault{Method: "POST", Path: scbe.UrlScbeResourceVolume, StatusCode: http.StatusInternalServerError})
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(server.Volumes()).To(BeEmpty())
+
+			// the fault is consumed, so the next request succeeds and the volume name is free again
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should fail to attach if the mapping fails and leave the volume unmapped", func() {
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).NotTo(HaveOccurred())
+			server.InjectFault(simulator.Fault{Method: "POST", Path: scbe.UrlScbeResourceMapping, StatusCode: http.StatusServiceUnavailable})
+			_, err = client.Attach(resources.AttachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: hostName})
+			Expect(err).To(HaveOccurred())
+			Expect(server.MappedHosts(server.Volumes()[0].ScsiIdentifier)).To(BeEmpty())
+		})
+		It("should fail to remove a volume that is still mapped", func() {
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.Attach(resources.AttachRequest{CredentialInfo: credentialInfo, Name: "vol1", Host: hostName})
+			Expect(err).NotTo(HaveOccurred())
+			err = client.RemoveVolume(resources.RemoveVolumeRequest{CredentialInfo: credentialInfo, Name: "vol1"})
+			Expect(err).To(HaveOccurred())
+			Expect(len(server.Volumes())).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,549 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Description
+An in-memory Spectrum Connect (SCBE) REST server for integration tests.
+It serves the api/v1 resources that the scbe package uses (auth token, services, volumes, mappings and hosts)
+over TLS, with token expiry and fault injection.
+*/
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/IBM/ubiquity/local/scbe"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTokenTTL = 10 * time.Minute
+	apiPrefix       = "/" + scbe.UrlScbeBaseSuffix
+)
+
+// Fault makes the next Count requests that match Method and Path fail with StatusCode.
+// Path is the resource url (e.g volumes or hosts/1), an empty Method or Path matches everything.
+type Fault struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+	Count      int
+	Delay      time.Duration // delay the response, also if StatusCode is 0 (no failure)
+}
+
+// Server is the Spectrum Connect simulator. All its methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+	TokenTTL time.Duration
+
+	lock        sync.Mutex
+	credentials map[string]string    // user name -> password
+	tokens      map[string]time.Time // token -> expiry time
+	services    []*scbe.ScbeStorageService
+	arrays      map[string]string // service id -> array id
+	volumes     []*scbe.ScbeResponseVolume
+	hosts       []*scbe.ScbeResponseHost
+	mappings    []*scbe.ScbeResponseMapping
+	faults      []*Fault
+	requests    []string
+	lastId      int
+}
+
+// NewServer starts a simulator that accepts the given user
+func NewServer(userName string, password string) *Server {
+	s := &Server{
+		TokenTTL:    DefaultTokenTTL,
+		credentials: map[string]string{userName: password},
+		tokens:      make(map[string]time.Time),
+		arrays:      make(map[string]string),
+	}
+	s.Server = httptest.NewTLSServer(s.newRouter())
+	return s
+}
+
+// ConnectionInfo return the connection info of the simulator for the given credentials
+func (s *Server) ConnectionInfo(credentialInfo resources.CredentialInfo) resources.ConnectionInfo {
+	host, portStr, _ := net.SplitHostPort(s.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	return resources.ConnectionInfo{CredentialInfo: credentialInfo, Port: port, ManagementIP: host}
+}
+
+// AddService adds a storage service that provisions its volumes on the given array.
+// The service id is generated if not set.
+func (s *Server) AddService(service scbe.ScbeStorageService, arrayId string) scbe.ScbeStorageService {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if service.Id == "" {
+		service.Id = s.newId("service")
+	}
+	s.services = append(s.services, &service)
+	s.arrays[service.Id] = arrayId
+	return service
+}
+
+// AddVolume adds an existing volume (e.g created outside ubiquity) and return it with its generated WWN
+func (s *Server) AddVolume(name string, serviceName string, sizeBytes int) (scbe.ScbeResponseVolume, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	service := s.findService(func(service *scbe.ScbeStorageService) bool { return service.Name == serviceName })
+	if service == nil {
+		return scbe.ScbeResponseVolume{}, fmt.Errorf("service [%s] does not exist", serviceName)
+	}
+	return *s.createVolume(name, service, sizeBytes), nil
+}
+
+// AddHost defines a host on the array and return its id
+func (s *Server) AddHost(arrayId string, name string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.createHost(arrayId, name).Id
+}
+
+// Volumes return a copy of the volumes
+func (s *Server) Volumes() []scbe.ScbeResponseVolume {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	volumes := []scbe.ScbeResponseVolume{}
+	for _, volume := range s.volumes {
+		volumes = append(volumes, *volume)
+	}
+	return volumes
+}
+
+// Hosts return a copy of the hosts
+func (s *Server) Hosts() []scbe.ScbeResponseHost {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	hosts := []scbe.ScbeResponseHost{}
+	for _, host := range s.hosts {
+		hosts = append(hosts, *host)
+	}
+	return hosts
+}
+
+// MappedHosts return the names of the hosts the volume is mapped to
+func (s *Server) MappedHosts(wwn string) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	names := []string{}
+	for _, mapping := range s.mappings {
+		if mapping.Volume == wwn {
+			names = append(names, s.findHost(mapping.Host).Name)
+		}
+	}
+	return names
+}
+
+// ExpireTokens invalidates all the tokens, so the clients have to login again
+func (s *Server) ExpireTokens() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tokens = make(map[string]time.Time)
+}
+
+// InjectFault adds a fault, faults are matched in the order they were added
+func (s *Server) InjectFault(fault Fault) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if fault.Count <= 0 {
+		fault.Count = 1
+	}
+	s.faults = append(s.faults, &fault)
+}
+
+// Requests return the requests the simulator got, e.g "POST volumes"
+func (s *Server) Requests() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) newRouter() http.Handler {
+	router := mux.NewRouter()
+	api := router.PathPrefix(apiPrefix).Subrouter()
+	api.HandleFunc("/"+scbe.UrlScbeResourceGetAuth, s.login).Methods("POST")
+	api.HandleFunc("/"+scbe.UrlScbeResourceService, s.authenticated(s.getServices)).Methods("GET")
+	api.HandleFunc("/"+scbe.UrlScbeResourceVolume, s.authenticated(s.getVolumes)).Methods("GET")
+	api.HandleFunc("/"+scbe.UrlScbeResourceVolume, s.authenticated(s.postVolume)).Methods("POST")
+	api.HandleFunc("/"+scbe.UrlScbeResourceVolume+"/{wwn}", s.authenticated(s.deleteVolume)).Methods("DELETE")
+	api.HandleFunc("/"+scbe.UrlScbeResourceMapping, s.authenticated(s.getMappings)).Methods("GET")
+	api.HandleFunc("/"+scbe.UrlScbeResourceMapping, s.authenticated(s.postMapping)).Methods("POST")
+	api.HandleFunc("/"+scbe.UrlScbeResourceMapping, s.authenticated(s.deleteMapping)).Methods("DELETE")
+	api.HandleFunc("/"+scbe.UrlScbeResourceHost, s.authenticated(s.getHosts)).Methods("GET")
+	api.HandleFunc("/"+scbe.UrlScbeResourceHost, s.authenticated(s.postHost)).Methods("POST")
+	api.HandleFunc("/"+scbe.UrlScbeResourceHost+"/{id}", s.authenticated(s.getHost)).Methods("GET")
+	return s.withFaults(router)
+}
+
+// withFaults records the request and applies the first matching fault
+func (s *Server) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, apiPrefix), "/")
+		s.lock.Lock()
+		s.requests = append(s.requests, req.Method+" "+path)
+		var fault *Fault
+		for i, f := range s.faults {
+			if (f.Method == "" || f.Method == req.Method) && (f.Path == "" || f.Path == path) {
+				fault = f
+				if f.Count--; f.Count == 0 {
+					s.faults = append(s.faults[:i], s.faults[i+1:]...)
+				}
+				break
+			}
+		}
+		s.lock.Unlock()
+
+		if fault != nil {
+			time.Sleep(fault.Delay)
+			if fault.StatusCode != 0 {
+				w.WriteHeader(fault.StatusCode)
+				w.Write([]byte(fault.Body))
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// authenticated rejects requests without a valid token with 401, like Spectrum Connect does
+func (s *Server) authenticated(handler func(w http.ResponseWriter, req *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		token := strings.TrimPrefix(req.Header.Get(scbe.HTTP_AUTH_KEY), "Token ")
+		s.lock.Lock()
+		expiry, exists := s.tokens[token]
+		valid := exists && time.Now().Before(expiry)
+		s.lock.Unlock()
+		if !valid {
+			writeError(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+		handler(w, req)
+	}
+}
+
+func (s *Server) login(w http.ResponseWriter, req *http.Request) {
+	credentialInfo := resources.CredentialInfo{}
+	if err := readJson(req, &credentialInfo); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if password, exists := s.credentials[credentialInfo.UserName]; !exists || password != credentialInfo.Password {
+		writeError(w, http.StatusUnauthorized, "Invalid user name or password")
+		return
+	}
+	token := s.newId("token")
+	s.tokens[token] = time.Now().Add(s.TokenTTL)
+	writeJson(w, http.StatusOK, scbe.LoginResponse{Token: token})
+}
+
+func (s *Server) getServices(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("name")
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	services := []scbe.ScbeStorageService{}
+	for _, service := range s.services {
+		if name == "" || service.Name == name {
+			services = append(services, *service)
+		}
+	}
+	writeJson(w, http.StatusOK, services)
+}
+
+func (s *Server) getVolumes(w http.ResponseWriter, req *http.Request) {
+	wwn := req.URL.Query().Get("scsi_identifier")
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	volumes := []scbe.ScbeResponseVolume{}
+	for _, volume := range s.volumes {
+		if wwn == "" || volume.ScsiIdentifier == wwn {
+			volumes = append(volumes, *volume)
+		}
+	}
+	writeJson(w, http.StatusOK, volumes)
+}
+
+func (s *Server) postVolume(w http.ResponseWriter, req *http.Request) {
+	params := scbe.ScbeCreateVolumePostParams{}
+	if err := readJson(req, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sizeBytes, err := utils.ParseQuantity(fmt.Sprintf("%d%s", params.Size, params.SizeUnit))
+	if err != nil || params.Size <= 0 || !utils.StringInSlice(params.SizeUnit, scbe.SupportedSizeUnits) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid size [%d%s]", params.Size, params.SizeUnit))
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	service := s.findService(func(service *scbe.ScbeStorageService) bool { return service.Id == params.Service })
+	if service == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Service [%s] does not exist", params.Service))
+		return
+	}
+	for _, volume := range s.volumes {
+		if volume.Name == params.Name {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Volume name [%s] already exists", params.Name))
+			return
+		}
+	}
+	if scbe.IsServiceCapacityReported(*service) && int(sizeBytes) > scbe.GetServiceHeadroom(*service) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Not enough space in service [%s]", service.Name))
+		return
+	}
+
+	volume := s.createVolume(params.Name, service, int(sizeBytes))
+	if params.MaxIops > 0 {
+		volume.IOPsLimit = strconv.Itoa(params.MaxIops)
+	}
+	if params.MaxMbps > 0 {
+		volume.BandwidthLimitMB = strconv.Itoa(params.MaxMbps)
+	}
+	volume.Compressed = params.Compression
+	writeJson(w, http.StatusCreated, volume)
+}
+
+func (s *Server) deleteVolume(w http.ResponseWriter, req *http.Request) {
+	wwn := mux.Vars(req)["wwn"]
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, volume := range s.volumes {
+		if volume.ScsiIdentifier != wwn {
+			continue
+		}
+		for _, mapping := range s.mappings {
+			if mapping.Volume == wwn {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Volume [%s] is mapped", wwn))
+				return
+			}
+		}
+		if service := s.findService(func(service *scbe.ScbeStorageService) bool { return service.Id == volume.ServiceId }); service != nil {
+			updateServiceCapacity(service, -1, volume.LogicalCapacity)
+		}
+		s.volumes = append(s.volumes[:i], s.volumes[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Volume [%s] does not exist", wwn))
+}
+
+func (s *Server) getMappings(w http.ResponseWriter, req *http.Request) {
+	wwn := req.URL.Query().Get("volume")
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	mappings := []scbe.ScbeResponseMapping{}
+	for _, mapping := range s.mappings {
+		if wwn == "" || mapping.Volume == wwn {
+			mappings = append(mappings, *mapping)
+		}
+	}
+	writeJson(w, http.StatusOK, mappings)
+}
+
+func (s *Server) postMapping(w http.ResponseWriter, req *http.Request) {
+	params := scbe.ScbeMapVolumePostParams{}
+	if err := readJson(req, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.findVolume(params.VolumeId) == nil || s.findHost(params.HostId) == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Volume [%s] or host [%d] does not exist", params.VolumeId, params.HostId))
+		return
+	}
+	lunNumber := 1
+	for _, mapping := range s.mappings {
+		if mapping.Volume == params.VolumeId && mapping.Host == params.HostId {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Volume [%s] is already mapped to host [%d]", params.VolumeId, params.HostId))
+			return
+		}
+		if mapping.Host == params.HostId && mapping.LunNumber >= lunNumber {
+			lunNumber = mapping.LunNumber + 1
+		}
+	}
+	s.lastId++
+	mapping := &scbe.ScbeResponseMapping{Id: s.lastId, Volume: params.VolumeId, Host: params.HostId, LunNumber: lunNumber}
+	s.mappings = append(s.mappings, mapping)
+	writeJson(w, http.StatusCreated, scbe.ScbeResponseMappings{Mappings: []scbe.ScbeResponseMapping{*mapping}})
+}
+
+func (s *Server) deleteMapping(w http.ResponseWriter, req *http.Request) {
+	params := scbe.ScbeUnMapVolumePostParams{}
+	if err := readJson(req, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, mapping := range s.mappings {
+		if mapping.Volume == params.VolumeId && mapping.Host == params.HostId {
+			s.mappings = append(s.mappings[:i], s.mappings[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Volume [%s] is not mapped to host [%d]", params.VolumeId, params.HostId))
+}
+
+func (s *Server) getHosts(w http.ResponseWriter, req *http.Request) {
+	arrayId, name := req.URL.Query().Get("array_id"), req.URL.Query().Get("name")
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	hosts := []scbe.ScbeResponseHost{}
+	for _, host := range s.hosts {
+		if (arrayId == "" || host.Array == arrayId) && (name == "" || host.Name == name) {
+			hosts = append(hosts, *host)
+		}
+	}
+	writeJson(w, http.StatusOK, hosts)
+}
+
+func (s *Server) getHost(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(req)["id"])
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	host := s.findHost(id)
+	if err != nil || host == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Host [%s] does not exist", mux.Vars(req)["id"]))
+		return
+	}
+	writeJson(w, http.StatusOK, host)
+}
+
+func (s *Server) postHost(w http.ResponseWriter, req *http.Request) {
+	params := scbe.ScbeCreateHostPostParams{}
+	if err := readJson(req, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(params.IscsiPorts) == 0 && len(params.FcPorts) == 0 {
+		writeError(w, http.StatusBadRequest, "A host must have at least one port")
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, host := range s.hosts {
+		if host.Array == params.ArrayId && host.Name == params.Name {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Host [%s] already exists", params.Name))
+			return
+		}
+	}
+	writeJson(w, http.StatusCreated, s.createHost(params.ArrayId, params.Name))
+}
+
+// createVolume must be called with the lock held
+func (s *Server) createVolume(name string, service *scbe.ScbeStorageService, sizeBytes int) *scbe.ScbeResponseVolume {
+	s.lastId++
+	volume := &scbe.ScbeResponseVolume{
+		ScsiIdentifier:  fmt.Sprintf("6001738CFC9035E8%016X", s.lastId),
+		Id:              strconv.Itoa(s.lastId),
+		Name:            name,
+		Array:           s.arrays[service.Id],
+		ArrayName:       s.arrays[service.Id],
+		ServiceName:     service.Name,
+		ServiceId:       service.Id,
+		PoolName:        service.Name + "_pool",
+		LogicalCapacity: sizeBytes,
+	}
+	s.volumes = append(s.volumes, volume)
+	updateServiceCapacity(service, 1, sizeBytes)
+	return volume
+}
+
+// createHost must be called with the lock held
+func (s *Server) createHost(arrayId string, name string) *scbe.ScbeResponseHost {
+	s.lastId++
+	host := &scbe.ScbeResponseHost{Id: s.lastId, Array: arrayId, Name: name, HostId: strconv.Itoa(s.lastId)}
+	s.hosts = append(s.hosts, host)
+	return host
+}
+
+func (s *Server) findService(match func(service *scbe.ScbeStorageService) bool) *scbe.ScbeStorageService {
+	for _, service := range s.services {
+		if match(service) {
+			return service
+		}
+	}
+	return nil
+}
+
+func (s *Server) findVolume(wwn string) *scbe.ScbeResponseVolume {
+	for _, volume := range s.volumes {
+		if volume.ScsiIdentifier == wwn {
+			return volume
+		}
+	}
+	return nil
+}
+
+func (s *Server) findHost(id int) *scbe.ScbeResponseHost {
+	for _, host := range s.hosts {
+		if host.Id == id {
+			return host
+		}
+	}
+	return nil
+}
+
+func (s *Server) newId(prefix string) string {
+	s.lastId++
+	return fmt.Sprintf("%s-%d-%d", prefix, s.lastId, time.Now().UnixNano())
+}
+
+// updateServiceCapacity accounts a created (numVolumes 1) or deleted (numVolumes -1) volume of sizeBytes in the service
+func updateServiceCapacity(service *scbe.ScbeStorageService, numVolumes int, sizeBytes int) {
+	service.NumVolumes += numVolumes
+	sizeBytes *= numVolumes
+	if !scbe.IsServiceCapacityReported(*service) {
+		return
+	}
+	service.UsedCapacity += sizeBytes
+	service.LogicalFree -= sizeBytes
+	service.MaxResourceFreeSizeForProvisioning -= sizeBytes
+	service.MaxResourceLogicalFree -= sizeBytes
+}
+
+func readJson(req *http.Request, v interface{}) error {
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJson(w, statusCode, map[string]string{"error": message})
+}
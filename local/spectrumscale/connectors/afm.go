@@ -0,0 +1,73 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import "fmt"
+
+// AfmModes are the AFM cache modes a volume can use: read-only, single-writer, independent-writer and local-updates
+var AfmModes = []string{"ro", "sw", "iw", "lu"}
+
+// afmAttributes maps the AFM options of a fileset to the fileset attributes of mmcrfileset -p and of the REST API
+var afmAttributes = []struct {
+	option    string
+	attribute string
+}{
+	{UserSpecifiedAfmTarget, "afmTarget"},
+	{UserSpecifiedAfmMode, "afmMode"},
+	{UserSpecifiedAfmAsyncDelay, "afmAsyncDelay"},
+	{UserSpecifiedAfmExpirationTimeout, "afmExpirationTimeout"},
+	{UserSpecifiedAfmFileLookupRefreshInterval, "afmFileLookupRefreshInterval"},
+	{UserSpecifiedAfmDirLookupRefreshInterval, "afmDirLookupRefreshInterval"},
+}
+
+// AfmOptions are the fileset options of an AFM fileset
+func AfmOptions() []string {
+	options := []string{}
+	for _, afmAttribute := range afmAttributes {
+		options = append(options, afmAttribute.option)
+	}
+	return options
+}
+
+// IsAfmFileset return true if the opts create an AFM fileset, which is always an independent fileset
+func IsAfmFileset(opts map[string]interface{}) bool {
+	_, afmTargetSpecified := opts[UserSpecifiedAfmTarget]
+	return afmTargetSpecified
+}
+
+// afmFilesetAttributes return the attribute=value pairs of the AFM options in opts
+func afmFilesetAttributes(opts map[string]interface{}) map[string]string {
+	attributes := make(map[string]string)
+	for _, afmAttribute := range afmAttributes {
+		if value, specified := opts[afmAttribute.option]; specified {
+			attributes[afmAttribute.attribute] = fmt.Sprintf("%v", value)
+		}
+	}
+	return attributes
+}
+
+// afmFilesetArgs return the -p arguments of mmcrfileset for the AFM options in opts
+func afmFilesetArgs(opts map[string]interface{}) []string {
+	args := []string{}
+	attributes := afmFilesetAttributes(opts)
+	for _, afmAttribute := range afmAttributes {
+		if value, specified := attributes[afmAttribute.attribute]; specified {
+			args = append(args, "-p", afmAttribute.attribute+"="+value)
+		}
+	}
+	return args
+}
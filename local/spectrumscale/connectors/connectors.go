@@ -31,38 +31,59 @@ type SpectrumScaleConnector interface {
 	MountFileSystem(filesystemName string) error
 	ListFilesystems() ([]string, error)
 	GetFilesystemMountpoint(filesystemName string) (string, error)
+	GetFilesystemCapacity(filesystemName string) (FilesystemCapacity, error)
+	ListFilesystemPools(filesystemName string) ([]string, error)
+	//Policy operations
+	GetPolicy(filesystemName string) (string, error)
+	ChangePolicy(filesystemName string, policy string) error
 	//Fileset operations
 	CreateFileset(filesystemName string, filesetName string, opts map[string]interface{}) error
 	DeleteFileset(filesystemName string, filesetName string) error
 	LinkFileset(filesystemName string, filesetName string) error
+	LinkFilesetAt(filesystemName string, filesetName string, junctionPath string) error
 	UnlinkFileset(filesystemName string, filesetName string) error
 	ListFilesets(filesystemName string) ([]resources.Volume, error)
 	ListFileset(filesystemName string, filesetName string) (resources.Volume, error)
 	IsFilesetLinked(filesystemName string, filesetName string) (bool, error)
+	GetFilesetAfmState(filesystemName string, filesetName string) (string, error)
 	//TODO modify quota from string to Capacity (see kubernetes)
 	ListFilesetQuota(filesystemName string, filesetName string) (string, error)
 	SetFilesetQuota(filesystemName string, filesetName string, quota string) error
-	ExportNfs(volumeMountpoint string, clientConfig string) error
+	ListFilesetQuotaUsage(filesystemName string, filesetName string) (FilesetQuotaUsage, error)
+	ExportNfs(volumeMountpoint string, clients []NfsClient) error
+	UpdateNfsExport(volumeMountpoint string, addClients []NfsClient, changeClients []NfsClient, removeClients []NfsClient) error
+	ListNfsExportClients(volumeMountpoint string) ([]NfsClient, error)
 	UnexportNfs(volumeMountpoint string) error
+	ExportSmb(shareName string, volumeMountpoint string, smbOptions map[string]string) error
+	UnexportSmb(shareName string) error
 }
 
 const (
 	UserSpecifiedFilesetType string = "fileset-type"
 	UserSpecifiedInodeLimit  string = "inode-limit"
+	UserSpecifiedInodeSpace  string = "inode-space" // the fileset whose inode space a dependent fileset shares, the root fileset if not given
+	UserSpecifiedPool        string = "pool"
 	UserSpecifiedUid         string = "uid"
 	UserSpecifiedGid         string = "gid"
+
+	UserSpecifiedAfmTarget                    string = "afm-target"
+	UserSpecifiedAfmMode                      string = "afm-mode"
+	UserSpecifiedAfmAsyncDelay                string = "afm-async-delay"
+	UserSpecifiedAfmExpirationTimeout         string = "afm-expiration-timeout"
+	UserSpecifiedAfmFileLookupRefreshInterval string = "afm-file-lookup-refresh-interval"
+	UserSpecifiedAfmDirLookupRefreshInterval  string = "afm-dir-lookup-refresh-interval"
 )
 
 func GetSpectrumScaleConnector(logger *log.Logger, config resources.SpectrumScaleConfig) (SpectrumScaleConnector, error) {
 	if config.RestConfig.Endpoint != "" {
 		logger.Printf("Initializing SpectrumScale REST connector\n")
-		return NewSpectrumRestV2(logger, config.RestConfig)
+		return NewSpectrumRestConnector(logger, config.RestConfig)
 	}
 	if config.SshConfig.User != "" && config.SshConfig.Host != "" {
 		if config.SshConfig.Port == "" || config.SshConfig.Port == "0" {
 			config.SshConfig.Port = "22"
 		}
-		logger.Printf("Initializing SpectrumScale SSH connector with sshConfig: %+v\n", config.SshConfig)
+		logger.Printf("Initializing SpectrumScale SSH connector with user: %s, host: %s, port: %s\n", config.SshConfig.User, config.SshConfig.Host, config.SshConfig.Port)
 		return NewSpectrumSSH(logger, config.SshConfig)
 	}
 	logger.Println("Initializing SpectrumScale MMCLI Connector")
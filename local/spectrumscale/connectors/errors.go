@@ -0,0 +1,137 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/IBM/ubiquity/resources"
+)
+
+type UnsupportedOperationError struct {
+	Connector string
+	Operation string
+}
+
+func (e *UnsupportedOperationError) Error() string {
+	return fmt.Sprintf("Operation [%s] is not supported by the Spectrum Scale %s connector", e.Operation, e.Connector)
+}
+
+type unsupportedRestApiVersionError struct {
+	version string
+}
+
+func (e *unsupportedRestApiVersionError) Error() string {
+	return fmt.Sprintf("Unsupported Spectrum Scale REST API version [%s], supported versions are [%s, %s]",
+		e.version, RestApiVersionV2, RestApiVersionV1)
+}
+
+type restApiVersionProbeError struct {
+	endpoint string
+	reason   string
+}
+
+func (e *restApiVersionProbeError) Error() string {
+	return fmt.Sprintf("Failed to determine the REST API version of the Spectrum Scale GUI [%s]: %s", e.endpoint, e.reason)
+}
+
+type restSslModeValueInvalidError struct {
+	sslMode string
+}
+
+func (e *restSslModeValueInvalidError) Error() string {
+	return fmt.Sprintf("Illegal SSL mode value [%s] of the Spectrum Scale REST connector, the possible values are [%s, %s]",
+		e.sslMode, resources.SslModeRequire, resources.SslModeVerifyFull)
+}
+
+type restSslModeFullVerifyWithoutCAfileError struct {
+}
+
+func (e *restSslModeFullVerifyWithoutCAfileError) Error() string {
+	return fmt.Sprintf("The Spectrum Scale REST connector requires a CA file (SSC_REST_CA_FILE) in SSL mode [%s]", resources.SslModeVerifyFull)
+}
+
+type restCertFingerprintMismatchError struct {
+	fingerprint string
+}
+
+func (e *restCertFingerprintMismatchError) Error() string {
+	return fmt.Sprintf("The certificate of the Spectrum Scale GUI (SHA-256 fingerprint [%s]) does not match the pinned certificate fingerprint", e.fingerprint)
+}
+
+// JobTimeoutError is returned if an asynchronous job is still running when the job timeout exceeds, the job is cancelled
+type JobTimeoutError struct {
+	Operation string
+	JobID     uint64
+	Timeout   time.Duration
+	Progress  []string
+}
+
+func (e *JobTimeoutError) Error() string {
+	return fmt.Sprintf("Unable to %s: job [%d] did not complete within %v and was cancelled, progress %v", e.Operation, e.JobID, e.Timeout, e.Progress)
+}
+
+// JobFailedError is returned if an asynchronous job ends in the FAILED status
+type JobFailedError struct {
+	Operation string
+	JobID     uint64
+	Stderr    []string
+	Progress  []string
+}
+
+func (e *JobFailedError) Error() string {
+	return fmt.Sprintf("Unable to %s: job [%d] failed: %v", e.Operation, e.JobID, e.Stderr)
+}
+
+// JobCancelledError is returned if an asynchronous job ends in the CANCELLED status
+type JobCancelledError struct {
+	Operation string
+	JobID     uint64
+	Progress  []string
+}
+
+func (e *JobCancelledError) Error() string {
+	return fmt.Sprintf("Unable to %s: job [%d] was cancelled, progress %v", e.Operation, e.JobID, e.Progress)
+}
+
+// JobStatusUnexpectedError is returned if an asynchronous job reports a status that is not known
+type JobStatusUnexpectedError struct {
+	Operation string
+	JobID     uint64
+	Status    string
+}
+
+func (e *JobStatusUnexpectedError) Error() string {
+	return fmt.Sprintf("Unable to %s: job [%d] has the unexpected status [%s]", e.Operation, e.JobID, e.Status)
+}
+
+// SshCommandTimeoutError is returned if a command executed over SSH did not complete within the command timeout.
+// If MayStillRun, the SSH session was closed before the server stopped the command, so it may still be running until
+// the timeout of the server stops it.
+type SshCommandTimeoutError struct {
+	Command     string
+	Timeout     time.Duration
+	MayStillRun bool
+}
+
+func (e *SshCommandTimeoutError) Error() string {
+	if e.MayStillRun {
+		return fmt.Sprintf("Command [%s] over SSH did not complete within %v, the SSH session was closed but the command may still be running on the server", e.Command, e.Timeout)
+	}
+	return fmt.Sprintf("Command [%s] over SSH did not complete within %v and was stopped by the server", e.Command, e.Timeout)
+}
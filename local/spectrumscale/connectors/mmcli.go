@@ -21,8 +21,9 @@ import (
 	"github.com/IBM/ubiquity/resources"
 	"github.com/IBM/ubiquity/utils"
 	"log"
-	"net/url"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -42,33 +43,25 @@ func NewSpectrumMMCLIWithExecutor(logger *log.Logger, executor utils.Executor) (
 
 func (s *spectrum_mmcli) GetClusterId() (string, error) {
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlscluster"
-	//args := []string{spectrumCommand}
-	return GetClusterIdInternal(s.logger, s.executor, spectrumCommand, nil)
+	args := []string{"-Y"}
+	return GetClusterIdInternal(s.logger, s.executor, spectrumCommand, args)
 }
 func GetClusterIdInternal(logger *log.Logger, executor utils.Executor, command string, args []string) (string, error) {
-	var clusterId string
-
 	outputBytes, err := executor.Execute(command, args)
 	if err != nil {
 		logger.Printf("Error running command: %v", err)
 		return "", err
 	}
-	spectrumOutput := string(outputBytes)
-
-	lines := strings.Split(spectrumOutput, "\n")
-	if len(lines) < 4 {
-		logger.Println("Error determining cluster id")
+	records, err := parseMMSection(string(outputBytes), "clusterSummary")
+	if err != nil {
+		logger.Printf("Error parsing mmlscluster output: %v", err)
 		return "", fmt.Errorf("Error determining cluster id")
 	}
-	tokens := strings.Split(lines[4], ":")
-
-	if len(tokens) == 2 {
-		if strings.TrimSpace(tokens[0]) == "GPFS cluster id" {
-			clusterId = strings.TrimSpace(tokens[1])
-		}
+	if len(records) == 0 || records[0]["clusterId"] == "" {
+		logger.Println("Error determining cluster id")
+		return "", fmt.Errorf("Error determining cluster id")
 	}
-	return clusterId, nil
-
+	return records[0]["clusterId"], nil
 }
 
 func (s *spectrum_mmcli) IsFilesystemMounted(filesystemName string) (bool, error) {
@@ -91,7 +84,11 @@ func IsFilesystemMountedInternal(logger *log.Logger, executor utils.Executor, fi
 		logger.Printf("Error running command %v\n", err)
 		return false, err
 	}
-	mountedNodes := extractMountedNodes(string(outputBytes))
+	mountedNodes, err := extractMountedNodes(string(outputBytes))
+	if err != nil {
+		logger.Printf("Error parsing mmlsmount output: %v", err)
+		return false, err
+	}
 	if len(mountedNodes) == 0 {
 		//not mounted anywhere
 		return false, nil
@@ -113,21 +110,18 @@ func IsFilesystemMountedInternal(logger *log.Logger, executor utils.Executor, fi
 
 }
 
-func extractMountedNodes(spectrumOutput string) []string {
+func extractMountedNodes(spectrumOutput string) ([]string, error) {
 	var nodes []string
-	lines := strings.Split(spectrumOutput, "\n")
-	if len(lines) == 1 {
-		return nodes
-	}
-	for _, line := range lines[1:] {
-		tokens := strings.Split(line, ":")
-		if len(tokens) > 10 {
-			if tokens[11] != "" {
-				nodes = append(nodes, tokens[11])
-			}
+	records, err := parseMMSection(spectrumOutput, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record["nodeName"] != "" {
+			nodes = append(nodes, record["nodeName"])
 		}
 	}
-	return nodes
+	return nodes, nil
 }
 
 func (s *spectrum_mmcli) MountFileSystem(filesystemName string) error {
@@ -163,8 +157,29 @@ func MountFileSystemInternal(logger *log.Logger, executor utils.Executor, filesy
 }
 
 func (s *spectrum_mmcli) ListFilesystems() ([]string, error) {
-	//TODO not yet implemented
-	return nil, nil
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfs"
+	args := []string{"all", "-T", "-Y"}
+	return ListFilesystemsInternal(s.logger, s.executor, spectrumCommand, args)
+}
+
+func ListFilesystemsInternal(logger *log.Logger, executor utils.Executor, command string, args []string) ([]string, error) {
+	outputBytes, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Printf("Error running command: %s", err.Error())
+		return nil, err
+	}
+	records, err := parseMMSection(string(outputBytes), "")
+	if err != nil {
+		logger.Printf("Error parsing mmlsfs output: %v", err)
+		return nil, fmt.Errorf("Unable to list filesystems: %s", err.Error())
+	}
+	var filesystems []string
+	for _, record := range records {
+		if record["fieldName"] == "defaultMountPoint" {
+			filesystems = append(filesystems, record["deviceName"])
+		}
+	}
+	return filesystems, nil
 }
 func (s *spectrum_mmcli) GetFilesystemMountpoint(filesystemName string) (string, error) {
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfs"
@@ -178,25 +193,15 @@ func GetFilesystemMountpointInternal(logger *log.Logger, executor utils.Executor
 		logger.Printf("Error running command: %s", err.Error())
 		return "", err
 	}
-	spectrumOutput := string(outputBytes)
-
-	lines := strings.Split(spectrumOutput, "\n")
-	if len(lines) < 2 {
+	records, err := parseMMSection(string(outputBytes), "")
+	if err != nil {
+		logger.Printf("Error parsing mmlsfs output: %v", err)
 		return "", fmt.Errorf("Cannot determine filesystem mountpoint")
 	}
-	tokens := strings.Split(lines[1], ":")
-
-	if len(tokens) > 8 {
-		if strings.TrimSpace(tokens[6]) == filesystemName {
-			mountpoint := strings.TrimSpace(tokens[8])
-
-			//Todo this should be changed to url.PathUnescape when available
-			mountpoint, err := url.PathUnescape(mountpoint)
-			if err != nil {
-				logger.Printf("Error decoding mountpoint: %s\n", err)
-			} else {
-				logger.Printf("Returning mountpoint: %s\n", mountpoint)
-			}
+	for _, record := range records {
+		if record["deviceName"] == filesystemName && record["fieldName"] == "defaultMountPoint" {
+			mountpoint := record["data"]
+			logger.Printf("Returning mountpoint: %s\n", mountpoint)
 			return mountpoint, nil
 		}
 	}
@@ -204,6 +209,115 @@ func GetFilesystemMountpointInternal(logger *log.Logger, executor utils.Executor
 
 }
 
+func (s *spectrum_mmcli) GetFilesystemCapacity(filesystemName string) (FilesystemCapacity, error) {
+	s.logger.Println("spectrumLocalClient: getFilesystemCapacity start")
+	defer s.logger.Println("spectrumLocalClient: getFilesystemCapacity end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmdf"
+	args := []string{filesystemName, "-Y"}
+	return GetFilesystemCapacityInternal(s.logger, s.executor, filesystemName, spectrumCommand, args)
+}
+
+// GetFilesystemCapacityInternal return the size and the free space of the filesystem from the fsTotal record of mmdf -Y
+func GetFilesystemCapacityInternal(logger *log.Logger, executor utils.Executor, filesystemName string, command string, args []string) (FilesystemCapacity, error) {
+	outputBytes, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Printf("failed to get the capacity of filesystem %s: %v", filesystemName, err)
+		return FilesystemCapacity{}, fmt.Errorf("Failed to get the capacity of filesystem %s: %s", filesystemName, err.Error())
+	}
+	records, err := parseMMSection(string(outputBytes), "fsTotal")
+	if err != nil || len(records) == 0 {
+		logger.Printf("error parsing mmdf output for filesystem %s: %v", filesystemName, err)
+		return FilesystemCapacity{}, fmt.Errorf("Error parsing the capacity of filesystem %s", filesystemName)
+	}
+	// the sizes are in KB
+	size, err := strconv.ParseInt(records[0]["fsSize"], 10, 64)
+	if err != nil {
+		return FilesystemCapacity{}, fmt.Errorf("Error parsing fsSize [%s] of filesystem %s", records[0]["fsSize"], filesystemName)
+	}
+	free, err := strconv.ParseInt(records[0]["freeBlocks"], 10, 64)
+	if err != nil {
+		return FilesystemCapacity{}, fmt.Errorf("Error parsing freeBlocks [%s] of filesystem %s", records[0]["freeBlocks"], filesystemName)
+	}
+	return FilesystemCapacity{TotalBytes: size * 1024, FreeBytes: free * 1024}, nil
+}
+
+func (s *spectrum_mmcli) ListFilesystemPools(filesystemName string) ([]string, error) {
+	s.logger.Println("spectrumLocalClient: listFilesystemPools start")
+	defer s.logger.Println("spectrumLocalClient: listFilesystemPools end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfs"
+	args := []string{filesystemName, "-P", "-Y"}
+	return ListFilesystemPoolsInternal(s.logger, s.executor, filesystemName, spectrumCommand, args)
+}
+
+// ListFilesystemPoolsInternal return the storage pools of the filesystem from the pools attribute of mmlsfs -P -Y
+func ListFilesystemPoolsInternal(logger *log.Logger, executor utils.Executor, filesystemName string, command string, args []string) ([]string, error) {
+	outputBytes, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Printf("failed to list the pools of filesystem %s: %v", filesystemName, err)
+		return nil, fmt.Errorf("Failed to list the pools of filesystem %s: %s", filesystemName, err.Error())
+	}
+	records, err := parseMMSection(string(outputBytes), "")
+	if err != nil {
+		logger.Printf("error parsing mmlsfs output for filesystem %s: %v", filesystemName, err)
+		return nil, fmt.Errorf("Error parsing the pools of filesystem %s", filesystemName)
+	}
+	for _, record := range records {
+		if record["fieldName"] == "pools" {
+			return parsePoolNames(record["data"]), nil
+		}
+	}
+	return nil, fmt.Errorf("Error parsing the pools of filesystem %s", filesystemName)
+}
+
+func (s *spectrum_mmcli) GetPolicy(filesystemName string) (string, error) {
+	s.logger.Println("spectrumLocalClient: getPolicy start")
+	defer s.logger.Println("spectrumLocalClient: getPolicy end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlspolicy"
+	args := []string{filesystemName, "-L"}
+	return GetPolicyInternal(s.logger, s.executor, filesystemName, spectrumCommand, args)
+}
+
+// GetPolicyInternal return the rules of the policy installed in the filesystem, or an empty policy if none is installed
+func GetPolicyInternal(logger *log.Logger, executor utils.Executor, filesystemName string, command string, args []string) (string, error) {
+	outputBytes, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Printf("failed to get the policy of filesystem %s: %v", filesystemName, err)
+		return "", fmt.Errorf("Failed to get the policy of filesystem %s: %s", filesystemName, err.Error())
+	}
+	policy := string(outputBytes)
+	if strings.HasPrefix(strings.TrimSpace(policy), noPolicyInstalled) {
+		return "", nil
+	}
+	return policy, nil
+}
+
+func (s *spectrum_mmcli) ChangePolicy(filesystemName string, policy string) error {
+	s.logger.Println("spectrumLocalClient: changePolicy start")
+	defer s.logger.Println("spectrumLocalClient: changePolicy end")
+
+	return ChangePolicyInternal(s.logger, s.executor, filesystemName, policy, "sh", changePolicyArgs(filesystemName, policy))
+}
+
+// changePolicyArgs return the arguments of a shell that writes the policy to a temporary file and installs it with
+// mmchpolicy, since mmchpolicy only reads the rules from a file
+func changePolicyArgs(filesystemName string, policy string) []string {
+	script := `f=$(mktemp) && printf "%s" "$1" > "$f" && /usr/lpp/mmfs/bin/mmchpolicy "$2" "$f"; rc=$?; rm -f "$f"; exit $rc`
+	return []string{"-c", script, "sh", policy, filesystemName}
+}
+
+func ChangePolicyInternal(logger *log.Logger, executor utils.Executor, filesystemName string, policy string, command string, args []string) error {
+	output, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Printf("failed to change the policy of filesystem %s: %v (%s)", filesystemName, err, string(output))
+		return fmt.Errorf("Failed to change the policy of filesystem %s: %s", filesystemName, err.Error())
+	}
+	logger.Printf("changed the policy of filesystem %s: %s", filesystemName, string(output))
+	return nil
+}
+
 func (s *spectrum_mmcli) CreateFileset(filesystemName string, filesetName string, opts map[string]interface{}) error {
 	s.logger.Println("spectrumLocalClient: createFileset start")
 	defer s.logger.Println("spectrumLocalClient: createFileset end")
@@ -217,17 +331,51 @@ func (s *spectrum_mmcli) CreateFileset(filesystemName string, filesetName string
 	filesetType, filesetTypeSpecified := opts[UserSpecifiedFilesetType]
 	inodeLimit, inodeLimitSpecified := opts[UserSpecifiedInodeLimit]
 
-	if filesetTypeSpecified && filesetType.(string) == "independent" {
+	if (filesetTypeSpecified && filesetType.(string) == "independent") || IsAfmFileset(opts) {
 		args = append(args, "--inode-space", "new")
 
 		if inodeLimitSpecified {
 			args = append(args, "--inode-limit", inodeLimit.(string))
 		}
+	} else if inodeSpace, inodeSpaceSpecified := opts[UserSpecifiedInodeSpace]; inodeSpaceSpecified {
+		args = append(args, "--inode-space", inodeSpace.(string))
 	}
+	args = append(args, afmFilesetArgs(opts)...)
 
 	return CreateFilesetInternal(s.logger, s.executor, filesystemName, filesetName, spectrumCommand, args)
 }
 
+func (s *spectrum_mmcli) GetFilesetAfmState(filesystemName string, filesetName string) (string, error) {
+	s.logger.Println("spectrumLocalClient: getFilesetAfmState start")
+	defer s.logger.Println("spectrumLocalClient: getFilesetAfmState end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmafmctl"
+	args := []string{filesystemName, "getstate", "-j", filesetName, "-Y"}
+	return GetFilesetAfmStateInternal(s.logger, s.executor, filesystemName, filesetName, spectrumCommand, args)
+}
+
+// GetFilesetAfmStateInternal return the cache state of the AFM fileset from mmafmctl getstate -Y
+func GetFilesetAfmStateInternal(logger *log.Logger, executor utils.Executor, filesystemName string, filesetName string, command string, args []string) (string, error) {
+	outputBytes, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Printf("failed to get the AFM state of fileset %s: %v", filesetName, err)
+		return "", fmt.Errorf("Failed to get the AFM state of fileset %s on filesystem %s: %s", filesetName, filesystemName, err.Error())
+	}
+	sections, err := ParseMMOutput(string(outputBytes))
+	if err != nil {
+		logger.Printf("error parsing mmafmctl output for fileset %s: %v", filesetName, err)
+		return "", fmt.Errorf("Error parsing the AFM state of fileset %s", filesetName)
+	}
+	for _, records := range sections {
+		for _, record := range records {
+			if record["filesetName"] == filesetName {
+				return record["cacheState"], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("Error parsing the AFM state of fileset %s", filesetName)
+}
+
 func CreateFilesetInternal(logger *log.Logger, executor utils.Executor, filesystemName string, filesetName string, command string, args []string) error {
 	output, err := executor.Execute(command, args)
 
@@ -273,25 +421,27 @@ func IsFilesetLinkedInternal(logger *log.Logger, executor utils.Executor, filesy
 		return false, err
 	}
 
-	spectrumOutput := string(outputBytes)
-	lines := strings.Split(spectrumOutput, "\n")
-
-	if len(lines) == 1 {
+	records, err := parseMMSection(string(outputBytes), "")
+	if err != nil {
+		logger.Printf("Error listing fileset %s after parsing: %v", filesetName, err)
+		return false, fmt.Errorf("Error listing fileset %s after parsing", filesetName)
+	}
+	record, found := findFilesetRecord(records, filesetName)
+	if !found {
 		logger.Printf("Error in listing fileset\n")
 		return false, fmt.Errorf("Error listing fileset %s", filesetName)
 	}
+	return record["status"] == "Linked", nil
+}
 
-	tokens := strings.Split(lines[1], ":")
-	if len(tokens) >= 11 {
-		if tokens[10] == "Linked" {
-			return true, nil
-		} else {
-			return false, nil
+// findFilesetRecord return the record of the fileset in the mmlsfileset output
+func findFilesetRecord(records []MMRecord, filesetName string) (MMRecord, bool) {
+	for _, record := range records {
+		if record["filesetName"] == filesetName {
+			return record, true
 		}
 	}
-	logger.Printf("Error listing fileset %s after parsing", filesetName)
-	return false, fmt.Errorf("Error listing fileset %s after parsing", filesetName)
-
+	return nil, false
 }
 
 func (s *spectrum_mmcli) LinkFileset(filesystemName string, filesetName string) error {
@@ -306,11 +456,17 @@ func (s *spectrum_mmcli) LinkFileset(filesystemName string, filesetName string)
 		return err
 	}
 
+	return s.LinkFilesetAt(filesystemName, filesetName, path.Join(mountpoint, filesetName))
+}
+
+func (s *spectrum_mmcli) LinkFilesetAt(filesystemName string, filesetName string, junctionPath string) error {
+	s.logger.Println("spectrumLocalClient: linkFilesetAt start")
+	defer s.logger.Println("spectrumLocalClient: linkFilesetAt end")
+
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlinkfileset"
-	filesetPath := path.Join(mountpoint, filesetName)
-	args := []string{filesystemName, filesetName, "-J", filesetPath}
+	args := []string{filesystemName, filesetName, "-J", junctionPath}
 	s.logger.Printf("Args for link fileset%#v", args)
-	err = LinkFilesetInternal(s.logger, s.executor, filesystemName, filesetName, spectrumCommand, args)
+	err := LinkFilesetInternal(s.logger, s.executor, filesystemName, filesetName, spectrumCommand, args)
 	if err != nil {
 		s.logger.Printf("error linking fileset %v", err)
 		return err
@@ -346,8 +502,32 @@ func UnlinkFilesetInternal(logger *log.Logger, executor utils.Executor, filesyst
 }
 
 func (s *spectrum_mmcli) ListFilesets(filesystemName string) ([]resources.Volume, error) {
-	return nil, nil
+	s.logger.Println("spectrumLocalClient: ListFilesets start")
+	defer s.logger.Println("spectrumLocalClient: ListFilesets end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfileset"
+	args := []string{filesystemName, "-Y"}
+	return ListFilesetsInternal(s.logger, s.executor, filesystemName, spectrumCommand, args)
+}
+
+func ListFilesetsInternal(logger *log.Logger, executor utils.Executor, filesystemName string, command string, args []string) ([]resources.Volume, error) {
+	outputBytes, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Println(err)
+		return nil, err
+	}
+	records, err := parseMMSection(string(outputBytes), "")
+	if err != nil {
+		logger.Printf("Error parsing mmlsfileset output: %v", err)
+		return nil, fmt.Errorf("Unable to list filesets for %s: %s", filesystemName, err.Error())
+	}
+	var volumes []resources.Volume
+	for _, record := range records {
+		volumes = append(volumes, resources.Volume{Name: record["filesetName"], Mountpoint: record["path"]})
+	}
+	return volumes, nil
 }
+
 func (s *spectrum_mmcli) ListFileset(filesystemName string, filesetName string) (resources.Volume, error) {
 	s.logger.Println("spectrumLocalClient: ListFileset start")
 	defer s.logger.Println("spectrumLocalClient: ListFileset end")
@@ -357,13 +537,21 @@ func (s *spectrum_mmcli) ListFileset(filesystemName string, filesetName string)
 	return ListFilesetInternal(s.logger, s.executor, filesystemName, filesetName, spectrumCommand, args)
 }
 func ListFilesetInternal(logger *log.Logger, executor utils.Executor, filesystemName string, filesetName string, command string, args []string) (resources.Volume, error) {
-	_, err := executor.Execute(command, args)
+	outputBytes, err := executor.Execute(command, args)
 	if err != nil {
 		logger.Println(err)
 		return resources.Volume{}, err
 	}
-	//TODO check what we need to return
-	return resources.Volume{Name: filesetName}, err
+	records, err := parseMMSection(string(outputBytes), "")
+	if err != nil {
+		logger.Printf("Error parsing mmlsfileset output: %v", err)
+		return resources.Volume{}, fmt.Errorf("Unable to list fileset %s: %s", filesetName, err.Error())
+	}
+	record, found := findFilesetRecord(records, filesetName)
+	if !found {
+		return resources.Volume{}, fmt.Errorf("Unable to list fileset %s: fileset not found in filesystem %s", filesetName, filesystemName)
+	}
+	return resources.Volume{Name: filesetName, Mountpoint: record["path"]}, nil
 }
 
 //TODO modify quota from string to Capacity (see kubernetes)
@@ -372,10 +560,11 @@ func (s *spectrum_mmcli) ListFilesetQuota(filesystemName string, filesetName str
 	defer s.logger.Println("spectrumLocalClient: verifyFilesetQuota end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsquota"
-	args := []string{"-j", filesetName, filesystemName, "--block-size", "auto"}
+	args := []string{"-j", filesetName, filesystemName, "-Y"}
 	return ListFilesetQuotaInternal(s.logger, s.executor, filesystemName, filesetName, spectrumCommand, args)
 }
 
+// ListFilesetQuotaInternal return the block quota of the fileset in KB, e.g 1048576K
 func ListFilesetQuotaInternal(logger *log.Logger, executor utils.Executor, filesystemName string, filesetName string, command string, args []string) (string, error) {
 	outputBytes, err := executor.Execute(command, args)
 
@@ -384,23 +573,74 @@ func ListFilesetQuotaInternal(logger *log.Logger, executor utils.Executor, files
 		return "", fmt.Errorf("Failed to list quota for fileset %s: %s", filesetName, err.Error())
 	}
 
-	spectrumOutput := string(outputBytes)
+	record, err := findFilesetQuotaRecord(logger, string(outputBytes), filesetName)
+	if err != nil {
+		return "", err
+	}
+	if record["blockQuota"] == "" {
+		return "", fmt.Errorf("Error listing quota for fileset %s", filesetName)
+	}
+	return record["blockQuota"] + "K", nil
+}
 
-	lines := strings.Split(spectrumOutput, "\n")
+func (s *spectrum_mmcli) ListFilesetQuotaUsage(filesystemName string, filesetName string) (FilesetQuotaUsage, error) {
+	s.logger.Println("spectrumLocalClient: listFilesetQuotaUsage start")
+	defer s.logger.Println("spectrumLocalClient: listFilesetQuotaUsage end")
 
-	if len(lines) > 2 {
-		tokens := strings.Fields(lines[2])
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsquota"
+	args := []string{"-j", filesetName, filesystemName, "-Y"}
+	return ListFilesetQuotaUsageInternal(s.logger, s.executor, filesystemName, filesetName, spectrumCommand, args)
+}
 
-		if len(tokens) > 3 {
-			if tokens[3] != "" {
-				return tokens[3], nil
-			}
-		} else {
-			logger.Printf("error parsing tokens while listing quota for fileset %s: %v", filesetName, err)
-			return "", fmt.Errorf("Error parsing tokens while listing quota for fileset %s", filesetName)
+// ListFilesetQuotaUsageInternal return the usage and the limits of the fileset from its mmlsquota -Y record
+func ListFilesetQuotaUsageInternal(logger *log.Logger, executor utils.Executor, filesystemName string, filesetName string, command string, args []string) (FilesetQuotaUsage, error) {
+	outputBytes, err := executor.Execute(command, args)
+	if err != nil {
+		logger.Printf("failed to list quota usage for fileset %s: %v", filesetName, err)
+		return FilesetQuotaUsage{}, fmt.Errorf("Failed to list quota usage for fileset %s: %s", filesetName, err.Error())
+	}
+
+	record, err := findFilesetQuotaRecord(logger, string(outputBytes), filesetName)
+	if err != nil {
+		return FilesetQuotaUsage{}, err
+	}
+	// the block values are in KB, the hard limit is 0 if only the soft quota is set
+	values := make(map[string]int64)
+	for _, field := range []string{"blockUsage", "blockQuota", "blockLimit", "filesUsage", "filesQuota", "filesLimit"} {
+		if record[field] == "" {
+			continue
 		}
+		value, err := strconv.ParseInt(record[field], 10, 64)
+		if err != nil {
+			logger.Printf("error parsing %s [%s] of fileset %s: %v", field, record[field], filesetName, err)
+			return FilesetQuotaUsage{}, fmt.Errorf("Error parsing %s [%s] while listing quota usage for fileset %s", field, record[field], filesetName)
+		}
+		values[field] = value
+	}
+	usage := FilesetQuotaUsage{BlockUsage: values["blockUsage"] * 1024, BlockLimit: values["blockLimit"] * 1024,
+		FilesUsage: values["filesUsage"], FilesLimit: values["filesLimit"]}
+	if usage.BlockLimit == 0 {
+		usage.BlockLimit = values["blockQuota"] * 1024
 	}
-	return "", fmt.Errorf("Error listing quota for fileset %s", filesetName)
+	if usage.FilesLimit == 0 {
+		usage.FilesLimit = values["filesQuota"]
+	}
+	return usage, nil
+}
+
+// findFilesetQuotaRecord return the FILESET record of the fileset in a mmlsquota -Y output
+func findFilesetQuotaRecord(logger *log.Logger, output string, filesetName string) (MMRecord, error) {
+	records, err := parseMMSection(output, "")
+	if err != nil {
+		logger.Printf("error parsing tokens while listing quota for fileset %s: %v", filesetName, err)
+		return nil, fmt.Errorf("Error parsing tokens while listing quota for fileset %s", filesetName)
+	}
+	for _, record := range records {
+		if record["quotaType"] == "FILESET" && record["name"] == filesetName {
+			return record, nil
+		}
+	}
+	return nil, fmt.Errorf("Error listing quota for fileset %s", filesetName)
 }
 
 func (s *spectrum_mmcli) SetFilesetQuota(filesystemName string, filesetName string, quota string) error {
@@ -426,12 +666,12 @@ func SetFilesetQuotaInternal(logger *log.Logger, executor utils.Executor, filesy
 	return nil
 }
 
-func (s *spectrum_mmcli) ExportNfs(volumeMountpoint string, clientConfig string) error {
+func (s *spectrum_mmcli) ExportNfs(volumeMountpoint string, clients []NfsClient) error {
 	s.logger.Println("spectrumLocalClient: ExportNfs start")
 	defer s.logger.Println("spectrumLocalClient: ExportNfs end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmnfs"
-	args := []string{"export", "add", volumeMountpoint, "--client", clientConfig}
+	args := []string{"export", "add", volumeMountpoint, "--client", FormatNfsClients(clients)}
 
 	return ExportNfsInternal(s.logger, s.executor, spectrumCommand, args)
 }
@@ -449,6 +689,90 @@ func ExportNfsInternal(logger *log.Logger, executor utils.Executor, command stri
 	return nil
 }
 
+func (s *spectrum_mmcli) UpdateNfsExport(volumeMountpoint string, addClients []NfsClient, changeClients []NfsClient, removeClients []NfsClient) error {
+	s.logger.Println("spectrumLocalClient: UpdateNfsExport start")
+	defer s.logger.Println("spectrumLocalClient: UpdateNfsExport end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmnfs"
+	for _, args := range nfsExportChangeArgs(volumeMountpoint, addClients, changeClients, removeClients) {
+		if err := UpdateNfsExportInternal(s.logger, s.executor, spectrumCommand, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nfsExportChangeArgs return the args of the mmnfs export change commands which add, change and remove the clients
+// of an export. The clients are added first so that the export keeps a client while it changes.
+func nfsExportChangeArgs(volumeMountpoint string, addClients []NfsClient, changeClients []NfsClient, removeClients []NfsClient) [][]string {
+	commands := [][]string{}
+	if len(addClients) > 0 {
+		commands = append(commands, []string{"export", "change", volumeMountpoint, "--nfsadd", FormatNfsClients(addClients)})
+	}
+	if len(changeClients) > 0 {
+		commands = append(commands, []string{"export", "change", volumeMountpoint, "--nfschange", FormatNfsClients(changeClients)})
+	}
+	for _, client := range nfsClientNames(removeClients) {
+		commands = append(commands, []string{"export", "change", volumeMountpoint, "--nfsremove", client})
+	}
+	return commands
+}
+
+func UpdateNfsExportInternal(logger *log.Logger, executor utils.Executor, command string, args []string) error {
+
+	output, err := executor.Execute(command, args)
+
+	if err != nil {
+		logger.Printf("Failed to change Nfs export: error %#v UpdateNfsExport output: %#v\n", err, output)
+		return fmt.Errorf("Failed to change Nfs export: %s", err.Error())
+	}
+
+	logger.Printf("UpdateNfsExport output: %s\n", string(output))
+	return nil
+}
+
+func (s *spectrum_mmcli) ListNfsExportClients(volumeMountpoint string) ([]NfsClient, error) {
+	s.logger.Println("spectrumLocalClient: ListNfsExportClients start")
+	defer s.logger.Println("spectrumLocalClient: ListNfsExportClients end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmnfs"
+	args := []string{"export", "list", "--nfsdefs", volumeMountpoint, "-Y"}
+
+	return ListNfsExportClientsInternal(s.logger, s.executor, volumeMountpoint, spectrumCommand, args)
+}
+
+// ListNfsExportClientsInternal return the clients of the export from the mmnfs export list -Y output, one line per client
+func ListNfsExportClientsInternal(logger *log.Logger, executor utils.Executor, volumeMountpoint string, command string, args []string) ([]NfsClient, error) {
+
+	output, err := executor.Execute(command, args)
+
+	if err != nil {
+		logger.Printf("Failed to list Nfs export: error %#v ListNfsExportClients output: %#v\n", err, output)
+		return nil, fmt.Errorf("Failed to list Nfs export %s: %s", volumeMountpoint, err.Error())
+	}
+
+	sections, err := ParseMMOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+	clients := []NfsClient{}
+	for _, records := range sections {
+		for _, record := range records {
+			if record["Path"] != volumeMountpoint {
+				continue
+			}
+			clients = append(clients, newListedNfsClient(record["Clients"], record["Access_Type"], record["Squash"], record["SecType"], record["Protocols"]))
+		}
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("The Nfs export %s does not exist", volumeMountpoint)
+	}
+	if err := ValidateNfsClients(clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
 func (s *spectrum_mmcli) UnexportNfs(volumeMountpoint string) error {
 	s.logger.Println("spectrumLocalClient: UnexportNfs start")
 	defer s.logger.Println("spectrumLocalClient: UnexportNfs end")
@@ -471,3 +795,63 @@ func UnexportNfsInternal(logger *log.Logger, executor utils.Executor, command st
 	logger.Printf("UnexportNfs output: %s\n", string(output))
 	return nil
 }
+
+func (s *spectrum_mmcli) ExportSmb(shareName string, volumeMountpoint string, smbOptions map[string]string) error {
+	s.logger.Println("spectrumLocalClient: ExportSmb start")
+	defer s.logger.Println("spectrumLocalClient: ExportSmb end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmsmb"
+	args := append([]string{"export", "add", shareName, volumeMountpoint}, smbOptionArgs(smbOptions)...)
+
+	return ExportSmbInternal(s.logger, s.executor, spectrumCommand, args)
+}
+
+// smbOptionArgs return the --option args of mmsmb export add, sorted by option so that the command is stable
+func smbOptionArgs(smbOptions map[string]string) []string {
+	keys := make([]string, 0, len(smbOptions))
+	for key := range smbOptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := []string{}
+	for _, key := range keys {
+		args = append(args, "--option", fmt.Sprintf("%s=%s", key, smbOptions[key]))
+	}
+	return args
+}
+
+func ExportSmbInternal(logger *log.Logger, executor utils.Executor, command string, args []string) error {
+
+	output, err := executor.Execute(command, args)
+
+	if err != nil {
+		logger.Printf("Failed to export fileset via Smb: error %#v ExportSmb output: %#v\n", err, output)
+		return fmt.Errorf("Failed to export fileset via Smb: %s", err.Error())
+	}
+
+	logger.Printf("ExportSmb output: %s\n", string(output))
+	return nil
+}
+
+func (s *spectrum_mmcli) UnexportSmb(shareName string) error {
+	s.logger.Println("spectrumLocalClient: UnexportSmb start")
+	defer s.logger.Println("spectrumLocalClient: UnexportSmb end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmsmb"
+	args := []string{"export", "remove", shareName, "--force"}
+
+	return UnexportSmbInternal(s.logger, s.executor, spectrumCommand, args)
+}
+
+func UnexportSmbInternal(logger *log.Logger, executor utils.Executor, command string, args []string) error {
+
+	output, err := executor.Execute(command, args)
+
+	if err != nil {
+		logger.Printf("Failed to unexport fileset via Smb: error %#v UnexportSmb output: %#v \n", err, output)
+		return fmt.Errorf("Failed to unexport fileset via Smb: %s", err.Error())
+	}
+
+	logger.Printf("UnexportSmb output: %s\n", string(output))
+	return nil
+}
@@ -0,0 +1,59 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors_test
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Regression scenarios of real mm command outputs, replayed from the fixtures in testdata
+var _ = Describe("spectrum_mmcli_replay_test", func() {
+	replayFixture := func(fixture string) (utils.ReplayExecutor, connectors.SpectrumScaleConnector) {
+		scenario, err := utils.LoadExecutorScenario(filepath.Join("testdata", fixture))
+		Expect(err).ToNot(HaveOccurred())
+		replay, err := utils.NewReplayExecutor(scenario, new(fakes.FakeExecutor))
+		Expect(err).ToNot(HaveOccurred())
+		logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+		spectrumMMCLI, err := connectors.NewSpectrumMMCLIWithExecutor(logger, replay)
+		Expect(err).ToNot(HaveOccurred())
+		return replay, spectrumMMCLI
+	}
+
+	It("creates a fileset, links it under the filesystem mountpoint and fails to list a missing fileset", func() {
+		replay, spectrumMMCLI := replayFixture("link_fileset.json")
+		Expect(spectrumMMCLI.CreateFileset("gold", "fset1", map[string]interface{}{})).To(Succeed())
+		linked, err := spectrumMMCLI.IsFilesetLinked("gold", "fset1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(linked).To(BeFalse())
+		Expect(spectrumMMCLI.LinkFileset("gold", "fset1")).To(Succeed())
+
+		_, err = spectrumMMCLI.ListFileset("gold", "fset2")
+		Expect(err).To(HaveOccurred())
+		exitCode, ok := utils.GetExitStatusCode(err)
+		Expect(ok).To(BeTrue())
+		Expect(exitCode).To(Equal(2))
+		Expect(replay.Verify()).To(Succeed())
+	})
+})
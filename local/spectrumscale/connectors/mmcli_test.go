@@ -28,6 +28,21 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+const mmlsclusterOutput = `mmlscluster:clusterSummary:HEADER:version:reserved:reserved:clusterName:clusterId:uidDomain:rshPath:rshSudoWrapper:rcpPath:rcpSudoWrapper:repositoryType:primaryServer:secondaryServer:
+mmlscluster:clusterSummary:0:1:::gpfs1.local:7293282412741634556:gpfs1.local:%2Fusr%2Fbin%2Fssh:no:%2Fusr%2Fbin%2Fscp:no:CCR:node1:node2:
+mmlscluster:clusterNode:HEADER:version:reserved:reserved:nodeNumber:daemonNodeName:ipAddress:adminNodeName:designation:otherNodeRoles:adminLoginName:otherNodeRolesAlias:
+mmlscluster:clusterNode:0:1:::1:node1:10.0.0.1:node1:quorumManager::::
+`
+
+const mmlsmountOutput = `mmlsmount::HEADER:version:reserved:reserved:localDevName:realDevName:owningCluster:totalNodeCount:nodeIP:nodeName:clusterName:env:
+mmlsmount::0:1:::fake-filesystem:fake-filesystem:gpfs1.local:2:10.0.0.1:node1:gpfs1.local:RW:
+`
+
+func mmlsfilesetOutput(fileset string, status string, path string) string {
+	return "mmlsfileset::HEADER:version:reserved:reserved:filesystemName:filesetName:id:rootInode:status:path:parentId:created:inodes:dataInKB:comment:\n" +
+		fmt.Sprintf("mmlsfileset::0:1:::fake-filesystem:%s:0:3:%s:%s:--:Mon Oct 19 10%%3A00%%3A00 2026:0:0:root fileset:\n", fileset, status, path)
+}
+
 var _ = Describe("spectrum_mmcli", func() {
 	var (
 		spectrumMMCLI connectors.SpectrumScaleConnector
@@ -78,12 +93,14 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should succeed when execute command returns the right info", func() {
-			stringOutput := "a \n b\n c \n d \nGPFS cluster id:123\n"
+			stringOutput := mmlsclusterOutput
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			clusterID, err := spectrumMMCLI.GetClusterId()
 			Expect(err).ToNot(HaveOccurred())
-			Expect(clusterID).To(Equal("123"))
+			Expect(clusterID).To(Equal("7293282412741634556"))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{"-Y"}))
 		})
 	})
 
@@ -99,7 +116,7 @@ var _ = Describe("spectrum_mmcli", func() {
 
 		It("should fail when execute hostname errors", func() {
 			fakeExec.HostnameReturns("", fmt.Errorf("failed to execute hostname"))
-			stringOutput := "a \n1:2:3:4:5:6:7:8:9:10:11:12\n"
+			stringOutput := mmlsmountOutput
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			response, err := spectrumMMCLI.IsFilesystemMounted("fake-filesystem")
@@ -110,7 +127,7 @@ var _ = Describe("spectrum_mmcli", func() {
 
 		It("should succeed when execute command returns response that does not contain the actual hostname", func() {
 			fakeExec.HostnameReturns("fake-host", nil)
-			stringOutput := "a \n1:2:3:4:5:6:7:8:9:10:11:12\n"
+			stringOutput := mmlsmountOutput
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			response, err := spectrumMMCLI.IsFilesystemMounted("fake-filesystem")
@@ -120,7 +137,7 @@ var _ = Describe("spectrum_mmcli", func() {
 
 		It("should succeed when execute command returns response that contains the actual hostname", func() {
 			fakeExec.HostnameReturns("fake-host", nil)
-			stringOutput := "a \n1:2:3:4:5:6:7:8:9:10:11:fake-host\n 12:21:32"
+			stringOutput := mmlsmountOutput + "mmlsmount::0:1:::fake-filesystem:fake-filesystem:gpfs1.local:2:10.0.0.2:fake-host:gpfs1.local:RW:\n"
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			response, err := spectrumMMCLI.IsFilesystemMounted("fake-filesystem")
@@ -148,7 +165,26 @@ var _ = Describe("spectrum_mmcli", func() {
 
 	//TODO when listfilesystems is implemented
 	Context(".ListFileSystems", func() {
-		It("", func() {})
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("failed to execute command"))
+
+			filesystems, err := spectrumMMCLI.ListFilesystems()
+			Expect(err).To(HaveOccurred())
+			Expect(filesystems).To(BeNil())
+		})
+
+		It("should succeed when execute command returns valid output", func() {
+			stringOutput := "mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:\n" +
+				"mmlsfs::0:1:::gold:defaultMountPoint:%2Fgpfs%2Fgold::\n" +
+				"mmlsfs::0:1:::silver:defaultMountPoint:%2Fgpfs%2Fsilver::\n"
+			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
+
+			filesystems, err := spectrumMMCLI.ListFilesystems()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filesystems).To(Equal([]string{"gold", "silver"}))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{"all", "-T", "-Y"}))
+		})
 	})
 
 	Context(".GetFileSystemMountpoint", func() {
@@ -172,7 +208,7 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should fail when execute command returns non valid output", func() {
-			stringOutput := "a \nfake-filesystem:8:fake-mountpoint:10:11:fake-host\n 12:21:32"
+			stringOutput := "mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:\nmmlsfs::0:1:::other-filesystem:defaultMountPoint:%2Fgpfs%2Fother::\n"
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			mountpoint, err := spectrumMMCLI.GetFilesystemMountpoint("fake-filesystem")
@@ -182,12 +218,108 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should succeed when execute command returns valid output", func() {
-			stringOutput := "a \n1:2:3:4:5:6:fake-filesystem:8:fake-mountpoint:10:11:fake-host\n 12:21:32"
+			stringOutput := "mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:\nmmlsfs::0:1:::fake-filesystem:defaultMountPoint:%2Fgpfs%2Ffake-filesystem::\n"
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			mountpoint, err := spectrumMMCLI.GetFilesystemMountpoint("fake-filesystem")
 			Expect(err).ToNot(HaveOccurred())
-			Expect(mountpoint).To(Equal("fake-mountpoint"))
+			Expect(mountpoint).To(Equal("/gpfs/fake-filesystem"))
+		})
+	})
+
+	Context(".GetFilesystemCapacity", func() {
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			_, err := spectrumMMCLI.GetFilesystemCapacity(filesystem)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Failed to get the capacity of filesystem %s: error executing command", filesystem)))
+		})
+
+		It("should fail when execute command returns no fsTotal record", func() {
+			returnMsg := "mmdf:inode:HEADER:version:reserved:reserved:usedInodes:freeInodes:allocatedInodes:maxInodes:\nmmdf:inode:0:1:::4038:61754:65792:65792:\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			_, err := spectrumMMCLI.GetFilesystemCapacity(filesystem)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Error parsing the capacity of filesystem %s", filesystem)))
+		})
+
+		It("should return the size and the free space in bytes", func() {
+			returnMsg := "mmdf:nsd:HEADER:version:reserved:reserved:nsdName:storagePool:diskSize:failureGroup:metadata:data:freeBlocks:freeBlocksPct:freeFragments:freeFragmentsPct:diskAvailableForAlloc:\n" +
+				"mmdf:nsd:0:1:::nsd1:system:20971520:1:Yes:Yes:16777216:80:2872:0::\n" +
+				"mmdf:fsTotal:HEADER:version:reserved:reserved:fsSize:freeBlocks:freeBlocksPct:freeFragments:freeFragmentsPct:\n" +
+				"mmdf:fsTotal:0:1:::20971520:16777216:80:2872:0:\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			capacity, err := spectrumMMCLI.GetFilesystemCapacity(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capacity).To(Equal(connectors.FilesystemCapacity{TotalBytes: 20971520 * 1024, FreeBytes: 16777216 * 1024}))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, "-Y"}))
+		})
+	})
+
+	Context(".ListFilesystemPools", func() {
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			_, err := spectrumMMCLI.ListFilesystemPools(filesystem)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Failed to list the pools of filesystem %s: error executing command", filesystem)))
+		})
+
+		It("should return the pools of the filesystem", func() {
+			returnMsg := "mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:\n" +
+				"mmlsfs::0:1:::" + filesystem + ":pools:system%3Bflash%3Bnlsas::\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			pools, err := spectrumMMCLI.ListFilesystemPools(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pools).To(Equal([]string{"system", "flash", "nlsas"}))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, "-P", "-Y"}))
+		})
+	})
+
+	Context(".GetPolicy", func() {
+		It("should return the installed rules", func() {
+			fakeExec.ExecuteReturns([]byte("RULE 'default' SET POOL 'system'\n"), nil)
+
+			policy, err := spectrumMMCLI.GetPolicy(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy).To(Equal("RULE 'default' SET POOL 'system'\n"))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, "-L"}))
+		})
+
+		It("should return an empty policy when no policy is installed", func() {
+			fakeExec.ExecuteReturns([]byte("No policy file was installed for file system '"+filesystem+"'.\n"), nil)
+
+			policy, err := spectrumMMCLI.GetPolicy(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy).To(Equal(""))
+		})
+	})
+
+	Context(".ChangePolicy", func() {
+		It("should install the policy through a temporary file", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+
+			err := spectrumMMCLI.ChangePolicy(filesystem, "RULE 'default' SET POOL 'system'\n")
+			Expect(err).ToNot(HaveOccurred())
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("sh"))
+			Expect(args[1]).To(ContainSubstring("/usr/lpp/mmfs/bin/mmchpolicy"))
+			Expect(args[3:]).To(Equal([]string{"RULE 'default' SET POOL 'system'\n", filesystem}))
+		})
+
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns([]byte("[E] Error on SET POOL"), fmt.Errorf("exit status 1"))
+
+			err := spectrumMMCLI.ChangePolicy(filesystem, "RULE 'default' SET POOL 'nopool'\n")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Failed to change the policy of filesystem %s: exit status 1", filesystem)))
 		})
 	})
 
@@ -206,6 +338,59 @@ var _ = Describe("spectrum_mmcli", func() {
 			err = spectrumMMCLI.CreateFileset(filesystem, fileset, opts)
 			Expect(err).ToNot(HaveOccurred())
 		})
+		It("should create a dependent fileset in the inode space of the given fileset", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+
+			err = spectrumMMCLI.CreateFileset(filesystem, fileset, map[string]interface{}{connectors.UserSpecifiedInodeSpace: "parent-fileset"})
+			Expect(err).ToNot(HaveOccurred())
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("/usr/lpp/mmfs/bin/mmcrfileset"))
+			Expect(args).To(Equal([]string{filesystem, fileset, "-t", "fileset for container volume", "--inode-space", "parent-fileset"}))
+		})
+		It("should create an independent fileset in a new inode space", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+
+			err = spectrumMMCLI.CreateFileset(filesystem, fileset, map[string]interface{}{connectors.UserSpecifiedFilesetType: "independent", connectors.UserSpecifiedInodeSpace: "parent-fileset"})
+			Expect(err).ToNot(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, fileset, "-t", "fileset for container volume", "--inode-space", "new"}))
+		})
+		It("should create an independent fileset with the afm attributes", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+			afmOpts := map[string]interface{}{"afm-target": "nfs://home1/gpfs/datasets", "afm-mode": "ro", "afm-async-delay": "30"}
+
+			err = spectrumMMCLI.CreateFileset(filesystem, fileset, afmOpts)
+			Expect(err).ToNot(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, fileset, "-t", "fileset for container volume", "--inode-space", "new",
+				"-p", "afmTarget=nfs://home1/gpfs/datasets", "-p", "afmMode=ro", "-p", "afmAsyncDelay=30"}))
+		})
+	})
+
+	Context(".GetFilesetAfmState", func() {
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			_, err := spectrumMMCLI.GetFilesetAfmState(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should return the cache state of the fileset", func() {
+			returnMsg := "mmafmctl::HEADER:version:reserved:reserved:filesetName:filesetTarget:cacheState:gatewayNode:queueLength:queueNumExec:\n" +
+				"mmafmctl::0:1:::" + fileset + ":nfs%3A%2F%2Fhome1%2Fgpfs%2Fdatasets:Active:node1:0:16:\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			afmState, err := spectrumMMCLI.GetFilesetAfmState(filesystem, fileset)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(afmState).To(Equal("Active"))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, "getstate", "-j", fileset, "-Y"}))
+		})
+		It("should fail when the fileset is not an afm fileset", func() {
+			fakeExec.ExecuteReturns([]byte("mmafmctl::HEADER:version:reserved:reserved:filesetName:filesetTarget:cacheState:gatewayNode:queueLength:queueNumExec:\n"), nil)
+
+			_, err := spectrumMMCLI.GetFilesetAfmState(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Context(".DeleteFileset", func() {
@@ -237,8 +422,8 @@ var _ = Describe("spectrum_mmcli", func() {
 			Expect(response).To(Equal(false))
 		})
 
-		It("should fail when execute command returns one line output", func() {
-			stringOutput := "a"
+		It("should fail when execute command returns no fileset", func() {
+			stringOutput := "mmlsfileset::HEADER:version:reserved:reserved:filesystemName:filesetName:id:rootInode:status:path:\n"
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			response, err := spectrumMMCLI.IsFilesetLinked(filesystem, fileset)
@@ -258,7 +443,7 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should succeed when execute command returns valid output", func() {
-			stringOutput := "a \n1:2:3:4:5:6:fake-filesystem:8:fake-mountpoint:10:Linked:fake-host"
+			stringOutput := mmlsfilesetOutput(fileset, "Linked", "%2Fgpfs%2Ffake-filesystem%2Ffake-fileset")
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			response, err := spectrumMMCLI.IsFilesetLinked(filesystem, fileset)
@@ -267,7 +452,7 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should succeed when execute command returns valid output", func() {
-			stringOutput := "a \n1:2:3:4:5:6:fake-filesystem:8:fake-mountpoint:10:nothing:fake-host"
+			stringOutput := mmlsfilesetOutput(fileset, "Unlinked", "--")
 			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
 
 			response, err := spectrumMMCLI.IsFilesetLinked(filesystem, fileset)
@@ -288,12 +473,34 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should succeed when execute command does not error", func() {
-			returnMsg := "a \n1:2:3:4:5:6:fake-filesystem:8:fake-mountpoint:10:11:fake-host\n 12:21:32"
+			returnMsg := "mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:\nmmlsfs::0:1:::fake-filesystem:defaultMountPoint:%2Fgpfs%2Ffake-filesystem::\n"
 			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
 
 			err = spectrumMMCLI.LinkFileset(filesystem, fileset)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(fakeExec.ExecuteCallCount()).To(Equal(2))
+			_, args := fakeExec.ExecuteArgsForCall(1)
+			Expect(args).To(Equal([]string{filesystem, fileset, "-J", "/gpfs/fake-filesystem/fake-fileset"}))
+		})
+	})
+
+	Context(".LinkFilesetAt", func() {
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			err = spectrumMMCLI.LinkFilesetAt(filesystem, fileset, "/gpfs/fake-filesystem/parent/fake-fileset")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to link fileset: error executing command"))
+		})
+
+		It("should link the fileset at the junction path", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+
+			err = spectrumMMCLI.LinkFilesetAt(filesystem, fileset, "/gpfs/fake-filesystem/parent/fake-fileset")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(1))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, fileset, "-J", "/gpfs/fake-filesystem/parent/fake-fileset"}))
 		})
 	})
 
@@ -317,7 +524,28 @@ var _ = Describe("spectrum_mmcli", func() {
 
 	//TODO when listfilesets is implemented
 	Context(".ListFilesets", func() {
-		It("", func() {})
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			volumes, err := spectrumMMCLI.ListFilesets(filesystem)
+			Expect(err).To(HaveOccurred())
+			Expect(volumes).To(BeNil())
+		})
+
+		It("should succeed when execute command returns valid output", func() {
+			stringOutput := mmlsfilesetOutput("root", "Linked", "%2Fgpfs%2Ffake-filesystem") +
+				"mmlsfileset::0:1:::fake-filesystem:fake-fileset:1:8193:Unlinked:--:0:Mon Oct 19 10%3A00%3A00 2026:0:0:fileset for container volume:\n"
+			fakeExec.ExecuteReturns([]byte(stringOutput), nil)
+
+			volumes, err := spectrumMMCLI.ListFilesets(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(volumes).To(Equal([]resources.Volume{
+				{Name: "root", Mountpoint: "/gpfs/fake-filesystem"},
+				{Name: "fake-fileset", Mountpoint: "--"},
+			}))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{filesystem, "-Y"}))
+		})
 	})
 
 	Context(".ListFileset", func() {
@@ -331,12 +559,19 @@ var _ = Describe("spectrum_mmcli", func() {
 			Expect(volume).To(Equal(resources.Volume{}))
 		})
 
-		It("should succeed when execute command does not error", func() {
+		It("should fail when the fileset is not in the output", func() {
 			fakeExec.ExecuteReturns(nil, nil)
 
+			_, err := spectrumMMCLI.ListFileset(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should succeed when execute command returns valid output", func() {
+			fakeExec.ExecuteReturns([]byte(mmlsfilesetOutput(fileset, "Linked", "%2Fgpfs%2Ffake-filesystem%2Ffake-fileset")), nil)
+
 			volume, err := spectrumMMCLI.ListFileset(filesystem, fileset)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(volume.Name).To(Equal(fileset))
+			Expect(volume).To(Equal(resources.Volume{Name: fileset, Mountpoint: "/gpfs/fake-filesystem/fake-fileset"}))
 		})
 	})
 
@@ -352,7 +587,7 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should fail when execute command returns an invalid output", func() {
-			returnMsg := "a"
+			returnMsg := "mmlsquota::HEADER:version:reserved:reserved:quotaType:id:name:filesystemName:blockUsage:blockQuota:blockLimit:\n"
 			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
 
 			quota, err := spectrumMMCLI.ListFilesetQuota(filesystem, fileset)
@@ -372,12 +607,55 @@ var _ = Describe("spectrum_mmcli", func() {
 		})
 
 		It("should succeed when execute command returns a valid output", func() {
-			returnMsg := "a \n b \n1 2 3 fake-quota 5 6 fake-filesystem 8 fake-mountpoint 10 11 fake-host\n 12:21:32"
+			returnMsg := "mmlsquota::HEADER:version:reserved:reserved:quotaType:id:name:filesystemName:blockUsage:blockQuota:blockLimit:blockInDoubt:blockGrace:filesUsage:filesQuota:filesLimit:filesInDoubt:filesGrace:remarks:quota:defQuota:fid:filesetname:\n" +
+				"mmlsquota::0:1:::FILESET:1:fake-fileset:fake-filesystem:256:1048576:1048576:0:none:1:0:0:0:none:i:on:off:::\n"
 			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
 
 			quota, err := spectrumMMCLI.ListFilesetQuota(filesystem, fileset)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(quota).To(Equal("fake-quota"))
+			Expect(quota).To(Equal("1048576K"))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{"-j", fileset, filesystem, "-Y"}))
+		})
+	})
+
+	Context(".ListFilesetQuotaUsage", func() {
+		header := "mmlsquota::HEADER:version:reserved:reserved:quotaType:id:name:filesystemName:blockUsage:blockQuota:blockLimit:blockInDoubt:blockGrace:filesUsage:filesQuota:filesLimit:filesInDoubt:filesGrace:remarks:quota:defQuota:fid:filesetname:\n"
+
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			_, err := spectrumMMCLI.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Failed to list quota usage for fileset %s: error executing command", fileset)))
+		})
+
+		It("should fail when execute command returns a non numeric usage", func() {
+			returnMsg := header + "mmlsquota::0:1:::FILESET:1:fake-fileset:fake-filesystem:many:1048576:1048576:0:none:1:0:0:0:none:i:on:off:::\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			_, err := spectrumMMCLI.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Error parsing blockUsage [many] while listing quota usage for fileset %s", fileset)))
+		})
+
+		It("should return the usage and the hard limit in bytes", func() {
+			returnMsg := header + "mmlsquota::0:1:::FILESET:1:fake-fileset:fake-filesystem:256:1048576:2097152:0:none:3:0:1000:0:none:i:on:off:::\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			usage, err := spectrumMMCLI.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage).To(Equal(connectors.FilesetQuotaUsage{BlockUsage: 256 * 1024, BlockLimit: 2097152 * 1024, FilesUsage: 3, FilesLimit: 1000}))
+		})
+
+		It("should return the soft quota when no hard limit is set", func() {
+			returnMsg := header + "mmlsquota::0:1:::FILESET:1:fake-fileset:fake-filesystem:256:1048576:0:0:none:3:0:0:0:none:i:on:off:::\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			usage, err := spectrumMMCLI.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage.BlockLimit).To(Equal(int64(1048576 * 1024)))
+			Expect(usage.FilesLimit).To(Equal(int64(0)))
 		})
 	})
 
@@ -403,7 +681,7 @@ var _ = Describe("spectrum_mmcli", func() {
 			errorMsg := fmt.Sprintf("error executing command")
 			fakeExec.ExecuteReturns(nil, fmt.Errorf(errorMsg))
 
-			err = spectrumMMCLI.ExportNfs("fake-mount", "fake-config")
+			err = spectrumMMCLI.ExportNfs("fake-mount", []connectors.NfsClient{{Client: "fake-client"}})
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal(fmt.Sprintf("Failed to export fileset via Nfs: %s", errorMsg)))
 		})
@@ -411,8 +689,108 @@ var _ = Describe("spectrum_mmcli", func() {
 		It("should succeed when execute command does not error", func() {
 			fakeExec.ExecuteReturns(nil, nil)
 
-			err := spectrumMMCLI.ExportNfs("fake-mount", "fake-config")
+			err := spectrumMMCLI.ExportNfs("fake-mount", []connectors.NfsClient{{Client: "fake-client"}})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+	Context(".UpdateNfsExport", func() {
+		It("should add, change and remove the clients in this order", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+
+			err := spectrumMMCLI.UpdateNfsExport("fake-mount",
+				[]connectors.NfsClient{{Client: "host1", AccessType: "RW"}, {Client: "host2", AccessType: "RO"}},
+				[]connectors.NfsClient{{Client: "*", AccessType: "RO", Squash: "root_squash"}},
+				[]connectors.NfsClient{{Client: "host3"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(3))
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{"export", "change", "fake-mount", "--nfsadd", "host1(Access_Type=RW);host2(Access_Type=RO)"}))
+			_, args = fakeExec.ExecuteArgsForCall(1)
+			Expect(args).To(Equal([]string{"export", "change", "fake-mount", "--nfschange", "*(Access_Type=RO,Squash=root_squash)"}))
+			_, args = fakeExec.ExecuteArgsForCall(2)
+			Expect(args).To(Equal([]string{"export", "change", "fake-mount", "--nfsremove", "host3"}))
+		})
+
+		It("should stop at the first failed command", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			err := spectrumMMCLI.UpdateNfsExport("fake-mount", []connectors.NfsClient{{Client: "host1"}}, nil, []connectors.NfsClient{{Client: "host3"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to change Nfs export: error executing command"))
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(1))
+		})
+	})
+	Context(".ListNfsExportClients", func() {
+		header := "mmnfs:nfsexports:HEADER:version:reserved:reserved:Path:Delegations:Clients:Access_Type:Protocols:Transports:Squash:Anonymous_uid:Anonymous_gid:SecType:PrivilegedPort:DefaultDelegations:Manage_Gids:NFS_Commit:\n"
+
+		It("should fail when execute command errors", func() {
+			fakeExec.ExecuteReturns(nil, fmt.Errorf("error executing command"))
+
+			_, err := spectrumMMCLI.ListNfsExportClients("/gpfs/fs1/fset1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to list Nfs export /gpfs/fs1/fset1: error executing command"))
+		})
+
+		It("should fail when the export does not exist", func() {
+			fakeExec.ExecuteReturns([]byte(header), nil)
+
+			_, err := spectrumMMCLI.ListNfsExportClients("/gpfs/fs1/fset1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("The Nfs export /gpfs/fs1/fset1 does not exist"))
+		})
+
+		It("should return the clients of the export with their options", func() {
+			returnMsg := header +
+				"mmnfs:nfsexports:0:1:::%2Fgpfs%2Ffs1%2Ffset1:NONE:10.0.0.0%2F24:RW:3,4:TCP:ROOT_SQUASH:-2:-2:SYS:FALSE:NONE:FALSE:FALSE:\n" +
+				"mmnfs:nfsexports:0:1:::%2Fgpfs%2Ffs1%2Ffset1:NONE:host1:RO:4:TCP:NO_ROOT_SQUASH:-2:-2:KRB5,KRB5P:FALSE:NONE:FALSE:FALSE:\n"
+			fakeExec.ExecuteReturns([]byte(returnMsg), nil)
+
+			clients, err := spectrumMMCLI.ListNfsExportClients("/gpfs/fs1/fset1")
+			Expect(err).ToNot(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{"export", "list", "--nfsdefs", "/gpfs/fs1/fset1", "-Y"}))
+			Expect(clients).To(Equal([]connectors.NfsClient{
+				{Client: "10.0.0.0/24", AccessType: "RW", Squash: "root_squash", SecTypes: []string{"sys"}, Protocols: []string{"3", "4"}},
+				{Client: "host1", AccessType: "RO", Squash: "no_root_squash", SecTypes: []string{"krb5", "krb5p"}, Protocols: []string{"4"}}}))
+		})
+	})
+	Context(".ExportSmb", func() {
+		It("should fail when execute command errors", func() {
+			errorMsg := fmt.Sprintf("error executing command")
+			fakeExec.ExecuteReturns(nil, fmt.Errorf(errorMsg))
+
+			err = spectrumMMCLI.ExportSmb("fake-share", "fake-mount", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Failed to export fileset via Smb: %s", errorMsg)))
+		})
+
+		It("should add the share with its options sorted", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+
+			err := spectrumMMCLI.ExportSmb("fake-share", "fake-mount", map[string]string{"read only": "no", "browseable": "yes"})
+			Expect(err).ToNot(HaveOccurred())
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("/usr/lpp/mmfs/bin/mmsmb"))
+			Expect(args).To(Equal([]string{"export", "add", "fake-share", "fake-mount", "--option", "browseable=yes", "--option", "read only=no"}))
+		})
+	})
+	Context(".UnexportSmb", func() {
+		It("should fail when execute command errors", func() {
+			errorMsg := fmt.Sprintf("error executing command")
+			fakeExec.ExecuteReturns(nil, fmt.Errorf(errorMsg))
+
+			err = spectrumMMCLI.UnexportSmb("fake-share")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("Failed to unexport fileset via Smb: %s", errorMsg)))
+		})
+
+		It("should remove the share", func() {
+			fakeExec.ExecuteReturns(nil, nil)
+
+			err := spectrumMMCLI.UnexportSmb("fake-share")
 			Expect(err).ToNot(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(args).To(Equal([]string{"export", "remove", "fake-share", "--force"}))
 		})
 	})
 })
@@ -0,0 +1,89 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*
+The mm commands print their machine-readable output with -Y, e.g for mmlsfs gold -T -Y:
+
+	mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:
+	mmlsfs::0:1:::gold:defaultMountPoint:%2Fgpfs%2Fgold::
+
+Every line starts with the command name and the section name (empty if the command prints one section),
+then HEADER for the line of the field names of the section or the record index for the data lines.
+Colons and other special characters in the values are percent encoded.
+*/
+
+const mmHeader = "HEADER"
+
+// MMRecord is a data line of the -Y output of a mm command, keyed by the field names of its section header
+type MMRecord map[string]string
+
+// ParseMMOutput parses the -Y output of a mm command and return the records of every section
+func ParseMMOutput(output string) (map[string][]MMRecord, error) {
+	sections := make(map[string][]MMRecord)
+	headers := make(map[string][]string)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		tokens := strings.Split(line, ":")
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("Failed to parse mm command output line [%s]: not a -Y output", line)
+		}
+		section := tokens[1]
+		if tokens[2] == mmHeader {
+			headers[section] = tokens
+			if _, exists := sections[section]; !exists {
+				sections[section] = []MMRecord{}
+			}
+			continue
+		}
+		header, exists := headers[section]
+		if !exists {
+			return nil, fmt.Errorf("Failed to parse mm command output line [%s]: no header for section [%s]", line, section)
+		}
+		record := MMRecord{}
+		for i := 3; i < len(header) && i < len(tokens); i++ {
+			if header[i] == "" || header[i] == "reserved" {
+				continue
+			}
+			value, err := url.PathUnescape(tokens[i])
+			if err != nil {
+				value = tokens[i]
+			}
+			record[header[i]] = value
+		}
+		sections[section] = append(sections[section], record)
+	}
+	return sections, nil
+}
+
+// parseMMSection return the records of the section of a -Y output, "" for commands with one section
+func parseMMSection(output string, section string) ([]MMRecord, error) {
+	sections, err := ParseMMOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	return sections[section], nil
+}
@@ -0,0 +1,58 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors_test
+
+import (
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseMMOutput", func() {
+	It("should parse the records of every section", func() {
+		sections, err := connectors.ParseMMOutput(mmlsclusterOutput)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sections).To(HaveLen(2))
+		Expect(sections["clusterSummary"]).To(HaveLen(1))
+		Expect(sections["clusterSummary"][0]["clusterName"]).To(Equal("gpfs1.local"))
+		Expect(sections["clusterSummary"][0]["rshPath"]).To(Equal("/usr/bin/ssh"))
+		Expect(sections["clusterNode"]).To(Equal([]connectors.MMRecord{{
+			"version": "1", "nodeNumber": "1", "daemonNodeName": "node1", "ipAddress": "10.0.0.1", "adminNodeName": "node1",
+			"designation": "quorumManager", "otherNodeRoles": "", "adminLoginName": "", "otherNodeRolesAlias": "",
+		}}))
+	})
+	It("should decode the percent encoded values", func() {
+		sections, err := connectors.ParseMMOutput(mmlsfilesetOutput("fset1", "Linked", "%2Fgpfs%2Ffs%201%2Ffset1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sections[""][0]["path"]).To(Equal("/gpfs/fs 1/fset1"))
+		Expect(sections[""][0]["created"]).To(Equal("Mon Oct 19 10:00:00 2026"))
+	})
+	It("should return a section without records if there is only a header", func() {
+		sections, err := connectors.ParseMMOutput("mmlsmount::HEADER:version:reserved:reserved:localDevName:\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sections).To(HaveKey(""))
+		Expect(sections[""]).To(BeEmpty())
+	})
+	It("should fail on a human readable output", func() {
+		_, err := connectors.ParseMMOutput("GPFS cluster information\n========================\n")
+		Expect(err).To(HaveOccurred())
+	})
+	It("should fail on a record without a header", func() {
+		_, err := connectors.ParseMMOutput("mmlsfs::0:1:::gold:defaultMountPoint:%2Fgpfs%2Fgold::\n")
+		Expect(err).To(HaveOccurred())
+	})
+})
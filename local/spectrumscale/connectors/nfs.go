@@ -0,0 +1,204 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The export options of an NFS client, the client options of mmnfs and of the REST API
+const (
+	NfsAccessTypeOption string = "Access_Type"
+	NfsSquashOption     string = "Squash"
+	NfsSecTypeOption    string = "SecType"
+	NfsProtocolsOption  string = "Protocols"
+)
+
+var (
+	NfsAccessTypes = []string{"RW", "RO", "MDONLY", "MDONLY_RO", "NONE"}
+	NfsSquashModes = []string{"root_squash", "root_id_squash", "all_squash", "no_root_squash"}
+	NfsSecTypes    = []string{"sys", "krb5", "krb5i", "krb5p", "none"}
+	NfsProtocols   = []string{"3", "4"}
+)
+
+// NfsClient is a client of an NFS export with its export options, an empty option is left to the CES default
+type NfsClient struct {
+	Client     string   // host name, IP address, subnet or netgroup of the client, * for all the clients
+	AccessType string   // one of NfsAccessTypes
+	Squash     string   // one of NfsSquashModes
+	SecTypes   []string // of NfsSecTypes
+	Protocols  []string // of NfsProtocols
+}
+
+// String return the client in the syntax of mmnfs, e.g. 10.0.0.0/24(Access_Type=RW,Squash=root_squash,Protocols=3:4)
+func (c NfsClient) String() string {
+	options := []string{}
+	if c.AccessType != "" {
+		options = append(options, NfsAccessTypeOption+"="+c.AccessType)
+	}
+	if c.Squash != "" {
+		options = append(options, NfsSquashOption+"="+c.Squash)
+	}
+	if len(c.SecTypes) > 0 {
+		options = append(options, NfsSecTypeOption+"="+strings.Join(c.SecTypes, ":"))
+	}
+	if len(c.Protocols) > 0 {
+		options = append(options, NfsProtocolsOption+"="+strings.Join(c.Protocols, ":"))
+	}
+	if len(options) == 0 {
+		return c.Client
+	}
+	return fmt.Sprintf("%s(%s)", c.Client, strings.Join(options, ","))
+}
+
+// FormatNfsClients return the client configuration of an export in the syntax of mmnfs, the clients separated by ';'
+func FormatNfsClients(clients []NfsClient) string {
+	return strings.Join(nfsClientSpecs(clients), ";")
+}
+
+// ParseNfsClients parses and validates a client configuration in the syntax of mmnfs,
+// e.g. 10.0.0.0/24(Access_Type=RW,Squash=root_squash);*(Access_Type=RO)
+func ParseNfsClients(clientConfig string) ([]NfsClient, error) {
+	clients := []NfsClient{}
+	for _, spec := range strings.Split(clientConfig, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		client, err := parseNfsClient(spec)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, ValidateNfsClients(clients)
+}
+
+func parseNfsClient(spec string) (NfsClient, error) {
+	open := strings.Index(spec, "(")
+	if open < 0 {
+		return NfsClient{Client: spec}, nil
+	}
+	if !strings.HasSuffix(spec, ")") {
+		return NfsClient{}, fmt.Errorf("Invalid NFS client %s: the export options must be enclosed in parentheses", spec)
+	}
+	client := NfsClient{Client: strings.TrimSpace(spec[:open])}
+	for _, option := range strings.Split(spec[open+1:len(spec)-1], ",") {
+		keyValue := strings.SplitN(option, "=", 2)
+		if len(keyValue) != 2 {
+			return NfsClient{}, fmt.Errorf("Invalid NFS client %s: the export options are option=value pairs", spec)
+		}
+		value := strings.TrimSpace(keyValue[1])
+		switch key := strings.TrimSpace(keyValue[0]); strings.ToLower(key) {
+		case strings.ToLower(NfsAccessTypeOption):
+			client.AccessType = value
+		case strings.ToLower(NfsSquashOption):
+			client.Squash = value
+		case strings.ToLower(NfsSecTypeOption):
+			client.SecTypes = strings.Split(value, ":")
+		case strings.ToLower(NfsProtocolsOption):
+			client.Protocols = strings.Split(value, ":")
+		default:
+			return NfsClient{}, fmt.Errorf("Invalid NFS client %s: unsupported export option %s, supported options are %s", spec, key,
+				strings.Join([]string{NfsAccessTypeOption, NfsSquashOption, NfsSecTypeOption, NfsProtocolsOption}, ", "))
+		}
+	}
+	return client, nil
+}
+
+// ValidateNfsClients checks the clients of an export and normalizes the case of their options,
+// an export has at least one client and every client once
+func ValidateNfsClients(clients []NfsClient) error {
+	if len(clients) == 0 {
+		return fmt.Errorf("An NFS export must have at least one client")
+	}
+	seen := make(map[string]bool)
+	for i := range clients {
+		client := &clients[i]
+		if client.Client == "" || strings.ContainsAny(client.Client, " \t(),;=") {
+			return fmt.Errorf("Invalid NFS client '%s'", client.Client)
+		}
+		if seen[client.Client] {
+			return fmt.Errorf("NFS client %s is specified more than once", client.Client)
+		}
+		seen[client.Client] = true
+
+		var err error
+		if client.AccessType != "" {
+			if client.AccessType, err = nfsOptionValue(NfsAccessTypeOption, strings.ToUpper(client.AccessType), NfsAccessTypes); err != nil {
+				return err
+			}
+		}
+		if client.Squash != "" {
+			if client.Squash, err = nfsOptionValue(NfsSquashOption, strings.ToLower(client.Squash), NfsSquashModes); err != nil {
+				return err
+			}
+		}
+		for j := range client.SecTypes {
+			if client.SecTypes[j], err = nfsOptionValue(NfsSecTypeOption, strings.ToLower(client.SecTypes[j]), NfsSecTypes); err != nil {
+				return err
+			}
+		}
+		for j := range client.Protocols {
+			if client.Protocols[j], err = nfsOptionValue(NfsProtocolsOption, strings.TrimPrefix(strings.ToUpper(client.Protocols[j]), "NFS"), NfsProtocols); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func nfsOptionValue(option, value string, supported []string) (string, error) {
+	for _, supportedValue := range supported {
+		if value == supportedValue {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("Invalid NFS export option %s=%s, supported values are %s", option, value, strings.Join(supported, ", "))
+}
+
+// newListedNfsClient return a client of an existing export from the options listed by mmnfs or the REST API,
+// which separate the values of a list option by ','
+func newListedNfsClient(client, accessType, squash, secTypes, protocols string) NfsClient {
+	listedClient := NfsClient{Client: client, AccessType: accessType, Squash: squash}
+	if secTypes != "" {
+		listedClient.SecTypes = strings.Split(secTypes, ",")
+	}
+	if protocols != "" {
+		listedClient.Protocols = strings.Split(protocols, ",")
+	}
+	return listedClient
+}
+
+// nfsClientSpecs return the clients in the syntax of mmnfs, one spec per client
+func nfsClientSpecs(clients []NfsClient) []string {
+	specs := []string{}
+	for _, client := range clients {
+		specs = append(specs, client.String())
+	}
+	return specs
+}
+
+// nfsClientNames return the names of the clients
+func nfsClientNames(clients []NfsClient) []string {
+	names := []string{}
+	for _, client := range clients {
+		names = append(names, client.Client)
+	}
+	return names
+}
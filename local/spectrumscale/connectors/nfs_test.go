@@ -0,0 +1,64 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors_test
+
+import (
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NfsClients", func() {
+	It("should format the clients in the syntax of mmnfs", func() {
+		clients := []connectors.NfsClient{
+			{Client: "10.0.0.0/24", AccessType: "RW", Squash: "root_squash", SecTypes: []string{"sys", "krb5"}, Protocols: []string{"3", "4"}},
+			{Client: "host1"},
+		}
+		Expect(connectors.FormatNfsClients(clients)).To(Equal("10.0.0.0/24(Access_Type=RW,Squash=root_squash,SecType=sys:krb5,Protocols=3:4);host1"))
+	})
+	It("should parse the clients it formats", func() {
+		config := "10.0.0.0/24(Access_Type=RW,Squash=root_squash,SecType=sys:krb5,Protocols=3:4);*(Access_Type=RO)"
+		clients, err := connectors.ParseNfsClients(config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clients).To(HaveLen(2))
+		Expect(clients[1]).To(Equal(connectors.NfsClient{Client: "*", AccessType: "RO"}))
+		Expect(connectors.FormatNfsClients(clients)).To(Equal(config))
+	})
+	It("should normalize the case of the options", func() {
+		clients, err := connectors.ParseNfsClients("host1(access_type=ro, squash=NO_ROOT_SQUASH, sectype=KRB5P, protocols=nfs4)")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connectors.FormatNfsClients(clients)).To(Equal("host1(Access_Type=RO,Squash=no_root_squash,SecType=krb5p,Protocols=4)"))
+	})
+	It("should fail for unsupported options and values", func() {
+		_, err := connectors.ParseNfsClients("host1(Anonymous_uid=0)")
+		Expect(err).To(HaveOccurred())
+		_, err = connectors.ParseNfsClients("host1(Access_Type=WO)")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Invalid NFS export option Access_Type=WO, supported values are RW, RO, MDONLY, MDONLY_RO, NONE"))
+		_, err = connectors.ParseNfsClients("host1(Protocols=2)")
+		Expect(err).To(HaveOccurred())
+		_, err = connectors.ParseNfsClients("host1(Access_Type=RW")
+		Expect(err).To(HaveOccurred())
+	})
+	It("should fail for an export without clients or with a client twice", func() {
+		_, err := connectors.ParseNfsClients(" ; ")
+		Expect(err).To(HaveOccurred())
+		_, err = connectors.ParseNfsClients("host1(Access_Type=RW);host1(Access_Type=RO)")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("NFS client host1 is specified more than once"))
+	})
+})
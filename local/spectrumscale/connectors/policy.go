@@ -0,0 +1,91 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+Ubiquity places the data of a fileset in a storage pool with a placement rule of its own in the filesystem policy:
+
+	RULE 'ubiquity_fset1' SET POOL 'flash' FOR FILESET ('fset1')
+
+The rules of ubiquity are kept at the top of the policy, before the rules of the admin, so that they take precedence
+over the default placement rule. A filesystem without a policy gets a default rule for the system pool as well,
+since the files that no placement rule matches cannot be created once a policy is installed.
+*/
+
+const (
+	placementRulePrefix  = "ubiquity_"
+	defaultPlacementRule = "RULE '" + placementRulePrefix + "default' SET POOL 'system'"
+	noPolicyInstalled    = "No policy file was installed"
+)
+
+// SetFilesetPlacementRule return the policy with the placement rule of the fileset set to the pool
+func SetFilesetPlacementRule(policy string, filesetName string, poolName string) string {
+	lines := removePlacementRuleLines(policy, filesetName)
+	rule := fmt.Sprintf("RULE '%s%s' SET POOL '%s' FOR FILESET ('%s')", placementRulePrefix, filesetName, poolName, filesetName)
+	if len(lines) == 0 {
+		return rule + "\n" + defaultPlacementRule + "\n"
+	}
+	return rule + "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// RemoveFilesetPlacementRule return the policy without the placement rule of the fileset
+func RemoveFilesetPlacementRule(policy string, filesetName string) string {
+	lines := removePlacementRuleLines(policy, filesetName)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// HasFilesetPlacementRule return true if the policy has a placement rule for the fileset
+func HasFilesetPlacementRule(policy string, filesetName string) bool {
+	return len(removePlacementRuleLines(policy, filesetName)) != len(policyLines(policy))
+}
+
+func removePlacementRuleLines(policy string, filesetName string) []string {
+	ruleStart := fmt.Sprintf("RULE '%s%s'", placementRulePrefix, filesetName)
+	lines := []string{}
+	for _, line := range policyLines(policy) {
+		if !strings.HasPrefix(strings.TrimSpace(line), ruleStart) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func policyLines(policy string) []string {
+	if strings.HasPrefix(strings.TrimSpace(policy), noPolicyInstalled) {
+		return []string{}
+	}
+	lines := []string{}
+	for _, line := range strings.Split(policy, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, "\r"))
+		}
+	}
+	return lines
+}
+
+// parsePoolNames splits the pools of a filesystem, mmlsfs separates them with ';' and the GUI with ','
+func parsePoolNames(pools string) []string {
+	return strings.FieldsFunc(pools, func(r rune) bool { return r == ';' || r == ',' || r == ' ' })
+}
@@ -0,0 +1,54 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors_test
+
+import (
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlacementRules", func() {
+	adminPolicy := "RULE 'archive' SET POOL 'nlsas' FOR FILESET ('archive')\nRULE 'default' SET POOL 'system'\n"
+
+	It("should add the rule and a default rule to an empty policy", func() {
+		policy := connectors.SetFilesetPlacementRule("", "fset1", "flash")
+		Expect(policy).To(Equal("RULE 'ubiquity_fset1' SET POOL 'flash' FOR FILESET ('fset1')\nRULE 'ubiquity_default' SET POOL 'system'\n"))
+	})
+	It("should treat the mmlspolicy message of a filesystem without policy as an empty policy", func() {
+		policy := connectors.SetFilesetPlacementRule("No policy file was installed for file system 'gold'.\n", "fset1", "flash")
+		Expect(policy).To(Equal("RULE 'ubiquity_fset1' SET POOL 'flash' FOR FILESET ('fset1')\nRULE 'ubiquity_default' SET POOL 'system'\n"))
+	})
+	It("should add the rule before the rules of the admin", func() {
+		policy := connectors.SetFilesetPlacementRule(adminPolicy, "fset1", "flash")
+		Expect(policy).To(Equal("RULE 'ubiquity_fset1' SET POOL 'flash' FOR FILESET ('fset1')\n" + adminPolicy))
+	})
+	It("should replace the rule of the fileset only", func() {
+		policy := connectors.SetFilesetPlacementRule(adminPolicy, "fset10", "system")
+		policy = connectors.SetFilesetPlacementRule(policy, "fset1", "flash")
+		policy = connectors.SetFilesetPlacementRule(policy, "fset1", "nlsas")
+		Expect(policy).To(Equal("RULE 'ubiquity_fset1' SET POOL 'nlsas' FOR FILESET ('fset1')\n" +
+			"RULE 'ubiquity_fset10' SET POOL 'system' FOR FILESET ('fset10')\n" + adminPolicy))
+	})
+	It("should remove the rule of the fileset only", func() {
+		policy := connectors.SetFilesetPlacementRule(adminPolicy, "fset1", "flash")
+		Expect(connectors.HasFilesetPlacementRule(policy, "fset1")).To(BeTrue())
+		Expect(connectors.HasFilesetPlacementRule(policy, "fset10")).To(BeFalse())
+		Expect(connectors.RemoveFilesetPlacementRule(policy, "fset1")).To(Equal(adminPolicy))
+		Expect(connectors.RemoveFilesetPlacementRule(policy, "archive")).To(Equal(policy))
+	})
+})
@@ -92,6 +92,13 @@ type Job struct {
 	Status    string      `json:"status,omitempty"`
 }
 
+const (
+	JobStatusRunning   = "RUNNING"
+	JobStatusCompleted = "COMPLETED"
+	JobStatusFailed    = "FAILED"
+	JobStatusCancelled = "CANCELLED"
+)
+
 type Respresult struct {
 	Commands []string `json:"commands,omitempty"`
 	Progress []string `json:"progress,omitempty"`
@@ -193,6 +200,7 @@ type AFM struct {
 	AFMRPO                       int    `json:"afmRPO"`
 	AFMShowHomeSnapshots         bool   `json:"afmShowHomeSnapshots"`
 	AFMTarget                    string `json:"afmTarget"`
+	AFMState                     string `json:"afmState,omitempty"`
 }
 
 type FilesetConfig struct {
@@ -237,6 +245,18 @@ type nfsExportRequest struct {
 	ClientDetail []string `json:"nfsClients,omitempty"`
 }
 
+type nfsExportChangeRequest struct {
+	NfsAdd    []string `json:"nfsAdd,omitempty"`
+	NfsChange []string `json:"nfsChange,omitempty"`
+	NfsRemove []string `json:"nfsRemove,omitempty"`
+}
+
+type smbShareRequest struct {
+	ShareName  string            `json:"shareName,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	SmbOptions map[string]string `json:"smbOptions,omitempty"`
+}
+
 type GetQuotaResponse struct {
 	Links  map[string]string `json:"links,omitempty"`
 	Quotas []Quota           `json:"quotas,omitempty"`
@@ -470,6 +490,68 @@ type GetQuotaResponse_v2 struct {
 	Paging string     `json:"paging,omitempty"`
 }
 
+// FilesystemCapacity is the size and the free space of a filesystem in bytes
+type FilesystemCapacity struct {
+	TotalBytes int64
+	FreeBytes  int64
+}
+
+type GetStoragePoolsResponse_v2 struct {
+	StoragePools []StoragePool_v2 `json:"storagePools,omitempty"`
+	Status       Status           `json:"status,omitempty"`
+	Paging       Pages            `json:"paging,omitempty"`
+}
+
+type StoragePool_v2 struct {
+	FilesystemName  string `json:"filesystemName,omitempty"`
+	StoragePoolName string `json:"storagePoolName,omitempty"`
+	TotalDataSize   int64  `json:"totalDataSize,omitempty"`
+	FreeDataSize    int64  `json:"freeDataSize,omitempty"`
+}
+
+type GetNfsExportResponse_v2 struct {
+	Exports []NfsExport_v2 `json:"exports,omitempty"`
+	Status  Status         `json:"status,omitempty"`
+}
+
+type NfsExport_v2 struct {
+	FilesystemName string               `json:"filesystemName,omitempty"`
+	FilesetName    string               `json:"filesetName,omitempty"`
+	Path           string               `json:"path,omitempty"`
+	NfsClients     []NfsExportClient_v2 `json:"nfsClients,omitempty"`
+}
+
+// NfsExportClient_v2 is a client of an export, the values of the list options are separated by ','
+type NfsExportClient_v2 struct {
+	ClientName string `json:"clientName,omitempty"`
+	AccessType string `json:"access_type,omitempty"`
+	Squash     string `json:"squash,omitempty"`
+	SecType    string `json:"sectype,omitempty"`
+	Protocols  string `json:"protocols,omitempty"`
+}
+
+type GetPolicyResponse_v2 struct {
+	Policies []Policy_v2 `json:"policies,omitempty"`
+	Status   Status      `json:"status,omitempty"`
+}
+
+type Policy_v2 struct {
+	FilesystemName string `json:"filesystemName,omitempty"`
+	Policy         string `json:"policy,omitempty"`
+}
+
+type ChangePolicyRequest_v2 struct {
+	Policy string `json:"policy"`
+}
+
+// FilesetQuotaUsage is the usage of a fileset and its quota limits, the block values are in bytes. A 0 limit means no limit.
+type FilesetQuotaUsage struct {
+	BlockUsage int64
+	BlockLimit int64
+	FilesUsage int64
+	FilesLimit int64
+}
+
 type Quota_v2 struct {
 	QuotaID        int    `json:"quotaID,omitempty"`
 	FilesystemName string `json:"filesystemName,omitempty"`
@@ -479,6 +561,7 @@ type Quota_v2 struct {
 	ObjectId       int    `json:"objectId,omitempty"`
 	BlockUsage     int    `json:"blockUsage,omitempty"`
 	BlockQuota     int    `json:"blockQuota,omitempty"`
+	BlockLimit     int    `json:"blockLimit,omitempty"`
 	BlockInDoubt   int    `json:"blockInDoubt,omitempty"`
 	BlockGrace     string `json:"blockGrace,omitempty"`
 	FilesUsage     int    `json:"filesUsage,omitempty"`
@@ -538,3 +621,14 @@ type CreateFilesetRequest struct {
 	AfmRPO                       int    `json:"afmRPO,omitempty"`
 	AfmShowHomeSnapshots         string `json:"afmShowHomeSnapshots,omitempty"`
 }
+
+type GetInfoResponse_v2 struct {
+	Info   Info_v2 `json:"info,omitempty"`
+	Status Status  `json:"status,omitempty"`
+}
+
+type Info_v2 struct {
+	Name           string `json:"name,omitempty"`
+	RestApiVersion string `json:"restApiVersion,omitempty"`
+	ServerVersion  string `json:"serverVersion,omitempty"`
+}
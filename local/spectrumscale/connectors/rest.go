@@ -17,7 +17,6 @@
 package connectors
 
 import (
-	"crypto/tls"
 	"fmt"
 	"github.com/IBM/ubiquity/resources"
 	"github.com/IBM/ubiquity/utils"
@@ -25,8 +24,10 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 )
 
+// spectrum_rest is the connector of the deprecated REST v1 API, use spectrumRestV2 instead
 type spectrum_rest struct {
 	logger     *log.Logger
 	httpClient *http.Client
@@ -40,11 +41,11 @@ func NewSpectrumRest(logger *log.Logger, restConfig resources.RestConfig) (Spect
 	user := restConfig.User
 	password := restConfig.Password
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	client, err := newRestHttpClient(logger, restConfig)
+	if err != nil {
+		return nil, err
 	}
-
-	return &spectrum_rest{logger: logger, httpClient: &http.Client{Transport: tr}, endpoint: endpoint, user: user, password: password}, nil
+	return &spectrum_rest{logger: logger, httpClient: client, endpoint: endpoint, user: user, password: password}, nil
 }
 
 func NewSpectrumRestWithClient(logger *log.Logger, restConfig resources.RestConfig, client *http.Client) (SpectrumScaleConnector, error) {
@@ -52,12 +53,28 @@ func NewSpectrumRestWithClient(logger *log.Logger, restConfig resources.RestConf
 	return &spectrum_rest{logger: logger, httpClient: client, endpoint: endpoint}, nil
 }
 
-func (s *spectrum_rest) ExportNfs(volumeMountpoint string, clientConfig string) error {
-	return nil
+func (s *spectrum_rest) ExportNfs(volumeMountpoint string, clients []NfsClient) error {
+	return &UnsupportedOperationError{"REST " + RestApiVersionV1, "ExportNfs"}
+}
+
+func (s *spectrum_rest) UpdateNfsExport(volumeMountpoint string, addClients []NfsClient, changeClients []NfsClient, removeClients []NfsClient) error {
+	return &UnsupportedOperationError{"REST " + RestApiVersionV1, "UpdateNfsExport"}
+}
+
+func (s *spectrum_rest) ListNfsExportClients(volumeMountpoint string) ([]NfsClient, error) {
+	return nil, &UnsupportedOperationError{"REST " + RestApiVersionV1, "ListNfsExportClients"}
 }
 
 func (s *spectrum_rest) UnexportNfs(volumeMountpoint string) error {
-	return nil
+	return &UnsupportedOperationError{"REST " + RestApiVersionV1, "UnexportNfs"}
+}
+
+func (s *spectrum_rest) ExportSmb(shareName string, volumeMountpoint string, smbOptions map[string]string) error {
+	return &UnsupportedOperationError{"REST " + RestApiVersionV1, "ExportSmb"}
+}
+
+func (s *spectrum_rest) UnexportSmb(shareName string) error {
+	return &UnsupportedOperationError{"REST " + RestApiVersionV1, "UnexportSmb"}
 }
 
 func (s *spectrum_rest) GetClusterId() (string, error) {
@@ -99,8 +116,7 @@ func (s *spectrum_rest) IsFilesystemMounted(filesystemName string) (bool, error)
 }
 
 func (s *spectrum_rest) MountFileSystem(filesystemName string) error {
-	fmt.Printf("This method is not yet implemented")
-	return nil
+	return &UnsupportedOperationError{"REST " + RestApiVersionV1, "MountFileSystem"}
 }
 
 func (s *spectrum_rest) ListFilesystems() ([]string, error) {
@@ -136,6 +152,22 @@ func (s *spectrum_rest) GetFilesystemMountpoint(filesystemName string) (string,
 	return getFilesystemResponse.FileSystems[0].DefaultMountPoint, nil
 }
 
+func (s *spectrum_rest) GetFilesystemCapacity(filesystemName string) (FilesystemCapacity, error) {
+	return FilesystemCapacity{}, &UnsupportedOperationError{"REST " + RestApiVersionV1, "GetFilesystemCapacity"}
+}
+
+func (s *spectrum_rest) ListFilesystemPools(filesystemName string) ([]string, error) {
+	return nil, &UnsupportedOperationError{"REST " + RestApiVersionV1, "ListFilesystemPools"}
+}
+
+func (s *spectrum_rest) GetPolicy(filesystemName string) (string, error) {
+	return "", &UnsupportedOperationError{"REST " + RestApiVersionV1, "GetPolicy"}
+}
+
+func (s *spectrum_rest) ChangePolicy(filesystemName string, policy string) error {
+	return &UnsupportedOperationError{"REST " + RestApiVersionV1, "ChangePolicy"}
+}
+
 func (s *spectrum_rest) CreateFileset(filesystemName string, filesetName string, opts map[string]interface{}) error {
 	filesetConfig := FilesetConfig{}
 	filesetConfig.Comment = "fileset for container volume"
@@ -153,7 +185,17 @@ func (s *spectrum_rest) CreateFileset(filesystemName string, filesetName string,
 		}
 	}
 
-	fileset := Fileset{Config: filesetConfig}
+	if IsAfmFileset(opts) {
+		filesetConfig.INodeSpace = "new"
+	} else if inodeSpace, inodeSpaceSpecified := opts[UserSpecifiedInodeSpace]; inodeSpaceSpecified && filesetConfig.INodeSpace == "" {
+		filesetConfig.INodeSpace = inodeSpace.(string)
+	}
+	afm, err := restV1Afm(opts)
+	if err != nil {
+		return err
+	}
+
+	fileset := Fileset{Config: filesetConfig, AFM: afm}
 	createFilesetURL := utils.FormatURL(s.endpoint, "scalemgmt/v1/filesets")
 	createFilesetResponse := GenericResponse{}
 	response, err := s.doHTTP(createFilesetURL, "POST", createFilesetResponse, fileset)
@@ -187,15 +229,19 @@ func (s *spectrum_rest) DeleteFileset(filesystemName string, filesetName string)
 }
 
 func (s *spectrum_rest) LinkFileset(filesystemName string, filesetName string) error {
-	filesetConfig := FilesetConfig{}
-	filesetConfig.Comment = "fileset for container volume"
-	filesetConfig.FilesetName = filesetName
-	filesetConfig.FilesystemName = filesystemName
 	fsMountpoint, err := s.GetFilesystemMountpoint(filesystemName)
 	if err != nil {
 		s.logger.Printf("error in linking fileset")
 	}
-	filesetConfig.Path = path.Join(fsMountpoint, filesetName)
+	return s.LinkFilesetAt(filesystemName, filesetName, path.Join(fsMountpoint, filesetName))
+}
+
+func (s *spectrum_rest) LinkFilesetAt(filesystemName string, filesetName string, junctionPath string) error {
+	filesetConfig := FilesetConfig{}
+	filesetConfig.Comment = "fileset for container volume"
+	filesetConfig.FilesetName = filesetName
+	filesetConfig.FilesystemName = filesystemName
+	filesetConfig.Path = junctionPath
 	fileset := Fileset{Config: filesetConfig}
 	linkFilesetURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v1/filesets/%s", filesetName))
 	linkFilesetResponse := GenericResponse{}
@@ -271,6 +317,46 @@ func (s *spectrum_rest) ListFileset(filesystemName string, filesetName string) (
 	return resources.Volume{Name: name, Mountpoint: mountpoint}, nil
 }
 
+func (s *spectrum_rest) GetFilesetAfmState(filesystemName string, filesetName string) (string, error) {
+	getFilesetURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v1/filesets/%s?filesystemname=%s", filesetName, filesystemName))
+	getFilesetResponse := GetFilesetResponse{}
+	gfsResponse, err := s.doHTTP(getFilesetURL, "GET", getFilesetResponse, nil)
+	if err != nil {
+		s.logger.Printf("error in processing remote call %v", err)
+		return "", err
+	}
+
+	getFilesetResponse = gfsResponse.(GetFilesetResponse)
+	if len(getFilesetResponse.Filesets) == 0 || getFilesetResponse.Filesets[0].AFM.AFMTarget == "" {
+		return "", fmt.Errorf("Fileset %v is not an AFM fileset", filesetName)
+	}
+	return getFilesetResponse.Filesets[0].State.AFMState, nil
+}
+
+// restV1Afm return the AFM attributes of the opts, the v1 API takes the intervals as numbers
+func restV1Afm(opts map[string]interface{}) (AFM, error) {
+	attributes := afmFilesetAttributes(opts)
+	afm := AFM{AFMTarget: attributes["afmTarget"], AFMMode: attributes["afmMode"]}
+	intervals := map[string]*int{
+		"afmAsyncDelay":                &afm.AFMAsyncDelay,
+		"afmExpirationTimeout":         &afm.AFMExpirationTimeout,
+		"afmFileLookupRefreshInterval": &afm.AFMFileLookupRefreshInterval,
+		"afmDirLookupRefreshInterval":  &afm.AFMDirLookupRefreshInterval,
+	}
+	for attribute, interval := range intervals {
+		value, specified := attributes[attribute]
+		if !specified {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			return AFM{}, fmt.Errorf("Invalid value '%s' for %s", value, attribute)
+		}
+		*interval = seconds
+	}
+	return afm, nil
+}
+
 func (s *spectrum_rest) IsFilesetLinked(filesystemName string, filesetName string) (bool, error) {
 	fileset, err := s.ListFileset(filesystemName, filesetName)
 	if err != nil {
@@ -299,6 +385,10 @@ func (s *spectrum_rest) ListFilesetQuota(filesystemName string, filesetName stri
 	return listQuotaResponse.Quotas[0].BlockQuota, nil
 }
 
+func (s *spectrum_rest) ListFilesetQuotaUsage(filesystemName string, filesetName string) (FilesetQuotaUsage, error) {
+	return FilesetQuotaUsage{}, &UnsupportedOperationError{"REST " + RestApiVersionV1, "ListFilesetQuotaUsage"}
+}
+
 func (s *spectrum_rest) SetFilesetQuota(filesystemName string, filesetName string, quota string) error {
 	setQuotaURL := utils.FormatURL(s.endpoint, "scalemgmt/v1/quotas")
 	quotaRequest := SetQuotaRequest{}
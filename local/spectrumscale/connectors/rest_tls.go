@@ -0,0 +1,102 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/IBM/ubiquity/resources"
+)
+
+// newRestHttpClient return the http client of the REST connectors, with the TLS settings of the SSL mode of restConfig
+func newRestHttpClient(logger *log.Logger, restConfig resources.RestConfig) (*http.Client, error) {
+	tlsConfig, err := newRestTlsConfig(logger, restConfig)
+	if err != nil {
+		logger.Printf("Failed to initialize the TLS settings of the Spectrum Scale REST connector: %v\n", err)
+		return nil, err
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}, nil
+}
+
+func newRestTlsConfig(logger *log.Logger, restConfig resources.RestConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	sslMode := strings.ToLower(restConfig.SslMode)
+	if sslMode == "" {
+		sslMode = resources.DefaultSpectrumScaleRestSslMode
+	}
+	switch sslMode {
+	case resources.SslModeVerifyFull:
+		if restConfig.CACertFile == "" {
+			return nil, &restSslModeFullVerifyWithoutCAfileError{}
+		}
+		caCert, err := ioutil.ReadFile(restConfig.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		if ok := caCertPool.AppendCertsFromPEM(caCert); !ok {
+			return nil, fmt.Errorf("parse %v failed", restConfig.CACertFile)
+		}
+		tlsConfig.RootCAs = caCertPool
+		logger.Printf("Spectrum Scale REST SSL mode set to [%s], verifying the GUI certificate with [%s]\n", sslMode, restConfig.CACertFile)
+	case resources.SslModeRequire:
+		logger.Printf("Spectrum Scale REST SSL mode set to [%s]. Attention: the communication to the GUI is InsecureSkipVerify\n", sslMode)
+		tlsConfig.InsecureSkipVerify = true
+	default:
+		return nil, &restSslModeValueInvalidError{restConfig.SslMode}
+	}
+
+	if restConfig.ClientCertFile != "" || restConfig.ClientKeyFile != "" {
+		if restConfig.ClientCertFile == "" || restConfig.ClientKeyFile == "" {
+			return nil, fmt.Errorf("Both the client certificate and the client key file must be set for the Spectrum Scale REST connector")
+		}
+		clientCert, err := tls.LoadX509KeyPair(restConfig.ClientCertFile, restConfig.ClientKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	if restConfig.CertFingerprint != "" {
+		pinned, err := hex.DecodeString(strings.Replace(restConfig.CertFingerprint, ":", "", -1))
+		if err != nil || len(pinned) != sha256.Size {
+			return nil, fmt.Errorf("Invalid certificate fingerprint [%s], expected the hex encoded SHA-256 of the GUI certificate", restConfig.CertFingerprint)
+		}
+		// called also with InsecureSkipVerify, so the pinning applies in both SSL modes
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return &restCertFingerprintMismatchError{""}
+			}
+			fingerprint := sha256.Sum256(rawCerts[0])
+			if !bytes.Equal(fingerprint[:], pinned) {
+				return &restCertFingerprintMismatchError{hex.EncodeToString(fingerprint[:])}
+			}
+			return nil
+		}
+	}
+	return tlsConfig, nil
+}
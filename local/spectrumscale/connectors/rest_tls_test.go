@@ -0,0 +1,210 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/resources"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// writeSelfSignedCert writes a new self signed certificate and its key as PEM files to dir
+func writeSelfSignedCert(dir string, name string) (certFile string, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+".key")
+	Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).To(Succeed())
+	Expect(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)).To(Succeed())
+	return certFile, keyFile
+}
+
+var _ = Describe("REST connector TLS", func() {
+	var (
+		logger     *log.Logger
+		server     *httptest.Server
+		certDir    string
+		serverCA   string
+		restConfig resources.RestConfig
+		err        error
+	)
+
+	BeforeEach(func() {
+		logger = log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{}"))
+		}))
+		certDir, err = ioutil.TempDir("", "rest-tls")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		server.StartTLS()
+		serverCA = filepath.Join(certDir, "server-ca.pem")
+		err = ioutil.WriteFile(serverCA, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]}), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		restConfig = resources.RestConfig{Endpoint: server.URL, User: "admin", Password: "admin001"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(certDir)
+	})
+
+	Context("SSL mode", func() {
+		It("should verify the GUI certificate with the CA file in verify-full mode", func() {
+			restConfig.SslMode = resources.SslModeVerifyFull
+			restConfig.CACertFile = serverCA
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should default to verify-full", func() {
+			restConfig.CACertFile = serverCA
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail if the GUI certificate is not signed by the CA in verify-full mode", func() {
+			restConfig.CACertFile, _ = writeSelfSignedCert(certDir, "other-ca")
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("certificate"))
+		})
+		It("should fail in verify-full mode without a CA file", func() {
+			restConfig.ApiVersion = connectors.RestApiVersionV2
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("requires a CA file"))
+		})
+		It("should fail if the CA file is not a PEM certificate", func() {
+			restConfig.CACertFile = filepath.Join(certDir, "invalid.pem")
+			Expect(ioutil.WriteFile(restConfig.CACertFile, []byte("invalid"), 0600)).To(Succeed())
+			restConfig.ApiVersion = connectors.RestApiVersionV2
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should skip the verification in require mode", func() {
+			restConfig.SslMode = resources.SslModeRequire
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail on an illegal SSL mode", func() {
+			restConfig.SslMode = "disable"
+			restConfig.ApiVersion = connectors.RestApiVersionV1
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("Illegal SSL mode value \\[disable\\]"))
+		})
+	})
+
+	Context("certificate pinning", func() {
+		var fingerprint string
+
+		JustBeforeEach(func() {
+			sum := sha256.Sum256(server.TLS.Certificates[0].Certificate[0])
+			fingerprint = hex.EncodeToString(sum[:])
+			restConfig.SslMode = resources.SslModeRequire
+		})
+
+		It("should accept the GUI certificate with the pinned fingerprint", func() {
+			restConfig.CertFingerprint = fingerprint
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should reject a GUI certificate with another fingerprint", func() {
+			restConfig.CertFingerprint = "00" + fingerprint[2:]
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("does not match the pinned certificate fingerprint"))
+		})
+		It("should fail on an invalid fingerprint", func() {
+			restConfig.CertFingerprint = "AB:CD"
+			restConfig.ApiVersion = connectors.RestApiVersionV2
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("Invalid certificate fingerprint"))
+		})
+	})
+
+	Context("client certificate", func() {
+		var clientCert, clientKey string
+
+		BeforeEach(func() {
+			clientCert, clientKey = writeSelfSignedCert(certDir, "client")
+			clientCA, err := ioutil.ReadFile(clientCert)
+			Expect(err).ToNot(HaveOccurred())
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(clientCA)
+			server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+		})
+
+		JustBeforeEach(func() {
+			restConfig.SslMode = resources.SslModeRequire
+		})
+
+		It("should authenticate with the client certificate", func() {
+			restConfig.ClientCertFile = clientCert
+			restConfig.ClientKeyFile = clientKey
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail if the GUI requires a client certificate and none is configured", func() {
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail if the client key file is not set", func() {
+			restConfig.ClientCertFile = clientCert
+			restConfig.ApiVersion = connectors.RestApiVersionV2
+			_, err = connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -17,26 +17,34 @@
 package connectors
 
 import (
-	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/IBM/ubiquity/resources"
 	"github.com/IBM/ubiquity/utils"
 )
 
+const (
+	DefaultRestJobTimeout      = 600 // seconds
+	DefaultRestJobPollInterval = 500 // milliseconds
+	maxJobPollInterval         = 30 * time.Second
+)
+
 type spectrumRestV2 struct {
-	logger     *log.Logger
-	httpClient *http.Client
-	endpoint   string
-	user       string
-	password   string
-	hostname   string
+	logger          *log.Logger
+	httpClient      *http.Client
+	endpoint        string
+	user            string
+	password        string
+	hostname        string
+	jobTimeout      time.Duration
+	jobPollInterval time.Duration
 }
 
 func (s *spectrumRestV2) isStatusOK(statusCode int) bool {
@@ -76,76 +84,118 @@ func (s *spectrumRestV2) isRequestAccepted(response GenericResponse, url string)
 	return nil
 }
 
-func (s *spectrumRestV2) waitForJobCompletion(statusCode int, jobID uint64) error {
+// waitForJobCompletion waits for the job of an accepted request, the typed job errors are returned as is
+// with the operation, other errors are wrapped
+func (s *spectrumRestV2) waitForJobCompletion(statusCode int, jobID uint64, operation string) error {
 	s.logger.Println("spectrumRestConnector: waitForJobCompletion")
 	defer s.logger.Println("spectrumRestConnector: waitForJobCompletion end")
 
 	if s.checkAsynchronousJob(statusCode) {
-		jobURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/jobs?filter=jobId=%d&fields=:all:", jobID))
-		s.logger.Println("Job URL: ", jobURL)
-		err := s.AsyncJobCompletion(jobURL)
+		err := s.AsyncJobCompletion(jobID, operation)
 		if err != nil {
 			s.logger.Printf("%v\n", err)
-			return err
+			switch err.(type) {
+			case *JobTimeoutError, *JobFailedError, *JobCancelledError, *JobStatusUnexpectedError:
+				return err
+			}
+			return fmt.Errorf("Unable to %s:%v. Please refer Ubiquity server logs for more details", operation, err)
 		}
 	}
 	return nil
 }
 
-func (s *spectrumRestV2) AsyncJobCompletion(jobURL string) error {
+// AsyncJobCompletion polls the job with exponential backoff until it is no longer RUNNING.
+// The job is cancelled if it is still RUNNING when the job timeout exceeds.
+func (s *spectrumRestV2) AsyncJobCompletion(jobID uint64, operation string) error {
 	s.logger.Println("spectrumRestConnector: AsyncJobCompletion")
 	defer s.logger.Println("spectrumRestConnector: AsyncJobCompletion end")
 
-	jobQueryResponse := GenericResponse{}
+	jobURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/jobs?filter=jobId=%d&fields=:all:", jobID))
+	s.logger.Println("Job URL: ", jobURL)
+	deadline := time.Now().Add(s.jobTimeout)
+	interval := s.jobPollInterval
 	for {
-		s.logger.Printf("jobUrl  %v", jobURL)
+		jobQueryResponse := GenericResponse{}
 		err := s.doHTTP(jobURL, "GET", &jobQueryResponse, nil)
 		if err != nil {
 			return err
 		}
 		if len(jobQueryResponse.Jobs) == 0 {
-			return fmt.Errorf("Unable to get Job %v details", jobURL)
+			return fmt.Errorf("Unable to get Job [%d] details", jobID)
 		}
 
-		if jobQueryResponse.Jobs[0].Status == "RUNNING" {
-			time.Sleep(2000 * time.Millisecond)
-			continue
+		job := jobQueryResponse.Jobs[0]
+		switch job.Status {
+		case JobStatusRunning:
+			s.logger.Printf("Job [%d] is running, progress %v\n", jobID, job.Result.Progress)
+			remaining := deadline.Sub(time.Now())
+			if remaining <= 0 {
+				s.cancelJob(jobID)
+				return &JobTimeoutError{JobID: jobID, Operation: operation, Timeout: s.jobTimeout, Progress: job.Result.Progress}
+			}
+			if interval > remaining {
+				interval = remaining
+			}
+			time.Sleep(interval)
+			interval *= 2
+			if interval > maxJobPollInterval {
+				interval = maxJobPollInterval
+			}
+		case JobStatusCompleted:
+			s.logger.Printf("Job [%d] Completed Successfully: %v\n", jobID, job.Result)
+			return nil
+		case JobStatusFailed:
+			return &JobFailedError{JobID: jobID, Operation: operation, Stderr: job.Result.Stderr, Progress: job.Result.Progress}
+		case JobStatusCancelled:
+			return &JobCancelledError{JobID: jobID, Operation: operation, Progress: job.Result.Progress}
+		default:
+			return &JobStatusUnexpectedError{JobID: jobID, Operation: operation, Status: job.Status}
 		}
-		break
 	}
-	if jobQueryResponse.Jobs[0].Status == "COMPLETED" {
-		s.logger.Printf("Job %v Completed Successfully: %v\n", jobURL, jobQueryResponse.Jobs[0].Result)
-		return nil
-	} else {
-		return fmt.Errorf("%v", jobQueryResponse.Jobs[0].Result.Stderr)
+}
+
+// cancelJob cancels a running job, a failure is only logged since the job may have completed meanwhile
+func (s *spectrumRestV2) cancelJob(jobID uint64) {
+	s.logger.Println("spectrumRestConnector: cancelJob")
+	defer s.logger.Println("spectrumRestConnector: cancelJob end")
+
+	cancelJobURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/jobs/%d/cancel", jobID))
+	cancelJobResponse := GenericResponse{}
+	err := s.doHTTP(cancelJobURL, "PUT", &cancelJobResponse, nil)
+	if err != nil {
+		s.logger.Printf("Failed to cancel job [%d]: %v\n", jobID, err)
+		return
 	}
+	s.logger.Printf("Job [%d] cancelled\n", jobID)
 }
 
 func NewSpectrumRestV2(logger *log.Logger, restConfig resources.RestConfig) (SpectrumScaleConnector, error) {
+	client, err := newRestHttpClient(logger, restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return newSpectrumRestV2(logger, restConfig, client), nil
+}
 
-	endpoint := restConfig.Endpoint
-	user := restConfig.User
-	password := restConfig.Password
-	hostname := restConfig.Hostname
-
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+func newSpectrumRestV2(logger *log.Logger, restConfig resources.RestConfig, client *http.Client) *spectrumRestV2 {
+	jobTimeout := restConfig.JobTimeout
+	if jobTimeout <= 0 {
+		jobTimeout = DefaultRestJobTimeout
 	}
-	return &spectrumRestV2{logger: logger, httpClient: &http.Client{Transport: tr}, endpoint: endpoint, user: user, password: password, hostname: hostname}, nil
+	jobPollInterval := restConfig.JobPollInterval
+	if jobPollInterval <= 0 {
+		jobPollInterval = DefaultRestJobPollInterval
+	}
+	return &spectrumRestV2{logger: logger, httpClient: client, endpoint: restConfig.Endpoint, user: restConfig.User, password: restConfig.Password,
+		hostname: restConfig.Hostname, jobTimeout: time.Duration(jobTimeout) * time.Second, jobPollInterval: time.Duration(jobPollInterval) * time.Millisecond}
 }
 
 func NewspectrumRestV2WithClient(logger *log.Logger, restConfig resources.RestConfig) (SpectrumScaleConnector, *http.Client, error) {
-	endpoint := restConfig.Endpoint
-	user := restConfig.User
-	password := restConfig.Password
-	hostname := restConfig.Hostname
-
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	client, err := newRestHttpClient(logger, restConfig)
+	if err != nil {
+		return nil, nil, err
 	}
-	client := &http.Client{Transport: tr}
-	return &spectrumRestV2{logger: logger, httpClient: client, endpoint: endpoint, user: user, password: password, hostname: hostname}, client, nil
-
+	return newSpectrumRestV2(logger, restConfig, client), client, nil
 }
 
 func (s *spectrumRestV2) GetClusterId() (string, error) {
@@ -255,6 +305,109 @@ func (s *spectrumRestV2) GetFilesystemMountpoint(filesystemName string) (string,
 	}
 }
 
+// GetFilesystemCapacity return the sum of the data size and the free data space of the storage pools of the filesystem
+func (s *spectrumRestV2) GetFilesystemCapacity(filesystemName string) (FilesystemCapacity, error) {
+
+	s.logger.Println("spectrumRestConnector: GetFilesystemCapacity")
+	defer s.logger.Println("spectrumRestConnector: GetFilesystemCapacity end")
+
+	getPoolsURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s/pools?fields=:all:", filesystemName))
+	getPoolsResponse := GetStoragePoolsResponse_v2{}
+
+	s.logger.Println("Get Storage Pools URL: ", getPoolsURL)
+
+	err := s.doHTTP(getPoolsURL, "GET", &getPoolsResponse, nil)
+	if err != nil {
+		s.logger.Printf("error in executing remote call: %v", err)
+		return FilesystemCapacity{}, fmt.Errorf("Unable to fetch the capacity of %v. Please refer Ubiquity server logs for more details", filesystemName)
+	}
+	if len(getPoolsResponse.StoragePools) == 0 {
+		return FilesystemCapacity{}, fmt.Errorf("Unable to fetch the capacity of %v. Please refer Ubiquity server logs for more details", filesystemName)
+	}
+
+	// the sizes are in KB
+	capacity := FilesystemCapacity{}
+	for _, pool := range getPoolsResponse.StoragePools {
+		capacity.TotalBytes += pool.TotalDataSize * 1024
+		capacity.FreeBytes += pool.FreeDataSize * 1024
+	}
+	return capacity, nil
+}
+
+// ListFilesystemPools return the storage pools of the filesystem
+func (s *spectrumRestV2) ListFilesystemPools(filesystemName string) ([]string, error) {
+
+	s.logger.Println("spectrumRestConnector: ListFilesystemPools")
+	defer s.logger.Println("spectrumRestConnector: ListFilesystemPools end")
+
+	getFilesystemURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s?fields=:all:", filesystemName))
+	getFilesystemResponse := GetFilesystemResponse_v2{}
+
+	s.logger.Println("Get Filesystem URL: ", getFilesystemURL)
+
+	err := s.doHTTP(getFilesystemURL, "GET", &getFilesystemResponse, nil)
+	if err != nil {
+		s.logger.Printf("error in executing remote call: %v", err)
+		return nil, fmt.Errorf("Unable to fetch the pools of %v. Please refer Ubiquity server logs for more details", filesystemName)
+	}
+	if len(getFilesystemResponse.FileSystems) == 0 {
+		return nil, fmt.Errorf("Unable to fetch the pools of %v. Please refer Ubiquity server logs for more details", filesystemName)
+	}
+	return parsePoolNames(getFilesystemResponse.FileSystems[0].Block.Pools), nil
+}
+
+// GetPolicy return the rules of the policy installed in the filesystem
+func (s *spectrumRestV2) GetPolicy(filesystemName string) (string, error) {
+
+	s.logger.Println("spectrumRestConnector: GetPolicy")
+	defer s.logger.Println("spectrumRestConnector: GetPolicy end")
+
+	getPolicyURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s/policies", filesystemName))
+	getPolicyResponse := GetPolicyResponse_v2{}
+
+	s.logger.Println("Get Policy URL: ", getPolicyURL)
+
+	err := s.doHTTP(getPolicyURL, "GET", &getPolicyResponse, nil)
+	if err != nil {
+		s.logger.Printf("error in executing remote call: %v", err)
+		return "", fmt.Errorf("Unable to fetch the policy of %v. Please refer Ubiquity server logs for more details", filesystemName)
+	}
+	if len(getPolicyResponse.Policies) == 0 {
+		return "", nil
+	}
+	policy := getPolicyResponse.Policies[0].Policy
+	if strings.HasPrefix(strings.TrimSpace(policy), noPolicyInstalled) {
+		return "", nil
+	}
+	return policy, nil
+}
+
+// ChangePolicy installs the policy in the filesystem
+func (s *spectrumRestV2) ChangePolicy(filesystemName string, policy string) error {
+
+	s.logger.Println("spectrumRestConnector: ChangePolicy")
+	defer s.logger.Println("spectrumRestConnector: ChangePolicy end")
+
+	changePolicyURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s/policies", filesystemName))
+	changePolicyRequest := ChangePolicyRequest_v2{Policy: policy}
+	changePolicyResponse := GenericResponse{}
+
+	s.logger.Println("Change Policy URL: ", changePolicyURL)
+
+	err := s.doHTTP(changePolicyURL, "PUT", &changePolicyResponse, changePolicyRequest)
+	if err != nil {
+		s.logger.Printf("error changing the policy %v", err)
+		return fmt.Errorf("Unable to change the policy of %v. Please refer Ubiquity server logs for more details", filesystemName)
+	}
+
+	err = s.isRequestAccepted(changePolicyResponse, changePolicyURL)
+	if err != nil {
+		return err
+	}
+
+	return s.waitForJobCompletion(changePolicyResponse.Status.Code, changePolicyResponse.Jobs[0].JobID, fmt.Sprintf("change policy of filesystem %v", filesystemName))
+}
+
 func (s *spectrumRestV2) CreateFileset(filesystemName string, filesetName string, opts map[string]interface{}) error {
 
 	s.logger.Println("spectrumRestConnector: CreateFileset")
@@ -266,15 +419,24 @@ func (s *spectrumRestV2) CreateFileset(filesystemName string, filesetName string
 
 	filesetType, filesetTypeSpecified := opts[UserSpecifiedFilesetType]
 	inodeLimit, inodeLimitSpecified := opts[UserSpecifiedInodeLimit]
-	if filesetTypeSpecified && filesetType.(string) == "independent" {
+	if (filesetTypeSpecified && filesetType.(string) == "independent") || IsAfmFileset(opts) {
 		filesetreq.InodeSpace = "new"
 		if inodeLimitSpecified {
 			filesetreq.MaxNumInodes = inodeLimit.(string)
 			filesetreq.AllocInodes = inodeLimit.(string)
 		}
+	} else if inodeSpace, inodeSpaceSpecified := opts[UserSpecifiedInodeSpace]; inodeSpaceSpecified {
+		filesetreq.InodeSpace = inodeSpace.(string)
 	} else {
 		filesetreq.InodeSpace = "root"
 	}
+	afm := afmFilesetAttributes(opts)
+	filesetreq.AfmTarget = afm["afmTarget"]
+	filesetreq.AfmMode = afm["afmMode"]
+	filesetreq.AfmAsyncDelay = afm["afmAsyncDelay"]
+	filesetreq.AfmExpirationTimeout = afm["afmExpirationTimeout"]
+	filesetreq.AfmFileLookupRefreshInterval = afm["afmFileLookupRefreshInterval"]
+	filesetreq.AfmDirLookupRefreshInterval = afm["afmDirLookupRefreshInterval"]
 
 	s.logger.Printf("filesetreq %v\n", filesetreq)
 	createFilesetURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s/filesets", filesystemName))
@@ -293,9 +455,9 @@ func (s *spectrumRestV2) CreateFileset(filesystemName string, filesetName string
 		return err
 	}
 
-	err = s.waitForJobCompletion(createFilesetResponse.Status.Code, createFilesetResponse.Jobs[0].JobID)
+	err = s.waitForJobCompletion(createFilesetResponse.Status.Code, createFilesetResponse.Jobs[0].JobID, fmt.Sprintf("create fileset %v", filesetName))
 	if err != nil {
-		return fmt.Errorf("Unable to create fileset %v:%v Please refer Ubiquity server logs for more details", filesetName, err)
+		return err
 	}
 	return nil
 }
@@ -321,9 +483,9 @@ func (s *spectrumRestV2) DeleteFileset(filesystemName string, filesetName string
 		return err
 	}
 
-	err = s.waitForJobCompletion(deleteFilesetResponse.Status.Code, deleteFilesetResponse.Jobs[0].JobID)
+	err = s.waitForJobCompletion(deleteFilesetResponse.Status.Code, deleteFilesetResponse.Jobs[0].JobID, fmt.Sprintf("delete fileset %v", filesetName))
 	if err != nil {
-		return fmt.Errorf("Unable to delete fileset %v:%v. Please refer Ubiquity server logs for more details", filesetName, err)
+		return err
 	}
 
 	return nil
@@ -334,20 +496,26 @@ func (s *spectrumRestV2) LinkFileset(filesystemName string, filesetName string)
 	s.logger.Println("spectrumRestConnector: LinkFileset")
 	defer s.logger.Println("spectrumRestConnector: LinkFileset end")
 
-	linkReq := LinkFilesetRequest{}
 	fsMountpoint, err := s.GetFilesystemMountpoint(filesystemName)
 	if err != nil {
 		s.logger.Printf("error in linking fileset")
 		return err
 	}
+	return s.LinkFilesetAt(filesystemName, filesetName, path.Join(fsMountpoint, filesetName))
+}
 
-	linkReq.Path = path.Join(fsMountpoint, filesetName)
+func (s *spectrumRestV2) LinkFilesetAt(filesystemName string, filesetName string, junctionPath string) error {
+
+	s.logger.Println("spectrumRestConnector: LinkFilesetAt")
+	defer s.logger.Println("spectrumRestConnector: LinkFilesetAt end")
+
+	linkReq := LinkFilesetRequest{Path: junctionPath}
 	linkFilesetURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s/filesets/%s/link", filesystemName, filesetName))
 	linkFilesetResponse := GenericResponse{}
 
 	s.logger.Println("Link Fileset URL: ", linkFilesetURL)
 
-	err = s.doHTTP(linkFilesetURL, "POST", &linkFilesetResponse, linkReq)
+	err := s.doHTTP(linkFilesetURL, "POST", &linkFilesetResponse, linkReq)
 	if err != nil {
 		s.logger.Printf("error in remote call %v", err)
 		return fmt.Errorf("Unable to link fileset %v. Please refer Ubiquity server logs for more details", filesetName)
@@ -358,9 +526,9 @@ func (s *spectrumRestV2) LinkFileset(filesystemName string, filesetName string)
 		return err
 	}
 
-	err = s.waitForJobCompletion(linkFilesetResponse.Status.Code, linkFilesetResponse.Jobs[0].JobID)
+	err = s.waitForJobCompletion(linkFilesetResponse.Status.Code, linkFilesetResponse.Jobs[0].JobID, fmt.Sprintf("link fileset %v", filesetName))
 	if err != nil {
-		return fmt.Errorf("Unable to link fileset %v:%v. Please refer Ubiquity server logs for more details", filesetName, err)
+		return err
 	}
 	return nil
 }
@@ -387,9 +555,9 @@ func (s *spectrumRestV2) UnlinkFileset(filesystemName string, filesetName string
 		return err
 	}
 
-	err = s.waitForJobCompletion(unlinkFilesetResponse.Status.Code, unlinkFilesetResponse.Jobs[0].JobID)
+	err = s.waitForJobCompletion(unlinkFilesetResponse.Status.Code, unlinkFilesetResponse.Jobs[0].JobID, fmt.Sprintf("unlink fileset %v", filesetName))
 	if err != nil {
-		return fmt.Errorf("Unable to unlink fileset %v:%v. Please refer Ubiquity server logs for more details", filesetName, err)
+		return err
 	}
 
 	return nil
@@ -421,6 +589,28 @@ func (s *spectrumRestV2) ListFileset(filesystemName string, filesetName string)
 	return resources.Volume{Name: name, Mountpoint: mountpoint}, nil
 }
 
+// GetFilesetAfmState return the cache state of an AFM fileset
+func (s *spectrumRestV2) GetFilesetAfmState(filesystemName string, filesetName string) (string, error) {
+
+	s.logger.Println("spectrumRestConnector: GetFilesetAfmState")
+	defer s.logger.Println("spectrumRestConnector: GetFilesetAfmState end")
+
+	getFilesetURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s/filesets/%s?fields=:all:", filesystemName, filesetName))
+	getFilesetResponse := GetFilesetResponse_v2{}
+
+	s.logger.Println("Get Fileset AFM state URL: ", getFilesetURL)
+
+	err := s.doHTTP(getFilesetURL, "GET", &getFilesetResponse, nil)
+	if err != nil {
+		s.logger.Printf("error in processing remote call %v", err)
+		return "", fmt.Errorf("Unable to fetch the AFM state of fileset %v. Please refer Ubiquity server logs for more details", filesetName)
+	}
+	if len(getFilesetResponse.Filesets) == 0 || getFilesetResponse.Filesets[0].AFM.AFMTarget == "" {
+		return "", fmt.Errorf("Fileset %v is not an AFM fileset", filesetName)
+	}
+	return getFilesetResponse.Filesets[0].AFM.AFMState, nil
+}
+
 func (s *spectrumRestV2) ListFilesets(filesystemName string) ([]resources.Volume, error) {
 
 	s.logger.Println("spectrumRestConnector: ListFilesets")
@@ -502,9 +692,9 @@ func (s *spectrumRestV2) SetFilesetQuota(filesystemName string, filesetName stri
 		return err
 	}
 
-	err = s.waitForJobCompletion(setQuotaResponse.Status.Code, setQuotaResponse.Jobs[0].JobID)
+	err = s.waitForJobCompletion(setQuotaResponse.Status.Code, setQuotaResponse.Jobs[0].JobID, fmt.Sprintf("set quota for fileset %v", filesetName))
 	if err != nil {
-		return fmt.Errorf("Unable to set quota for fileset %v:%v. Please refer Ubiquity server logs for more details", filesetName, err)
+		return err
 	}
 	return nil
 }
@@ -533,7 +723,41 @@ func (s *spectrumRestV2) ListFilesetQuota(filesystemName string, filesetName str
 	}
 }
 
-func (s *spectrumRestV2) ExportNfs(volumeMountpoint string, clientConfig string) error {
+func (s *spectrumRestV2) ListFilesetQuotaUsage(filesystemName string, filesetName string) (FilesetQuotaUsage, error) {
+
+	s.logger.Println("spectrumRestConnector: ListFilesetQuotaUsage")
+	defer s.logger.Println("spectrumRestConnector: ListFilesetQuotaUsage end")
+
+	listQuotaURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/filesystems/%s/quotas?filter=objectName=%s", filesystemName, filesetName))
+	listQuotaResponse := GetQuotaResponse_v2{}
+
+	s.logger.Println("List Quota URL: ", listQuotaURL)
+
+	err := s.doHTTP(listQuotaURL, "GET", &listQuotaResponse, nil)
+	if err != nil {
+		s.logger.Printf("error in processing remote call %v", err)
+		return FilesetQuotaUsage{}, fmt.Errorf("Unable to fetch quota information %v. Please refer Ubiquity server logs for more details", filesystemName)
+	}
+
+	for _, quota := range listQuotaResponse.Quotas {
+		if quota.QuotaType != "FILESET" || quota.ObjectName != filesetName {
+			continue
+		}
+		// the block values are in KB, the hard limit is 0 if only the soft quota is set
+		usage := FilesetQuotaUsage{BlockUsage: int64(quota.BlockUsage) * 1024, BlockLimit: int64(quota.BlockLimit) * 1024,
+			FilesUsage: int64(quota.FilesUsage), FilesLimit: int64(quota.FilesLimit)}
+		if usage.BlockLimit == 0 {
+			usage.BlockLimit = int64(quota.BlockQuota) * 1024
+		}
+		if usage.FilesLimit == 0 {
+			usage.FilesLimit = int64(quota.FilesQuota)
+		}
+		return usage, nil
+	}
+	return FilesetQuotaUsage{}, fmt.Errorf("Unable to fetch quota information of fileset %v. Please refer Ubiquity server logs for more details", filesetName)
+}
+
+func (s *spectrumRestV2) ExportNfs(volumeMountpoint string, clients []NfsClient) error {
 
 	s.logger.Println("spectrumRestConnector: ExportNfs")
 	defer s.logger.Println("spectrumRestConnector: ExportNfs end")
@@ -541,7 +765,7 @@ func (s *spectrumRestV2) ExportNfs(volumeMountpoint string, clientConfig string)
 	exportNfsURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/nfs/exports"))
 	nfsExportReq := nfsExportRequest{}
 	nfsExportReq.Path = volumeMountpoint
-	nfsExportReq.ClientDetail = append(nfsExportReq.ClientDetail, clientConfig)
+	nfsExportReq.ClientDetail = nfsClientSpecs(clients)
 
 	s.logger.Println("Export NFS URL: ", exportNfsURL)
 	s.logger.Printf("volumemount %s clientdetail %s\n", nfsExportReq.Path, nfsExportReq.ClientDetail)
@@ -558,13 +782,77 @@ func (s *spectrumRestV2) ExportNfs(volumeMountpoint string, clientConfig string)
 		return err
 	}
 
-	err = s.waitForJobCompletion(nfsExportResp.Status.Code, nfsExportResp.Jobs[0].JobID)
+	err = s.waitForJobCompletion(nfsExportResp.Status.Code, nfsExportResp.Jobs[0].JobID, fmt.Sprintf("export %v", volumeMountpoint))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *spectrumRestV2) UpdateNfsExport(volumeMountpoint string, addClients []NfsClient, changeClients []NfsClient, removeClients []NfsClient) error {
+
+	s.logger.Println("spectrumRestConnector: UpdateNfsExport")
+	defer s.logger.Println("spectrumRestConnector: UpdateNfsExport end")
+
+	updateNfsURL := utils.FormatURL(s.endpoint, "scalemgmt/v2/nfs/exports/", url.QueryEscape(volumeMountpoint))
+	nfsExportChangeReq := nfsExportChangeRequest{NfsAdd: nfsClientSpecs(addClients), NfsChange: nfsClientSpecs(changeClients), NfsRemove: nfsClientNames(removeClients)}
+
+	s.logger.Printf("NFS export PUT URL: %s\n", updateNfsURL)
+	s.logger.Printf("add %v change %v remove %v\n", nfsExportChangeReq.NfsAdd, nfsExportChangeReq.NfsChange, nfsExportChangeReq.NfsRemove)
+
+	updateNfsResp := GenericResponse{}
+	err := s.doHTTP(updateNfsURL, "PUT", &updateNfsResp, nfsExportChangeReq)
+	if err != nil {
+		s.logger.Printf("Error while changing NFS export %v", err)
+		return fmt.Errorf("Unable to change export %v. Please refer Ubiquity server logs for more details", volumeMountpoint)
+	}
+
+	err = s.isRequestAccepted(updateNfsResp, updateNfsURL)
+	if err != nil {
+		return err
+	}
+
+	err = s.waitForJobCompletion(updateNfsResp.Status.Code, updateNfsResp.Jobs[0].JobID, fmt.Sprintf("change export %v", volumeMountpoint))
 	if err != nil {
-		return fmt.Errorf("Unable to export %v:%v. Please refer Ubiquity server logs for more details", volumeMountpoint, err)
+		return err
 	}
 	return nil
 }
 
+func (s *spectrumRestV2) ListNfsExportClients(volumeMountpoint string) ([]NfsClient, error) {
+
+	s.logger.Println("spectrumRestConnector: ListNfsExportClients")
+	defer s.logger.Println("spectrumRestConnector: ListNfsExportClients end")
+
+	getNfsURL := utils.FormatURL(s.endpoint, fmt.Sprintf("scalemgmt/v2/nfs/exports/%s?fields=:all:", url.QueryEscape(volumeMountpoint)))
+	getNfsResp := GetNfsExportResponse_v2{}
+
+	s.logger.Printf("NFS export GET URL: %s\n", getNfsURL)
+
+	err := s.doHTTP(getNfsURL, "GET", &getNfsResp, nil)
+	if err != nil {
+		s.logger.Printf("error in executing remote call: %v", err)
+		return nil, fmt.Errorf("Unable to list the clients of export %v. Please refer Ubiquity server logs for more details", volumeMountpoint)
+	}
+
+	clients := []NfsClient{}
+	for _, export := range getNfsResp.Exports {
+		if export.Path != volumeMountpoint {
+			continue
+		}
+		for _, client := range export.NfsClients {
+			clients = append(clients, newListedNfsClient(client.ClientName, client.AccessType, client.Squash, client.SecType, client.Protocols))
+		}
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("Unable to list the clients of export %v. Please refer Ubiquity server logs for more details", volumeMountpoint)
+	}
+	if err := ValidateNfsClients(clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
 func (s *spectrumRestV2) UnexportNfs(volumeMountpoint string) error {
 
 	s.logger.Println("spectrumRestConnector: UnexportNfs")
@@ -587,10 +875,67 @@ func (s *spectrumRestV2) UnexportNfs(volumeMountpoint string) error {
 		return err
 	}
 
-	err = s.waitForJobCompletion(unexportNfsResp.Status.Code, unexportNfsResp.Jobs[0].JobID)
+	err = s.waitForJobCompletion(unexportNfsResp.Status.Code, unexportNfsResp.Jobs[0].JobID, fmt.Sprintf("remove export %v", volumeMountpoint))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *spectrumRestV2) ExportSmb(shareName string, volumeMountpoint string, smbOptions map[string]string) error {
+
+	s.logger.Println("spectrumRestConnector: ExportSmb")
+	defer s.logger.Println("spectrumRestConnector: ExportSmb end")
+
+	exportSmbURL := utils.FormatURL(s.endpoint, "scalemgmt/v2/smb/shares")
+	smbShareReq := smbShareRequest{ShareName: shareName, Path: volumeMountpoint, SmbOptions: smbOptions}
+
+	s.logger.Println("Export SMB URL: ", exportSmbURL)
+	s.logger.Printf("share %s volumemount %s smboptions %v\n", smbShareReq.ShareName, smbShareReq.Path, smbShareReq.SmbOptions)
+
+	smbShareResp := GenericResponse{}
+	err := s.doHTTP(exportSmbURL, "POST", &smbShareResp, smbShareReq)
+	if err != nil {
+		s.logger.Printf("error during SMB export %v", err)
+		return fmt.Errorf("Unable to create share %v. Please refer Ubiquity server logs for more details", shareName)
+	}
+
+	err = s.isRequestAccepted(smbShareResp, exportSmbURL)
+	if err != nil {
+		return err
+	}
+
+	err = s.waitForJobCompletion(smbShareResp.Status.Code, smbShareResp.Jobs[0].JobID, fmt.Sprintf("create share %v", shareName))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *spectrumRestV2) UnexportSmb(shareName string) error {
+
+	s.logger.Println("spectrumRestConnector: UnexportSmb")
+	defer s.logger.Println("spectrumRestConnector: UnexportSmb end")
+
+	unexportSmbURL := utils.FormatURL(s.endpoint, "scalemgmt/v2/smb/shares/", url.PathEscape(shareName))
+	unexportSmbResp := GenericResponse{}
+
+	s.logger.Printf("SMB share DELETE URL: %s\n", unexportSmbURL)
+
+	err := s.doHTTP(unexportSmbURL, "DELETE", &unexportSmbResp, nil)
+	if err != nil {
+		s.logger.Printf("Error while deleting SMB share %v", err)
+		return fmt.Errorf("Unable to remove share %v. Please refer Ubiquity server logs for more details", shareName)
+	}
+
+	err = s.isRequestAccepted(unexportSmbResp, unexportSmbURL)
+	if err != nil {
+		return err
+	}
 
+	err = s.waitForJobCompletion(unexportSmbResp.Status.Code, unexportSmbResp.Jobs[0].JobID, fmt.Sprintf("remove share %v", shareName))
 	if err != nil {
-		return fmt.Errorf("Unable to remove export %v:%v. Please refer Ubiquity server logs for more details", volumeMountpoint, err)
+		return err
 	}
 	return nil
 }
@@ -50,6 +50,7 @@ var _ = Describe("spectrumRestV2", func() {
 		restConfig.User = "fakeuser"
 		restConfig.Password = "fakepassword"
 		restConfig.Hostname = "fakehostname"
+		restConfig.SslMode = resources.SslModeRequire
 		spectrumRestV2, client, err = connectors.NewspectrumRestV2WithClient(logger, restConfig)
 		Expect(err).ToNot(HaveOccurred())
 		httpmock.ActivateNonDefault(client)
@@ -274,6 +275,202 @@ var _ = Describe("spectrumRestV2", func() {
 		})
 	})
 
+	Context(".GetFilesystemCapacity", func() {
+		var registerurl string
+		BeforeEach(func() {
+			registerurl = fakeurl + "/scalemgmt/v2/filesystems/" + filesystem + "/pools?fields=:all:"
+		})
+		It("should sum the data size and the free data space of the pools in bytes", func() {
+			getPoolsResp := connectors.GetStoragePoolsResponse_v2{StoragePools: []connectors.StoragePool_v2{
+				{StoragePoolName: "system", TotalDataSize: 1024, FreeDataSize: 512},
+				{StoragePoolName: "data", TotalDataSize: 2048, FreeDataSize: 2048},
+			}}
+			getPoolsResp.Status.Code = 200
+			marshalledResponse, err := json.Marshal(getPoolsResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			capacity, err := spectrumRestV2.GetFilesystemCapacity(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capacity).To(Equal(connectors.FilesystemCapacity{TotalBytes: 3072 * 1024, FreeBytes: 2560 * 1024}))
+		})
+		It("should fail if the filesystem has no pools", func() {
+			getPoolsResp := connectors.GetStoragePoolsResponse_v2{}
+			getPoolsResp.Status.Code = 200
+			marshalledResponse, err := json.Marshal(getPoolsResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			_, err = spectrumRestV2.GetFilesystemCapacity(filesystem)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail with http error", func() {
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(500, "{}"))
+			_, err := spectrumRestV2.GetFilesystemCapacity(filesystem)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".ListFilesystemPools", func() {
+		It("should return the pools of the filesystem", func() {
+			getFilesystemResp := connectors.GetFilesystemResponse_v2{FileSystems: []connectors.FileSystem_v2{
+				{Name: filesystem, Block: connectors.BlockInfo{Pools: "system, flash"}},
+			}}
+			getFilesystemResp.Status.Code = 200
+			marshalledResponse, err := json.Marshal(getFilesystemResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", fakeurl+"/scalemgmt/v2/filesystems/"+filesystem+"?fields=:all:", httpmock.NewStringResponder(200, string(marshalledResponse)))
+			pools, err := spectrumRestV2.ListFilesystemPools(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pools).To(Equal([]string{"system", "flash"}))
+		})
+		It("should fail with http error", func() {
+			httpmock.RegisterResponder("GET", fakeurl+"/scalemgmt/v2/filesystems/"+filesystem+"?fields=:all:", httpmock.NewStringResponder(500, "{}"))
+			_, err := spectrumRestV2.ListFilesystemPools(filesystem)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".GetFilesetAfmState", func() {
+		var registerurl string
+		BeforeEach(func() {
+			registerurl = fakeurl + "/scalemgmt/v2/filesystems/" + filesystem + "/filesets/" + fileset + "?fields=:all:"
+		})
+		It("should return the cache state of an afm fileset", func() {
+			getFilesetResp := connectors.GetFilesetResponse_v2{Filesets: []connectors.Fileset_v2{
+				{AFM: connectors.AFM{AFMTarget: "nfs://home1/gpfs/datasets", AFMMode: "ro", AFMState: "Dirty"}},
+			}}
+			marshalledResponse, err := json.Marshal(getFilesetResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			afmState, err := spectrumRestV2.GetFilesetAfmState(filesystem, fileset)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(afmState).To(Equal("Dirty"))
+		})
+		It("should fail if the fileset is not an afm fileset", func() {
+			getFilesetResp := connectors.GetFilesetResponse_v2{Filesets: []connectors.Fileset_v2{{}}}
+			marshalledResponse, err := json.Marshal(getFilesetResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			_, err = spectrumRestV2.GetFilesetAfmState(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".GetPolicy", func() {
+		var registerurl string
+		BeforeEach(func() {
+			registerurl = fakeurl + "/scalemgmt/v2/filesystems/" + filesystem + "/policies"
+		})
+		It("should return the policy of the filesystem", func() {
+			getPolicyResp := connectors.GetPolicyResponse_v2{Policies: []connectors.Policy_v2{{FilesystemName: filesystem, Policy: "RULE 'default' SET POOL 'system'\n"}}}
+			getPolicyResp.Status.Code = 200
+			marshalledResponse, err := json.Marshal(getPolicyResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			policy, err := spectrumRestV2.GetPolicy(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy).To(Equal("RULE 'default' SET POOL 'system'\n"))
+		})
+		It("should return an empty policy when no policy is installed", func() {
+			getPolicyResp := connectors.GetPolicyResponse_v2{Policies: []connectors.Policy_v2{{FilesystemName: filesystem, Policy: "No policy file was installed for file system '" + filesystem + "'."}}}
+			getPolicyResp.Status.Code = 200
+			marshalledResponse, err := json.Marshal(getPolicyResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			policy, err := spectrumRestV2.GetPolicy(filesystem)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy).To(Equal(""))
+		})
+	})
+
+	Context(".ChangePolicy", func() {
+		It("should fail with http error", func() {
+			httpmock.RegisterResponder("PUT", fakeurl+"/scalemgmt/v2/filesystems/"+filesystem+"/policies", httpmock.NewStringResponder(500, "{}"))
+			err := spectrumRestV2.ChangePolicy(filesystem, "RULE 'default' SET POOL 'system'\n")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".AsyncJobCompletion", func() {
+		var (
+			registerurl string
+			joburl      string
+			cancelurl   string
+			statuses    []string
+			polls       int
+			cancels     int
+		)
+		BeforeEach(func() {
+			registerurl = fakeurl + "/scalemgmt/v2/filesystems/" + filesystem + "/filesets"
+			joburl = fakeurl + "/scalemgmt/v2/jobs?filter=jobId=1234&fields=:all:"
+			cancelurl = fakeurl + "/scalemgmt/v2/jobs/1234/cancel"
+			polls = 0
+			cancels = 0
+
+			jobConfig := restConfig
+			jobConfig.JobTimeout = 1
+			jobConfig.JobPollInterval = 10
+			spectrumRestV2, client, err = connectors.NewspectrumRestV2WithClient(logger, jobConfig)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.ActivateNonDefault(client)
+
+			accepted := connectors.GenericResponse{Status: connectors.Status{Code: 202}, Jobs: []connectors.Job{{JobID: 1234, Status: "RUNNING"}}}
+			marshalledResponse, err := json.Marshal(accepted)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("POST", registerurl, httpmock.NewStringResponder(202, string(marshalledResponse)))
+			// the job reports the statuses in turn, and then the last one
+			httpmock.RegisterResponder("GET", joburl, func(req *http.Request) (*http.Response, error) {
+				status := statuses[len(statuses)-1]
+				if polls < len(statuses) {
+					status = statuses[polls]
+				}
+				polls++
+				job := connectors.Job{JobID: 1234, Status: status,
+					Result: connectors.Respresult{Progress: []string{"step 1"}, Stderr: []string{"EFSSG0072C No space left."}}}
+				return httpmock.NewJsonResponse(200, connectors.GenericResponse{Status: connectors.Status{Code: 200}, Jobs: []connectors.Job{job}})
+			})
+			httpmock.RegisterResponder("PUT", cancelurl, func(req *http.Request) (*http.Response, error) {
+				cancels++
+				return httpmock.NewJsonResponse(200, connectors.GenericResponse{Status: connectors.Status{Code: 200}})
+			})
+		})
+		It("should poll the RUNNING job until it completes", func() {
+			statuses = []string{"RUNNING", "RUNNING", "COMPLETED"}
+			err = spectrumRestV2.CreateFileset(filesystem, fileset, opts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(polls).To(Equal(3))
+			Expect(cancels).To(Equal(0))
+		})
+		It("should cancel the job and fail with JobTimeoutError if the job timeout exceeds", func() {
+			statuses = []string{"RUNNING"}
+			err = spectrumRestV2.CreateFileset(filesystem, fileset, opts)
+			Expect(err).To(BeAssignableToTypeOf(&connectors.JobTimeoutError{}))
+			Expect(err.(*connectors.JobTimeoutError).JobID).To(Equal(uint64(1234)))
+			Expect(err.(*connectors.JobTimeoutError).Progress).To(Equal([]string{"step 1"}))
+			Expect(err.Error()).To(MatchRegexp("Unable to create fileset fake-fileset: job \\[1234\\]"))
+			Expect(cancels).To(Equal(1))
+			// exponential backoff from 10ms within 1s
+			Expect(polls).To(BeNumerically("<=", 9))
+		})
+		It("should fail with JobFailedError if the job fails", func() {
+			statuses = []string{"RUNNING", "FAILED"}
+			err = spectrumRestV2.CreateFileset(filesystem, fileset, opts)
+			Expect(err).To(BeAssignableToTypeOf(&connectors.JobFailedError{}))
+			Expect(err.(*connectors.JobFailedError).Stderr).To(Equal([]string{"EFSSG0072C No space left."}))
+		})
+		It("should fail with JobCancelledError if the job is cancelled", func() {
+			statuses = []string{"CANCELLED"}
+			err = spectrumRestV2.CreateFileset(filesystem, fileset, opts)
+			Expect(err).To(BeAssignableToTypeOf(&connectors.JobCancelledError{}))
+			Expect(cancels).To(Equal(0))
+		})
+		It("should fail with JobStatusUnexpectedError on an unknown job status", func() {
+			statuses = []string{"PAUSED"}
+			err = spectrumRestV2.CreateFileset(filesystem, fileset, opts)
+			Expect(err).To(BeAssignableToTypeOf(&connectors.JobStatusUnexpectedError{}))
+			Expect(err.(*connectors.JobStatusUnexpectedError).Status).To(Equal("PAUSED"))
+		})
+	})
+
 	Context(".CreateFileset", func() {
 		var (
 			createFilesetResp connectors.GenericResponse
@@ -333,6 +530,32 @@ var _ = Describe("spectrumRestV2", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("Should create a dependent fileset in the inode space of the given fileset", func() {
+			createFilesetResp.Status.Code = 202
+			createFilesetResp.Jobs[0].Status = "COMPLETED"
+			marshalledResponse, err := json.Marshal(createFilesetResp)
+			Expect(err).ToNot(HaveOccurred())
+
+			var createRequest map[string]interface{}
+			httpmock.RegisterResponder(
+				"POST",
+				registerurl,
+				func(req *http.Request) (*http.Response, error) {
+					Expect(json.NewDecoder(req.Body).Decode(&createRequest)).To(Succeed())
+					return httpmock.NewStringResponse(http.StatusAccepted, string(marshalledResponse)), nil
+				},
+			)
+
+			httpmock.RegisterResponder(
+				"GET",
+				joburl,
+				httpmock.NewStringResponder(200, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.CreateFileset(filesystem, fileset, map[string]interface{}{connectors.UserSpecifiedInodeSpace: "parent-fileset"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(createRequest["inodeSpace"]).To(Equal("parent-fileset"))
+		})
+
 		It("Should fail with http error", func() {
 			createFilesetResp.Status.Code = 500
 			createFilesetResp.Jobs[0].Status = "COMPLETED"
@@ -1111,6 +1334,55 @@ var _ = Describe("spectrumRestV2", func() {
 		})
 	})
 
+	Context(".ListFilesetQuotaUsage", func() {
+		var (
+			getFilesetquota connectors.GetQuotaResponse_v2
+			registerurl     string
+		)
+		BeforeEach(func() {
+			getFilesetquota = connectors.GetQuotaResponse_v2{}
+			getFilesetquota.Status.Code = 200
+			getFilesetquota.Quotas = []connectors.Quota_v2{
+				{QuotaType: "USR", ObjectName: fileset, BlockUsage: 1},
+				{QuotaType: "FILESET", ObjectName: fileset, BlockUsage: 256, BlockQuota: 1024, FilesUsage: 3, FilesLimit: 1000},
+			}
+			registerurl = fakeurl + "/scalemgmt/v2/filesystems/" + filesystem + "/quotas" + "?filter=objectName=" + fileset
+		})
+		It("should return the usage and the limit of the fileset quota in bytes", func() {
+			getFilesetquota.Quotas[1].BlockLimit = 2048
+			marshalledResponse, err := json.Marshal(getFilesetquota)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			usage, err := spectrumRestV2.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage).To(Equal(connectors.FilesetQuotaUsage{BlockUsage: 256 * 1024, BlockLimit: 2048 * 1024, FilesUsage: 3, FilesLimit: 1000}))
+		})
+		It("should return the soft quota when no hard limit is set", func() {
+			marshalledResponse, err := json.Marshal(getFilesetquota)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			usage, err := spectrumRestV2.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage.BlockLimit).To(Equal(int64(1024 * 1024)))
+		})
+		It("should fail if the fileset has no fileset quota", func() {
+			getFilesetquota.Quotas = getFilesetquota.Quotas[:1]
+			marshalledResponse, err := json.Marshal(getFilesetquota)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
+			_, err = spectrumRestV2.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail with http error", func() {
+			getFilesetquota.Status.Code = 500
+			marshalledResponse, err := json.Marshal(getFilesetquota)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(500, string(marshalledResponse)))
+			_, err = spectrumRestV2.ListFilesetQuotaUsage(filesystem, fileset)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Context(".ExportNfs", func() {
 		var (
 			exportNfsResp connectors.GenericResponse
@@ -1144,7 +1416,7 @@ var _ = Describe("spectrumRestV2", func() {
 				joburl,
 				httpmock.NewStringResponder(http.StatusAccepted, string(marshalledResponse)),
 			)
-			err = spectrumRestV2.ExportNfs(filesystem, filesystem)
+			err = spectrumRestV2.ExportNfs(filesystem, []connectors.NfsClient{{Client: "*", AccessType: "RW"}})
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -1165,7 +1437,7 @@ var _ = Describe("spectrumRestV2", func() {
 				joburl,
 				httpmock.NewStringResponder(200, string(marshalledResponse)),
 			)
-			err = spectrumRestV2.ExportNfs(filesystem, fileset)
+			err = spectrumRestV2.ExportNfs(filesystem, []connectors.NfsClient{{Client: "*", AccessType: "RW"}})
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -1187,7 +1459,7 @@ var _ = Describe("spectrumRestV2", func() {
 				joburl,
 				httpmock.NewStringResponder(400, string(marshalledResponse)),
 			)
-			err = spectrumRestV2.ExportNfs(filesystem, fileset)
+			err = spectrumRestV2.ExportNfs(filesystem, []connectors.NfsClient{{Client: "*", AccessType: "RW"}})
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -1208,9 +1480,89 @@ var _ = Describe("spectrumRestV2", func() {
 				joburl,
 				httpmock.NewStringResponder(200, string(marshalledResponse)),
 			)
-			err = spectrumRestV2.ExportNfs(filesystem, fileset)
+			err = spectrumRestV2.ExportNfs(filesystem, []connectors.NfsClient{{Client: "*", AccessType: "RW"}})
+			Expect(err).To(HaveOccurred())
+
+		})
+	})
+
+	Context(".UpdateNfsExport", func() {
+		var (
+			updateNfsResp connectors.GenericResponse
+			registerurl   string
+			joburl        string
+		)
+		BeforeEach(func() {
+			updateNfsResp = connectors.GenericResponse{}
+			updateNfsResp.Jobs = make([]connectors.Job, 1)
+			updateNfsResp.Jobs[0].JobID = 1234
+			registerurl = fakeurl + "/scalemgmt/v2/nfs/exports/" + fileset
+			joburl = fakeurl + "/scalemgmt/v2/jobs?filter=jobId=1234&fields=:all:"
+		})
+		It("Should pass while changing the clients of an export", func() {
+			updateNfsResp.Status.Code = 202
+			updateNfsResp.Jobs[0].Status = "COMPLETED"
+			marshalledResponse, err := json.Marshal(updateNfsResp)
+			Expect(err).ToNot(HaveOccurred())
+
+			var changeRequest map[string]interface{}
+			httpmock.RegisterResponder(
+				"PUT",
+				registerurl,
+				func(req *http.Request) (*http.Response, error) {
+					Expect(json.NewDecoder(req.Body).Decode(&changeRequest)).To(Succeed())
+					return httpmock.NewStringResponse(http.StatusAccepted, string(marshalledResponse)), nil
+				},
+			)
+
+			httpmock.RegisterResponder(
+				"GET",
+				joburl,
+				httpmock.NewStringResponder(200, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.UpdateNfsExport(fileset, []connectors.NfsClient{{Client: "host1", AccessType: "RW"}}, nil, []connectors.NfsClient{{Client: "*", AccessType: "RW"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changeRequest).To(Equal(map[string]interface{}{"nfsAdd": []interface{}{"host1(Access_Type=RW)"}, "nfsRemove": []interface{}{"*"}}))
+		})
+
+		It("Should fail with http error", func() {
+			updateNfsResp.Status.Code = 500
+			marshalledResponse, err := json.Marshal(updateNfsResp)
+			Expect(err).ToNot(HaveOccurred())
+
+			httpmock.RegisterResponder(
+				"PUT",
+				registerurl,
+				httpmock.NewStringResponder(500, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.UpdateNfsExport(fileset, nil, []connectors.NfsClient{{Client: "*", AccessType: "RO"}}, nil)
 			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".ListNfsExportClients", func() {
+		var registerurl string
+
+		BeforeEach(func() {
+			registerurl = fakeurl + "/scalemgmt/v2/nfs/exports/" + fileset + "?fields=:all:"
+		})
+		It("Should return the clients of the export", func() {
+			getNfsResp := connectors.GetNfsExportResponse_v2{Status: connectors.Status{Code: 200}, Exports: []connectors.NfsExport_v2{{Path: fileset,
+				NfsClients: []connectors.NfsExportClient_v2{{ClientName: "*", AccessType: "RW", Squash: "ROOT_SQUASH", SecType: "SYS", Protocols: "3,4"}}}}}
+			marshalledResponse, err := json.Marshal(getNfsResp)
+			Expect(err).ToNot(HaveOccurred())
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(200, string(marshalledResponse)))
 
+			clients, err := spectrumRestV2.ListNfsExportClients(fileset)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clients).To(Equal([]connectors.NfsClient{{Client: "*", AccessType: "RW", Squash: "root_squash", SecTypes: []string{"sys"}, Protocols: []string{"3", "4"}}}))
+		})
+
+		It("Should fail with http error", func() {
+			httpmock.RegisterResponder("GET", registerurl, httpmock.NewStringResponder(404, `{"status": {"code": 404}}`))
+
+			_, err := spectrumRestV2.ListNfsExportClients(fileset)
+			Expect(err).To(HaveOccurred())
 		})
 	})
 
@@ -1356,4 +1708,130 @@ var _ = Describe("spectrumRestV2", func() {
 
 		})
 	})
+
+	Context(".ExportSmb", func() {
+		var (
+			smbShareResp connectors.GenericResponse
+			registerurl  string
+			joburl       string
+		)
+		BeforeEach(func() {
+			smbShareResp = connectors.GenericResponse{}
+			smbShareResp.Jobs = make([]connectors.Job, 1)
+			smbShareResp.Jobs[0].JobID = 1234
+			registerurl = fakeurl + "/scalemgmt/v2/smb/shares"
+			joburl = fakeurl + "/scalemgmt/v2/jobs?filter=jobId=1234&fields=:all:"
+		})
+		It("Should pass while creating a share", func() {
+			smbShareResp.Status.Code = 202
+			smbShareResp.Jobs[0].Status = "COMPLETED"
+			marshalledResponse, err := json.Marshal(smbShareResp)
+			Expect(err).ToNot(HaveOccurred())
+
+			var shareRequest map[string]interface{}
+			httpmock.RegisterResponder(
+				"POST",
+				registerurl,
+				func(req *http.Request) (*http.Response, error) {
+					Expect(json.NewDecoder(req.Body).Decode(&shareRequest)).To(Succeed())
+					return httpmock.NewStringResponse(http.StatusAccepted, string(marshalledResponse)), nil
+				},
+			)
+
+			httpmock.RegisterResponder(
+				"GET",
+				joburl,
+				httpmock.NewStringResponder(http.StatusAccepted, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.ExportSmb(fileset, "/gpfs/"+fileset, map[string]string{"browseable": "yes"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shareRequest).To(Equal(map[string]interface{}{"shareName": fileset, "path": "/gpfs/" + fileset, "smbOptions": map[string]interface{}{"browseable": "yes"}}))
+		})
+
+		It("Should fail with http error", func() {
+			smbShareResp.Status.Code = 500
+			smbShareResp.Jobs[0].Status = "COMPLETED"
+			marshalledResponse, err := json.Marshal(smbShareResp)
+			Expect(err).ToNot(HaveOccurred())
+
+			httpmock.RegisterResponder(
+				"POST",
+				registerurl,
+				httpmock.NewStringResponder(500, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.ExportSmb(fileset, "/gpfs/"+fileset, nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should fail to do zero length job array", func() {
+			smbShareResp.Status.Code = 202
+			smbShareResp.Jobs = make([]connectors.Job, 0)
+			marshalledResponse, err := json.Marshal(smbShareResp)
+			Expect(err).ToNot(HaveOccurred())
+
+			httpmock.RegisterResponder(
+				"POST",
+				registerurl,
+				httpmock.NewStringResponder(202, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.ExportSmb(fileset, "/gpfs/"+fileset, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".UnexportSmb", func() {
+		var (
+			unexportSmb connectors.GenericResponse
+			registerurl string
+			joburl      string
+		)
+		BeforeEach(func() {
+			unexportSmb = connectors.GenericResponse{}
+			unexportSmb.Jobs = make([]connectors.Job, 1)
+			unexportSmb.Jobs[0].JobID = 1234
+			registerurl = fakeurl + "/scalemgmt/v2/smb/shares/" + fileset
+			joburl = fakeurl + "/scalemgmt/v2/jobs?filter=jobId=1234&fields=:all:"
+		})
+		It("Should pass while deleting a share", func() {
+			unexportSmb.Status.Code = 202
+			unexportSmb.Jobs[0].Status = "COMPLETED"
+			marshalledResponse, err := json.Marshal(unexportSmb)
+			Expect(err).ToNot(HaveOccurred())
+
+			httpmock.RegisterResponder(
+				"DELETE",
+				registerurl,
+				httpmock.NewStringResponder(202, string(marshalledResponse)),
+			)
+
+			httpmock.RegisterResponder(
+				"GET",
+				joburl,
+				httpmock.NewStringResponder(200, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.UnexportSmb(fileset)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should fail since it is unable to fetch job details due to http error", func() {
+			unexportSmb.Status.Code = 202
+			unexportSmb.Jobs[0].Status = "COMPLETED"
+			marshalledResponse, err := json.Marshal(unexportSmb)
+			Expect(err).ToNot(HaveOccurred())
+
+			httpmock.RegisterResponder(
+				"DELETE",
+				registerurl,
+				httpmock.NewStringResponder(202, string(marshalledResponse)),
+			)
+
+			httpmock.RegisterResponder(
+				"GET",
+				joburl,
+				httpmock.NewStringResponder(400, string(marshalledResponse)),
+			)
+			err = spectrumRestV2.UnexportSmb(fileset)
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
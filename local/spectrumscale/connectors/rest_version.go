@@ -0,0 +1,101 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+)
+
+const (
+	RestApiVersionV1 = "v1"
+	RestApiVersionV2 = "v2"
+)
+
+// NewSpectrumRestConnector return the REST connector of the configured API version.
+// If no version is configured it probes the GUI, and picks v2 if available or else the deprecated v1.
+func NewSpectrumRestConnector(logger *log.Logger, restConfig resources.RestConfig) (SpectrumScaleConnector, error) {
+	version := restConfig.ApiVersion
+	if version == "" {
+		client, err := newRestHttpClient(logger, restConfig)
+		if err != nil {
+			return nil, err
+		}
+		version, err = ProbeRestApiVersion(logger, client, restConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch version {
+	case RestApiVersionV2:
+		logger.Printf("Initializing SpectrumScale REST %s connector\n", version)
+		return NewSpectrumRestV2(logger, restConfig)
+	case RestApiVersionV1:
+		logger.Printf("WARNING: the SpectrumScale REST %s API is deprecated and does not support NFS exports, use the %s API\n", version, RestApiVersionV2)
+		return NewSpectrumRest(logger, restConfig)
+	}
+	return nil, &unsupportedRestApiVersionError{version}
+}
+
+// ProbeRestApiVersion return v2 if the GUI serves the v2 API, v1 if it serves only the v1 API
+func ProbeRestApiVersion(logger *log.Logger, client *http.Client, restConfig resources.RestConfig) (string, error) {
+	infoURL := utils.FormatURL(restConfig.Endpoint, "scalemgmt/v2/info")
+	statusCode, err := probeRestURL(client, infoURL, restConfig, &GetInfoResponse_v2{})
+	if err != nil {
+		return "", &restApiVersionProbeError{restConfig.Endpoint, err.Error()}
+	}
+	switch statusCode {
+	case http.StatusOK:
+		return RestApiVersionV2, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", &restApiVersionProbeError{restConfig.Endpoint, fmt.Sprintf("authentication of user [%s] failed", restConfig.User)}
+	case http.StatusNotFound:
+	default:
+		return "", &restApiVersionProbeError{restConfig.Endpoint, fmt.Sprintf("GET %s returned status %d", infoURL, statusCode)}
+	}
+
+	logger.Printf("The Spectrum Scale GUI does not serve the REST %s API, probing the %s API\n", RestApiVersionV2, RestApiVersionV1)
+	clusterURL := utils.FormatURL(restConfig.Endpoint, "scalemgmt/v1/cluster")
+	statusCode, err = probeRestURL(client, clusterURL, restConfig, &GetClusterResponse{})
+	if err != nil {
+		return "", &restApiVersionProbeError{restConfig.Endpoint, err.Error()}
+	}
+	if statusCode != http.StatusOK {
+		return "", &restApiVersionProbeError{restConfig.Endpoint, fmt.Sprintf("GET %s returned status %d", clusterURL, statusCode)}
+	}
+	return RestApiVersionV1, nil
+}
+
+func probeRestURL(client *http.Client, url string, restConfig resources.RestConfig, responseObject interface{}) (int, error) {
+	response, err := utils.HttpExecuteUserAuth(client, "GET", url, restConfig.User, restConfig.Password, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return response.StatusCode, nil
+	}
+	if err = utils.UnmarshalResponse(response, responseObject); err != nil {
+		return 0, fmt.Errorf("invalid response of GET %s: %s", url, err.Error())
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,132 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors_test
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/resources"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("REST API version negotiation", func() {
+	var (
+		logger     *log.Logger
+		server     *httptest.Server
+		lock       sync.Mutex
+		requests   []string
+		responses  map[string]int
+		restConfig resources.RestConfig
+		client     *http.Client
+	)
+
+	BeforeEach(func() {
+		logger = log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+		requests = nil
+		responses = map[string]int{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			requests = append(requests, req.URL.Path)
+			status, exists := responses[req.URL.Path]
+			lock.Unlock()
+			if !exists {
+				status = http.StatusNotFound
+			}
+			w.WriteHeader(status)
+			w.Write([]byte("{}"))
+		}))
+		restConfig = resources.RestConfig{Endpoint: server.URL, User: "admin", Password: "admin001", SslMode: resources.SslModeRequire}
+		// not the default transport, which httpmock blocks
+		client = &http.Client{Transport: &http.Transport{}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context(".ProbeRestApiVersion", func() {
+		It("should pick v2 if the GUI serves it", func() {
+			responses["/scalemgmt/v2/info"] = http.StatusOK
+			responses["/scalemgmt/v1/cluster"] = http.StatusOK
+			version, err := connectors.ProbeRestApiVersion(logger, client, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal(connectors.RestApiVersionV2))
+			Expect(requests).To(Equal([]string{"/scalemgmt/v2/info"}))
+		})
+		It("should fall back to v1 if the GUI does not serve v2", func() {
+			responses["/scalemgmt/v1/cluster"] = http.StatusOK
+			version, err := connectors.ProbeRestApiVersion(logger, client, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal(connectors.RestApiVersionV1))
+			Expect(requests).To(Equal([]string{"/scalemgmt/v2/info", "/scalemgmt/v1/cluster"}))
+		})
+		It("should fail if the GUI serves no known version", func() {
+			_, err := connectors.ProbeRestApiVersion(logger, client, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("scalemgmt/v1/cluster returned status 404"))
+		})
+		It("should fail if the authentication fails", func() {
+			responses["/scalemgmt/v2/info"] = http.StatusUnauthorized
+			_, err := connectors.ProbeRestApiVersion(logger, client, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("authentication of user \\[admin\\] failed"))
+			Expect(requests).To(HaveLen(1))
+		})
+		It("should fail if the GUI is not reachable", func() {
+			server.Close()
+			_, err := connectors.ProbeRestApiVersion(logger, client, restConfig)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context(".NewSpectrumRestConnector", func() {
+		It("should not probe the configured version", func() {
+			restConfig.ApiVersion = connectors.RestApiVersionV2
+			_, err := connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests).To(BeEmpty())
+		})
+		It("should fail on an unsupported version", func() {
+			restConfig.ApiVersion = "v3"
+			_, err := connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("Unsupported Spectrum Scale REST API version \\[v3\\]"))
+		})
+		It("should return a v1 connector that fails the operations it does not support", func() {
+			responses["/scalemgmt/v1/cluster"] = http.StatusOK
+			connector, err := connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = connector.ExportNfs("/gpfs/fs1/fset1", []connectors.NfsClient{{Client: "*", AccessType: "RW"}})
+			Expect(err).To(BeAssignableToTypeOf(&connectors.UnsupportedOperationError{}))
+			err = connector.UnexportNfs("/gpfs/fs1/fset1")
+			Expect(err).To(BeAssignableToTypeOf(&connectors.UnsupportedOperationError{}))
+			err = connector.MountFileSystem("fs1")
+			Expect(err).To(BeAssignableToTypeOf(&connectors.UnsupportedOperationError{}))
+		})
+		It("should fail if the version cannot be probed", func() {
+			_, err := connectors.NewSpectrumRestConnector(logger, restConfig)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -17,7 +17,6 @@
 package connectors
 
 import (
-	"fmt"
 	"log"
 	"path"
 
@@ -25,33 +24,31 @@ import (
 	"github.com/IBM/ubiquity/utils"
 )
 
+// spectrum_ssh runs the Spectrum Scale CLI with sudo on a remote host, through an executor that executes
+// the commands over SSH
 type spectrum_ssh struct {
 	logger    *log.Logger
 	executor  utils.Executor
-	user      string
-	host      string
-	port      string
 	isMounted bool
 }
 
 func NewSpectrumSSH(logger *log.Logger, sshConfig resources.SshConfig) (SpectrumScaleConnector, error) {
-	user := sshConfig.User
-	host := sshConfig.Host
-	port := sshConfig.Port
-	return &spectrum_ssh{logger: logger, executor: utils.NewExecutor(), user: user, host: host, port: port}, nil
+	executor, err := newSshExecutor(logger, sshConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &spectrum_ssh{logger: logger, executor: executor}, nil
 }
+
+// NewSpectrumSSHWithExecutor return a connector that executes the commands with executor instead of over SSH
 func NewSpectrumSSHWithExecutor(logger *log.Logger, sshConfig resources.SshConfig, executor utils.Executor) (SpectrumScaleConnector, error) {
-	user := sshConfig.User
-	host := sshConfig.Host
-	port := sshConfig.Port
-	return &spectrum_ssh{logger: logger, executor: executor, user: user, host: host, port: port}, nil
+	return &spectrum_ssh{logger: logger, executor: executor}, nil
 }
 
 func (s *spectrum_ssh) GetClusterId() (string, error) {
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlscluster"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand}
-	return GetClusterIdInternal(s.logger, s.executor, "ssh", args)
+	args := []string{spectrumCommand, "-Y"}
+	return GetClusterIdInternal(s.logger, s.executor, "sudo", args)
 }
 func (s *spectrum_ssh) IsFilesystemMounted(filesystemName string) (bool, error) {
 	s.logger.Println("spectrumLocalClient: isMounted start")
@@ -62,9 +59,8 @@ func (s *spectrum_ssh) IsFilesystemMounted(filesystemName string) (bool, error)
 		return s.isMounted, nil
 	}
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsmount"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, "-L", "-Y"}
-	isMounted, err := IsFilesystemMountedInternal(s.logger, s.executor, filesystemName, "ssh", args)
+	args := []string{spectrumCommand, filesystemName, "-L", "-Y"}
+	isMounted, err := IsFilesystemMountedInternal(s.logger, s.executor, filesystemName, "sudo", args)
 	s.isMounted = isMounted
 	return s.isMounted, err
 
@@ -78,10 +74,9 @@ func (s *spectrum_ssh) MountFileSystem(filesystemName string) error {
 	}
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmmount"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, "-a"}
+	args := []string{spectrumCommand, filesystemName, "-a"}
 
-	err := MountFileSystemInternal(s.logger, s.executor, filesystemName, "ssh", args)
+	err := MountFileSystemInternal(s.logger, s.executor, filesystemName, "sudo", args)
 	if err != nil {
 		s.logger.Printf("error mounting filesystem %v", err)
 		return err
@@ -91,13 +86,49 @@ func (s *spectrum_ssh) MountFileSystem(filesystemName string) error {
 }
 
 func (s *spectrum_ssh) ListFilesystems() ([]string, error) {
-	return nil, nil
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfs"
+	args := []string{spectrumCommand, "all", "-T", "-Y"}
+	return ListFilesystemsInternal(s.logger, s.executor, "sudo", args)
 }
 func (s *spectrum_ssh) GetFilesystemMountpoint(filesystemName string) (string, error) {
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfs"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, "-T", "-Y"}
-	return GetFilesystemMountpointInternal(s.logger, s.executor, filesystemName, "ssh", args)
+	args := []string{spectrumCommand, filesystemName, "-T", "-Y"}
+	return GetFilesystemMountpointInternal(s.logger, s.executor, filesystemName, "sudo", args)
+}
+
+func (s *spectrum_ssh) GetFilesystemCapacity(filesystemName string) (FilesystemCapacity, error) {
+	s.logger.Println("spectrumLocalClient: getFilesystemCapacity start")
+	defer s.logger.Println("spectrumLocalClient: getFilesystemCapacity end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmdf"
+	args := []string{spectrumCommand, filesystemName, "-Y"}
+	return GetFilesystemCapacityInternal(s.logger, s.executor, filesystemName, "sudo", args)
+}
+
+func (s *spectrum_ssh) ListFilesystemPools(filesystemName string) ([]string, error) {
+	s.logger.Println("spectrumLocalClient: listFilesystemPools start")
+	defer s.logger.Println("spectrumLocalClient: listFilesystemPools end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfs"
+	args := []string{spectrumCommand, filesystemName, "-P", "-Y"}
+	return ListFilesystemPoolsInternal(s.logger, s.executor, filesystemName, "sudo", args)
+}
+
+func (s *spectrum_ssh) GetPolicy(filesystemName string) (string, error) {
+	s.logger.Println("spectrumLocalClient: getPolicy start")
+	defer s.logger.Println("spectrumLocalClient: getPolicy end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlspolicy"
+	args := []string{spectrumCommand, filesystemName, "-L"}
+	return GetPolicyInternal(s.logger, s.executor, filesystemName, "sudo", args)
+}
+
+func (s *spectrum_ssh) ChangePolicy(filesystemName string, policy string) error {
+	s.logger.Println("spectrumLocalClient: changePolicy start")
+	defer s.logger.Println("spectrumLocalClient: changePolicy end")
+
+	args := append([]string{"sh"}, changePolicyArgs(filesystemName, policy)...)
+	return ChangePolicyInternal(s.logger, s.executor, filesystemName, policy, "sudo", args)
 }
 
 func (s *spectrum_ssh) CreateFileset(filesystemName string, filesetName string, opts map[string]interface{}) error {
@@ -108,21 +139,32 @@ func (s *spectrum_ssh) CreateFileset(filesystemName string, filesetName string,
 
 	// create fileset
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmcrfileset"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, filesetName, "-t", "'fileset for container volume'"}
+	args := []string{spectrumCommand, filesystemName, filesetName, "-t", "fileset for container volume"}
 
 	filesetType, filesetTypeSpecified := opts[UserSpecifiedFilesetType]
 	inodeLimit, inodeLimitSpecified := opts[UserSpecifiedInodeLimit]
 
-	if filesetTypeSpecified && filesetType.(string) == "independent" {
+	if (filesetTypeSpecified && filesetType.(string) == "independent") || IsAfmFileset(opts) {
 		args = append(args, "--inode-space", "new")
 
 		if inodeLimitSpecified {
 			args = append(args, "--inode-limit", inodeLimit.(string))
 		}
+	} else if inodeSpace, inodeSpaceSpecified := opts[UserSpecifiedInodeSpace]; inodeSpaceSpecified {
+		args = append(args, "--inode-space", inodeSpace.(string))
 	}
+	args = append(args, afmFilesetArgs(opts)...)
+
+	return CreateFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
+}
 
-	return CreateFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "ssh", args)
+func (s *spectrum_ssh) GetFilesetAfmState(filesystemName string, filesetName string) (string, error) {
+	s.logger.Println("spectrumLocalClient: getFilesetAfmState start")
+	defer s.logger.Println("spectrumLocalClient: getFilesetAfmState end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmafmctl"
+	args := []string{spectrumCommand, filesystemName, "getstate", "-j", filesetName, "-Y"}
+	return GetFilesetAfmStateInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
 }
 
 func (s *spectrum_ssh) DeleteFileset(filesystemName string, filesetName string) error {
@@ -130,9 +172,8 @@ func (s *spectrum_ssh) DeleteFileset(filesystemName string, filesetName string)
 	defer s.logger.Println("spectrumLocalClient: deleteFileset end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmdelfileset"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, filesetName, "-f"}
-	return DeleteFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "ssh", args)
+	args := []string{spectrumCommand, filesystemName, filesetName, "-f"}
+	return DeleteFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
 }
 
 func (s *spectrum_ssh) IsFilesetLinked(filesystemName string, filesetName string) (bool, error) {
@@ -140,10 +181,9 @@ func (s *spectrum_ssh) IsFilesetLinked(filesystemName string, filesetName string
 	defer s.logger.Println("spectrumLocalClient: isFilesetLinked end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfileset"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, filesetName, "-Y"}
+	args := []string{spectrumCommand, filesystemName, filesetName, "-Y"}
 	s.logger.Printf("%#v\n", args)
-	return IsFilesetLinkedInternal(s.logger, s.executor, filesystemName, filesetName, "ssh", args)
+	return IsFilesetLinkedInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
 }
 
 func (s *spectrum_ssh) LinkFileset(filesystemName string, filesetName string) error {
@@ -158,12 +198,17 @@ func (s *spectrum_ssh) LinkFileset(filesystemName string, filesetName string) er
 		return err
 	}
 
+	return s.LinkFilesetAt(filesystemName, filesetName, path.Join(mountpoint, filesetName))
+}
+
+func (s *spectrum_ssh) LinkFilesetAt(filesystemName string, filesetName string, junctionPath string) error {
+	s.logger.Println("spectrumLocalClient: linkFilesetAt start")
+	defer s.logger.Println("spectrumLocalClient: linkFilesetAt end")
+
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlinkfileset"
-	filesetPath := path.Join(mountpoint, filesetName)
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, filesetName, "-J", filesetPath}
+	args := []string{spectrumCommand, filesystemName, filesetName, "-J", junctionPath}
 	s.logger.Printf("Args for link fileset%#v", args)
-	err = LinkFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "ssh", args)
+	err := LinkFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
 	if err != nil {
 		s.logger.Printf("error linking fileset %v", err)
 		return err
@@ -176,13 +221,17 @@ func (s *spectrum_ssh) UnlinkFileset(filesystemName string, filesetName string)
 	defer s.logger.Println("spectrumLocalClient: unlinkFileset end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmunlinkfileset"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, filesetName}
-	return UnlinkFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "ssh", args)
+	args := []string{spectrumCommand, filesystemName, filesetName}
+	return UnlinkFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
 }
 
 func (s *spectrum_ssh) ListFilesets(filesystemName string) ([]resources.Volume, error) {
-	return nil, nil
+	s.logger.Println("spectrumLocalClient: ListFilesets start")
+	defer s.logger.Println("spectrumLocalClient: ListFilesets end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfileset"
+	args := []string{spectrumCommand, filesystemName, "-Y"}
+	return ListFilesetsInternal(s.logger, s.executor, filesystemName, "sudo", args)
 }
 
 func (s *spectrum_ssh) ListFileset(filesystemName string, filesetName string) (resources.Volume, error) {
@@ -190,9 +239,8 @@ func (s *spectrum_ssh) ListFileset(filesystemName string, filesetName string) (r
 	defer s.logger.Println("spectrumLocalClient: ListFileset end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsfileset"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName, filesetName, "-Y"}
-	return ListFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "ssh", args)
+	args := []string{spectrumCommand, filesystemName, filesetName, "-Y"}
+	return ListFilesetInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
 }
 
 //TODO modify quota from string to Capacity (see kubernetes)
@@ -201,9 +249,17 @@ func (s *spectrum_ssh) ListFilesetQuota(filesystemName string, filesetName strin
 	defer s.logger.Println("spectrumLocalClient: verifyFilesetQuota end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsquota"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, "-j", filesetName, filesystemName, "--block-size", "auto"}
-	return ListFilesetQuotaInternal(s.logger, s.executor, filesystemName, filesetName, "ssh", args)
+	args := []string{spectrumCommand, "-j", filesetName, filesystemName, "-Y"}
+	return ListFilesetQuotaInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
+}
+
+func (s *spectrum_ssh) ListFilesetQuotaUsage(filesystemName string, filesetName string) (FilesetQuotaUsage, error) {
+	s.logger.Println("spectrumLocalClient: listFilesetQuotaUsage start")
+	defer s.logger.Println("spectrumLocalClient: listFilesetQuotaUsage end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmlsquota"
+	args := []string{spectrumCommand, "-j", filesetName, filesystemName, "-Y"}
+	return ListFilesetQuotaUsageInternal(s.logger, s.executor, filesystemName, filesetName, "sudo", args)
 }
 
 func (s *spectrum_ssh) SetFilesetQuota(filesystemName string, filesetName string, quota string) error {
@@ -213,21 +269,42 @@ func (s *spectrum_ssh) SetFilesetQuota(filesystemName string, filesetName string
 	s.logger.Printf("setting quota to %s for fileset %s\n", quota, filesetName)
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmsetquota"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, filesystemName + ":" + filesetName, "--block", quota + ":" + quota}
-	return SetFilesetQuotaInternal(s.logger, s.executor, filesystemName, filesetName, quota, "ssh", args)
+	args := []string{spectrumCommand, filesystemName + ":" + filesetName, "--block", quota + ":" + quota}
+	return SetFilesetQuotaInternal(s.logger, s.executor, filesystemName, filesetName, quota, "sudo", args)
 }
 
-func (s *spectrum_ssh) ExportNfs(volumeMountpoint string, clientConfig string) error {
+func (s *spectrum_ssh) ExportNfs(volumeMountpoint string, clients []NfsClient) error {
 
 	s.logger.Println("spectrumLocalClient: ExportNfs start")
 	defer s.logger.Println("spectrumLocalClient: ExportNfs end")
-	quotedClientConf := fmt.Sprintf("'%s'", clientConfig)
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmnfs"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, "export", "add", volumeMountpoint, "--client", quotedClientConf}
-	return ExportNfsInternal(s.logger, s.executor, "ssh", args)
+	args := []string{spectrumCommand, "export", "add", volumeMountpoint, "--client", FormatNfsClients(clients)}
+	return ExportNfsInternal(s.logger, s.executor, "sudo", args)
+}
+
+func (s *spectrum_ssh) UpdateNfsExport(volumeMountpoint string, addClients []NfsClient, changeClients []NfsClient, removeClients []NfsClient) error {
+
+	s.logger.Println("spectrumLocalClient: UpdateNfsExport start")
+	defer s.logger.Println("spectrumLocalClient: UpdateNfsExport end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmnfs"
+	for _, args := range nfsExportChangeArgs(volumeMountpoint, addClients, changeClients, removeClients) {
+		if err := UpdateNfsExportInternal(s.logger, s.executor, "sudo", append([]string{spectrumCommand}, args...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *spectrum_ssh) ListNfsExportClients(volumeMountpoint string) ([]NfsClient, error) {
+
+	s.logger.Println("spectrumLocalClient: ListNfsExportClients start")
+	defer s.logger.Println("spectrumLocalClient: ListNfsExportClients end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmnfs"
+	args := []string{spectrumCommand, "export", "list", "--nfsdefs", volumeMountpoint, "-Y"}
+	return ListNfsExportClientsInternal(s.logger, s.executor, volumeMountpoint, "sudo", args)
 }
 
 func (s *spectrum_ssh) UnexportNfs(volumeMountpoint string) error {
@@ -236,7 +313,26 @@ func (s *spectrum_ssh) UnexportNfs(volumeMountpoint string) error {
 	defer s.logger.Println("spectrumLocalClient: UnexportNfs end")
 
 	spectrumCommand := "/usr/lpp/mmfs/bin/mmnfs"
-	userAndHost := fmt.Sprintf("%s@%s", s.user, s.host)
-	args := []string{userAndHost, "-p", s.port, "sudo", spectrumCommand, "export", "remove", volumeMountpoint, "--force"}
-	return UnexportNfsInternal(s.logger, s.executor, "ssh", args)
+	args := []string{spectrumCommand, "export", "remove", volumeMountpoint, "--force"}
+	return UnexportNfsInternal(s.logger, s.executor, "sudo", args)
+}
+
+func (s *spectrum_ssh) ExportSmb(shareName string, volumeMountpoint string, smbOptions map[string]string) error {
+
+	s.logger.Println("spectrumLocalClient: ExportSmb start")
+	defer s.logger.Println("spectrumLocalClient: ExportSmb end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmsmb"
+	args := append([]string{spectrumCommand, "export", "add", shareName, volumeMountpoint}, smbOptionArgs(smbOptions)...)
+	return ExportSmbInternal(s.logger, s.executor, "sudo", args)
+}
+
+func (s *spectrum_ssh) UnexportSmb(shareName string) error {
+
+	s.logger.Println("spectrumLocalClient: UnexportSmb start")
+	defer s.logger.Println("spectrumLocalClient: UnexportSmb end")
+
+	spectrumCommand := "/usr/lpp/mmfs/bin/mmsmb"
+	args := []string{spectrumCommand, "export", "remove", shareName, "--force"}
+	return UnexportSmbInternal(s.logger, s.executor, "sudo", args)
 }
@@ -0,0 +1,225 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	DefaultSshCommandTimeout = 300 // seconds
+	sshDialTimeout           = 30 * time.Second
+	sshTimeoutExitStatus     = 124 // the exit status of the timeout command if it stopped the command
+)
+
+var sshSafeArg = regexp.MustCompile(`^[A-Za-z0-9_/.:=,@%+-]+$`)
+
+// sshExecutor executes the commands on the Spectrum Scale host over a persistent SSH connection,
+// each command in its own session. All the other Executor methods are executed locally.
+type sshExecutor struct {
+	utils.Executor
+	logger         *log.Logger
+	address        string
+	clientConfig   *ssh.ClientConfig
+	commandTimeout int // milliseconds
+	lock           sync.Mutex
+	client         *ssh.Client
+}
+
+func newSshExecutor(logger *log.Logger, sshConfig resources.SshConfig) (*sshExecutor, error) {
+	auth, err := sshAuthMethod(sshConfig)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := sshHostKeyCallback(sshConfig)
+	if err != nil {
+		return nil, err
+	}
+	commandTimeout := sshConfig.CommandTimeout
+	if commandTimeout <= 0 {
+		commandTimeout = DefaultSshCommandTimeout
+	}
+	clientConfig := &ssh.ClientConfig{
+		User:            sshConfig.User,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshDialTimeout,
+	}
+	return &sshExecutor{
+		Executor:       utils.NewExecutor(),
+		logger:         logger,
+		address:        net.JoinHostPort(sshConfig.Host, sshConfig.Port),
+		clientConfig:   clientConfig,
+		commandTimeout: commandTimeout * 1000,
+	}, nil
+}
+
+// sshAuthMethod return the key auth if KeyFile is set, else the password auth.
+// Without both it falls back to the default key of the user, as the ssh command does.
+func sshAuthMethod(sshConfig resources.SshConfig) (ssh.AuthMethod, error) {
+	keyFile := sshConfig.KeyFile
+	if keyFile == "" {
+		if sshConfig.Password != "" {
+			return ssh.Password(sshConfig.Password), nil
+		}
+		keyFile = filepath.Join(os.Getenv("HOME"), ".ssh", "id_rsa")
+	}
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read SSH key file %s: %s", keyFile, err.Error())
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse SSH key file %s: %s", keyFile, err.Error())
+	}
+	return ssh.PublicKeys(signer), nil
+}
+
+// sshHostKeyCallback accepts only the pinned HostKey if set, else the host keys in the known_hosts file of the user
+func sshHostKeyCallback(sshConfig resources.SshConfig) (ssh.HostKeyCallback, error) {
+	if sshConfig.HostKey == "" {
+		knownHostsFile := filepath.Join(os.Getenv("HOME"), ".ssh", "known_hosts")
+		callback, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("SSH host key is not configured and known hosts file %s cannot be used: %s", knownHostsFile, err.Error())
+		}
+		return callback, nil
+	}
+
+	var pinnedKeys [][]byte
+	rest := []byte(sshConfig.HostKey)
+	for len(bytes.TrimSpace(rest)) > 0 {
+		key, _, _, remaining, err := ssh.ParseAuthorizedKey(rest)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse SSH host key: %s", err.Error())
+		}
+		pinnedKeys = append(pinnedKeys, key.Marshal())
+		rest = remaining
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		for _, pinnedKey := range pinnedKeys {
+			if bytes.Equal(pinnedKey, key.Marshal()) {
+				return nil
+			}
+		}
+		return &sshHostKeyMismatchError{hostname, ssh.FingerprintSHA256(key)}
+	}, nil
+}
+
+func (e *sshExecutor) Execute(command string, args []string) ([]byte, error) {
+	return e.ExecuteWithTimeout(e.commandTimeout, command, args)
+}
+
+func (e *sshExecutor) ExecuteWithTimeout(mSeconds int, command string, args []string) ([]byte, error) {
+	session, err := e.newSession()
+	if err != nil {
+		e.logger.Printf("Failed to open SSH session to %s: %s", e.address, err.Error())
+		return nil, err
+	}
+	defer session.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	// the server stops the command by itself at the timeout, closing the session does not stop it on most SSH servers
+	timeout := time.Duration(mSeconds) * time.Millisecond
+	commandLine := sshCommandLine(command, args)
+	if err := session.Start(sshTimeoutCommandLine(timeout, commandLine)); err != nil {
+		e.logger.Printf("Failed to start command [%s] over SSH: %s", commandLine, err.Error())
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		e.logger.Printf("Command [%s] timeout reached, closing the SSH session", commandLine)
+		session.Close()
+		return nil, &SshCommandTimeoutError{Command: commandLine, Timeout: timeout, MayStillRun: true}
+	}
+	if exitErr, ok := err.(*ssh.ExitError); ok && exitErr.ExitStatus() == sshTimeoutExitStatus {
+		e.logger.Printf("Command [%s] was stopped by the server at the timeout", commandLine)
+		return nil, &SshCommandTimeoutError{Command: commandLine, Timeout: timeout}
+	}
+	e.logger.Printf("Command [%s] executed over SSH, error: %v, stderr: %s", commandLine, err, stderr.String())
+	return stdout.Bytes(), err
+}
+
+// newSession opens a session on the persistent connection, it reconnects once if the connection was lost
+func (e *sshExecutor) newSession() (*ssh.Session, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.client != nil {
+		session, err := e.client.NewSession()
+		if err == nil {
+			return session, nil
+		}
+		e.logger.Printf("SSH connection to %s was lost (%s), reconnecting", e.address, err.Error())
+		e.client.Close()
+		e.client = nil
+	}
+	client, err := ssh.Dial("tcp", e.address, e.clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	e.client = client
+	return client.NewSession()
+}
+
+// sshTimeoutCommandLine runs the command line under the timeout command of the server, rounded up to seconds
+func sshTimeoutCommandLine(timeout time.Duration, commandLine string) string {
+	seconds := int((timeout + time.Second - 1) / time.Second)
+	return fmt.Sprintf("timeout %d %s", seconds, commandLine)
+}
+
+// sshCommandLine quotes the args for the remote shell
+func sshCommandLine(command string, args []string) string {
+	words := []string{command}
+	for _, arg := range args {
+		if !sshSafeArg.MatchString(arg) {
+			arg = "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+type sshHostKeyMismatchError struct {
+	hostname    string
+	fingerprint string
+}
+
+func (e *sshHostKeyMismatchError) Error() string {
+	return fmt.Sprintf("SSH host key %s of %s does not match the configured host key", e.fingerprint, e.hostname)
+}
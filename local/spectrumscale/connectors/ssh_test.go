@@ -0,0 +1,323 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connectors_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/resources"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshTestServer is an in process SSH server that answers exec requests with the output of handler
+type sshTestServer struct {
+	listener    net.Listener
+	hostKey     ssh.Signer
+	lock        sync.Mutex
+	connections int
+	commands    []string
+	handler     func(command string) (stdout string, exitStatus uint32, delay time.Duration)
+}
+
+func newSshTestServer(password string, authorizedKey ssh.PublicKey) *sshTestServer {
+	hostKey, err := newEcdsaSigner()
+	Expect(err).ToNot(HaveOccurred())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	server := &sshTestServer{listener: listener, hostKey: hostKey}
+	server.handler = func(command string) (string, uint32, time.Duration) { return "", 0, 0 }
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() == "ubiquity" && string(pass) == password {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorizedKey != nil && bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	config.AddHostKey(hostKey)
+	go server.serve(config)
+	return server
+}
+
+func (s *sshTestServer) serve(config *ssh.ServerConfig) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, channels, requests, err := ssh.NewServerConn(conn, config)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			s.lock.Lock()
+			s.connections++
+			s.lock.Unlock()
+			go ssh.DiscardRequests(requests)
+			for newChannel := range channels {
+				if newChannel.ChannelType() != "session" {
+					newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+					continue
+				}
+				channel, requests, err := newChannel.Accept()
+				if err != nil {
+					continue
+				}
+				go s.session(channel, requests)
+			}
+		}()
+	}
+}
+
+func (s *sshTestServer) session(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for request := range requests {
+		if request.Type != "exec" {
+			request.Reply(false, nil)
+			continue
+		}
+		var exec struct{ Command string }
+		ssh.Unmarshal(request.Payload, &exec)
+		request.Reply(true, nil)
+		s.lock.Lock()
+		s.commands = append(s.commands, exec.Command)
+		handler := s.handler
+		s.lock.Unlock()
+
+		stdout, exitStatus, delay := handler(exec.Command)
+		time.Sleep(delay)
+		channel.Write([]byte(stdout))
+		channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{exitStatus}))
+		return
+	}
+}
+
+func (s *sshTestServer) Commands() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+func (s *sshTestServer) Connections() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.connections
+}
+
+func (s *sshTestServer) SetHandler(handler func(command string) (string, uint32, time.Duration)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handler = handler
+}
+
+func (s *sshTestServer) SshConfig() resources.SshConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return resources.SshConfig{
+		User:    "ubiquity",
+		Host:    host,
+		Port:    port,
+		HostKey: string(ssh.MarshalAuthorizedKey(s.hostKey.PublicKey())),
+	}
+}
+
+func newEcdsaSigner() (ssh.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.NewSignerFromKey(key)
+}
+
+var _ = Describe("spectrum_ssh", func() {
+	var (
+		logger      *log.Logger
+		server      *sshTestServer
+		sshConfig   resources.SshConfig
+		spectrumSSH connectors.SpectrumScaleConnector
+		err         error
+	)
+
+	BeforeEach(func() {
+		logger = log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+		server = newSshTestServer("secret", nil)
+		sshConfig = server.SshConfig()
+		sshConfig.Password = "secret"
+	})
+
+	AfterEach(func() {
+		server.listener.Close()
+	})
+
+	Context(".NewSpectrumSSH", func() {
+		It("should run the commands with sudo over one persistent connection", func() {
+			server.SetHandler(func(command string) (string, uint32, time.Duration) {
+				if strings.Contains(command, "mmlscluster") {
+					return "mmlscluster:clusterSummary:HEADER:version:reserved:reserved:clusterName:clusterId:\nmmlscluster:clusterSummary:0:1:::gpfs1.local:12345:\n", 0, 0
+				}
+				return "", 0, 0
+			})
+			spectrumSSH, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, err := spectrumSSH.GetClusterId()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal("12345"))
+			err = spectrumSSH.ExportNfs("/gpfs/fs1/fset1", []connectors.NfsClient{{Client: "*", AccessType: "RW", Squash: "no_root_squash"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.Commands()).To(Equal([]string{
+				"timeout 300 sudo /usr/lpp/mmfs/bin/mmlscluster -Y",
+				"timeout 300 sudo /usr/lpp/mmfs/bin/mmnfs export add /gpfs/fs1/fset1 --client '*(Access_Type=RW,Squash=no_root_squash)'",
+			}))
+			Expect(server.Connections()).To(Equal(1))
+		})
+		It("should authenticate with the key file", func() {
+			clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			signer, err := ssh.NewSignerFromKey(clientKey)
+			Expect(err).ToNot(HaveOccurred())
+			server.listener.Close()
+			server = newSshTestServer("", signer.PublicKey())
+
+			keyDir, err := ioutil.TempDir("", "ssh-key")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(keyDir)
+			der, err := x509.MarshalECPrivateKey(clientKey)
+			Expect(err).ToNot(HaveOccurred())
+			keyFile := filepath.Join(keyDir, "id_ecdsa")
+			err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			sshConfig = server.SshConfig()
+			sshConfig.KeyFile = keyFile
+			spectrumSSH, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			err = spectrumSSH.UnlinkFileset("fs1", "fset1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.Commands()).To(Equal([]string{"timeout 300 sudo /usr/lpp/mmfs/bin/mmunlinkfileset fs1 fset1"}))
+		})
+		It("should fail if the key file does not exist", func() {
+			sshConfig.KeyFile = "/no/such/key"
+			_, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail if the password is wrong", func() {
+			sshConfig.Password = "wrong"
+			spectrumSSH, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			err = spectrumSSH.DeleteFileset("fs1", "fset1")
+			Expect(err).To(HaveOccurred())
+			Expect(server.Commands()).To(BeEmpty())
+		})
+		It("should fail if the host key does not match the pinned host key", func() {
+			otherKey, err := newEcdsaSigner()
+			Expect(err).ToNot(HaveOccurred())
+			sshConfig.HostKey = string(ssh.MarshalAuthorizedKey(otherKey.PublicKey()))
+			spectrumSSH, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			err = spectrumSSH.DeleteFileset("fs1", "fset1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("does not match the configured host key"))
+			Expect(server.Commands()).To(BeEmpty())
+		})
+		It("should fail if the host key is invalid", func() {
+			sshConfig.HostKey = "ecdsa-sha2-nistp256 invalid"
+			_, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail if the command fails", func() {
+			server.SetHandler(func(command string) (string, uint32, time.Duration) { return "", 2, 0 })
+			spectrumSSH, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = spectrumSSH.GetClusterId()
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*ssh.ExitError)
+			Expect(ok).To(BeTrue())
+		})
+		It("should fail if the command timeout exceeds", func() {
+			server.SetHandler(func(command string) (string, uint32, time.Duration) { return "", 0, 3 * time.Second })
+			sshConfig.CommandTimeout = 1
+			spectrumSSH, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = spectrumSSH.GetClusterId()
+			Expect(err).To(HaveOccurred())
+			timeoutErr, ok := err.(*connectors.SshCommandTimeoutError)
+			Expect(ok).To(BeTrue())
+			Expect(timeoutErr.MayStillRun).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("may still be running"))
+			Expect(server.Commands()).To(Equal([]string{"timeout 1 sudo /usr/lpp/mmfs/bin/mmlscluster -Y"}))
+		})
+		It("should fail if the server stopped the command at the timeout", func() {
+			server.SetHandler(func(command string) (string, uint32, time.Duration) { return "", 124, 0 })
+			spectrumSSH, err = connectors.NewSpectrumSSH(logger, sshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = spectrumSSH.GetClusterId()
+			Expect(err).To(HaveOccurred())
+			timeoutErr, ok := err.(*connectors.SshCommandTimeoutError)
+			Expect(ok).To(BeTrue())
+			Expect(timeoutErr.MayStillRun).To(BeFalse())
+		})
+	})
+
+	Context(".NewSpectrumSSHWithExecutor", func() {
+		It("should execute the commands with sudo", func() {
+			fakeExec := new(fakes.FakeExecutor)
+			spectrumSSH, err = connectors.NewSpectrumSSHWithExecutor(logger, sshConfig, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+			err = spectrumSSH.SetFilesetQuota("fs1", "fset1", "1G")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(1))
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("sudo"))
+			Expect(args).To(Equal([]string{"/usr/lpp/mmfs/bin/mmsetquota", "fs1:fset1", "--block", "1G:1G"}))
+		})
+		It("should create a dependent fileset in the inode space of the given fileset", func() {
+			fakeExec := new(fakes.FakeExecutor)
+			spectrumSSH, err = connectors.NewSpectrumSSHWithExecutor(logger, sshConfig, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+			err = spectrumSSH.CreateFileset("fs1", "fset2", map[string]interface{}{connectors.UserSpecifiedInodeSpace: "fset1"})
+			Expect(err).ToNot(HaveOccurred())
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("sudo"))
+			Expect(args).To(Equal([]string{"/usr/lpp/mmfs/bin/mmcrfileset", "fs1", "fset2", "-t", "fileset for container volume", "--inode-space", "fset1"}))
+		})
+	})
+})
@@ -21,6 +21,7 @@ import (
 
 	"fmt"
 
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
 	"github.com/IBM/ubiquity/model"
 	"github.com/IBM/ubiquity/resources"
 	"github.com/jinzhu/gorm"
@@ -35,9 +36,17 @@ type SpectrumDataModel interface {
 	InsertFilesetVolume(fileset, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error
 	InsertLightweightVolume(fileset, directory, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error
 	InsertFilesetQuotaVolume(fileset, quota, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error
+	InsertLightweightQuotaVolume(fileset, directory, quotaFileset, quota, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error
 	GetVolume(name string) (SpectrumScaleVolume, bool, error)
 	ListVolumes() ([]resources.Volume, error)
 	UpdateVolumeMountpoint(name string, mountpoint string) error
+	UpdateVolumeQuota(name string, quota string) error
+	UpdateVolumeNfsClientConfig(name string, nfsClientConfig string) error
+	InsertAttachment(name string, host string) error
+	DeleteAttachment(name string, host string) error
+	ListAttachments(name string) ([]SpectrumScaleAttachment, error)
+	ListFilesetAttachments(filesystem string, fileset string) ([]SpectrumScaleAttachment, error)
+	ListFilesetVolumes(filesystem string, fileset string) ([]SpectrumScaleVolume, error)
 }
 
 type spectrumDataModel struct {
@@ -56,23 +65,41 @@ const (
 )
 
 const (
-	UserSpecifiedUID string = "uid"
-	UserSpecifiedGID string = "gid"
+	UserSpecifiedUID  string = "uid"
+	UserSpecifiedGID  string = "gid"
+	UserSpecifiedPool string = "pool"
 )
 
 type SpectrumScaleVolume struct {
-	ID            uint
-	Volume        resources.Volume
-	VolumeID      uint
-	Type          VolumeType
-	ClusterId     string
-	FileSystem    string
-	Fileset       string
-	Directory     string
-	UID           string
-	GID           string
-	Quota         string
-	IsPreexisting bool
+	ID              uint
+	Volume          resources.Volume
+	VolumeID        uint
+	Type            VolumeType
+	ClusterId       string
+	FileSystem      string
+	Fileset         string
+	Directory       string
+	UID             string
+	GID             string
+	Quota           string
+	QuotaFileset    string
+	Pool            string
+	AfmTarget       string
+	AfmMode         string
+	JunctionPath    string // relative to the filesystem mountpoint, "" for the default junction <mountpoint>/<fileset>
+	NfsClientConfig string // the clients of the NFS export of the volume in the syntax of mmnfs, "" if the volume is not exported
+	IsPreexisting   bool
+}
+
+// SpectrumScaleAttachment is a volume attached to a host. The filesystem and the fileset of the volume are kept with the
+// attachment, so that the volumes which share a fileset are found when the fileset is unlinked.
+type SpectrumScaleAttachment struct {
+	ID         uint
+	VolumeID   uint
+	VolumeName string
+	FileSystem string
+	Fileset    string
+	Host       string
 }
 
 func NewSpectrumDataModel(log *log.Logger, db *gorm.DB, backend string) SpectrumDataModel {
@@ -89,7 +116,7 @@ func (d *spectrumDataModel) CreateVolumeTable() error {
 	d.log.Println("SpectrumDataModel: Create Volumes Table start")
 	defer d.log.Println("SpectrumDataModel: Create Volumes Table end")
 
-	if err := d.database.AutoMigrate(&SpectrumScaleVolume{}).Error; err != nil {
+	if err := d.database.AutoMigrate(&SpectrumScaleVolume{}, &SpectrumScaleAttachment{}).Error; err != nil {
 		return err
 	}
 	return nil
@@ -111,6 +138,9 @@ func (d *spectrumDataModel) DeleteVolume(name string) error {
 	if err := d.database.Delete(&volume).Error; err != nil {
 		return err
 	}
+	if err := d.database.Where("volume_id = ?", volume.VolumeID).Delete(SpectrumScaleAttachment{}).Error; err != nil {
+		return err
+	}
 	if err := model.DeleteVolume(d.database, &volume.Volume).Error; err != nil {
 		return err
 	}
@@ -125,6 +155,9 @@ func (d *spectrumDataModel) InsertFilesetVolume(fileset, volumeName string, file
 		Fileset: fileset, IsPreexisting: isPreexisting}
 
 	addPermissionsForVolume(&volume, opts)
+	addPoolForVolume(&volume, opts)
+	addAfmForVolume(&volume, opts)
+	addJunctionPathForVolume(&volume, opts)
 
 	return d.insertVolume(volume)
 }
@@ -137,6 +170,9 @@ func (d *spectrumDataModel) InsertLightweightVolume(fileset, directory, volumeNa
 		Fileset: fileset, Directory: directory, IsPreexisting: isPreexisting}
 
 	addPermissionsForVolume(&volume, opts)
+	addPoolForVolume(&volume, opts)
+	addAfmForVolume(&volume, opts)
+	addJunctionPathForVolume(&volume, opts)
 
 	return d.insertVolume(volume)
 }
@@ -149,6 +185,24 @@ func (d *spectrumDataModel) InsertFilesetQuotaVolume(fileset, quota, volumeName
 		Fileset: fileset, Quota: quota, IsPreexisting: isPreexisting}
 
 	addPermissionsForVolume(&volume, opts)
+	addPoolForVolume(&volume, opts)
+	addAfmForVolume(&volume, opts)
+	addJunctionPathForVolume(&volume, opts)
+
+	return d.insertVolume(volume)
+}
+
+func (d *spectrumDataModel) InsertLightweightQuotaVolume(fileset, directory, quotaFileset, quota, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error {
+	d.log.Println("SpectrumDataModel: InsertLightweightQuotaVolume start")
+	defer d.log.Println("SpectrumDataModel: InsertLightweightQuotaVolume end")
+
+	volume := SpectrumScaleVolume{Volume: resources.Volume{Name: volumeName, Backend: d.backend}, Type: Lightweight, ClusterId: d.clusterId, FileSystem: filesystem,
+		Fileset: fileset, Directory: directory, QuotaFileset: quotaFileset, Quota: quota, IsPreexisting: isPreexisting}
+
+	addPermissionsForVolume(&volume, opts)
+	addPoolForVolume(&volume, opts)
+	addAfmForVolume(&volume, opts)
+	addJunctionPathForVolume(&volume, opts)
 
 	return d.insertVolume(volume)
 }
@@ -218,6 +272,48 @@ func (d *spectrumDataModel) UpdateVolumeMountpoint(name string, mountpoint strin
 	return nil
 }
 
+// UpdateVolumeQuota sets the quota of a volume, a fileset volume without quota becomes a fileset volume with quota
+func (d *spectrumDataModel) UpdateVolumeQuota(name string, quota string) error {
+	d.log.Println("SpectrumDataModel: UpdateVolumeQuota start")
+	defer d.log.Println("SpectrumDataModel: UpdateVolumeQuota end")
+
+	volume, exists, err := d.GetVolume(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &resources.VolumeNotFoundError{VolName: name}
+	}
+
+	updates := map[string]interface{}{"quota": quota}
+	if volume.Type == Fileset {
+		updates["type"] = FilesetWithQuota
+	}
+	if err = d.database.Model(&volume).Updates(updates).Error; err != nil {
+		return fmt.Errorf("Error updating quota of volume %s to %s: %s", name, quota, err.Error())
+	}
+	return nil
+}
+
+// UpdateVolumeNfsClientConfig records the clients of the NFS export of a volume
+func (d *spectrumDataModel) UpdateVolumeNfsClientConfig(name string, nfsClientConfig string) error {
+	d.log.Println("SpectrumDataModel: UpdateVolumeNfsClientConfig start")
+	defer d.log.Println("SpectrumDataModel: UpdateVolumeNfsClientConfig end")
+
+	volume, exists, err := d.GetVolume(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &resources.VolumeNotFoundError{VolName: name}
+	}
+
+	if err = d.database.Model(&volume).Update("nfs_client_config", nfsClientConfig).Error; err != nil {
+		return fmt.Errorf("Error updating NFS clients of volume %s to %s: %s", name, nfsClientConfig, err.Error())
+	}
+	return nil
+}
+
 func addPermissionsForVolume(volume *SpectrumScaleVolume, opts map[string]interface{}) {
 
 	if len(opts) > 0 {
@@ -231,3 +327,95 @@ func addPermissionsForVolume(volume *SpectrumScaleVolume, opts map[string]interf
 		}
 	}
 }
+
+func addAfmForVolume(volume *SpectrumScaleVolume, opts map[string]interface{}) {
+	if afmTarget, afmTargetSpecified := opts[connectors.UserSpecifiedAfmTarget]; afmTargetSpecified {
+		volume.AfmTarget = afmTarget.(string)
+		volume.AfmMode, _ = opts[connectors.UserSpecifiedAfmMode].(string)
+	}
+}
+
+func addPoolForVolume(volume *SpectrumScaleVolume, opts map[string]interface{}) {
+	if pool, poolSpecified := opts[UserSpecifiedPool]; poolSpecified {
+		volume.Pool = pool.(string)
+	}
+}
+
+func addJunctionPathForVolume(volume *SpectrumScaleVolume, opts map[string]interface{}) {
+	if junctionPath, junctionPathSpecified := opts[JunctionPath]; junctionPathSpecified {
+		volume.JunctionPath = junctionPath.(string)
+	}
+}
+
+// InsertAttachment records that the volume is attached to the host, a volume is attached once to each host
+func (d *spectrumDataModel) InsertAttachment(name string, host string) error {
+	d.log.Println("SpectrumDataModel: InsertAttachment start")
+	defer d.log.Println("SpectrumDataModel: InsertAttachment end")
+
+	volume, exists, err := d.GetVolume(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &resources.VolumeNotFoundError{VolName: name}
+	}
+
+	attachment := SpectrumScaleAttachment{VolumeID: volume.VolumeID, VolumeName: name, FileSystem: volume.FileSystem, Fileset: volume.Fileset, Host: host}
+	return d.database.Where("volume_id = ? AND host = ?", volume.VolumeID, host).FirstOrCreate(&attachment).Error
+}
+
+func (d *spectrumDataModel) DeleteAttachment(name string, host string) error {
+	d.log.Println("SpectrumDataModel: DeleteAttachment start")
+	defer d.log.Println("SpectrumDataModel: DeleteAttachment end")
+
+	volume, exists, err := d.GetVolume(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &resources.VolumeNotFoundError{VolName: name}
+	}
+	return d.database.Where("volume_id = ? AND host = ?", volume.VolumeID, host).Delete(SpectrumScaleAttachment{}).Error
+}
+
+func (d *spectrumDataModel) ListAttachments(name string) ([]SpectrumScaleAttachment, error) {
+	d.log.Println("SpectrumDataModel: ListAttachments start")
+	defer d.log.Println("SpectrumDataModel: ListAttachments end")
+
+	volume, exists, err := d.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &resources.VolumeNotFoundError{VolName: name}
+	}
+	var attachments []SpectrumScaleAttachment
+	if err := d.database.Where("volume_id = ?", volume.VolumeID).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// ListFilesetAttachments return the attachments of all the volumes in the fileset, of all the backends
+func (d *spectrumDataModel) ListFilesetAttachments(filesystem string, fileset string) ([]SpectrumScaleAttachment, error) {
+	d.log.Println("SpectrumDataModel: ListFilesetAttachments start")
+	defer d.log.Println("SpectrumDataModel: ListFilesetAttachments end")
+
+	var attachments []SpectrumScaleAttachment
+	if err := d.database.Where("file_system = ? AND fileset = ?", filesystem, fileset).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// ListFilesetVolumes return the volumes in the fileset, of all the backends
+func (d *spectrumDataModel) ListFilesetVolumes(filesystem string, fileset string) ([]SpectrumScaleVolume, error) {
+	d.log.Println("SpectrumDataModel: ListFilesetVolumes start")
+	defer d.log.Println("SpectrumDataModel: ListFilesetVolumes end")
+
+	var volumes []SpectrumScaleVolume
+	if err := d.database.Where("file_system = ? AND fileset = ?", filesystem, fileset).Preload("Volume").Find(&volumes).Error; err != nil {
+		return nil, err
+	}
+	return volumes, nil
+}
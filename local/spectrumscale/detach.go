@@ -0,0 +1,148 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spectrumscale
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IBM/ubiquity/utils"
+)
+
+/*
+The detach policy sets which filesets are unlinked when a volume is detached from a host:
+
+	keep-linked    the filesets stay linked, Attach links them if needed (default)
+	unlink-unused  the filesets ubiquity created for the volumes are unlinked once no host uses them
+	always-unlink  the filesets of the volumes from existing filesets are unlinked once no host uses them as well
+
+The attachments of the volumes to the hosts are kept in the DB, and a fileset is never unlinked while a volume of the
+fileset is attached to a host. The volumes attached before the attachments were kept have a mountpoint but no
+attachment, they are considered attached to an unknown host until they are detached. The parent filesets of
+lightweight volumes are never unlinked, since other volumes and the dependent filesets of lightweight volumes with quota
+are linked in them.
+*/
+
+const (
+	DetachPolicyKeepLinked   string = "keep-linked"
+	DetachPolicyUnlinkUnused string = "unlink-unused"
+	DetachPolicyAlwaysUnlink string = "always-unlink"
+)
+
+// legacyAttachmentHost is the host of the attachments of the volumes attached before the attachments were kept
+const legacyAttachmentHost = "unknown host (attached before the upgrade)"
+
+var detachPolicies = []string{DetachPolicyKeepLinked, DetachPolicyUnlinkUnused, DetachPolicyAlwaysUnlink}
+
+func validateDetachPolicy(detachPolicy string) error {
+	if detachPolicy != "" && !utils.StringInSlice(detachPolicy, detachPolicies) {
+		return fmt.Errorf("Unknown detach policy %s, supported policies are %s", detachPolicy, strings.Join(detachPolicies, ", "))
+	}
+	return nil
+}
+
+// unlinkUnusedFilesets unlinks the filesets of a detached volume which the detach policy unlinks and no host uses
+func (s *spectrumLocalClient) unlinkUnusedFilesets(volume SpectrumScaleVolume) error {
+	if s.config.DetachPolicy == "" || s.config.DetachPolicy == DetachPolicyKeepLinked {
+		return nil
+	}
+
+	if volume.Type == Lightweight {
+		if volume.QuotaFileset == "" {
+			return nil
+		}
+		// the dependent fileset of a lightweight volume with quota is used by the volume only
+		attachments, err := s.dataModel.ListAttachments(volume.Volume.Name)
+		if err != nil {
+			return err
+		}
+		if len(attachments) > 0 {
+			return nil
+		}
+		return s.unlinkFilesetIfLinked(volume.FileSystem, volume.QuotaFileset)
+	}
+
+	if volume.IsPreexisting && s.config.DetachPolicy != DetachPolicyAlwaysUnlink {
+		return nil
+	}
+	attachments, err := s.listFilesetAttachments(volume)
+	if err != nil {
+		return err
+	}
+	if len(attachments) > 0 {
+		s.logger.Printf("Fileset %s stays linked, volume %s is attached to host %s\n", volume.Fileset, attachments[0].VolumeName, attachments[0].Host)
+		return nil
+	}
+	return s.unlinkFilesetIfLinked(volume.FileSystem, volume.Fileset)
+}
+
+// isFilesetUsedByOtherVolumes return true if a volume which shares the fileset of the volume is attached to a host
+func (s *spectrumLocalClient) isFilesetUsedByOtherVolumes(volume SpectrumScaleVolume) (bool, error) {
+	attachments, err := s.listFilesetAttachments(volume)
+	if err != nil {
+		return false, err
+	}
+	for _, attachment := range attachments {
+		if attachment.VolumeName != volume.Volume.Name {
+			s.logger.Printf("Fileset %s stays linked, volume %s is attached to host %s\n", volume.Fileset, attachment.VolumeName, attachment.Host)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listFilesetAttachments return the attachments of the volumes in the fileset of the volume, including the legacy
+// attachments of the volumes that have a mountpoint without attachments
+func (s *spectrumLocalClient) listFilesetAttachments(volume SpectrumScaleVolume) ([]SpectrumScaleAttachment, error) {
+	attachments, err := s.dataModel.ListFilesetAttachments(volume.FileSystem, volume.Fileset)
+	if err != nil {
+		return nil, err
+	}
+	filesetVolumes, err := s.dataModel.ListFilesetVolumes(volume.FileSystem, volume.Fileset)
+	if err != nil {
+		return nil, err
+	}
+	for _, filesetVolume := range filesetVolumes {
+		if filesetVolume.Volume.Mountpoint == "" || hasAttachment(attachments, filesetVolume.Volume.Name) {
+			continue
+		}
+		attachments = append(attachments, SpectrumScaleAttachment{VolumeID: filesetVolume.VolumeID, VolumeName: filesetVolume.Volume.Name,
+			FileSystem: filesetVolume.FileSystem, Fileset: filesetVolume.Fileset, Host: legacyAttachmentHost})
+	}
+	return attachments, nil
+}
+
+func hasAttachment(attachments []SpectrumScaleAttachment, volumeName string) bool {
+	for _, attachment := range attachments {
+		if attachment.VolumeName == volumeName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *spectrumLocalClient) unlinkFilesetIfLinked(filesystem, filesetName string) error {
+	isFilesetLinked, err := s.connector.IsFilesetLinked(filesystem, filesetName)
+	if err != nil {
+		return err
+	}
+	if !isFilesetLinked {
+		return nil
+	}
+	s.logger.Printf("Unlinking unused fileset %s\n", filesetName)
+	return s.connector.UnlinkFileset(filesystem, filesetName)
+}
@@ -0,0 +1,200 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spectrumscale
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+/*
+The junction of a fileset is the directory at which it is linked in the filesystem namespace. By default ubiquity links
+a fileset at <filesystem mountpoint>/<fileset>, a junction path template lays the filesets out in another directory
+convention of the filesystem, e.g. with the template
+
+	projects/{tenant}/{volume}
+
+the fileset of the volume vol1 with the opt tenant=team1 is linked at <filesystem mountpoint>/projects/team1/vol1.
+The junction of every volume is recorded relative to the mountpoint, so that the volumes keep their path when the
+template changes. A volume from an existing fileset which is already linked keeps the junction of the fileset.
+*/
+
+const (
+	JunctionVolumePlaceholder  string = "{volume}"
+	JunctionFilesetPlaceholder string = "{fileset}"
+	JunctionTenantPlaceholder  string = "{tenant}"
+
+	UserSpecifiedTenant string = "tenant"
+)
+
+var junctionPlaceholderRegexp = regexp.MustCompile(`\{[^}]*\}`)
+
+// validateJunctionPathTemplate checks that the template is a path in the filesystem which is unique for every volume
+func validateJunctionPathTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if path.IsAbs(template) {
+		return fmt.Errorf("Invalid junction path template %s: the template must be relative to the filesystem mountpoint", template)
+	}
+	for _, element := range strings.Split(template, "/") {
+		if element == ".." {
+			return fmt.Errorf("Invalid junction path template %s: the template cannot refer to a parent directory", template)
+		}
+	}
+	for _, placeholder := range junctionPlaceholderRegexp.FindAllString(template, -1) {
+		if placeholder != JunctionVolumePlaceholder && placeholder != JunctionFilesetPlaceholder && placeholder != JunctionTenantPlaceholder {
+			return fmt.Errorf("Invalid junction path template %s: unknown placeholder %s", template, placeholder)
+		}
+	}
+	if !strings.Contains(template, JunctionVolumePlaceholder) && !strings.Contains(template, JunctionFilesetPlaceholder) {
+		return fmt.Errorf("Invalid junction path template %s: the template must contain %s or %s", template, JunctionVolumePlaceholder, JunctionFilesetPlaceholder)
+	}
+	return nil
+}
+
+// renderJunctionPath return the junction of a fileset relative to the filesystem mountpoint
+func renderJunctionPath(template, volumeName, filesetName, tenant string) string {
+	replacer := strings.NewReplacer(JunctionVolumePlaceholder, volumeName, JunctionFilesetPlaceholder, filesetName, JunctionTenantPlaceholder, tenant)
+	return path.Clean(replacer.Replace(template))
+}
+
+// newJunctionPath return the junction of a new fileset of the volume from the configured template,
+// or "" if the fileset is linked at the default junction
+func (s *spectrumLocalClient) newJunctionPath(name, filesetName string, opts map[string]interface{}) (string, error) {
+	if s.config.JunctionPathTemplate == "" {
+		return "", nil
+	}
+	tenant, _ := opts[UserSpecifiedTenant].(string)
+	if tenant == "" && strings.Contains(s.config.JunctionPathTemplate, JunctionTenantPlaceholder) {
+		return "", fmt.Errorf("'tenant' is a required opt for the junction path template %s", s.config.JunctionPathTemplate)
+	}
+	return renderJunctionPath(s.config.JunctionPathTemplate, name, filesetName, tenant), nil
+}
+
+// validateTenant checks that the tenant is used by the template and is a single directory of the junction
+func (s *spectrumLocalClient) validateTenant(opts map[string]interface{}) error {
+	tenant, tenantSpecified := opts[UserSpecifiedTenant]
+	if !tenantSpecified {
+		return nil
+	}
+	if !strings.Contains(s.config.JunctionPathTemplate, JunctionTenantPlaceholder) {
+		return fmt.Errorf("'tenant' is supported with a junction path template which contains %s only", JunctionTenantPlaceholder)
+	}
+	if tenant.(string) == "" || tenant.(string) == "." || tenant.(string) == ".." || strings.Contains(tenant.(string), "/") {
+		return fmt.Errorf("Invalid 'tenant' = %s specified", tenant.(string))
+	}
+	return nil
+}
+
+// linkedJunctionPath return the junction of a linked fileset relative to the filesystem mountpoint,
+// or "" if the fileset is not linked
+func (s *spectrumLocalClient) linkedJunctionPath(filesystem, filesetName string) (string, error) {
+	fileset, err := s.connector.ListFileset(filesystem, filesetName)
+	if err != nil {
+		return "", err
+	}
+	if fileset.Mountpoint == "" || fileset.Mountpoint == "--" {
+		return "", nil
+	}
+	mountpoint, err := s.connector.GetFilesystemMountpoint(filesystem)
+	if err != nil {
+		return "", err
+	}
+	junction := path.Clean(fileset.Mountpoint)
+	if junction == path.Clean(mountpoint) {
+		return ".", nil
+	}
+	if !strings.HasPrefix(junction, path.Clean(mountpoint)+"/") {
+		return "", fmt.Errorf("Fileset %s is linked at %s outside of the mountpoint %s of filesystem %s", filesetName, junction, mountpoint, filesystem)
+	}
+	return strings.TrimPrefix(junction, path.Clean(mountpoint)+"/"), nil
+}
+
+// existingJunctionPath return the junction of an existing fileset, from the template if it is not linked
+func (s *spectrumLocalClient) existingJunctionPath(filesystem, name, filesetName string, opts map[string]interface{}) (string, error) {
+	junctionPath, err := s.linkedJunctionPath(filesystem, filesetName)
+	if err != nil {
+		return "", err
+	}
+	if junctionPath != "" {
+		s.logger.Printf("Fileset %s is linked at %s\n", filesetName, junctionPath)
+		return junctionPath, nil
+	}
+	return s.newJunctionPath(name, filesetName, opts)
+}
+
+// getParentFilesetJunction return the junction of the linked parent fileset of a lightweight volume,
+// both relative to the filesystem mountpoint and as a path
+func (s *spectrumLocalClient) getParentFilesetJunction(filesystem, filesetName string) (string, string, error) {
+	junctionPath, err := s.linkedJunctionPath(filesystem, filesetName)
+	if err != nil {
+		return "", "", err
+	}
+	filesetJunction, err := s.getFilesetJunction(SpectrumScaleVolume{FileSystem: filesystem, Fileset: filesetName, JunctionPath: junctionPath})
+	if err != nil {
+		return "", "", err
+	}
+	return junctionPath, filesetJunction, nil
+}
+
+// getFilesetJunction return the path at which the fileset of the volume is linked
+func (s *spectrumLocalClient) getFilesetJunction(volume SpectrumScaleVolume) (string, error) {
+	mountpoint, err := s.connector.GetFilesystemMountpoint(volume.FileSystem)
+	if err != nil {
+		return "", err
+	}
+	if volume.JunctionPath == "" {
+		return path.Join(mountpoint, volume.Fileset), nil
+	}
+	return path.Join(mountpoint, volume.JunctionPath), nil
+}
+
+// linkVolumeFileset links the fileset of the volume at its junction, and creates the parent directories of the junction.
+// A fileset is always unlinked by name, wherever it is linked.
+func (s *spectrumLocalClient) linkVolumeFileset(volume SpectrumScaleVolume) error {
+	if volume.JunctionPath == "" {
+		return s.connector.LinkFileset(volume.FileSystem, volume.Fileset)
+	}
+	junction, err := s.getFilesetJunction(volume)
+	if err != nil {
+		return err
+	}
+	args := []string{"-p", path.Dir(junction)}
+	_, err = s.executor.Execute("mkdir", args)
+	if err != nil {
+		s.logger.Printf("Failed to create the parent directory of junction %s: %s", junction, err.Error())
+		return err
+	}
+	return s.connector.LinkFilesetAt(volume.FileSystem, volume.Fileset, junction)
+}
+
+// setJunctionPathOpt passes the junction of the volume to the data model in the opts,
+// the client always sets it so that users cannot choose the junction with an opt
+func setJunctionPathOpt(opts map[string]interface{}, junctionPath string) map[string]interface{} {
+	if junctionPath == "" {
+		delete(opts, JunctionPath)
+		return opts
+	}
+	if opts == nil {
+		opts = make(map[string]interface{})
+	}
+	opts[JunctionPath] = junctionPath
+	return opts
+}
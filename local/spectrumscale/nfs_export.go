@@ -0,0 +1,160 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spectrumscale
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/utils"
+)
+
+/*
+The NFS export of a volume has one or more clients, each with its own export options. The clients are set by the opts:
+
+	nfsClients       the client specs separated by ';', e.g. 10.0.0.0/24;host1, a new client has read-write access
+	nfsClientConfig  the clients with their options in the syntax of mmnfs, e.g. 10.0.0.0/24(Access_Type=RW);*(Access_Type=RO)
+	nfsAccessType    the access type of all the clients, e.g. RO
+	nfsSquash        the squash mode of all the clients, e.g. root_squash
+	nfsSecType       the security flavours of all the clients separated by ':', e.g. krb5:krb5p
+	nfsProtocols     the NFS protocol versions of all the clients separated by ':', e.g. 3:4
+
+nfsClients takes precedence over nfsClientConfig, which is also set by the plugin for the volumes without their own
+clients. The other opts override the options of every client. The clients of the export are validated before the
+export is created, and recorded with the volume, so that the export of an existing volume is updated from its clients.
+*/
+
+const (
+	UserSpecifiedNfsClientConfig string = "nfsClientConfig"
+	UserSpecifiedNfsClients      string = "nfsClients"
+	UserSpecifiedNfsAccessType   string = "nfsAccessType"
+	UserSpecifiedNfsSquash       string = "nfsSquash"
+	UserSpecifiedNfsSecType      string = "nfsSecType"
+	UserSpecifiedNfsProtocols    string = "nfsProtocols"
+
+	defaultNfsAccessType string = "RW"
+)
+
+var nfsExportOpts = []string{UserSpecifiedNfsClientConfig, UserSpecifiedNfsClients, UserSpecifiedNfsAccessType, UserSpecifiedNfsSquash,
+	UserSpecifiedNfsSecType, UserSpecifiedNfsProtocols}
+
+// splitNfsExportOpts return the NFS export opts and the other opts of a volume
+func splitNfsExportOpts(opts map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	nfsOpts := make(map[string]interface{})
+	otherOpts := make(map[string]interface{})
+	for k, v := range opts {
+		if utils.StringInSlice(k, nfsExportOpts) {
+			nfsOpts[k] = v
+		} else {
+			otherOpts[k] = v
+		}
+	}
+	return nfsOpts, otherOpts
+}
+
+// nfsClientsFromOpts return the validated clients of the export from the NFS export opts,
+// the options of the current clients of an existing export are kept unless the opts override them
+func nfsClientsFromOpts(nfsOpts map[string]interface{}, currentClients []connectors.NfsClient) ([]connectors.NfsClient, error) {
+	stringOpts := make(map[string]string)
+	for k, v := range nfsOpts {
+		value, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("Invalid '%s' = %v specified", k, v)
+		}
+		stringOpts[k] = value
+	}
+
+	var clients []connectors.NfsClient
+	if clientSpecs, specified := stringOpts[UserSpecifiedNfsClients]; specified {
+		for _, spec := range strings.Split(clientSpecs, ";") {
+			if spec = strings.TrimSpace(spec); spec == "" {
+				continue
+			}
+			clients = append(clients, nfsClientOrDefault(currentClients, spec))
+		}
+	} else if clientConfig, specified := stringOpts[UserSpecifiedNfsClientConfig]; specified {
+		parsedClients, err := connectors.ParseNfsClients(clientConfig)
+		if err != nil {
+			return nil, err
+		}
+		clients = parsedClients
+	} else {
+		for _, client := range currentClients {
+			clients = append(clients, copyNfsClient(client))
+		}
+	}
+
+	for i := range clients {
+		if accessType, specified := stringOpts[UserSpecifiedNfsAccessType]; specified {
+			clients[i].AccessType = accessType
+		}
+		if squash, specified := stringOpts[UserSpecifiedNfsSquash]; specified {
+			clients[i].Squash = squash
+		}
+		if secTypes, specified := stringOpts[UserSpecifiedNfsSecType]; specified {
+			clients[i].SecTypes = strings.Split(secTypes, ":")
+		}
+		if protocols, specified := stringOpts[UserSpecifiedNfsProtocols]; specified {
+			clients[i].Protocols = strings.Split(protocols, ":")
+		}
+	}
+
+	if err := connectors.ValidateNfsClients(clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+func nfsClientOrDefault(clients []connectors.NfsClient, name string) connectors.NfsClient {
+	for _, client := range clients {
+		if client.Client == name {
+			return copyNfsClient(client)
+		}
+	}
+	return connectors.NfsClient{Client: name, AccessType: defaultNfsAccessType}
+}
+
+func copyNfsClient(client connectors.NfsClient) connectors.NfsClient {
+	client.SecTypes = append([]string(nil), client.SecTypes...)
+	client.Protocols = append([]string(nil), client.Protocols...)
+	return client
+}
+
+// diffNfsClients return the clients to add to an export, the clients whose options change and the clients to remove
+func diffNfsClients(currentClients, clients []connectors.NfsClient) ([]connectors.NfsClient, []connectors.NfsClient, []connectors.NfsClient) {
+	current := make(map[string]connectors.NfsClient)
+	for _, client := range currentClients {
+		current[client.Client] = client
+	}
+	var addClients, changeClients, removeClients []connectors.NfsClient
+	for _, client := range clients {
+		currentClient, exists := current[client.Client]
+		if !exists {
+			addClients = append(addClients, client)
+		} else if currentClient.String() != client.String() {
+			changeClients = append(changeClients, client)
+		}
+		delete(current, client.Client)
+	}
+	for _, client := range currentClients {
+		if _, removed := current[client.Client]; removed {
+			removeClients = append(removeClients, client)
+		}
+	}
+	return addClients, changeClients, removeClients
+}
@@ -0,0 +1,1087 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Description
+An in-process Spectrum Scale management REST API (scalemgmt/v2) server for integration tests.
+It models filesystems, storage pools and placement policies, linked and unlinked filesets, AFM cache filesets, fileset quotas and usage,
+filesystem capacity, NFS exports, SMB shares and asynchronous jobs.
+The filesystems are directories in a temp directory, a fileset is linked by a symlink from its junction path
+to its own data directory, so the paths the clients work with exist.
+*/
+package simulator
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	"github.com/gorilla/mux"
+)
+
+const (
+	apiPrefix = "/scalemgmt/v2"
+
+	JobStatusRunning   = connectors.JobStatusRunning
+	JobStatusCompleted = connectors.JobStatusCompleted
+	JobStatusFailed    = connectors.JobStatusFailed
+	JobStatusCancelled = connectors.JobStatusCancelled
+
+	FilesetStatusLinked   = "Linked"
+	FilesetStatusUnlinked = "Unlinked"
+	UnlinkedFilesetPath   = "--" // the path Spectrum Scale reports for an unlinked fileset
+	RootFileset           = "root"
+	AfmStateInactive      = "Inactive" // the cache state of an AFM fileset that was not accessed yet
+	AfmStateActive        = "Active"
+	SystemPool            = "system"
+)
+
+// Fault makes the next Count requests that match Method and Path fail.
+// Path is the url after scalemgmt/v2/ (e.g filesystems/gold/filesets), an empty Method or Path matches everything.
+// If JobError is set the request is accepted, but its job fails with JobError as stderr and changes nothing.
+// Otherwise the request fails with StatusCode.
+type Fault struct {
+	Method     string
+	Path       string
+	StatusCode int
+	JobError   string
+	Count      int
+}
+
+// Server is the Spectrum Scale simulator. All its methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+	ClusterId uint64
+	JobPolls  int // number of job queries that report a job as RUNNING before it completes, a negative value never completes it
+	SizeKB    int // the size of every filesystem, split evenly between its pools, its free space is the size less the data of its filesets
+
+	lock        sync.Mutex
+	user        string
+	password    string
+	root        string
+	caFile      string // the PEM certificate of the server, to verify it with
+	nodes       []string
+	filesystems map[string]*filesystem
+	exports     map[string][]string // path -> nfs clients
+	shares      map[string]*share   // share name -> smb share
+	jobs        map[uint64]*job
+	faults      []*Fault
+	requests    []string
+	lastId      int
+}
+
+type filesystem struct {
+	mountpoint string
+	filesets   map[string]*fileset
+	pools      []string
+	policy     string // the installed policy rules, empty if no policy is installed
+}
+
+type fileset struct {
+	config  connectors.FilesetConfig_v2
+	afm     connectors.AFM // the AFM attributes of an AFM cache fileset, its target is empty for other filesets
+	data    string         // the directory that holds the fileset data, the junction path links to it
+	quotaKB int
+}
+
+type share struct {
+	path    string
+	options map[string]string
+}
+
+type job struct {
+	job       connectors.Job
+	pollsLeft int
+	err       string          // stderr of a failed job, set when the job is submitted by a fault
+	action    func() []string // applied when the job completes, return the stderr if the job failed
+}
+
+// NewServer starts a simulator that accepts the given user, backed by a new temp directory
+func NewServer(user string, password string) (*Server, error) {
+	root, err := ioutil.TempDir("", "spectrum-scale-simulator")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ClusterId:   7118073361626808055,
+		SizeKB:      100 * 1024 * 1024,
+		user:        user,
+		password:    password,
+		root:        root,
+		filesystems: make(map[string]*filesystem),
+		exports:     make(map[string][]string),
+		shares:      make(map[string]*share),
+		jobs:        make(map[uint64]*job),
+	}
+	s.Server = httptest.NewTLSServer(s.newRouter())
+	s.caFile = filepath.Join(root, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Server.TLS.Certificates[0].Certificate[0]})
+	if err = ioutil.WriteFile(s.caFile, cert, 0600); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close stops the server and removes its temp directory
+func (s *Server) Close() {
+	s.Server.Close()
+	os.RemoveAll(s.root)
+}
+
+// RestConfig return the connector configuration for the simulator, as the node with the given host name.
+// The connector verifies the server certificate (verify-full).
+func (s *Server) RestConfig(hostname string) resources.RestConfig {
+	return resources.RestConfig{Endpoint: s.URL, User: s.user, Password: s.password, Hostname: hostname,
+		SslMode: resources.SslModeVerifyFull, CACertFile: s.caFile}
+}
+
+// AddNode adds a cluster node (the admin node name)
+func (s *Server) AddNode(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.nodes = append(s.nodes, name)
+}
+
+// AddFilesystem creates a mounted filesystem with its root fileset and return its mount point
+func (s *Server) AddFilesystem(name string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, exists := s.filesystems[name]; exists {
+		return "", fmt.Errorf("filesystem [%s] already exists", name)
+	}
+	mountpoint := filepath.Join(s.root, name)
+	if err := os.MkdirAll(filepath.Join(s.root, ".filesets", name), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Mkdir(mountpoint, 0755); err != nil {
+		return "", err
+	}
+	fs := &filesystem{mountpoint: mountpoint, filesets: make(map[string]*fileset), pools: []string{SystemPool}}
+	fs.filesets[RootFileset] = &fileset{
+		config: connectors.FilesetConfig_v2{FilesetName: RootFileset, FilesystemName: name, Path: mountpoint, Status: FilesetStatusLinked},
+		data:   mountpoint,
+	}
+	s.filesystems[name] = fs
+	return mountpoint, nil
+}
+
+// AddFileset creates an unlinked fileset, e.g a fileset created outside ubiquity
+func (s *Server) AddFileset(filesystemName string, filesetName string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, exists := s.filesystems[filesystemName]
+	if !exists {
+		return fmt.Errorf("filesystem [%s] does not exist", filesystemName)
+	}
+	return s.createFileset(filesystemName, fs, connectors.CreateFilesetRequest{FilesetName: filesetName})
+}
+
+// AddPool adds a storage pool to the filesystem
+func (s *Server) AddPool(filesystemName string, poolName string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, exists := s.filesystems[filesystemName]
+	if !exists {
+		return fmt.Errorf("filesystem [%s] does not exist", filesystemName)
+	}
+	fs.pools = append(fs.pools, poolName)
+	return nil
+}
+
+// Policy return the policy installed in the filesystem, empty if none is installed
+func (s *Server) Policy(filesystemName string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if fs, exists := s.filesystems[filesystemName]; exists {
+		return fs.policy
+	}
+	return ""
+}
+
+// Fileset return the fileset config, and false if it does not exist
+func (s *Server) Fileset(filesystemName string, filesetName string) (connectors.FilesetConfig_v2, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if fs, exists := s.filesystems[filesystemName]; exists {
+		if fset, exists := fs.filesets[filesetName]; exists {
+			return fset.config, true
+		}
+	}
+	return connectors.FilesetConfig_v2{}, false
+}
+
+// FilesetQuota return the block quota of the fileset in KB, 0 if not set
+func (s *Server) FilesetQuota(filesystemName string, filesetName string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if fs, exists := s.filesystems[filesystemName]; exists {
+		if fset, exists := fs.filesets[filesetName]; exists {
+			return fset.quotaKB
+		}
+	}
+	return 0
+}
+
+// Exports return the NFS exports, path -> clients
+func (s *Server) Exports() map[string][]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	exports := make(map[string][]string)
+	for path, clients := range s.exports {
+		exports[path] = append([]string{}, clients...)
+	}
+	return exports
+}
+
+// Shares return the paths of the SMB shares, share name -> path
+func (s *Server) Shares() map[string]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	shares := make(map[string]string)
+	for name, smbShare := range s.shares {
+		shares[name] = smbShare.path
+	}
+	return shares
+}
+
+// ShareOptions return the SMB options of a share, nil if the share does not exist
+func (s *Server) ShareOptions(name string) map[string]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	smbShare, exists := s.shares[name]
+	if !exists {
+		return nil
+	}
+	options := make(map[string]string)
+	for key, value := range smbShare.options {
+		options[key] = value
+	}
+	return options
+}
+
+// Jobs return all the submitted jobs ordered by id
+func (s *Server) Jobs() []connectors.Job {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	jobs := []connectors.Job{}
+	for _, j := range s.jobs {
+		jobs = append(jobs, j.job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].JobID < jobs[j].JobID })
+	return jobs
+}
+
+// InjectFault adds a fault, faults are matched in the order they were added
+func (s *Server) InjectFault(fault Fault) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if fault.Count <= 0 {
+		fault.Count = 1
+	}
+	s.faults = append(s.faults, &fault)
+}
+
+// Requests return the requests the simulator got, e.g "POST filesystems/gold/filesets"
+func (s *Server) Requests() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) newRouter() http.Handler {
+	router := mux.NewRouter().UseEncodedPath()
+	api := router.PathPrefix(apiPrefix).Subrouter()
+	api.HandleFunc("/info", s.getInfo).Methods("GET")
+	api.HandleFunc("/cluster", s.getCluster).Methods("GET")
+	api.HandleFunc("/nodes", s.getNodes).Methods("GET")
+	api.HandleFunc("/jobs", s.getJobs).Methods("GET")
+	api.HandleFunc("/jobs/{jobId}/cancel", s.cancelJob).Methods("PUT")
+	api.HandleFunc("/filesystems", s.getFilesystems).Methods("GET")
+	api.HandleFunc("/filesystems/{fs}", s.getFilesystems).Methods("GET")
+	api.HandleFunc("/filesystems/{fs}/filesets", s.getFilesets).Methods("GET")
+	api.HandleFunc("/filesystems/{fs}/filesets", s.postFileset).Methods("POST")
+	api.HandleFunc("/filesystems/{fs}/filesets/{fileset}", s.getFilesets).Methods("GET")
+	api.HandleFunc("/filesystems/{fs}/filesets/{fileset}", s.deleteFileset).Methods("DELETE")
+	api.HandleFunc("/filesystems/{fs}/filesets/{fileset}/link", s.postLink).Methods("POST")
+	api.HandleFunc("/filesystems/{fs}/filesets/{fileset}/link", s.deleteLink).Methods("DELETE")
+	api.HandleFunc("/filesystems/{fs}/filesets/{fileset}/quotas", s.postQuota).Methods("POST")
+	api.HandleFunc("/filesystems/{fs}/quotas", s.getQuotas).Methods("GET")
+	api.HandleFunc("/filesystems/{fs}/pools", s.getPools).Methods("GET")
+	api.HandleFunc("/filesystems/{fs}/policies", s.getPolicies).Methods("GET")
+	api.HandleFunc("/filesystems/{fs}/policies", s.putPolicies).Methods("PUT")
+	api.HandleFunc("/nfs/exports", s.postExport).Methods("POST")
+	api.HandleFunc("/nfs/exports/{path}", s.getExport).Methods("GET")
+	api.HandleFunc("/nfs/exports/{path}", s.putExport).Methods("PUT")
+	api.HandleFunc("/nfs/exports/{path}", s.deleteExport).Methods("DELETE")
+	api.HandleFunc("/smb/shares", s.postShare).Methods("POST")
+	api.HandleFunc("/smb/shares/{name}", s.deleteShare).Methods("DELETE")
+	return s.withAuthAndFaults(router)
+}
+
+// withAuthAndFaults records the request, checks the basic auth and applies the first matching request fault
+func (s *Server) withAuthAndFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.lock.Lock()
+		s.requests = append(s.requests, req.Method+" "+requestPath(req))
+		fault := s.takeFault(req, false)
+		s.lock.Unlock()
+
+		if user, password, ok := req.BasicAuth(); !ok || user != s.user || password != s.password {
+			writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		if fault != nil {
+			writeStatus(w, fault.StatusCode, "Injected fault")
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// takeFault return the first fault that match the request, and consume it. Must be called with the lock held.
+func (s *Server) takeFault(req *http.Request, jobFault bool) *Fault {
+	path := requestPath(req)
+	for i, f := range s.faults {
+		if (f.JobError != "") != jobFault {
+			continue
+		}
+		if (f.Method == "" || f.Method == req.Method) && (f.Path == "" || f.Path == path) {
+			if f.Count--; f.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Server) getInfo(w http.ResponseWriter, req *http.Request) {
+	response := connectors.GetInfoResponse_v2{Status: connectors.Status{Code: http.StatusOK}}
+	response.Info = connectors.Info_v2{Name: "simulator", RestApiVersion: "2.0.0", ServerVersion: "5.0.0"}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) getCluster(w http.ResponseWriter, req *http.Request) {
+	response := connectors.GetClusterResponse{}
+	response.Cluster.ClusterSummary.ClusterID = s.ClusterId
+	response.Cluster.ClusterSummary.ClusterName = "simulator"
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) getNodes(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	response := connectors.GetNodesResponse_v2{Status: connectors.Status{Code: http.StatusOK}}
+	for i, name := range s.nodes {
+		response.Nodes = append(response.Nodes, connectors.Node_v2{AdminNodename: name, NodeNumber: i + 1})
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) getFilesystems(w http.ResponseWriter, req *http.Request) {
+	name, single := mux.Vars(req)["fs"]
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	response := connectors.GetFilesystemResponse_v2{Status: connectors.Status{Code: http.StatusOK}}
+	names := []string{}
+	for fsName := range s.filesystems {
+		if !single || fsName == name {
+			names = append(names, fsName)
+		}
+	}
+	if single && len(names) == 0 {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("Invalid value in 'filesystemName': '%s'", name))
+		return
+	}
+	sort.Strings(names)
+	for _, fsName := range names {
+		response.FileSystems = append(response.FileSystems,
+			connectors.FileSystem_v2{Name: fsName, Mount: connectors.MountInfo{MountPoint: s.filesystems[fsName].mountpoint},
+				Block: connectors.BlockInfo{Pools: strings.Join(s.filesystems[fsName].pools, ",")}})
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) getFilesets(w http.ResponseWriter, req *http.Request) {
+	name, single := mux.Vars(req)["fileset"]
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	response := connectors.GetFilesetResponse_v2{Status: connectors.Status{Code: http.StatusOK}}
+	names := []string{}
+	for filesetName := range fs.filesets {
+		if !single || filesetName == name {
+			names = append(names, filesetName)
+		}
+	}
+	if single && len(names) == 0 {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("Invalid value in 'filesetName': '%s'", name))
+		return
+	}
+	sort.Strings(names)
+	for _, filesetName := range names {
+		response.Filesets = append(response.Filesets, connectors.Fileset_v2{Config: fs.filesets[filesetName].config, AFM: fs.filesets[filesetName].afm})
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) postFileset(w http.ResponseWriter, req *http.Request) {
+	request := connectors.CreateFilesetRequest{}
+	if !readJson(w, req, &request) {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	filesystemName := mux.Vars(req)["fs"]
+	s.submitJob(w, req, func() []string {
+		if err := s.createFileset(filesystemName, fs, request); err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	})
+}
+
+func (s *Server) deleteFileset(w http.ResponseWriter, req *http.Request) {
+	filesetName := mux.Vars(req)["fileset"]
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	s.submitJob(w, req, func() []string {
+		fset, exists := fs.filesets[filesetName]
+		switch {
+		case !exists:
+			return []string{fmt.Sprintf("Fileset %s not found.", filesetName)}
+		case filesetName == RootFileset:
+			return []string{"Cannot delete the root fileset."}
+		case fset.config.Status == FilesetStatusLinked:
+			return []string{fmt.Sprintf("Fileset %s is linked. Unlink it before deleting.", filesetName)}
+		}
+		if err := os.RemoveAll(fset.data); err != nil {
+			return []string{err.Error()}
+		}
+		delete(fs.filesets, filesetName)
+		return nil
+	})
+}
+
+func (s *Server) postLink(w http.ResponseWriter, req *http.Request) {
+	request := connectors.LinkFilesetRequest{}
+	if !readJson(w, req, &request) {
+		return
+	}
+	filesetName := mux.Vars(req)["fileset"]
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	s.submitJob(w, req, func() []string {
+		fset, exists := fs.filesets[filesetName]
+		if !exists {
+			return []string{fmt.Sprintf("Fileset %s not found.", filesetName)}
+		}
+		if fset.config.Status == FilesetStatusLinked {
+			return []string{fmt.Sprintf("Fileset %s is already linked.", filesetName)}
+		}
+		junction := request.Path
+		if junction == "" {
+			junction = filepath.Join(fs.mountpoint, filesetName)
+		}
+		if !strings.HasPrefix(junction, fs.mountpoint+"/") {
+			return []string{fmt.Sprintf("Junction path %s is not in the filesystem.", junction)}
+		}
+		if err := os.Symlink(fset.data, junction); err != nil {
+			return []string{err.Error()}
+		}
+		fset.config.Path = junction
+		fset.config.Status = FilesetStatusLinked
+		// the cache gets active on the first access, which is the link here
+		if fset.afm.AFMTarget != "" {
+			fset.afm.AFMState = AfmStateActive
+		}
+		return nil
+	})
+}
+
+func (s *Server) deleteLink(w http.ResponseWriter, req *http.Request) {
+	filesetName := mux.Vars(req)["fileset"]
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	s.submitJob(w, req, func() []string {
+		fset, exists := fs.filesets[filesetName]
+		switch {
+		case !exists:
+			return []string{fmt.Sprintf("Fileset %s not found.", filesetName)}
+		case filesetName == RootFileset:
+			return []string{"Cannot unlink the root fileset."}
+		case fset.config.Status != FilesetStatusLinked:
+			return []string{fmt.Sprintf("Fileset %s is not linked.", filesetName)}
+		}
+		if err := os.Remove(fset.config.Path); err != nil {
+			return []string{err.Error()}
+		}
+		fset.config.Path = UnlinkedFilesetPath
+		fset.config.Status = FilesetStatusUnlinked
+		return nil
+	})
+}
+
+func (s *Server) postQuota(w http.ResponseWriter, req *http.Request) {
+	request := connectors.SetQuotaRequest_v2{}
+	if !readJson(w, req, &request) {
+		return
+	}
+	filesetName := mux.Vars(req)["fileset"]
+	hardLimit, err := utils.ParseQuantity(request.BlockHardLimit)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("Invalid value in 'blockHardLimit': '%s'", request.BlockHardLimit))
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	s.submitJob(w, req, func() []string {
+		fset, exists := fs.filesets[filesetName]
+		if !exists {
+			return []string{fmt.Sprintf("Fileset %s not found.", filesetName)}
+		}
+		fset.quotaKB = int(hardLimit / utils.KiB)
+		return nil
+	})
+}
+
+func (s *Server) getQuotas(w http.ResponseWriter, req *http.Request) {
+	objectName := strings.TrimPrefix(req.URL.Query().Get("filter"), "objectName=")
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	response := connectors.GetQuotaResponse_v2{Status: connectors.Status{Code: http.StatusOK}}
+	names := []string{}
+	// every fileset but the root fileset has a fileset quota entry for its usage, with a 0 limit if no quota is set
+	for filesetName := range fs.filesets {
+		if filesetName != RootFileset && (objectName == "" || objectName == filesetName) {
+			names = append(names, filesetName)
+		}
+	}
+	sort.Strings(names)
+	for _, filesetName := range names {
+		fset := fs.filesets[filesetName]
+		usageKB, files := dataUsage(fset.data)
+		response.Quotas = append(response.Quotas, connectors.Quota_v2{
+			FilesystemName: fset.config.FilesystemName, FilesetName: filesetName, QuotaType: "FILESET",
+			ObjectName: filesetName, ObjectId: fset.config.Id, BlockQuota: fset.quotaKB, BlockLimit: fset.quotaKB,
+			BlockUsage: usageKB, FilesUsage: files})
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+// getPools return the pools of the filesystem, the system pool holds the data of all the filesets
+func (s *Server) getPools(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	usedKB := 0
+	for _, fset := range fs.filesets {
+		usageKB, _ := dataUsage(fset.data)
+		usedKB += usageKB
+	}
+	poolKB := s.SizeKB / len(fs.pools)
+	response := connectors.GetStoragePoolsResponse_v2{Status: connectors.Status{Code: http.StatusOK}}
+	for i, poolName := range fs.pools {
+		sizeKB := poolKB
+		if i == 0 {
+			sizeKB = s.SizeKB - poolKB*(len(fs.pools)-1)
+		}
+		freeKB := sizeKB
+		if poolName == SystemPool {
+			freeKB = sizeKB - usedKB
+		}
+		if freeKB < 0 {
+			freeKB = 0
+		}
+		response.StoragePools = append(response.StoragePools, connectors.StoragePool_v2{FilesystemName: mux.Vars(req)["fs"],
+			StoragePoolName: poolName, TotalDataSize: int64(sizeKB), FreeDataSize: int64(freeKB)})
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) getPolicies(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	policy := fs.policy
+	if policy == "" {
+		policy = fmt.Sprintf("No policy file was installed for file system '%s'.", mux.Vars(req)["fs"])
+	}
+	response := connectors.GetPolicyResponse_v2{Status: connectors.Status{Code: http.StatusOK},
+		Policies: []connectors.Policy_v2{{FilesystemName: mux.Vars(req)["fs"], Policy: policy}}}
+	writeJson(w, http.StatusOK, response)
+}
+
+var (
+	policyPoolPattern    = regexp.MustCompile(`SET POOL '([^']*)'`)
+	policyFilesetPattern = regexp.MustCompile(`FOR FILESET \('([^']*)'\)`)
+)
+
+// putPolicies installs the policy if the pools and the filesets of its rules exist, like mmchpolicy
+func (s *Server) putPolicies(w http.ResponseWriter, req *http.Request) {
+	request := connectors.ChangePolicyRequest_v2{}
+	if !readJson(w, req, &request) {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fs, ok := s.getFilesystem(w, req)
+	if !ok {
+		return
+	}
+	s.submitJob(w, req, func() []string {
+		for _, match := range policyPoolPattern.FindAllStringSubmatch(request.Policy, -1) {
+			if !utils.StringInSlice(match[1], fs.pools) {
+				return []string{fmt.Sprintf("[E] Error on SET POOL: Pool '%s' does not exist.", match[1])}
+			}
+		}
+		for _, match := range policyFilesetPattern.FindAllStringSubmatch(request.Policy, -1) {
+			if _, exists := fs.filesets[match[1]]; !exists {
+				return []string{fmt.Sprintf("[E] Error on FOR FILESET: Fileset '%s' does not exist.", match[1])}
+			}
+		}
+		fs.policy = request.Policy
+		return nil
+	})
+}
+
+// dataUsage return the size in KB (rounded up per file) and the number of inodes of the files under a fileset data directory
+func dataUsage(data string) (int, int) {
+	usageKB, files := 0, 0
+	filepath.Walk(data, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == data {
+			return nil
+		}
+		files++
+		if info.Mode().IsRegular() {
+			usageKB += int((info.Size() + 1023) / 1024)
+		}
+		return nil
+	})
+	return usageKB, files
+}
+
+func (s *Server) postExport(w http.ResponseWriter, req *http.Request) {
+	request := struct {
+		Path    string   `json:"path"`
+		Clients []string `json:"nfsClients"`
+	}{}
+	if !readJson(w, req, &request) {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.submitJob(w, req, func() []string {
+		if _, exists := s.exports[request.Path]; exists {
+			return []string{fmt.Sprintf("The NFS export %s already exists.", request.Path)}
+		}
+		if _, err := os.Stat(request.Path); err != nil {
+			return []string{fmt.Sprintf("The path %s does not exist.", request.Path)}
+		}
+		s.exports[request.Path] = request.Clients
+		return nil
+	})
+}
+
+// getExport lists the clients of an export with the option values in upper case and the lists separated by ',', like CES
+func (s *Server) getExport(w http.ResponseWriter, req *http.Request) {
+	path, err := url.PathUnescape(mux.Vars(req)["path"])
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	specs, exists := s.exports[path]
+	if !exists {
+		writeStatus(w, http.StatusNotFound, fmt.Sprintf("The NFS export %s does not exist.", path))
+		return
+	}
+	clients, err := connectors.ParseNfsClients(strings.Join(specs, ";"))
+	if err != nil {
+		writeStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	export := connectors.NfsExport_v2{Path: path}
+	for _, client := range clients {
+		export.NfsClients = append(export.NfsClients, connectors.NfsExportClient_v2{ClientName: client.Client, AccessType: client.AccessType,
+			Squash: strings.ToUpper(client.Squash), SecType: strings.ToUpper(strings.Join(client.SecTypes, ",")), Protocols: strings.Join(client.Protocols, ",")})
+	}
+	writeJson(w, http.StatusOK, connectors.GetNfsExportResponse_v2{Status: connectors.Status{Code: http.StatusOK}, Exports: []connectors.NfsExport_v2{export}})
+}
+
+// putExport adds, changes and removes the clients of an export, in this order like mmnfs export change
+func (s *Server) putExport(w http.ResponseWriter, req *http.Request) {
+	path, err := url.PathUnescape(mux.Vars(req)["path"])
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	request := struct {
+		NfsAdd    []string `json:"nfsAdd"`
+		NfsChange []string `json:"nfsChange"`
+		NfsRemove []string `json:"nfsRemove"`
+	}{}
+	if !readJson(w, req, &request) {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.submitJob(w, req, func() []string {
+		clients, exists := s.exports[path]
+		if !exists {
+			return []string{fmt.Sprintf("The NFS export %s does not exist.", path)}
+		}
+		clients = append([]string{}, clients...)
+		for _, spec := range request.NfsAdd {
+			if nfsClientIndex(clients, spec) >= 0 {
+				return []string{fmt.Sprintf("The client %s already exists in the NFS export %s.", nfsClientName(spec), path)}
+			}
+			clients = append(clients, spec)
+		}
+		for _, spec := range request.NfsChange {
+			i := nfsClientIndex(clients, spec)
+			if i < 0 {
+				return []string{fmt.Sprintf("The client %s does not exist in the NFS export %s.", nfsClientName(spec), path)}
+			}
+			clients[i] = spec
+		}
+		for _, name := range request.NfsRemove {
+			i := nfsClientIndex(clients, name)
+			if i < 0 {
+				return []string{fmt.Sprintf("The client %s does not exist in the NFS export %s.", name, path)}
+			}
+			clients = append(clients[:i], clients[i+1:]...)
+		}
+		if len(clients) == 0 {
+			return []string{fmt.Sprintf("Cannot remove the last client of the NFS export %s.", path)}
+		}
+		s.exports[path] = clients
+		return nil
+	})
+}
+
+// nfsClientName return the client of a client spec client(options)
+func nfsClientName(spec string) string {
+	if i := strings.Index(spec, "("); i >= 0 {
+		return spec[:i]
+	}
+	return spec
+}
+
+func nfsClientIndex(clients []string, spec string) int {
+	for i, client := range clients {
+		if nfsClientName(client) == nfsClientName(spec) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) deleteExport(w http.ResponseWriter, req *http.Request) {
+	path, err := url.PathUnescape(mux.Vars(req)["path"])
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.submitJob(w, req, func() []string {
+		if _, exists := s.exports[path]; !exists {
+			return []string{fmt.Sprintf("The NFS export %s does not exist.", path)}
+		}
+		delete(s.exports, path)
+		return nil
+	})
+}
+
+func (s *Server) postShare(w http.ResponseWriter, req *http.Request) {
+	request := struct {
+		ShareName  string            `json:"shareName"`
+		Path       string            `json:"path"`
+		SmbOptions map[string]string `json:"smbOptions"`
+	}{}
+	if !readJson(w, req, &request) {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.submitJob(w, req, func() []string {
+		if _, exists := s.shares[request.ShareName]; exists {
+			return []string{fmt.Sprintf("The SMB share %s already exists.", request.ShareName)}
+		}
+		if _, err := os.Stat(request.Path); err != nil {
+			return []string{fmt.Sprintf("The path %s does not exist.", request.Path)}
+		}
+		s.shares[request.ShareName] = &share{path: request.Path, options: request.SmbOptions}
+		return nil
+	})
+}
+
+func (s *Server) deleteShare(w http.ResponseWriter, req *http.Request) {
+	name, err := url.PathUnescape(mux.Vars(req)["name"])
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.submitJob(w, req, func() []string {
+		if _, exists := s.shares[name]; !exists {
+			return []string{fmt.Sprintf("The SMB share %s does not exist.", name)}
+		}
+		delete(s.shares, name)
+		return nil
+	})
+}
+
+func (s *Server) getJobs(w http.ResponseWriter, req *http.Request) {
+	filter := req.URL.Query().Get("filter")
+	jobId, err := strconv.ParseUint(strings.TrimPrefix(filter, "jobId="), 10, 64)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("Invalid filter '%s'", filter))
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, exists := s.jobs[jobId]
+	if !exists {
+		writeJson(w, http.StatusOK, connectors.GenericResponse{Status: connectors.Status{Code: http.StatusOK}})
+		return
+	}
+	if j.job.Status == JobStatusRunning {
+		if j.pollsLeft > 0 {
+			j.pollsLeft--
+		} else if j.pollsLeft == 0 {
+			s.completeJob(j)
+		}
+	}
+	writeJson(w, http.StatusOK, connectors.GenericResponse{Status: connectors.Status{Code: http.StatusOK}, Jobs: []connectors.Job{j.job}})
+}
+
+// cancelJob cancels a RUNNING job, its action is not applied
+func (s *Server) cancelJob(w http.ResponseWriter, req *http.Request) {
+	jobId, err := strconv.ParseUint(mux.Vars(req)["jobId"], 10, 64)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("Invalid job id '%s'", mux.Vars(req)["jobId"]))
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, exists := s.jobs[jobId]
+	if !exists {
+		writeStatus(w, http.StatusNotFound, fmt.Sprintf("Job %d not found", jobId))
+		return
+	}
+	if j.job.Status != JobStatusRunning {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("Job %d is not running", jobId))
+		return
+	}
+	j.job.Status = JobStatusCancelled
+	j.job.Completed = time.Now().Format(time.RFC3339)
+	writeJson(w, http.StatusOK, connectors.GenericResponse{Status: connectors.Status{Code: http.StatusOK}, Jobs: []connectors.Job{j.job}})
+}
+
+// submitJob accepts the request as a RUNNING job, the action is applied when the job completes.
+// Must be called with the lock held.
+func (s *Server) submitJob(w http.ResponseWriter, req *http.Request, action func() []string) {
+	s.lastId++
+	j := &job{
+		job: connectors.Job{
+			JobID:     uint64(s.lastId),
+			Status:    JobStatusRunning,
+			Submitted: time.Now().Format(time.RFC3339),
+			Request:   connectors.Resprequest{Type: req.Method, Url: req.URL.RequestURI()},
+		},
+		pollsLeft: s.JobPolls,
+		action:    action,
+	}
+	if fault := s.takeFault(req, true); fault != nil {
+		j.err = fault.JobError
+	}
+	s.jobs[j.job.JobID] = j
+	writeJson(w, http.StatusAccepted, connectors.GenericResponse{Status: connectors.Status{Code: http.StatusAccepted}, Jobs: []connectors.Job{j.job}})
+}
+
+// completeJob must be called with the lock held
+func (s *Server) completeJob(j *job) {
+	var stderr []string
+	if j.err != "" {
+		stderr = []string{j.err}
+	} else {
+		stderr = j.action()
+	}
+	j.job.Completed = time.Now().Format(time.RFC3339)
+	if len(stderr) > 0 {
+		j.job.Status = JobStatusFailed
+		j.job.Result = connectors.Respresult{ExitCode: 1, Stderr: stderr}
+	} else {
+		j.job.Status = JobStatusCompleted
+	}
+}
+
+// createFileset creates an unlinked fileset. Must be called with the lock held.
+func (s *Server) createFileset(filesystemName string, fs *filesystem, request connectors.CreateFilesetRequest) error {
+	if request.FilesetName == "" {
+		return fmt.Errorf("Fileset name is missing.")
+	}
+	if _, exists := fs.filesets[request.FilesetName]; exists {
+		return fmt.Errorf("Fileset %s already exists.", request.FilesetName)
+	}
+	afm, err := afmAttributes(request)
+	if err != nil {
+		return err
+	}
+	data := filepath.Join(s.root, ".filesets", filesystemName, request.FilesetName)
+	if err := os.Mkdir(data, 0755); err != nil {
+		return err
+	}
+	s.lastId++
+	config := connectors.FilesetConfig_v2{
+		FilesetName:    request.FilesetName,
+		FilesystemName: filesystemName,
+		Path:           UnlinkedFilesetPath,
+		Comment:        request.Comment,
+		Id:             s.lastId,
+		Status:         FilesetStatusUnlinked,
+		Created:        time.Now().Format(time.RFC3339),
+	}
+	if request.InodeSpace == "new" {
+		config.IsInodeSpaceOwner = true
+		config.MaxNumInodes, _ = strconv.Atoi(request.MaxNumInodes)
+	}
+	fs.filesets[request.FilesetName] = &fileset{config: config, data: data, afm: afm}
+	return nil
+}
+
+// afmAttributes return the AFM attributes of an AFM fileset request, which must create an independent fileset
+func afmAttributes(request connectors.CreateFilesetRequest) (connectors.AFM, error) {
+	if request.AfmTarget == "" {
+		return connectors.AFM{}, nil
+	}
+	if request.InodeSpace != "new" {
+		return connectors.AFM{}, fmt.Errorf("AFM fileset %s must be an independent fileset.", request.FilesetName)
+	}
+	if !utils.StringInSlice(request.AfmMode, connectors.AfmModes) {
+		return connectors.AFM{}, fmt.Errorf("Invalid value in 'afmMode': '%s'", request.AfmMode)
+	}
+	afm := connectors.AFM{AFMTarget: request.AfmTarget, AFMMode: request.AfmMode, AFMState: AfmStateInactive}
+	intervals := []struct {
+		value    string
+		interval *int
+	}{
+		{request.AfmAsyncDelay, &afm.AFMAsyncDelay},
+		{request.AfmExpirationTimeout, &afm.AFMExpirationTimeout},
+		{request.AfmFileLookupRefreshInterval, &afm.AFMFileLookupRefreshInterval},
+		{request.AfmDirLookupRefreshInterval, &afm.AFMDirLookupRefreshInterval},
+	}
+	for _, interval := range intervals {
+		if interval.value == "" {
+			continue
+		}
+		seconds, err := strconv.Atoi(interval.value)
+		if err != nil {
+			return connectors.AFM{}, fmt.Errorf("Invalid AFM interval '%s'", interval.value)
+		}
+		*interval.interval = seconds
+	}
+	return afm, nil
+}
+
+// getFilesystem return the filesystem of the request or writes an error. Must be called with the lock held.
+func (s *Server) getFilesystem(w http.ResponseWriter, req *http.Request) (*filesystem, bool) {
+	name := mux.Vars(req)["fs"]
+	fs, exists := s.filesystems[name]
+	if !exists {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("Invalid value in 'filesystemName': '%s'", name))
+	}
+	return fs, exists
+}
+
+func requestPath(req *http.Request) string {
+	return strings.TrimPrefix(strings.TrimPrefix(req.URL.EscapedPath(), apiPrefix), "/")
+}
+
+func readJson(w http.ResponseWriter, req *http.Request, v interface{}) bool {
+	data, err := ioutil.ReadAll(req.Body)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeStatus(w http.ResponseWriter, statusCode int, message string) {
+	writeJson(w, statusCode, connectors.GenericResponse{Status: connectors.Status{Code: statusCode, Message: message}})
+}
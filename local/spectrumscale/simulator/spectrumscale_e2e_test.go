@@ -0,0 +1,913 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulator_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/IBM/ubiquity/local/spectrumscale"
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/local/spectrumscale/simulator"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// memDataModel keeps the volumes in memory instead of the ubiquity database
+type memDataModel struct {
+	lock      sync.Mutex
+	clusterId string
+	volumes   map[string]spectrumscale.SpectrumScaleVolume
+
+	attachments []spectrumscale.SpectrumScaleAttachment
+}
+
+func newMemDataModel() *memDataModel {
+	return &memDataModel{volumes: make(map[string]spectrumscale.SpectrumScaleVolume)}
+}
+
+func (d *memDataModel) CreateVolumeTable() error { return nil }
+func (d *memDataModel) SetClusterId(id string)   { d.clusterId = id }
+func (d *memDataModel) GetClusterId() string     { return d.clusterId }
+
+func (d *memDataModel) DeleteVolume(name string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, exists := d.volumes[name]; !exists {
+		return fmt.Errorf("Volume : %s not found", name)
+	}
+	delete(d.volumes, name)
+	d.attachments = d.filterAttachments(func(attachment spectrumscale.SpectrumScaleAttachment) bool { return attachment.VolumeName != name })
+	return nil
+}
+
+func (d *memDataModel) InsertFilesetVolume(fileset, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error {
+	return d.insertVolume(spectrumscale.SpectrumScaleVolume{Type: spectrumscale.Fileset, FileSystem: filesystem, Fileset: fileset, IsPreexisting: isPreexisting}, volumeName, opts)
+}
+
+func (d *memDataModel) InsertLightweightVolume(fileset, directory, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error {
+	return d.insertVolume(spectrumscale.SpectrumScaleVolume{Type: spectrumscale.Lightweight, FileSystem: filesystem, Fileset: fileset, Directory: directory, IsPreexisting: isPreexisting}, volumeName, opts)
+}
+
+func (d *memDataModel) InsertFilesetQuotaVolume(fileset, quota, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error {
+	return d.insertVolume(spectrumscale.SpectrumScaleVolume{Type: spectrumscale.FilesetWithQuota, FileSystem: filesystem, Fileset: fileset, Quota: quota, IsPreexisting: isPreexisting}, volumeName, opts)
+}
+
+func (d *memDataModel) InsertLightweightQuotaVolume(fileset, directory, quotaFileset, quota, volumeName string, filesystem string, isPreexisting bool, opts map[string]interface{}) error {
+	return d.insertVolume(spectrumscale.SpectrumScaleVolume{Type: spectrumscale.Lightweight, FileSystem: filesystem, Fileset: fileset, Directory: directory, QuotaFileset: quotaFileset, Quota: quota, IsPreexisting: isPreexisting}, volumeName, opts)
+}
+
+func (d *memDataModel) insertVolume(volume spectrumscale.SpectrumScaleVolume, volumeName string, opts map[string]interface{}) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, exists := d.volumes[volumeName]; exists {
+		return fmt.Errorf("Volume : %s already exists", volumeName)
+	}
+	volume.Volume = resources.Volume{Name: volumeName, Backend: resources.SpectrumScale}
+	volume.ClusterId = d.clusterId
+	volume.Pool, _ = opts[spectrumscale.UserSpecifiedPool].(string)
+	volume.AfmTarget, _ = opts[connectors.UserSpecifiedAfmTarget].(string)
+	volume.AfmMode, _ = opts[connectors.UserSpecifiedAfmMode].(string)
+	volume.JunctionPath, _ = opts[spectrumscale.JunctionPath].(string)
+	d.volumes[volumeName] = volume
+	return nil
+}
+
+func (d *memDataModel) GetVolume(name string) (spectrumscale.SpectrumScaleVolume, bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	volume, exists := d.volumes[name]
+	return volume, exists, nil
+}
+
+func (d *memDataModel) ListVolumes() ([]resources.Volume, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var volumes []resources.Volume
+	for _, volume := range d.volumes {
+		volumes = append(volumes, volume.Volume)
+	}
+	return volumes, nil
+}
+
+func (d *memDataModel) UpdateVolumeMountpoint(name string, mountpoint string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	volume, exists := d.volumes[name]
+	if !exists {
+		return fmt.Errorf("Volume : %s not found", name)
+	}
+	volume.Volume.Mountpoint = mountpoint
+	d.volumes[name] = volume
+	return nil
+}
+
+func (d *memDataModel) UpdateVolumeQuota(name string, quota string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	volume, exists := d.volumes[name]
+	if !exists {
+		return fmt.Errorf("Volume : %s not found", name)
+	}
+	if volume.Type == spectrumscale.Fileset {
+		volume.Type = spectrumscale.FilesetWithQuota
+	}
+	volume.Quota = quota
+	d.volumes[name] = volume
+	return nil
+}
+
+func (d *memDataModel) UpdateVolumeNfsClientConfig(name string, nfsClientConfig string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	volume, exists := d.volumes[name]
+	if !exists {
+		return fmt.Errorf("Volume : %s not found", name)
+	}
+	volume.NfsClientConfig = nfsClientConfig
+	d.volumes[name] = volume
+	return nil
+}
+
+func (d *memDataModel) InsertAttachment(name string, host string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	volume, exists := d.volumes[name]
+	if !exists {
+		return fmt.Errorf("Volume : %s not found", name)
+	}
+	for _, attachment := range d.attachments {
+		if attachment.VolumeName == name && attachment.Host == host {
+			return nil
+		}
+	}
+	d.attachments = append(d.attachments, spectrumscale.SpectrumScaleAttachment{VolumeName: name, FileSystem: volume.FileSystem, Fileset: volume.Fileset, Host: host})
+	return nil
+}
+
+func (d *memDataModel) DeleteAttachment(name string, host string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.attachments = d.filterAttachments(func(attachment spectrumscale.SpectrumScaleAttachment) bool {
+		return attachment.VolumeName != name || attachment.Host != host
+	})
+	return nil
+}
+
+func (d *memDataModel) ListAttachments(name string) ([]spectrumscale.SpectrumScaleAttachment, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.filterAttachments(func(attachment spectrumscale.SpectrumScaleAttachment) bool { return attachment.VolumeName == name }), nil
+}
+
+func (d *memDataModel) ListFilesetAttachments(filesystem string, fileset string) ([]spectrumscale.SpectrumScaleAttachment, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.filterAttachments(func(attachment spectrumscale.SpectrumScaleAttachment) bool {
+		return attachment.FileSystem == filesystem && attachment.Fileset == fileset
+	}), nil
+}
+
+func (d *memDataModel) ListFilesetVolumes(filesystem string, fileset string) ([]spectrumscale.SpectrumScaleVolume, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var volumes []spectrumscale.SpectrumScaleVolume
+	for _, volume := range d.volumes {
+		if volume.FileSystem == filesystem && volume.Fileset == fileset {
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes, nil
+}
+
+func (d *memDataModel) filterAttachments(keep func(spectrumscale.SpectrumScaleAttachment) bool) []spectrumscale.SpectrumScaleAttachment {
+	var attachments []spectrumscale.SpectrumScaleAttachment
+	for _, attachment := range d.attachments {
+		if keep(attachment) {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments
+}
+
+var _ = Describe("spectrumLocalClient end to end with the Spectrum Scale simulator", func() {
+	const (
+		filesystemName = "gold"
+		nodeName       = "node1"
+	)
+	var (
+		server     *simulator.Server
+		client     resources.StorageClient
+		mountpoint string
+		err        error
+
+		usageCacheTimeout    int
+		junctionPathTemplate string
+		detachPolicy         string
+	)
+
+	newClient := func(forceDelete bool) resources.StorageClient {
+		logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+		config := resources.SpectrumScaleConfig{DefaultFilesystemName: filesystemName, RestConfig: server.RestConfig(nodeName), ForceDelete: forceDelete,
+			UsageCacheTimeout: usageCacheTimeout, JunctionPathTemplate: junctionPathTemplate, DetachPolicy: detachPolicy}
+		connector, err := connectors.GetSpectrumScaleConnector(logger, config)
+		Expect(err).NotTo(HaveOccurred())
+		client, err := spectrumscale.NewSpectrumLocalClientWithConnectors(logger, connector, utils.NewExecutor(), config, newMemDataModel())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Activate(resources.ActivateRequest{})).To(Succeed())
+		return client
+	}
+
+	BeforeEach(func() {
+		server, err = simulator.NewServer("admin", "admin001")
+		Expect(err).NotTo(HaveOccurred())
+		server.AddNode(nodeName)
+		mountpoint, err = server.AddFilesystem(filesystemName)
+		Expect(err).NotTo(HaveOccurred())
+		usageCacheTimeout = 0
+		junctionPathTemplate = ""
+		detachPolicy = ""
+		client = newClient(true)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createVolume := func(name string, opts map[string]interface{}) error {
+		return client.CreateVolume(resources.CreateVolumeRequest{Name: name, Backend: resources.SpectrumScale, Opts: opts})
+	}
+	attach := func(name string) (string, error) {
+		return client.Attach(resources.AttachRequest{Name: name, Host: nodeName})
+	}
+
+	Context("fileset volumes", func() {
+		It("should create, attach, detach and remove a fileset volume", func() {
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			fileset, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeTrue())
+			Expect(fileset.Status).To(Equal(simulator.FilesetStatusUnlinked))
+
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeMountpoint).To(Equal(filepath.Join(mountpoint, "vol1")))
+			fileset, _ = server.Fileset(filesystemName, "vol1")
+			Expect(fileset.Path).To(Equal(volumeMountpoint))
+
+			// the data is kept in the fileset while it is unlinked
+			Expect(ioutil.WriteFile(filepath.Join(volumeMountpoint, "data"), []byte("data"), 0644)).To(Succeed())
+
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig["mountpoint"]).To(Equal(volumeMountpoint))
+			Expect(volumeConfig[spectrumscale.Cluster]).To(Equal(fmt.Sprintf("%d", server.ClusterId)))
+
+			Expect(client.Detach(resources.DetachRequest{Name: "vol1", Host: nodeName})).To(Succeed())
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			_, exists = server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+			_, err = os.Stat(volumeMountpoint)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+		It("should keep the fileset on remove if force delete is not set", func() {
+			client = newClient(false)
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			_, err = attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			fileset, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeTrue())
+			Expect(fileset.Status).To(Equal(simulator.FilesetStatusUnlinked))
+		})
+		It("should create a fileset volume with a quota", func() {
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Quota: "1536M"})).To(Succeed())
+			Expect(server.FilesetQuota(filesystemName, "vol1")).To(Equal(1536 * 1024))
+		})
+		It("should use an existing fileset with the same quota", func() {
+			Expect(server.AddFileset(filesystemName, "existing")).To(Succeed())
+			Expect(createVolume("quota", map[string]interface{}{spectrumscale.Quota: "1G"})).To(Succeed())
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.TypeFileset: "quota", spectrumscale.Filesystem: filesystemName, spectrumscale.Quota: "1024M"})).To(Succeed())
+			Expect(createVolume("vol2", map[string]interface{}{spectrumscale.TypeFileset: "existing", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			Expect(createVolume("vol3", map[string]interface{}{spectrumscale.TypeFileset: "missing", spectrumscale.Filesystem: filesystemName})).NotTo(Succeed())
+		})
+		It("should wait for RUNNING jobs to complete", func() {
+			server.JobPolls = 1
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			jobs := server.Jobs()
+			Expect(len(jobs)).To(Equal(1))
+			Expect(jobs[0].Status).To(Equal(simulator.JobStatusCompleted))
+		})
+	})
+
+	Context("resize", func() {
+		It("should resize a fileset volume with quota", func() {
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Quota: "1G"})).To(Succeed())
+			Expect(client.(resources.VolumeResizer).ResizeVolume(resources.ResizeVolumeRequest{Name: "vol1", Size: "3G"})).To(Succeed())
+			Expect(server.FilesetQuota(filesystemName, "vol1")).To(Equal(3 * 1024 * 1024))
+		})
+		It("should convert a fileset volume into a quota volume", func() {
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			Expect(client.(resources.VolumeResizer).ResizeVolume(resources.ResizeVolumeRequest{Name: "vol1", Size: "512M"})).To(Succeed())
+			Expect(server.FilesetQuota(filesystemName, "vol1")).To(Equal(512 * 1024))
+		})
+	})
+
+	Context("lightweight volumes", func() {
+		It("should create a directory in a linked fileset", func() {
+			Expect(server.AddFileset(filesystemName, "shared")).To(Succeed())
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Type: spectrumscale.TypeLightweight, spectrumscale.TypeFileset: "shared", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			fileset, _ := server.Fileset(filesystemName, "shared")
+			Expect(fileset.Status).To(Equal(simulator.FilesetStatusLinked))
+			info, err := os.Stat(filepath.Join(mountpoint, "shared", "vol1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.IsDir()).To(BeTrue())
+
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			_, err = os.Stat(filepath.Join(mountpoint, "shared", "vol1"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+		It("should enforce the quota of a lightweight volume with a dependent fileset", func() {
+			Expect(server.AddFileset(filesystemName, "shared")).To(Succeed())
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Type: spectrumscale.TypeLightweight, spectrumscale.TypeFileset: "shared", spectrumscale.Filesystem: filesystemName, spectrumscale.Quota: "1G"})).To(Succeed())
+			volumePath := filepath.Join(mountpoint, "shared", "vol1")
+			fileset, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeTrue())
+			Expect(fileset.Status).To(Equal(simulator.FilesetStatusLinked))
+			Expect(fileset.Path).To(Equal(volumePath))
+			Expect(server.FilesetQuota(filesystemName, "vol1")).To(Equal(1024 * 1024))
+
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeMountpoint).To(Equal(volumePath))
+			Expect(ioutil.WriteFile(filepath.Join(volumeMountpoint, "data"), make([]byte, 4096), 0644)).To(Succeed())
+
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig[spectrumscale.Quota]).To(Equal("1G"))
+			Expect(volumeConfig[spectrumscale.UsedBytes]).To(Equal(int64(4096)))
+			Expect(volumeConfig[spectrumscale.QuotaLimit]).To(Equal(int64(1024 * 1024 * 1024)))
+
+			Expect(client.(resources.VolumeResizer).ResizeVolume(resources.ResizeVolumeRequest{Name: "vol1", Size: "2G"})).To(Succeed())
+			Expect(server.FilesetQuota(filesystemName, "vol1")).To(Equal(2 * 1024 * 1024))
+
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			_, exists = server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+			_, err = os.Stat(volumePath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Context("junction paths", func() {
+		It("should link the fileset of a volume at the junction of the template", func() {
+			junctionPathTemplate = "projects/{tenant}/{volume}"
+			client = newClient(true)
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.UserSpecifiedTenant: "team1"})).To(Succeed())
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeMountpoint).To(Equal(filepath.Join(mountpoint, "projects", "team1", "vol1")))
+			fileset, _ := server.Fileset(filesystemName, "vol1")
+			Expect(fileset.Path).To(Equal(volumeMountpoint))
+			Expect(ioutil.WriteFile(filepath.Join(volumeMountpoint, "data"), []byte("data"), 0644)).To(Succeed())
+
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig["mountpoint"]).To(Equal(volumeMountpoint))
+			Expect(volumeConfig[spectrumscale.JunctionPath]).To(Equal("projects/team1/vol1"))
+
+			// the volumes keep their junction when the template changes
+			junctionPathTemplate = "{fileset}"
+			client = newClient(true)
+			Expect(createVolume("vol2", map[string]interface{}{spectrumscale.TypeFileset: "vol1", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			vol2Mountpoint, err := attach("vol2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vol2Mountpoint).To(Equal(volumeMountpoint))
+
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol2"})).To(Succeed())
+			fileset, _ = server.Fileset(filesystemName, "vol1")
+			Expect(fileset.Status).To(Equal(simulator.FilesetStatusUnlinked))
+		})
+		It("should use the junction of an existing fileset linked elsewhere", func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			connector, err := connectors.NewSpectrumRestV2(logger, server.RestConfig(nodeName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.AddFileset(filesystemName, "existing")).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(mountpoint, "legacy"), 0755)).To(Succeed())
+			existingPath := filepath.Join(mountpoint, "legacy", "data")
+			Expect(connector.LinkFilesetAt(filesystemName, "existing", existingPath)).To(Succeed())
+
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.TypeFileset: "existing", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			Expect(createVolume("vol2", map[string]interface{}{spectrumscale.Type: spectrumscale.TypeLightweight, spectrumscale.TypeFileset: "existing", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			info, err := os.Stat(filepath.Join(existingPath, "vol2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.IsDir()).To(BeTrue())
+
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeMountpoint).To(Equal(existingPath))
+
+			// the fileset is linked again at its junction after it is unlinked
+			Expect(connector.UnlinkFileset(filesystemName, "existing")).To(Succeed())
+			volumeMountpoint, err = attach("vol2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeMountpoint).To(Equal(filepath.Join(existingPath, "vol2")))
+			fileset, _ := server.Fileset(filesystemName, "existing")
+			Expect(fileset.Path).To(Equal(existingPath))
+		})
+		It("should require the tenant of the template", func() {
+			junctionPathTemplate = "projects/{tenant}/{volume}"
+			client = newClient(true)
+			Expect(createVolume("vol1", map[string]interface{}{})).NotTo(Succeed())
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+		})
+	})
+
+	Context("detach policies", func() {
+		attachTo := func(name string, host string) error {
+			_, err := client.Attach(resources.AttachRequest{Name: name, Host: host})
+			return err
+		}
+		detachFrom := func(name string, host string) error {
+			return client.Detach(resources.DetachRequest{Name: name, Host: host})
+		}
+		filesetStatus := func(name string) string {
+			fileset, _ := server.Fileset(filesystemName, name)
+			return fileset.Status
+		}
+
+		It("should keep the fileset linked by default", func() {
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			Expect(attachTo("vol1", "host1")).To(Succeed())
+			Expect(detachFrom("vol1", "host1")).To(Succeed())
+			Expect(filesetStatus("vol1")).To(Equal(simulator.FilesetStatusLinked))
+		})
+		It("should unlink the fileset when the last host detaches", func() {
+			detachPolicy = spectrumscale.DetachPolicyUnlinkUnused
+			client = newClient(true)
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			Expect(attachTo("vol1", "host1")).To(Succeed())
+			Expect(attachTo("vol1", "host2")).To(Succeed())
+
+			Expect(detachFrom("vol1", "host1")).To(Succeed())
+			Expect(filesetStatus("vol1")).To(Equal(simulator.FilesetStatusLinked))
+			volume, err := client.GetVolume(resources.GetVolumeRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volume.Mountpoint).To(Equal(filepath.Join(mountpoint, "vol1")))
+
+			Expect(detachFrom("vol1", "host2")).To(Succeed())
+			Expect(filesetStatus("vol1")).To(Equal(simulator.FilesetStatusUnlinked))
+			volume, err = client.GetVolume(resources.GetVolumeRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volume.Mountpoint).To(Equal(""))
+
+			Expect(attachTo("vol1", "host1")).To(Succeed())
+			Expect(filesetStatus("vol1")).To(Equal(simulator.FilesetStatusLinked))
+		})
+		It("should unlink existing filesets only with always-unlink", func() {
+			Expect(server.AddFileset(filesystemName, "existing")).To(Succeed())
+			detachPolicy = spectrumscale.DetachPolicyUnlinkUnused
+			client = newClient(true)
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.TypeFileset: "existing", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			Expect(attachTo("vol1", "host1")).To(Succeed())
+			Expect(detachFrom("vol1", "host1")).To(Succeed())
+			Expect(filesetStatus("existing")).To(Equal(simulator.FilesetStatusLinked))
+
+			detachPolicy = spectrumscale.DetachPolicyAlwaysUnlink
+			client = newClient(true)
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.TypeFileset: "existing", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			Expect(createVolume("vol2", map[string]interface{}{spectrumscale.TypeFileset: "existing", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			Expect(attachTo("vol1", "host1")).To(Succeed())
+			Expect(attachTo("vol2", "host2")).To(Succeed())
+
+			// the fileset stays linked while another volume of the fileset is attached
+			Expect(detachFrom("vol1", "host1")).To(Succeed())
+			Expect(filesetStatus("existing")).To(Equal(simulator.FilesetStatusLinked))
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			Expect(filesetStatus("existing")).To(Equal(simulator.FilesetStatusLinked))
+
+			Expect(detachFrom("vol2", "host2")).To(Succeed())
+			Expect(filesetStatus("existing")).To(Equal(simulator.FilesetStatusUnlinked))
+		})
+		It("should unlink the dependent fileset of a lightweight volume and keep the parent fileset linked", func() {
+			detachPolicy = spectrumscale.DetachPolicyAlwaysUnlink
+			client = newClient(true)
+			Expect(server.AddFileset(filesystemName, "shared")).To(Succeed())
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Type: spectrumscale.TypeLightweight, spectrumscale.TypeFileset: "shared", spectrumscale.Filesystem: filesystemName, spectrumscale.Quota: "1G"})).To(Succeed())
+			Expect(attachTo("vol1", "host1")).To(Succeed())
+			Expect(detachFrom("vol1", "host1")).To(Succeed())
+			Expect(filesetStatus("vol1")).To(Equal(simulator.FilesetStatusUnlinked))
+			Expect(filesetStatus("shared")).To(Equal(simulator.FilesetStatusLinked))
+
+			Expect(attachTo("vol1", "host1")).To(Succeed())
+			Expect(filesetStatus("vol1")).To(Equal(simulator.FilesetStatusLinked))
+		})
+	})
+
+	Context("afm volumes", func() {
+		It("should create an afm cache fileset and report its cache state", func() {
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Type: spectrumscale.TypeAfm,
+				connectors.UserSpecifiedAfmTarget: "nfs://home1/gpfs/datasets", connectors.UserSpecifiedAfmAsyncDelay: "30"})).To(Succeed())
+			fileset, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeTrue())
+			Expect(fileset.IsInodeSpaceOwner).To(BeTrue())
+
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig[connectors.UserSpecifiedAfmTarget]).To(Equal("nfs://home1/gpfs/datasets"))
+			Expect(volumeConfig[connectors.UserSpecifiedAfmMode]).To(Equal("ro"))
+			Expect(volumeConfig[spectrumscale.AfmCacheState]).To(Equal(simulator.AfmStateInactive))
+
+			_, err = attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			volumeConfig, err = client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig[spectrumscale.AfmCacheState]).To(Equal(simulator.AfmStateActive))
+
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			_, exists = server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+		})
+		It("should not report a cache state for other volumes", func() {
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig).NotTo(HaveKey(spectrumscale.AfmCacheState))
+		})
+	})
+
+	Context("pools", func() {
+		BeforeEach(func() {
+			Expect(server.AddPool(filesystemName, "flash")).To(Succeed())
+		})
+		It("should place a fileset volume in the pool and remove its rule with the fileset", func() {
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.UserSpecifiedPool: "flash"})).To(Succeed())
+			Expect(server.Policy(filesystemName)).To(Equal("RULE 'ubiquity_vol1' SET POOL 'flash' FOR FILESET ('vol1')\nRULE 'ubiquity_default' SET POOL 'system'\n"))
+
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig[spectrumscale.UserSpecifiedPool]).To(Equal("flash"))
+
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			Expect(server.Policy(filesystemName)).To(Equal("RULE 'ubiquity_default' SET POOL 'system'\n"))
+		})
+		It("should keep the rules of the other volumes", func() {
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.UserSpecifiedPool: "flash"})).To(Succeed())
+			Expect(server.AddFileset(filesystemName, "shared")).To(Succeed())
+			Expect(createVolume("vol2", map[string]interface{}{spectrumscale.Type: spectrumscale.TypeLightweight, spectrumscale.TypeFileset: "shared",
+				spectrumscale.Filesystem: filesystemName, spectrumscale.Quota: "1G", spectrumscale.UserSpecifiedPool: "system"})).To(Succeed())
+			Expect(server.Policy(filesystemName)).To(Equal("RULE 'ubiquity_vol2' SET POOL 'system' FOR FILESET ('vol2')\n" +
+				"RULE 'ubiquity_vol1' SET POOL 'flash' FOR FILESET ('vol1')\nRULE 'ubiquity_default' SET POOL 'system'\n"))
+
+			Expect(client.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			Expect(server.Policy(filesystemName)).To(Equal("RULE 'ubiquity_vol2' SET POOL 'system' FOR FILESET ('vol2')\nRULE 'ubiquity_default' SET POOL 'system'\n"))
+		})
+		It("should fail on a pool the filesystem does not have", func() {
+			err = createVolume("vol1", map[string]interface{}{spectrumscale.UserSpecifiedPool: "nlsas"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Pool nlsas does not exist in filesystem gold (pools: system, flash)"))
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+		})
+		It("should delete the fileset if the policy job fails", func() {
+			server.InjectFault(simulator.Fault{Method: "PUT", Path: "filesystems/gold/policies", JobError: "[E] Error while loading policy rules."})
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.UserSpecifiedPool: "flash"})).NotTo(Succeed())
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+			Expect(server.Policy(filesystemName)).To(Equal(""))
+		})
+	})
+
+	Context("usage", func() {
+		getVolumeConfig := func(name string) map[string]interface{} {
+			volumeConfig, err := client.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: name})
+			Expect(err).NotTo(HaveOccurred())
+			return volumeConfig
+		}
+
+		It("should report the usage of a fileset volume and the free space of the filesystem", func() {
+			server.SizeKB = 1024 * 1024
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(volumeMountpoint, "data"), make([]byte, 8192), 0644)).To(Succeed())
+
+			volumeConfig := getVolumeConfig("vol1")
+			Expect(volumeConfig[spectrumscale.UsedBytes]).To(Equal(int64(8192)))
+			Expect(volumeConfig[spectrumscale.UsedInodes]).To(Equal(int64(1)))
+			Expect(volumeConfig[spectrumscale.FilesystemFree]).To(Equal(int64(1024*1024*1024 - 8192)))
+			Expect(volumeConfig).NotTo(HaveKey(spectrumscale.QuotaLimit))
+
+			volume, err := client.GetVolume(resources.GetVolumeRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volume.Usage).To(Equal(&resources.VolumeUsage{UsedBytes: 8192, UsedInodes: 1, FreeBytes: 1024*1024*1024 - 8192}))
+
+			capacity, err := client.(resources.CapacityReporter).GetCapacity(resources.GetCapacityRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(capacity).To(Equal([]resources.ServiceCapacity{{Name: filesystemName, TotalCapacity: 1024 * 1024 * 1024,
+				UsedCapacity: 8192, FreeCapacity: 1024*1024*1024 - 8192, MaxFreeForProvisioning: 1024*1024*1024 - 8192}}))
+		})
+		It("should account the usage of a lightweight volume without quota with du", func() {
+			Expect(server.AddFileset(filesystemName, "shared")).To(Succeed())
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Type: spectrumscale.TypeLightweight, spectrumscale.TypeFileset: "shared", spectrumscale.Filesystem: filesystemName})).To(Succeed())
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(volumeMountpoint, "data"), make([]byte, 8192), 0644)).To(Succeed())
+
+			volumeConfig := getVolumeConfig("vol1")
+			Expect(volumeConfig[spectrumscale.UsedBytes]).To(BeNumerically(">=", 8192))
+			// the directory and its file
+			Expect(volumeConfig[spectrumscale.UsedInodes]).To(Equal(int64(2)))
+			Expect(volumeConfig).To(HaveKey(spectrumscale.FilesystemFree))
+		})
+		It("should cache the usage until the volume is resized", func() {
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Quota: "1G"})).To(Succeed())
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getVolumeConfig("vol1")[spectrumscale.UsedBytes]).To(Equal(int64(0)))
+			requests := len(server.Requests())
+
+			Expect(ioutil.WriteFile(filepath.Join(volumeMountpoint, "data"), make([]byte, 4096), 0644)).To(Succeed())
+			Expect(getVolumeConfig("vol1")[spectrumscale.UsedBytes]).To(Equal(int64(0)))
+			Expect(server.Requests()).To(HaveLen(requests + 2)) // the volume fileset link status and its mountpoint
+
+			Expect(client.(resources.VolumeResizer).ResizeVolume(resources.ResizeVolumeRequest{Name: "vol1", Size: "2G"})).To(Succeed())
+			volumeConfig := getVolumeConfig("vol1")
+			Expect(volumeConfig[spectrumscale.UsedBytes]).To(Equal(int64(4096)))
+			Expect(volumeConfig[spectrumscale.QuotaLimit]).To(Equal(int64(2 * 1024 * 1024 * 1024)))
+		})
+		It("should not cache the usage if the cache timeout is negative", func() {
+			usageCacheTimeout = -1
+			client = newClient(true)
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getVolumeConfig("vol1")[spectrumscale.UsedBytes]).To(Equal(int64(0)))
+			Expect(ioutil.WriteFile(filepath.Join(volumeMountpoint, "data"), make([]byte, 4096), 0644)).To(Succeed())
+			Expect(getVolumeConfig("vol1")[spectrumscale.UsedBytes]).To(Equal(int64(4096)))
+		})
+	})
+
+	Context("failures", func() {
+		It("should fail to create a volume if the fileset job fails", func() {
+			server.InjectFault(simulator.Fault{Method: "POST", Path: "filesystems/gold/filesets", JobError: "EFSSG0072C No space left."})
+			err = createVolume("vol1", map[string]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("No space left"))
+			Expect(err).To(BeAssignableToTypeOf(&connectors.JobFailedError{}))
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+			Expect(server.Jobs()[0].Status).To(Equal(simulator.JobStatusFailed))
+
+			// the volume was not added, so it can be created once the fault is consumed
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+		})
+		It("should delete the fileset if setting the quota fails", func() {
+			server.InjectFault(simulator.Fault{Method: "POST", Path: "filesystems/gold/filesets/vol1/quotas", StatusCode: 500})
+			Expect(createVolume("vol1", map[string]interface{}{spectrumscale.Quota: "1G"})).NotTo(Succeed())
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+		})
+		It("should fail to attach if the link request fails", func() {
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			server.InjectFault(simulator.Fault{Method: "POST", Path: "filesystems/gold/filesets/vol1/link", StatusCode: 503})
+			_, err = attach("vol1")
+			Expect(err).To(HaveOccurred())
+			fileset, _ := server.Fileset(filesystemName, "vol1")
+			Expect(fileset.Status).To(Equal(simulator.FilesetStatusUnlinked))
+		})
+		It("should cancel a job that does not complete within the job timeout", func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			restConfig := server.RestConfig(nodeName)
+			restConfig.JobTimeout = 1
+			restConfig.JobPollInterval = 100
+			connector, err := connectors.NewSpectrumRestV2(logger, restConfig)
+			Expect(err).NotTo(HaveOccurred())
+			server.JobPolls = -1
+			err = connector.CreateFileset(filesystemName, "vol1", map[string]interface{}{})
+			Expect(err).To(BeAssignableToTypeOf(&connectors.JobTimeoutError{}))
+			Expect(err.(*connectors.JobTimeoutError).JobID).To(Equal(uint64(1)))
+			Expect(server.Jobs()[0].Status).To(Equal(simulator.JobStatusCancelled))
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+		})
+		It("should fail to negotiate the REST API version with wrong credentials", func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			config := resources.SpectrumScaleConfig{DefaultFilesystemName: filesystemName, RestConfig: server.RestConfig(nodeName)}
+			config.RestConfig.Password = "wrong"
+			_, err := connectors.GetSpectrumScaleConnector(logger, config)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("authentication of user \\[admin\\] failed"))
+		})
+		It("should fail to activate with wrong credentials", func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			config := resources.SpectrumScaleConfig{DefaultFilesystemName: filesystemName, RestConfig: server.RestConfig(nodeName)}
+			config.RestConfig.Password = "wrong"
+			config.RestConfig.ApiVersion = connectors.RestApiVersionV2
+			connector, err := connectors.GetSpectrumScaleConnector(logger, config)
+			Expect(err).NotTo(HaveOccurred())
+			client, err := spectrumscale.NewSpectrumLocalClientWithConnectors(logger, connector, utils.NewExecutor(), config, newMemDataModel())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Activate(resources.ActivateRequest{})).NotTo(Succeed())
+		})
+	})
+
+	Context("NFS exports", func() {
+		It("should export and unexport a linked fileset", func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			connector, err := connectors.NewSpectrumRestV2(logger, server.RestConfig(nodeName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createVolume("vol1", map[string]interface{}{})).To(Succeed())
+			volumeMountpoint, err := attach("vol1")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(connector.ExportNfs(volumeMountpoint, []connectors.NfsClient{{Client: "*", AccessType: "RW"}})).To(Succeed())
+			Expect(server.Exports()).To(Equal(map[string][]string{volumeMountpoint: {"*(Access_Type=RW)"}}))
+			Expect(connector.UnexportNfs(volumeMountpoint)).To(Succeed())
+			Expect(server.Exports()).To(BeEmpty())
+			Expect(connector.UnexportNfs(volumeMountpoint)).NotTo(Succeed())
+		})
+	})
+
+	Context("NFS volumes", func() {
+		var (
+			nfsClient resources.StorageClient
+			dataModel *memDataModel
+		)
+
+		BeforeEach(func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			config := resources.SpectrumScaleConfig{DefaultFilesystemName: filesystemName, RestConfig: server.RestConfig(nodeName), ForceDelete: true, NfsServerAddr: "ces.example.com"}
+			connector, err := connectors.GetSpectrumScaleConnector(logger, config)
+			Expect(err).NotTo(HaveOccurred())
+			dataModel = newMemDataModel()
+			nfsClient, err = spectrumscale.NewSpectrumNfsLocalClientWithConnectors(logger, connector, utils.NewExecutor(), config, dataModel)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nfsClient.Activate(resources.ActivateRequest{})).To(Succeed())
+		})
+		createNfsVolume := func(name string, opts map[string]interface{}) error {
+			return nfsClient.CreateVolume(resources.CreateVolumeRequest{Name: name, Backend: resources.SpectrumScaleNFS, Opts: opts})
+		}
+		updateNfsExport := func(name string, opts map[string]interface{}) error {
+			return nfsClient.(resources.NfsExportUpdater).UpdateNfsExport(resources.UpdateNfsExportRequest{Name: name, Opts: opts})
+		}
+
+		It("should export a new volume to the clients with their options", func() {
+			opts := map[string]interface{}{"nfsClients": "10.0.0.0/24; host1", "nfsSquash": "root_squash", "nfsProtocols": "4"}
+			Expect(createNfsVolume("vol1", opts)).To(Succeed())
+			clients := []string{"10.0.0.0/24(Access_Type=RW,Squash=root_squash,Protocols=4)", "host1(Access_Type=RW,Squash=root_squash,Protocols=4)"}
+			Expect(server.Exports()).To(Equal(map[string][]string{filepath.Join(mountpoint, "vol1"): clients}))
+
+			volumeConfig, err := nfsClient.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig["nfsClientConfig"]).To(Equal(strings.Join(clients, ";")))
+			Expect(volumeConfig["nfs_share"]).To(Equal("ces.example.com:" + filepath.Join(mountpoint, "vol1")))
+
+			Expect(nfsClient.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			Expect(server.Exports()).To(BeEmpty())
+		})
+
+		It("should override the options of the plugin client config", func() {
+			opts := map[string]interface{}{"nfsClientConfig": "*(Access_Type=RW)", "nfsAccessType": "ro", "nfsSecType": "krb5:krb5p"}
+			Expect(createNfsVolume("vol1", opts)).To(Succeed())
+			Expect(server.Exports()).To(Equal(map[string][]string{filepath.Join(mountpoint, "vol1"): {"*(Access_Type=RO,SecType=krb5:krb5p)"}}))
+		})
+
+		It("should validate the export options before it creates the volume", func() {
+			Expect(createNfsVolume("vol1", map[string]interface{}{"nfsClients": "host1", "nfsSquash": "squash_all"})).NotTo(Succeed())
+			Expect(createNfsVolume("vol1", map[string]interface{}{"nfsClientConfig": "host1(Anonymous_uid=0)"})).NotTo(Succeed())
+			Expect(createNfsVolume("vol1", map[string]interface{}{"nfsAccessType": "RO"})).NotTo(Succeed())
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+			Expect(server.Exports()).To(BeEmpty())
+		})
+
+		It("should add, change and remove the clients of an existing export", func() {
+			Expect(createNfsVolume("vol1", map[string]interface{}{"nfsClients": "10.0.0.0/24;host1"})).To(Succeed())
+
+			Expect(updateNfsExport("vol1", map[string]interface{}{"nfsClients": "10.0.0.0/24;host2", "nfsAccessType": "RO"})).To(Succeed())
+			clients := []string{"10.0.0.0/24(Access_Type=RO)", "host2(Access_Type=RO)"}
+			Expect(server.Exports()).To(Equal(map[string][]string{filepath.Join(mountpoint, "vol1"): clients}))
+			volumeConfig, err := nfsClient.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig["nfsClientConfig"]).To(Equal(strings.Join(clients, ";")))
+
+			Expect(updateNfsExport("vol1", map[string]interface{}{"nfsSquash": "all_squash"})).To(Succeed())
+			Expect(server.Exports()).To(Equal(map[string][]string{filepath.Join(mountpoint, "vol1"): {
+				"10.0.0.0/24(Access_Type=RO,Squash=all_squash)", "host2(Access_Type=RO,Squash=all_squash)"}}))
+		})
+
+		It("should update the export of a volume whose clients are not recorded", func() {
+			Expect(createNfsVolume("vol1", map[string]interface{}{"nfsClientConfig": "host1(Access_Type=RW,Squash=root_squash,SecType=sys,Protocols=3:4)"})).To(Succeed())
+			Expect(dataModel.UpdateVolumeNfsClientConfig("vol1", "")).To(Succeed())
+
+			Expect(updateNfsExport("vol1", map[string]interface{}{"nfsClients": "host1;host2"})).To(Succeed())
+			clients := []string{"host1(Access_Type=RW,Squash=root_squash,SecType=sys,Protocols=3:4)", "host2(Access_Type=RW)"}
+			Expect(server.Exports()).To(Equal(map[string][]string{filepath.Join(mountpoint, "vol1"): clients}))
+			volumeConfig, err := nfsClient.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig["nfsClientConfig"]).To(Equal(strings.Join(clients, ";")))
+		})
+
+		It("should record the clients of the export after a failed update", func() {
+			Expect(createNfsVolume("vol1", map[string]interface{}{"nfsClients": "host1"})).To(Succeed())
+			Expect(dataModel.UpdateVolumeNfsClientConfig("vol1", "stale(Access_Type=RO)")).To(Succeed())
+			server.InjectFault(simulator.Fault{Method: "PUT", Path: "nfs/exports/" + url.QueryEscape(filepath.Join(mountpoint, "vol1")), JobError: "EFSSG0050I NFS configuration change failed."})
+
+			Expect(updateNfsExport("vol1", map[string]interface{}{"nfsClients": "host2"})).NotTo(Succeed())
+			volumeConfig, err := nfsClient.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig["nfsClientConfig"]).To(Equal("host1(Access_Type=RW)"))
+		})
+
+		It("should keep the export if the new options are invalid", func() {
+			Expect(createNfsVolume("vol1", map[string]interface{}{"nfsClients": "host1"})).To(Succeed())
+			Expect(updateNfsExport("vol1", map[string]interface{}{"nfsProtocols": "2"})).NotTo(Succeed())
+			Expect(updateNfsExport("vol1", map[string]interface{}{"nfsClients": ""})).NotTo(Succeed())
+			Expect(updateNfsExport("vol1", map[string]interface{}{"quota": "1G"})).NotTo(Succeed())
+			Expect(updateNfsExport("vol2", map[string]interface{}{"nfsAccessType": "RO"})).NotTo(Succeed())
+			Expect(server.Exports()).To(Equal(map[string][]string{filepath.Join(mountpoint, "vol1"): {"host1(Access_Type=RW)"}}))
+		})
+	})
+
+	Context("SMB shares", func() {
+		var smbClient resources.StorageClient
+
+		BeforeEach(func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			config := resources.SpectrumScaleConfig{DefaultFilesystemName: filesystemName, RestConfig: server.RestConfig(nodeName), ForceDelete: true, SmbServerAddr: "ces.example.com"}
+			connector, err := connectors.GetSpectrumScaleConnector(logger, config)
+			Expect(err).NotTo(HaveOccurred())
+			smbClient, err = spectrumscale.NewSpectrumSmbLocalClientWithConnectors(logger, connector, utils.NewExecutor(), config, newMemDataModel())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(smbClient.Activate(resources.ActivateRequest{})).To(Succeed())
+		})
+
+		It("should share a new volume and remove the share with the volume", func() {
+			opts := map[string]interface{}{"smbOptions": "browseable=yes; read only=no"}
+			Expect(smbClient.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Backend: resources.SpectrumScaleSMB, Opts: opts})).To(Succeed())
+			Expect(server.Shares()).To(Equal(map[string]string{"vol1": filepath.Join(mountpoint, "vol1")}))
+			Expect(server.ShareOptions("vol1")).To(Equal(map[string]string{"browseable": "yes", "read only": "no"}))
+
+			volumeConfig, err := smbClient.GetVolumeConfig(resources.GetVolumeConfigRequest{Name: "vol1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeConfig["smb_share"]).To(Equal("//ces.example.com/vol1"))
+			smbShare, err := smbClient.Attach(resources.AttachRequest{Name: "vol1", Host: nodeName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(smbShare).To(Equal("//ces.example.com/vol1"))
+
+			Expect(smbClient.RemoveVolume(resources.RemoveVolumeRequest{Name: "vol1"})).To(Succeed())
+			Expect(server.Shares()).To(BeEmpty())
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+		})
+
+		It("should fail with invalid smb options", func() {
+			opts := map[string]interface{}{"smbOptions": "browseable"}
+			Expect(smbClient.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Backend: resources.SpectrumScaleSMB, Opts: opts})).NotTo(Succeed())
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+		})
+
+		It("should fail for a volume name which is not a share name", func() {
+			Expect(smbClient.CreateVolume(resources.CreateVolumeRequest{Name: "vol:1", Backend: resources.SpectrumScaleSMB, Opts: map[string]interface{}{}})).NotTo(Succeed())
+			Expect(server.Shares()).To(BeEmpty())
+		})
+
+		It("should remove the volume if the share cannot be created", func() {
+			logger := log.New(os.Stdout, "spectrum: ", log.Lshortfile|log.LstdFlags)
+			connector, err := connectors.NewSpectrumRestV2(logger, server.RestConfig(nodeName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(connector.ExportSmb("vol1", mountpoint, nil)).To(Succeed())
+
+			Expect(smbClient.CreateVolume(resources.CreateVolumeRequest{Name: "vol1", Backend: resources.SpectrumScaleSMB, Opts: map[string]interface{}{}})).NotTo(Succeed())
+			Expect(server.Shares()).To(Equal(map[string]string{"vol1": mountpoint}))
+			_, exists := server.Fileset(filesystemName, "vol1")
+			Expect(exists).To(BeFalse())
+			_, err = smbClient.GetVolume(resources.GetVolumeRequest{Name: "vol1"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
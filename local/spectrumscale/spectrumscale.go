@@ -23,6 +23,8 @@ import (
 
 	"os"
 	"path"
+	"strconv"
+	"strings"
 
 	"fmt"
 
@@ -43,18 +45,32 @@ type spectrumLocalClient struct {
 	isMounted      bool
 	config         resources.SpectrumScaleConfig
 	activationLock *sync.RWMutex
+	policyLock     *sync.Mutex // serializes the read-modify-write of the filesystem policies
+	attachLock     *sync.Mutex // serializes linking and unlinking filesets with the attachments of their volumes
+	usageCache     *usageCache
 }
 
 const (
 	Type            string = "type"
 	TypeFileset     string = "fileset"
 	TypeLightweight string = "lightweight"
+	TypeAfm         string = "afm"
 
 	FilesetID string = "fileset"
 	Directory string = "directory"
 	Quota     string = "quota"
 
-	Filesystem string = "filesystem"
+	UsedBytes      string = "usedBytes"
+	UsedInodes     string = "usedInodes"
+	QuotaLimit     string = "quotaLimit"
+	InodeLimit     string = "inodeLimit"
+	FilesystemFree string = "filesystemFreeBytes"
+
+	AfmCacheState  string = "afmCacheState"
+	AfmDefaultMode string = "ro" // afm volumes present the remote dataset read-only unless another afm-mode is set
+
+	Filesystem   string = "filesystem"
+	JunctionPath string = "junctionPath"
 
 	IsPreexisting string = "isPreexisting"
 
@@ -72,16 +88,32 @@ func NewSpectrumLocalClient(logger *log.Logger, config resources.UbiquityServerC
 }
 
 func NewSpectrumLocalClientWithConnectors(logger *log.Logger, connector connectors.SpectrumScaleConnector, spectrumExecutor utils.Executor, config resources.SpectrumScaleConfig, datamodel SpectrumDataModel) (resources.StorageClient, error) {
-	err := datamodel.CreateVolumeTable()
+	err := validateJunctionPathTemplate(config.JunctionPathTemplate)
+	if err != nil {
+		return &spectrumLocalClient{}, err
+	}
+	err = validateDetachPolicy(config.DetachPolicy)
 	if err != nil {
 		return &spectrumLocalClient{}, err
 	}
-	return &spectrumLocalClient{logger: logger, connector: connector, dataModel: datamodel, executor: spectrumExecutor, config: config, activationLock: &sync.RWMutex{}}, nil
+	err = datamodel.CreateVolumeTable()
+	if err != nil {
+		return &spectrumLocalClient{}, err
+	}
+	return &spectrumLocalClient{logger: logger, connector: connector, dataModel: datamodel, executor: spectrumExecutor, config: config, activationLock: &sync.RWMutex{}, policyLock: &sync.Mutex{}, attachLock: &sync.Mutex{}, usageCache: newUsageCache(config.UsageCacheTimeout)}, nil
 }
 
 func newSpectrumLocalClient(logger *log.Logger, config resources.SpectrumScaleConfig, database *gorm.DB, backend string) (*spectrumLocalClient, error) {
 	logger.Println("spectrumLocalClient: init start")
 	defer logger.Println("spectrumLocalClient: init end")
+	err := validateJunctionPathTemplate(config.JunctionPathTemplate)
+	if err != nil {
+		return &spectrumLocalClient{}, err
+	}
+	err = validateDetachPolicy(config.DetachPolicy)
+	if err != nil {
+		return &spectrumLocalClient{}, err
+	}
 	client, err := connectors.GetSpectrumScaleConnector(logger, config)
 	if err != nil {
 		logger.Fatalln(err.Error())
@@ -92,7 +124,7 @@ func newSpectrumLocalClient(logger *log.Logger, config resources.SpectrumScaleCo
 	if err != nil {
 		return &spectrumLocalClient{}, err
 	}
-	return &spectrumLocalClient{logger: logger, connector: client, dataModel: datamodel, config: config, executor: utils.NewExecutor(), activationLock: &sync.RWMutex{}}, nil
+	return &spectrumLocalClient{logger: logger, connector: client, dataModel: datamodel, config: config, executor: utils.NewExecutor(), activationLock: &sync.RWMutex{}, policyLock: &sync.Mutex{}, attachLock: &sync.Mutex{}, usageCache: newUsageCache(config.UsageCacheTimeout)}, nil
 }
 
 func (s *spectrumLocalClient) Activate(activateRequest resources.ActivateRequest) (err error) {
@@ -181,6 +213,24 @@ func (s *spectrumLocalClient) CreateVolume(createVolumeRequest resources.CreateV
 
 	s.logger.Printf("Params for create: %s,%s,%s,%s\n", isExistingVolume, filesystem, existingFileset, existingLightWeightDir)
 
+	err = validateAfm(userSpecifiedType, createVolumeRequest.Opts)
+	if err != nil {
+		s.logger.Printf("Error in validate afm options: %s\n", err.Error())
+		return err
+	}
+
+	err = s.validatePool(filesystem, userSpecifiedType, isExistingVolume, createVolumeRequest.Opts)
+	if err != nil {
+		s.logger.Printf("Error in validate pool: %s\n", err.Error())
+		return err
+	}
+
+	err = s.validateTenant(createVolumeRequest.Opts)
+	if err != nil {
+		s.logger.Printf("Error in validate tenant: %s\n", err.Error())
+		return err
+	}
+
 	if isExistingVolume && userSpecifiedType == TypeFileset {
 		quota, quotaSpecified := createVolumeRequest.Opts[Quota]
 		if quotaSpecified {
@@ -193,7 +243,8 @@ func (s *spectrumLocalClient) CreateVolume(createVolumeRequest resources.CreateV
 		return s.updateDBWithExistingDirectory(filesystem, createVolumeRequest.Name, existingFileset, existingLightWeightDir, createVolumeRequest.Opts)
 	}
 
-	if userSpecifiedType == TypeFileset {
+	// an afm volume is a fileset volume whose fileset is an AFM cache of the afm-target
+	if userSpecifiedType == TypeFileset || userSpecifiedType == TypeAfm {
 		quota, quotaSpecified := createVolumeRequest.Opts[Quota]
 		if quotaSpecified {
 			return s.createFilesetQuotaVolume(filesystem, createVolumeRequest.Name, quota.(string), createVolumeRequest.Opts)
@@ -201,6 +252,10 @@ func (s *spectrumLocalClient) CreateVolume(createVolumeRequest resources.CreateV
 		return s.createFilesetVolume(filesystem, createVolumeRequest.Name, createVolumeRequest.Opts)
 	}
 	if userSpecifiedType == TypeLightweight {
+		quota, quotaSpecified := createVolumeRequest.Opts[Quota]
+		if quotaSpecified {
+			return s.createLightweightQuotaVolume(filesystem, createVolumeRequest.Name, existingFileset, quota.(string), createVolumeRequest.Opts)
+		}
 		return s.createLightweightVolume(filesystem, createVolumeRequest.Name, existingFileset, createVolumeRequest.Opts)
 	}
 	return fmt.Errorf("Internal error")
@@ -220,6 +275,11 @@ func (s *spectrumLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveV
 	if volExists == false {
 		return fmt.Errorf("Volume not found")
 	}
+	s.usageCache.invalidate(volumeUsageKey(removeVolumeRequest.Name))
+
+	if existingVolume.Type == Lightweight && existingVolume.QuotaFileset != "" {
+		return s.removeLightweightQuotaVolume(existingVolume)
+	}
 
 	if existingVolume.Type == Lightweight {
 		err = s.dataModel.DeleteVolume(removeVolumeRequest.Name)
@@ -229,12 +289,11 @@ func (s *spectrumLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveV
 		}
 
 		if s.config.ForceDelete == true && existingVolume.IsPreexisting == false {
-			mountpoint, err := s.connector.GetFilesystemMountpoint(existingVolume.FileSystem)
+			lightweightVolumePath, err := s.getVolumeMountPoint(existingVolume)
 			if err != nil {
 				s.logger.Println(err.Error())
 				return err
 			}
-			lightweightVolumePath := path.Join(mountpoint, existingVolume.Fileset, existingVolume.Directory)
 
 			err = s.executor.RemoveAll(lightweightVolumePath)
 
@@ -253,7 +312,19 @@ func (s *spectrumLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveV
 		return err
 	}
 
-	if isFilesetLinked {
+	// the volumes from an existing fileset share it, it stays linked while one of them is attached
+	s.attachLock.Lock()
+	defer s.attachLock.Unlock()
+	isFilesetUsed, err := s.isFilesetUsedByOtherVolumes(existingVolume)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	if isFilesetUsed && s.config.ForceDelete == true && existingVolume.IsPreexisting == false {
+		return fmt.Errorf("Cannot delete fileset %s of volume %s, it is used by other volumes", existingVolume.Fileset, existingVolume.Volume.Name)
+	}
+
+	if isFilesetLinked && !isFilesetUsed {
 		err := s.connector.UnlinkFileset(existingVolume.FileSystem, existingVolume.Fileset)
 
 		if err != nil {
@@ -268,6 +339,11 @@ func (s *spectrumLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveV
 		return err
 	}
 	if s.config.ForceDelete == true && existingVolume.IsPreexisting == false {
+		err = s.removePlacementRule(existingVolume)
+		if err != nil {
+			s.logger.Println(err.Error())
+			return err
+		}
 		err = s.connector.DeleteFileset(existingVolume.FileSystem, existingVolume.Fileset)
 
 		if err != nil {
@@ -291,7 +367,12 @@ func (s *spectrumLocalClient) GetVolume(getVolumeRequest resources.GetVolumeRequ
 		return resources.Volume{}, fmt.Errorf("Volume not found")
 	}
 
-	return resources.Volume{Name: existingVolume.Volume.Name, Backend: existingVolume.Volume.Backend, Mountpoint: existingVolume.Volume.Mountpoint}, nil
+	volume := resources.Volume{Name: existingVolume.Volume.Name, Backend: existingVolume.Volume.Backend, Mountpoint: existingVolume.Volume.Mountpoint}
+	// the volume is reported without usage if the cluster does not report it
+	if usage, err := s.getVolumeUsage(existingVolume); err == nil {
+		volume.Usage = &usage
+	}
+	return volume, nil
 }
 
 func (s *spectrumLocalClient) GetVolumeConfig(getVolumeConfigRequest resources.GetVolumeConfigRequest) (volumeConfigDetails map[string]interface{}, err error) {
@@ -336,6 +417,44 @@ func (s *spectrumLocalClient) GetVolumeConfig(getVolumeConfigRequest resources.G
 		if existingVolume.Type == Lightweight {
 			volumeConfigDetails[Directory] = existingVolume.Directory
 		}
+		if existingVolume.JunctionPath != "" {
+			volumeConfigDetails[JunctionPath] = existingVolume.JunctionPath
+		}
+		if existingVolume.NfsClientConfig != "" {
+			volumeConfigDetails[UserSpecifiedNfsClientConfig] = existingVolume.NfsClientConfig
+		}
+
+		if existingVolume.Quota != "" {
+			volumeConfigDetails[Quota] = existingVolume.Quota
+		}
+		if existingVolume.Pool != "" {
+			volumeConfigDetails[UserSpecifiedPool] = existingVolume.Pool
+		}
+		if existingVolume.AfmTarget != "" {
+			volumeConfigDetails[connectors.UserSpecifiedAfmTarget] = existingVolume.AfmTarget
+			volumeConfigDetails[connectors.UserSpecifiedAfmMode] = existingVolume.AfmMode
+			// the volume config is reported without cache state if the cluster does not report it
+			afmState, err := s.connector.GetFilesetAfmState(existingVolume.FileSystem, existingVolume.Fileset)
+			if err == nil {
+				volumeConfigDetails[AfmCacheState] = afmState
+			} else {
+				s.logger.Printf("Failed to get the AFM state of volume %s: %v\n", existingVolume.Volume.Name, err)
+			}
+		}
+
+		// the volume config is reported without usage if the cluster does not report it
+		usage, err := s.getVolumeUsage(existingVolume)
+		if err == nil {
+			volumeConfigDetails[UsedBytes] = usage.UsedBytes
+			volumeConfigDetails[UsedInodes] = usage.UsedInodes
+			volumeConfigDetails[FilesystemFree] = usage.FreeBytes
+			if usage.LimitBytes > 0 {
+				volumeConfigDetails[QuotaLimit] = usage.LimitBytes
+			}
+			if usage.LimitInodes > 0 {
+				volumeConfigDetails[InodeLimit] = usage.LimitInodes
+			}
+		}
 
 		return volumeConfigDetails, nil
 	}
@@ -364,6 +483,9 @@ func (s *spectrumLocalClient) Attach(attachRequest resources.AttachRequest) (vol
 		return "", err
 	}
 
+	s.attachLock.Lock()
+	defer s.attachLock.Unlock()
+
 	isFilesetLinked, err := s.connector.IsFilesetLinked(existingVolume.FileSystem, existingVolume.Fileset)
 
 	if err != nil {
@@ -372,7 +494,7 @@ func (s *spectrumLocalClient) Attach(attachRequest resources.AttachRequest) (vol
 	}
 
 	if isFilesetLinked == false {
-		err = s.connector.LinkFileset(existingVolume.FileSystem, existingVolume.Fileset)
+		err = s.linkVolumeFileset(existingVolume)
 
 		if err != nil {
 			s.logger.Println(err.Error())
@@ -380,6 +502,22 @@ func (s *spectrumLocalClient) Attach(attachRequest resources.AttachRequest) (vol
 		}
 	}
 
+	if existingVolume.Type == Lightweight && existingVolume.QuotaFileset != "" {
+		isQuotaFilesetLinked, err := s.connector.IsFilesetLinked(existingVolume.FileSystem, existingVolume.QuotaFileset)
+		if err != nil {
+			s.logger.Println(err.Error())
+			return "", err
+		}
+
+		if isQuotaFilesetLinked == false {
+			err = s.connector.LinkFilesetAt(existingVolume.FileSystem, existingVolume.QuotaFileset, volumeMountpoint)
+			if err != nil {
+				s.logger.Println(err.Error())
+				return "", err
+			}
+		}
+	}
+
 	existingVolume.Volume.Mountpoint = volumeMountpoint
 
 	err = s.dataModel.UpdateVolumeMountpoint(attachRequest.Name, volumeMountpoint)
@@ -389,6 +527,13 @@ func (s *spectrumLocalClient) Attach(attachRequest resources.AttachRequest) (vol
 		return "", err
 	}
 
+	err = s.dataModel.InsertAttachment(attachRequest.Name, attachRequest.Host)
+
+	if err != nil {
+		s.logger.Println(err.Error())
+		return "", err
+	}
+
 	return volumeMountpoint, nil
 }
 
@@ -421,7 +566,30 @@ func (s *spectrumLocalClient) Detach(detachRequest resources.DetachRequest) (err
 		return fmt.Errorf("volume not attached")
 	}
 
-	err = s.dataModel.UpdateVolumeMountpoint(detachRequest.Name, "")
+	s.attachLock.Lock()
+	defer s.attachLock.Unlock()
+
+	err = s.dataModel.DeleteAttachment(detachRequest.Name, detachRequest.Host)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+
+	// the volume keeps its mountpoint while it is attached to other hosts
+	attachments, err := s.dataModel.ListAttachments(detachRequest.Name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	if len(attachments) == 0 {
+		err = s.dataModel.UpdateVolumeMountpoint(detachRequest.Name, "")
+		if err != nil {
+			s.logger.Println(err.Error())
+			return err
+		}
+	}
+
+	err = s.unlinkUnusedFilesets(existingVolume)
 	if err != nil {
 		s.logger.Println(err.Error())
 		return err
@@ -458,19 +626,90 @@ func (s *spectrumLocalClient) ListVolumes(listVolumesRequest resources.ListVolum
 	return volumesInDb, nil
 }
 
+// ResizeVolume sets the quota of a fileset volume, or of the dependent fileset of a lightweight volume
+// with quota, to the requested size and verifies it was applied.
+// A fileset volume without a quota becomes a fileset volume with quota.
+func (s *spectrumLocalClient) ResizeVolume(resizeVolumeRequest resources.ResizeVolumeRequest) (err error) {
+	s.logger.Println("spectrumLocalClient: ResizeVolume start")
+	defer s.logger.Println("spectrumLocalClient: ResizeVolume end")
+
+	name := resizeVolumeRequest.Name
+	existingVolume, volExists, err := s.dataModel.GetVolume(name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	if !volExists {
+		return &resources.VolumeNotFoundError{VolName: name}
+	}
+	quotaFileset := existingVolume.Fileset
+	if existingVolume.Type == Lightweight {
+		quotaFileset = existingVolume.QuotaFileset
+	}
+	if quotaFileset == "" {
+		return fmt.Errorf("Volume %s is a lightweight volume without quota, only fileset volumes and lightweight volumes with quota can be resized", name)
+	}
+
+	quota := resizeVolumeRequest.Size
+	quotaBytes, err := utils.ConvertToBytes(s.logger, quota)
+	if err != nil {
+		s.logger.Printf("Invalid quota %s: %v\n", quota, err)
+		return fmt.Errorf("Invalid quota '%s' for volume %s: %s", quota, name, err.Error())
+	}
+
+	err = s.connector.SetFilesetQuota(existingVolume.FileSystem, quotaFileset, utils.FormatQuantity(quotaBytes))
+	if err != nil {
+		return err
+	}
+
+	listedQuota, err := s.connector.ListFilesetQuota(existingVolume.FileSystem, quotaFileset)
+	if err != nil {
+		return err
+	}
+	if !isSameQuota(s.logger, listedQuota, quota) {
+		s.logger.Printf("Mismatch between requested quota %s and listed quota %s for fileset %s\n", quota, listedQuota, quotaFileset)
+		return fmt.Errorf("Mismatch between requested quota %s and listed quota %s for fileset %s", quota, listedQuota, quotaFileset)
+	}
+
+	err = s.dataModel.UpdateVolumeQuota(name, quota)
+	if err != nil {
+		return err
+	}
+	s.usageCache.invalidate(volumeUsageKey(name))
+
+	s.logger.Printf("Resized volume %s with fileset %s to quota %s\n", name, quotaFileset, quota)
+	return nil
+}
+
 func (s *spectrumLocalClient) createFilesetVolume(filesystem, name string, opts map[string]interface{}) error {
 	s.logger.Println("spectrumLocalClient: createFilesetVolume start")
 	defer s.logger.Println("spectrumLocalClient: createFilesetVolume end")
 
 	filesetName := generateFilesetName(name)
 
-	err := s.connector.CreateFileset(filesystem, filesetName, opts)
+	junctionPath, err := s.newJunctionPath(name, filesetName, opts)
+	if err != nil {
+		return err
+	}
+
+	err = s.connector.CreateFileset(filesystem, filesetName, opts)
 
 	if err != nil {
 		s.logger.Printf("Error creating fileset %v", err)
 		return err
 	}
 
+	err = s.setPlacementRule(filesystem, filesetName, opts)
+
+	if err != nil {
+		deleteErr := s.connector.DeleteFileset(filesystem, filesetName)
+		if deleteErr != nil {
+			return fmt.Errorf("Error setting placement rule (rollback error on delete fileset %s - manual cleanup needed)", filesetName)
+		}
+		return err
+	}
+
+	opts = setJunctionPathOpt(opts, junctionPath)
 	err = s.dataModel.InsertFilesetVolume(filesetName, name, filesystem, false, opts)
 
 	if err != nil {
@@ -486,15 +725,27 @@ func (s *spectrumLocalClient) createFilesetQuotaVolume(filesystem, name, quota s
 	s.logger.Println("spectrumLocalClient: createFilesetQuotaVolume start")
 	defer s.logger.Println("spectrumLocalClient: createFilesetQuotaVolume end")
 
+	quotaBytes, err := utils.ConvertToBytes(s.logger, quota)
+	if err != nil {
+		s.logger.Printf("Invalid quota %s: %v\n", quota, err)
+		return fmt.Errorf("Invalid quota '%s' for volume %s: %s", quota, name, err.Error())
+	}
+
 	filesetName := generateFilesetName(name)
 
-	err := s.connector.CreateFileset(filesystem, filesetName, opts)
+	junctionPath, err := s.newJunctionPath(name, filesetName, opts)
+	if err != nil {
+		return err
+	}
+
+	err = s.connector.CreateFileset(filesystem, filesetName, opts)
 
 	if err != nil {
 		return err
 	}
 
-	err = s.connector.SetFilesetQuota(filesystem, filesetName, quota)
+	// the connectors accept only whole K, M, G and T sizes, so send the exact size in the largest such unit
+	err = s.connector.SetFilesetQuota(filesystem, filesetName, utils.FormatQuantity(quotaBytes))
 
 	if err != nil {
 		deleteErr := s.connector.DeleteFileset(filesystem, filesetName)
@@ -504,6 +755,17 @@ func (s *spectrumLocalClient) createFilesetQuotaVolume(filesystem, name, quota s
 		return err
 	}
 
+	err = s.setPlacementRule(filesystem, filesetName, opts)
+
+	if err != nil {
+		deleteErr := s.connector.DeleteFileset(filesystem, filesetName)
+		if deleteErr != nil {
+			return fmt.Errorf("Error setting placement rule (rollback error on delete fileset %s - manual cleanup needed)", filesetName)
+		}
+		return err
+	}
+
+	opts = setJunctionPathOpt(opts, junctionPath)
 	err = s.dataModel.InsertFilesetQuotaVolume(filesetName, quota, name, filesystem, false, opts)
 
 	if err != nil {
@@ -536,13 +798,13 @@ func (s *spectrumLocalClient) createLightweightVolume(filesystem, name, fileset
 
 	lightweightVolumeName := generateLightweightVolumeName(name)
 
-	mountpoint, err := s.connector.GetFilesystemMountpoint(filesystem)
+	junctionPath, filesetJunction, err := s.getParentFilesetJunction(filesystem, fileset)
 	if err != nil {
 		s.logger.Println(err.Error())
 		return err
 	}
 	//open permissions on enclosing fileset
-	args := []string{"777", path.Join(mountpoint, fileset)}
+	args := []string{"777", filesetJunction}
 	_, err = s.executor.Execute("chmod", args)
 
 	if err != nil {
@@ -550,7 +812,7 @@ func (s *spectrumLocalClient) createLightweightVolume(filesystem, name, fileset
 		return err
 	}
 
-	lightweightVolumePath := path.Join(mountpoint, fileset, lightweightVolumeName)
+	lightweightVolumePath := path.Join(filesetJunction, lightweightVolumeName)
 	args = []string{"-p", lightweightVolumePath}
 	_, err = s.executor.Execute("mkdir", args)
 
@@ -560,6 +822,7 @@ func (s *spectrumLocalClient) createLightweightVolume(filesystem, name, fileset
 	}
 	s.logger.Printf("creating directory for lwv: %s\n", lightweightVolumePath)
 
+	opts = setJunctionPathOpt(opts, junctionPath)
 	err = s.dataModel.InsertLightweightVolume(fileset, lightweightVolumeName, name, filesystem, false, opts)
 
 	if err != nil {
@@ -570,6 +833,199 @@ func (s *spectrumLocalClient) createLightweightVolume(filesystem, name, fileset
 	return nil
 }
 
+// createLightweightQuotaVolume creates a lightweight volume whose directory is the junction of a dependent fileset,
+// so that the quota of the volume is enforced as a fileset quota within the parent fileset
+func (s *spectrumLocalClient) createLightweightQuotaVolume(filesystem, name, fileset, quota string, opts map[string]interface{}) error {
+	s.logger.Println("spectrumLocalClient: createLightweightQuotaVolume start")
+	defer s.logger.Println("spectrumLocalClient: createLightweightQuotaVolume end")
+
+	quotaBytes, err := utils.ConvertToBytes(s.logger, quota)
+	if err != nil {
+		s.logger.Printf("Invalid quota %s: %v\n", quota, err)
+		return fmt.Errorf("Invalid quota '%s' for volume %s: %s", quota, name, err.Error())
+	}
+
+	filesetLinked, err := s.connector.IsFilesetLinked(filesystem, fileset)
+	if err != nil {
+		s.logger.Printf("error finding fileset in the filesystem %s\n", err.Error())
+		return err
+	}
+
+	if !filesetLinked {
+		err = s.connector.LinkFileset(filesystem, fileset)
+		if err != nil {
+			s.logger.Println(err.Error())
+			return err
+		}
+	}
+
+	junctionPath, filesetJunction, err := s.getParentFilesetJunction(filesystem, fileset)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	//open permissions on enclosing fileset
+	args := []string{"777", filesetJunction}
+	_, err = s.executor.Execute("chmod", args)
+	if err != nil {
+		s.logger.Printf("Failed update permissions of fileset %s containing LTW volumes with error: %s", fileset, err.Error())
+		return err
+	}
+
+	lightweightVolumeName := generateLightweightVolumeName(name)
+	quotaFilesetName := generateFilesetName(name)
+
+	// a dependent fileset in the inode space of the parent fileset, so the volume files count in the parent inode limit
+	err = s.connector.CreateFileset(filesystem, quotaFilesetName, map[string]interface{}{connectors.UserSpecifiedInodeSpace: fileset})
+	if err != nil {
+		return err
+	}
+
+	lightweightVolumePath := path.Join(filesetJunction, lightweightVolumeName)
+	err = s.connector.LinkFilesetAt(filesystem, quotaFilesetName, lightweightVolumePath)
+	if err != nil {
+		deleteErr := s.connector.DeleteFileset(filesystem, quotaFilesetName)
+		if deleteErr != nil {
+			return fmt.Errorf("Error linking fileset %s at %s (rollback error on delete fileset %s - manual cleanup needed)", quotaFilesetName, lightweightVolumePath, quotaFilesetName)
+		}
+		return err
+	}
+
+	// the connectors accept only whole K, M, G and T sizes, so send the exact size in the largest such unit
+	err = s.connector.SetFilesetQuota(filesystem, quotaFilesetName, utils.FormatQuantity(quotaBytes))
+	if err != nil {
+		unlinkErr := s.connector.UnlinkFileset(filesystem, quotaFilesetName)
+		if unlinkErr != nil {
+			return fmt.Errorf("Error setting quota (rollback error on unlink fileset %s - manual cleanup needed)", quotaFilesetName)
+		}
+		deleteErr := s.connector.DeleteFileset(filesystem, quotaFilesetName)
+		if deleteErr != nil {
+			return fmt.Errorf("Error setting quota (rollback error on delete fileset %s - manual cleanup needed)", quotaFilesetName)
+		}
+		return err
+	}
+
+	err = s.setPlacementRule(filesystem, quotaFilesetName, opts)
+	if err != nil {
+		unlinkErr := s.connector.UnlinkFileset(filesystem, quotaFilesetName)
+		if unlinkErr != nil {
+			return fmt.Errorf("Error setting placement rule (rollback error on unlink fileset %s - manual cleanup needed)", quotaFilesetName)
+		}
+		deleteErr := s.connector.DeleteFileset(filesystem, quotaFilesetName)
+		if deleteErr != nil {
+			return fmt.Errorf("Error setting placement rule (rollback error on delete fileset %s - manual cleanup needed)", quotaFilesetName)
+		}
+		return err
+	}
+
+	opts = setJunctionPathOpt(opts, junctionPath)
+	err = s.dataModel.InsertLightweightQuotaVolume(fileset, lightweightVolumeName, quotaFilesetName, quota, name, filesystem, false, opts)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Printf("Created LightWeight volume at directory path: %s with fileset %s, quota %s\n", lightweightVolumePath, quotaFilesetName, quota)
+	return nil
+}
+
+// removeLightweightQuotaVolume unlinks the dependent fileset of a lightweight volume with quota,
+// and deletes it if force delete is configured
+func (s *spectrumLocalClient) removeLightweightQuotaVolume(existingVolume SpectrumScaleVolume) error {
+	s.logger.Println("spectrumLocalClient: removeLightweightQuotaVolume start")
+	defer s.logger.Println("spectrumLocalClient: removeLightweightQuotaVolume end")
+
+	isFilesetLinked, err := s.connector.IsFilesetLinked(existingVolume.FileSystem, existingVolume.QuotaFileset)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+
+	if isFilesetLinked {
+		err = s.connector.UnlinkFileset(existingVolume.FileSystem, existingVolume.QuotaFileset)
+		if err != nil {
+			s.logger.Println(err.Error())
+			return err
+		}
+	}
+
+	err = s.dataModel.DeleteVolume(existingVolume.Volume.Name)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+
+	if s.config.ForceDelete == true {
+		err = s.removePlacementRule(existingVolume)
+		if err != nil {
+			s.logger.Println(err.Error())
+			return err
+		}
+		err = s.connector.DeleteFileset(existingVolume.FileSystem, existingVolume.QuotaFileset)
+		if err != nil {
+			s.logger.Println(err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// setPlacementRule places the data of the fileset in the pool of the opts with a rule of the filesystem policy
+func (s *spectrumLocalClient) setPlacementRule(filesystem, filesetName string, opts map[string]interface{}) error {
+	pool, poolSpecified := opts[UserSpecifiedPool]
+	if !poolSpecified {
+		return nil
+	}
+	s.policyLock.Lock()
+	defer s.policyLock.Unlock()
+
+	policy, err := s.connector.GetPolicy(filesystem)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	err = s.connector.ChangePolicy(filesystem, connectors.SetFilesetPlacementRule(policy, filesetName, pool.(string)))
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+	s.logger.Printf("Placed fileset %s in pool %s\n", filesetName, pool.(string))
+	return nil
+}
+
+// removePlacementRule removes the placement rule of the fileset of a volume in a pool before the fileset is deleted,
+// since the policy cannot refer to missing filesets
+func (s *spectrumLocalClient) removePlacementRule(volume SpectrumScaleVolume) error {
+	if volume.Pool == "" {
+		return nil
+	}
+	filesetName := volume.Fileset
+	if volume.Type == Lightweight {
+		filesetName = volume.QuotaFileset
+	}
+	s.policyLock.Lock()
+	defer s.policyLock.Unlock()
+
+	policy, err := s.connector.GetPolicy(volume.FileSystem)
+	if err != nil {
+		return err
+	}
+	if !connectors.HasFilesetPlacementRule(policy, filesetName) {
+		return nil
+	}
+	return s.connector.ChangePolicy(volume.FileSystem, connectors.RemoveFilesetPlacementRule(policy, filesetName))
+}
+
+// getQuotaFileset returns the fileset which enforces the quota of the volume, or "" if the volume has no quota
+func getQuotaFileset(volume SpectrumScaleVolume) string {
+	switch volume.Type {
+	case FilesetWithQuota:
+		return volume.Fileset
+	case Lightweight:
+		return volume.QuotaFileset
+	}
+	return ""
+}
+
 func generateLightweightVolumeName(name string) string {
 	return name //TODO: check for convension/valid names
 }
@@ -587,12 +1043,13 @@ func (s *spectrumLocalClient) updateDBWithExistingFileset(filesystem, name, user
 	defer s.logger.Println("spectrumLocalClient: updateDBWithExistingFileset end")
 	s.logger.Printf("User specified fileset: %s\n", userSpecifiedFileset)
 
-	_, err := s.connector.ListFileset(filesystem, userSpecifiedFileset)
+	junctionPath, err := s.existingJunctionPath(filesystem, name, userSpecifiedFileset, opts)
 	if err != nil {
 		s.logger.Printf("Fileset does not exist %v", err.Error())
 		return err
 	}
 
+	opts = setJunctionPathOpt(opts, junctionPath)
 	err = s.dataModel.InsertFilesetVolume(userSpecifiedFileset, name, filesystem, true, opts)
 
 	if err != nil {
@@ -649,13 +1106,20 @@ func (s *spectrumLocalClient) updateDBWithExistingFilesetQuota(filesystem, name,
 			return fmt.Errorf("Mismatch between user-specified %v and listed quota %v for fileset %s", quotasBytes, filesetQuotaBytes, userSpecifiedFileset)
 		}
 	} else {
-		if filesetQuota != quota {
+		if !isSameQuota(s.logger, filesetQuota, quota) {
 			s.logger.Printf("Mismatch between user-specified and listed quota for fileset %s", userSpecifiedFileset)
 			return fmt.Errorf("Mismatch between user-specified and listed quota for fileset %s", userSpecifiedFileset)
 
 		}
 	}
 
+	junctionPath, err := s.existingJunctionPath(filesystem, name, userSpecifiedFileset, opts)
+	if err != nil {
+		s.logger.Println(err.Error())
+		return err
+	}
+
+	opts = setJunctionPathOpt(opts, junctionPath)
 	err = s.dataModel.InsertFilesetQuotaVolume(userSpecifiedFileset, quota, name, filesystem, true, opts)
 
 	if err != nil {
@@ -665,6 +1129,22 @@ func (s *spectrumLocalClient) updateDBWithExistingFilesetQuota(filesystem, name,
 	return nil
 }
 
+// isSameQuota compares quotas by their size in bytes, so 1G and 1024M are the same quota
+func isSameQuota(logger *log.Logger, listedQuota, requestedQuota string) bool {
+	if listedQuota == requestedQuota {
+		return true
+	}
+	listedBytes, err := utils.ConvertToBytes(logger, listedQuota)
+	if err != nil {
+		return false
+	}
+	requestedBytes, err := utils.ConvertToBytes(logger, requestedQuota)
+	if err != nil {
+		return false
+	}
+	return listedBytes == requestedBytes
+}
+
 func (s *spectrumLocalClient) updateDBWithExistingDirectory(filesystem, name, userSpecifiedFileset, userSpecifiedDirectory string, opts map[string]interface{}) error {
 	s.logger.Println("spectrumLocalClient:  updateDBWithExistingDirectory start")
 	defer s.logger.Println("spectrumLocalClient: updateDBWithExistingDirectory end")
@@ -681,13 +1161,13 @@ func (s *spectrumLocalClient) updateDBWithExistingDirectory(filesystem, name, us
 		}
 	}
 
-	mountpoint, err := s.connector.GetFilesystemMountpoint(filesystem)
+	junctionPath, filesetJunction, err := s.getParentFilesetJunction(filesystem, userSpecifiedFileset)
 	if err != nil {
 		s.logger.Println(err.Error())
 		return err
 	}
 
-	directoryPath := path.Join(mountpoint, userSpecifiedFileset, userSpecifiedDirectory)
+	directoryPath := path.Join(filesetJunction, userSpecifiedDirectory)
 
 	_, err = s.executor.Stat(directoryPath)
 
@@ -701,6 +1181,7 @@ func (s *spectrumLocalClient) updateDBWithExistingDirectory(filesystem, name, us
 		return err
 	}
 
+	opts = setJunctionPathOpt(opts, junctionPath)
 	err = s.dataModel.InsertLightweightVolume(userSpecifiedFileset, userSpecifiedDirectory, name, filesystem, true, opts)
 
 	if err != nil {
@@ -722,7 +1203,7 @@ func determineTypeFromRequest(logger *log.Logger, opts map[string]interface{}) (
 		return TypeFileset, nil
 	}
 
-	if userSpecifiedType.(string) != TypeFileset && userSpecifiedType.(string) != TypeLightweight {
+	if userSpecifiedType.(string) != TypeFileset && userSpecifiedType.(string) != TypeLightweight && userSpecifiedType.(string) != TypeAfm {
 		return "", fmt.Errorf("Unknown 'type' = %s specified", userSpecifiedType.(string))
 	}
 
@@ -765,8 +1246,8 @@ func (s *spectrumLocalClient) validateAndParseParams(logger *log.Logger, opts ma
 		if userSpecifiedType == TypeLightweight && existingLightWeightDir != nil {
 			_, quotaSpecified := opts[Quota]
 			if quotaSpecified {
-				logger.Println("'quota' is not supported for lightweight volumes")
-				return true, "", "", "", fmt.Errorf("'quota' is not supported for lightweight volumes")
+				logger.Println("'quota' is not supported for existing lightweight volumes")
+				return true, "", "", "", fmt.Errorf("'quota' is not supported for existing lightweight volumes")
 			}
 			logger.Println("Valid: existing LTWT")
 			return true, filesystem.(string), existingFileset.(string), existingLightWeightDir.(string), nil
@@ -778,13 +1259,6 @@ func (s *spectrumLocalClient) validateAndParseParams(logger *log.Logger, opts ma
 	} else if userSpecifiedType == TypeLightweight {
 		//lightweight -- new
 		if filesystemSpecified && existingFilesetSpecified {
-
-			_, quotaSpecified := opts[Quota]
-			if quotaSpecified {
-				logger.Println("'quota' is not supported for lightweight volumes")
-				return false, "", "", "", fmt.Errorf("'quota' is not supported for lightweight volumes")
-			}
-
 			return false, filesystem.(string), existingFileset.(string), "", nil
 		}
 		return false, "", "", "", fmt.Errorf("'filesystem' and 'fileset' are required opts for using lightweight volumes")
@@ -797,11 +1271,72 @@ func (s *spectrumLocalClient) validateAndParseParams(logger *log.Logger, opts ma
 
 }
 
+// validateAfm checks that the afm options are only set for afm volumes, and sets the default read-only mode.
+// An afm volume always gets a new independent fileset, so it cannot use an existing fileset or directory.
+func validateAfm(userSpecifiedType string, opts map[string]interface{}) error {
+	if userSpecifiedType != TypeAfm {
+		for _, option := range connectors.AfmOptions() {
+			if _, specified := opts[option]; specified {
+				return fmt.Errorf("'%s' is supported for afm volumes only", option)
+			}
+		}
+		return nil
+	}
+
+	_, existingFilesetSpecified := opts[TypeFileset]
+	_, existingLightWeightDirSpecified := opts[Directory]
+	if existingFilesetSpecified || existingLightWeightDirSpecified {
+		return fmt.Errorf("'fileset' and 'directory' are not supported for afm volumes")
+	}
+	if _, afmTargetSpecified := opts[connectors.UserSpecifiedAfmTarget]; !afmTargetSpecified {
+		return fmt.Errorf("'afm-target' is a required opt for afm volumes")
+	}
+	afmMode, afmModeSpecified := opts[connectors.UserSpecifiedAfmMode]
+	if !afmModeSpecified {
+		opts[connectors.UserSpecifiedAfmMode] = AfmDefaultMode
+	} else if !utils.StringInSlice(afmMode.(string), connectors.AfmModes) {
+		return fmt.Errorf("Unknown 'afm-mode' = %s specified, supported modes are %s", afmMode.(string), strings.Join(connectors.AfmModes, ", "))
+	}
+	for _, option := range []string{connectors.UserSpecifiedAfmAsyncDelay, connectors.UserSpecifiedAfmExpirationTimeout,
+		connectors.UserSpecifiedAfmFileLookupRefreshInterval, connectors.UserSpecifiedAfmDirLookupRefreshInterval} {
+		if value, specified := opts[option]; specified {
+			if _, err := strconv.Atoi(value.(string)); err != nil {
+				return fmt.Errorf("Invalid '%s' = %s specified, expected a number of seconds", option, value.(string))
+			}
+		}
+	}
+	return nil
+}
+
+// validatePool checks that a pool is only requested for the volumes that get a fileset of their own,
+// and that the pool exists in the filesystem
+func (s *spectrumLocalClient) validatePool(filesystem, userSpecifiedType string, isExistingVolume bool, opts map[string]interface{}) error {
+	pool, poolSpecified := opts[UserSpecifiedPool]
+	if !poolSpecified {
+		return nil
+	}
+	if isExistingVolume {
+		return fmt.Errorf("'pool' is not supported for existing volumes")
+	}
+	if _, quotaSpecified := opts[Quota]; userSpecifiedType == TypeLightweight && !quotaSpecified {
+		return fmt.Errorf("'pool' is supported for lightweight volumes with 'quota' only")
+	}
+
+	pools, err := s.connector.ListFilesystemPools(filesystem)
+	if err != nil {
+		return err
+	}
+	if !utils.StringInSlice(pool.(string), pools) {
+		return fmt.Errorf("Pool %s does not exist in filesystem %s (pools: %s)", pool.(string), filesystem, strings.Join(pools, ", "))
+	}
+	return nil
+}
+
 func (s *spectrumLocalClient) getVolumeMountPoint(volume SpectrumScaleVolume) (string, error) {
 	s.logger.Println("getVolumeMountPoint start")
 	defer s.logger.Println("getVolumeMountPoint end")
 
-	fsMountpoint, err := s.connector.GetFilesystemMountpoint(volume.FileSystem)
+	filesetJunction, err := s.getFilesetJunction(volume)
 	if err != nil {
 		return "", err
 	}
@@ -814,10 +1349,10 @@ func (s *spectrumLocalClient) getVolumeMountPoint(volume SpectrumScaleVolume) (s
 	//}
 
 	if volume.Type == Lightweight {
-		return path.Join(fsMountpoint, volume.Fileset, volume.Directory), nil
+		return path.Join(filesetJunction, volume.Directory), nil
 	}
 
-	return path.Join(fsMountpoint, volume.Fileset), nil
+	return filesetJunction, nil
 
 }
 func (s *spectrumLocalClient) updatePermissions(name string) error {
@@ -832,10 +1367,6 @@ func (s *spectrumLocalClient) updatePermissions(name string) error {
 	if exists == false {
 		return fmt.Errorf("Cannot determine filesystem for volume: %s", name)
 	}
-	fsMountpoint, err := s.connector.GetFilesystemMountpoint(filesystem.(string))
-	if err != nil {
-		return err
-	}
 	volumeType, exists := volumeConfig[Type]
 	if exists == false {
 		return fmt.Errorf("Cannot determine type for volume: %s", name)
@@ -844,8 +1375,12 @@ func (s *spectrumLocalClient) updatePermissions(name string) error {
 	if exists == false {
 		return fmt.Errorf("Cannot determine filesetId for volume: %s", name)
 	}
+	junctionPath, _ := volumeConfig[JunctionPath].(string)
 	// executor := utils.NewExecutor() // TODO check why its here ( #39: new logger in block_device_mounter_utils)
-	filesetPath := path.Join(fsMountpoint, fileset.(string))
+	filesetPath, err := s.getFilesetJunction(SpectrumScaleVolume{FileSystem: filesystem.(string), Fileset: fileset.(string), JunctionPath: junctionPath})
+	if err != nil {
+		return err
+	}
 	//chmod 777 mountpoint
 	args := []string{"777", filesetPath}
 	_, err = s.executor.Execute("chmod", args)
@@ -19,7 +19,9 @@ package spectrumscale
 import (
 	"fmt"
 	"log"
+	"strings"
 
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
 	"github.com/IBM/ubiquity/resources"
 	"github.com/IBM/ubiquity/utils"
 	"github.com/jinzhu/gorm"
@@ -54,6 +56,17 @@ func NewSpectrumNfsLocalClient(logger *log.Logger, config resources.UbiquityServ
 	return &spectrumNfsLocalClient{config: config.SpectrumScaleConfig, spectrumClient: spectrumClient, executor: utils.NewExecutor()}, nil
 }
 
+func NewSpectrumNfsLocalClientWithConnectors(logger *log.Logger, connector connectors.SpectrumScaleConnector, spectrumExecutor utils.Executor, config resources.SpectrumScaleConfig, datamodel SpectrumDataModel) (resources.StorageClient, error) {
+	if config.NfsServerAddr == "" {
+		return nil, fmt.Errorf("spectrumNfsLocalClient: init: missing required parameter 'spectrumNfsServerAddr'")
+	}
+	spectrumClient, err := NewSpectrumLocalClientWithConnectors(logger, connector, spectrumExecutor, config, datamodel)
+	if err != nil {
+		return nil, err
+	}
+	return &spectrumNfsLocalClient{config: config, spectrumClient: spectrumClient.(*spectrumLocalClient), executor: spectrumExecutor}, nil
+}
+
 func (s *spectrumNfsLocalClient) Activate(activateRequest resources.ActivateRequest) error {
 	s.spectrumClient.logger.Println("spectrumNfsLocalClient: Activate-start")
 	defer s.spectrumClient.logger.Println("spectrumNfsLocalClient: Activate-end")
@@ -106,19 +119,20 @@ func (s *spectrumNfsLocalClient) CreateVolume(createVolumeRequest resources.Crea
 	s.spectrumClient.logger.Printf("spectrumNfsLocalClient: Create-start")
 	defer s.spectrumClient.logger.Println("spectrumNfsLocalClient: Create-end")
 
-	nfsClientConfig, ok := createVolumeRequest.Opts["nfsClientConfig"].(string)
-	if !ok {
-		errorMsg := "Cannot create volume (opts missing required parameter 'nfsClientConfig')"
+	nfsOpts, spectrumOpts := splitNfsExportOpts(createVolumeRequest.Opts)
+	_, nfsClientConfigSpecified := nfsOpts[UserSpecifiedNfsClientConfig]
+	_, nfsClientsSpecified := nfsOpts[UserSpecifiedNfsClients]
+	if !nfsClientConfigSpecified && !nfsClientsSpecified {
+		errorMsg := "Cannot create volume (opts missing required parameter 'nfsClients' or 'nfsClientConfig')"
 		s.spectrumClient.logger.Printf("spectrumNfsLocalClient: Create: Error: %s", errorMsg)
 		return fmt.Errorf(errorMsg)
 	}
-
-	spectrumOpts := make(map[string]interface{})
-	for k, v := range createVolumeRequest.Opts {
-		if k != "nfsClientConfig" {
-			spectrumOpts[k] = v
-		}
+	nfsClients, err := nfsClientsFromOpts(nfsOpts, nil)
+	if err != nil {
+		s.spectrumClient.logger.Printf("spectrumNfsLocalClient: Create: Error: %s", err.Error())
+		return err
 	}
+	createVolumeRequest.Opts = spectrumOpts
 
 	if err := s.spectrumClient.CreateVolume(createVolumeRequest); err != nil {
 		s.spectrumClient.logger.Printf("spectrumNfsLocalClient: error creating volume %#v\n", err)
@@ -140,12 +154,86 @@ func (s *spectrumNfsLocalClient) CreateVolume(createVolumeRequest resources.Crea
 		return err
 	}
 
-	if err := s.exportNfs(createVolumeRequest.Name, nfsClientConfig); err != nil {
+	if err := s.exportNfs(createVolumeRequest.Name, nfsClients); err != nil {
 		s.spectrumClient.logger.Printf("spectrumNfsLocalClient: error exporting volume %#v\n; deleting volume", err)
 		removeVolumeRequest := resources.RemoveVolumeRequest{Name: createVolumeRequest.Name}
 		s.spectrumClient.RemoveVolume(removeVolumeRequest)
 		return err
 	}
+
+	if err := s.spectrumClient.dataModel.UpdateVolumeNfsClientConfig(createVolumeRequest.Name, connectors.FormatNfsClients(nfsClients)); err != nil {
+		s.spectrumClient.logger.Printf("spectrumNfsLocalClient: error recording the NFS clients of volume %#v\n; deleting volume", err)
+		s.RemoveVolume(resources.RemoveVolumeRequest{Name: createVolumeRequest.Name})
+		return err
+	}
+	return nil
+}
+
+// UpdateNfsExport changes the clients of the NFS export of an existing volume and their options
+func (s *spectrumNfsLocalClient) UpdateNfsExport(updateNfsExportRequest resources.UpdateNfsExportRequest) error {
+	s.spectrumClient.logger.Println("spectrumNfsLocalClient: UpdateNfsExport-start")
+	defer s.spectrumClient.logger.Println("spectrumNfsLocalClient: UpdateNfsExport-end")
+
+	for k := range updateNfsExportRequest.Opts {
+		if !utils.StringInSlice(k, nfsExportOpts) {
+			return fmt.Errorf("'%s' is not an NFS export option, the NFS export options are %s", k, strings.Join(nfsExportOpts, ", "))
+		}
+	}
+	nfsOpts, _ := splitNfsExportOpts(updateNfsExportRequest.Opts)
+	if len(nfsOpts) == 0 {
+		return fmt.Errorf("No NFS export options specified for volume %s", updateNfsExportRequest.Name)
+	}
+
+	existingVolume, exists, err := s.spectrumClient.dataModel.GetVolume(updateNfsExportRequest.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &resources.VolumeNotFoundError{VolName: updateNfsExportRequest.Name}
+	}
+	volumeMountpoint, err := s.spectrumClient.getVolumeMountPoint(existingVolume)
+	if err != nil {
+		return err
+	}
+	// the clients are read from the export rather than from the DB, which does not record the clients of the volumes
+	// exported by older versions and is stale if a previous update failed half way
+	currentClients, err := s.spectrumClient.connector.ListNfsExportClients(volumeMountpoint)
+	if err != nil {
+		return err
+	}
+	nfsClients, err := nfsClientsFromOpts(nfsOpts, currentClients)
+	if err != nil {
+		return err
+	}
+
+	addClients, changeClients, removeClients := diffNfsClients(currentClients, nfsClients)
+	if len(addClients) == 0 && len(changeClients) == 0 && len(removeClients) == 0 {
+		s.spectrumClient.logger.Printf("spectrumNfsLocalClient: the NFS export of volume %s is up to date\n", updateNfsExportRequest.Name)
+		return s.recordNfsClients(existingVolume, currentClients)
+	}
+
+	if err = s.spectrumClient.connector.UpdateNfsExport(volumeMountpoint, addClients, changeClients, removeClients); err != nil {
+		// some of the changes may be done, record the clients the export has now
+		if clients, listErr := s.spectrumClient.connector.ListNfsExportClients(volumeMountpoint); listErr == nil {
+			s.recordNfsClients(existingVolume, clients)
+		} else {
+			s.spectrumClient.logger.Printf("spectrumNfsLocalClient: failed to read the NFS export of volume %s after a failed update (error=%s)\n", updateNfsExportRequest.Name, listErr.Error())
+		}
+		return err
+	}
+	return s.recordNfsClients(existingVolume, nfsClients)
+}
+
+// recordNfsClients records the clients of the NFS export of the volume if they changed
+func (s *spectrumNfsLocalClient) recordNfsClients(volume SpectrumScaleVolume, clients []connectors.NfsClient) error {
+	nfsClientConfig := connectors.FormatNfsClients(clients)
+	if nfsClientConfig == volume.NfsClientConfig {
+		return nil
+	}
+	if err := s.spectrumClient.dataModel.UpdateVolumeNfsClientConfig(volume.Volume.Name, nfsClientConfig); err != nil {
+		s.spectrumClient.logger.Printf("spectrumNfsLocalClient: failed to record the NFS clients of volume %s (error=%s)\n", volume.Volume.Name, err.Error())
+		return err
+	}
 	return nil
 }
 
@@ -187,8 +275,22 @@ func (s *spectrumNfsLocalClient) GetVolume(getVolumeRequest resources.GetVolumeR
 	return s.spectrumClient.GetVolume(getVolumeRequest)
 }
 
-func (s *spectrumNfsLocalClient) exportNfs(name, clientConfig string) error {
-	s.spectrumClient.logger.Printf("spectrumNfsLocalClient: ExportNfs start with name=%#v and clientConfig=%#v\n", name, clientConfig)
+func (s *spectrumNfsLocalClient) ResizeVolume(resizeVolumeRequest resources.ResizeVolumeRequest) error {
+	s.spectrumClient.logger.Println("spectrumNfsLocalClient: ResizeVolume-start")
+	defer s.spectrumClient.logger.Println("spectrumNfsLocalClient: ResizeVolume-end")
+
+	return s.spectrumClient.ResizeVolume(resizeVolumeRequest)
+}
+
+func (s *spectrumNfsLocalClient) GetCapacity(getCapacityRequest resources.GetCapacityRequest) ([]resources.ServiceCapacity, error) {
+	s.spectrumClient.logger.Println("spectrumNfsLocalClient: GetCapacity-start")
+	defer s.spectrumClient.logger.Println("spectrumNfsLocalClient: GetCapacity-end")
+
+	return s.spectrumClient.GetCapacity(getCapacityRequest)
+}
+
+func (s *spectrumNfsLocalClient) exportNfs(name string, clients []connectors.NfsClient) error {
+	s.spectrumClient.logger.Printf("spectrumNfsLocalClient: ExportNfs start with name=%#v and clients=%s\n", name, connectors.FormatNfsClients(clients))
 	defer s.spectrumClient.logger.Printf("spectrumNfsLocalClient: ExportNfs end")
 
 	existingVolume, exists, err := s.spectrumClient.dataModel.GetVolume(name)
@@ -207,7 +309,7 @@ func (s *spectrumNfsLocalClient) exportNfs(name, clientConfig string) error {
 		return err
 	}
 
-	return s.spectrumClient.connector.ExportNfs(volumeMountpoint, clientConfig)
+	return s.spectrumClient.connector.ExportNfs(volumeMountpoint, clients)
 }
 
 func (s *spectrumNfsLocalClient) unexportNfs(name string) error {
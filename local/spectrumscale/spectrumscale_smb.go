@@ -0,0 +1,284 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spectrumscale
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	"github.com/jinzhu/gorm"
+)
+
+/*
+The SMB backend exports every volume as a CES SMB share named after the volume, at //<smb server address>/<volume>,
+so that Windows hosts use the filesets of the volumes as well. The opt smbOptions sets the options of the share,
+as key=value pairs separated by ';', e.g. smbOptions="browseable=yes;read only=no".
+*/
+
+const (
+	UserSpecifiedSmbOptions string = "smbOptions"
+
+	smbShareInvalidChars string = "\\/[]:|<>+=;,*?\""
+)
+
+type spectrumSmbLocalClient struct {
+	spectrumClient *spectrumLocalClient
+	config         resources.SpectrumScaleConfig
+}
+
+func NewSpectrumSmbLocalClient(logger *log.Logger, config resources.UbiquityServerConfig, db *gorm.DB) (resources.StorageClient, error) {
+	logger.Println("spectrumSmbLocalClient: init start")
+	defer logger.Println("spectrumSmbLocalClient: init end")
+
+	if config.ConfigPath == "" {
+		return nil, fmt.Errorf("spectrumSmbLocalClient: init: missing required parameter 'spectrumConfigPath'")
+	}
+
+	if config.SpectrumScaleConfig.DefaultFilesystemName == "" {
+		return nil, fmt.Errorf("spectrumSmbLocalClient: init: missing required parameter 'spectrumDefaultFileSystem'")
+	}
+
+	if config.SpectrumScaleConfig.SmbServerAddr == "" {
+		return nil, fmt.Errorf("spectrumSmbLocalClient: init: missing required parameter 'spectrumSmbServerAddr'")
+	}
+
+	spectrumClient, err := newSpectrumLocalClient(logger, config.SpectrumScaleConfig, db, resources.SpectrumScaleSMB)
+	if err != nil {
+		return nil, err
+	}
+	return &spectrumSmbLocalClient{config: config.SpectrumScaleConfig, spectrumClient: spectrumClient}, nil
+}
+
+func NewSpectrumSmbLocalClientWithConnectors(logger *log.Logger, connector connectors.SpectrumScaleConnector, spectrumExecutor utils.Executor, config resources.SpectrumScaleConfig, datamodel SpectrumDataModel) (resources.StorageClient, error) {
+	if config.SmbServerAddr == "" {
+		return nil, fmt.Errorf("spectrumSmbLocalClient: init: missing required parameter 'spectrumSmbServerAddr'")
+	}
+	spectrumClient, err := NewSpectrumLocalClientWithConnectors(logger, connector, spectrumExecutor, config, datamodel)
+	if err != nil {
+		return nil, err
+	}
+	return &spectrumSmbLocalClient{config: config, spectrumClient: spectrumClient.(*spectrumLocalClient)}, nil
+}
+
+func (s *spectrumSmbLocalClient) Activate(activateRequest resources.ActivateRequest) error {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: Activate-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: Activate-end")
+
+	return s.spectrumClient.Activate(activateRequest)
+}
+
+func (s *spectrumSmbLocalClient) ListVolumes(listVolumesRequest resources.ListVolumesRequest) ([]resources.Volume, error) {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: List-volumes-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: List-volumes-end")
+
+	return s.spectrumClient.ListVolumes(listVolumesRequest)
+}
+
+func (s *spectrumSmbLocalClient) Attach(attachRequest resources.AttachRequest) (string, error) {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: Attach-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: Attach-end")
+
+	getVolumeConfigRequest := resources.GetVolumeConfigRequest{Name: attachRequest.Name}
+	volumeConfig, err := s.GetVolumeConfig(getVolumeConfigRequest)
+	if err != nil {
+		return "", err
+	}
+	smbShare, ok := volumeConfig["smb_share"].(string)
+	if !ok {
+		err = fmt.Errorf("error getting SMB share info from volume config for volume %s", attachRequest.Name)
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: error: %v\n", err.Error())
+		return "", err
+	}
+	return smbShare, nil
+}
+
+func (s *spectrumSmbLocalClient) Detach(detachRequest resources.DetachRequest) error {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: Detach-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: Detach-end")
+
+	getVolumeConfigRequest := resources.GetVolumeConfigRequest{Name: detachRequest.Name}
+	_, err := s.spectrumClient.GetVolumeConfig(getVolumeConfigRequest)
+
+	if err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: error in no-op detach for volume %s: %#v\n", detachRequest.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *spectrumSmbLocalClient) CreateVolume(createVolumeRequest resources.CreateVolumeRequest) error {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: Create-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: Create-end")
+
+	if err := validateSmbShareName(createVolumeRequest.Name); err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: Create: Error: %s\n", err.Error())
+		return err
+	}
+
+	smbOptions := make(map[string]string)
+	spectrumOpts := make(map[string]interface{})
+	for k, v := range createVolumeRequest.Opts {
+		if k != UserSpecifiedSmbOptions {
+			spectrumOpts[k] = v
+			continue
+		}
+		var err error
+		smbOptions, err = parseSmbOptions(v)
+		if err != nil {
+			s.spectrumClient.logger.Printf("spectrumSmbLocalClient: Create: Error: %s\n", err.Error())
+			return err
+		}
+	}
+	createVolumeRequest.Opts = spectrumOpts
+
+	if err := s.spectrumClient.CreateVolume(createVolumeRequest); err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: error creating volume %#v\n", err)
+		return err
+	}
+	attachRequest := resources.AttachRequest{Name: createVolumeRequest.Name}
+	if _, err := s.spectrumClient.Attach(attachRequest); err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: error attaching volume %#v\n; deleting volume", err)
+		removeVolumeRequest := resources.RemoveVolumeRequest{Name: createVolumeRequest.Name}
+		s.spectrumClient.RemoveVolume(removeVolumeRequest)
+		return err
+	}
+
+	if err := s.spectrumClient.updatePermissions(createVolumeRequest.Name); err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: error updating permissions of volume %#v\n; deleting volume", err)
+		s.removeUnexportedVolume(createVolumeRequest.Name)
+		return err
+	}
+
+	if err := s.exportSmb(createVolumeRequest.Name, smbOptions); err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: error exporting volume %#v\n; deleting volume", err)
+		s.removeUnexportedVolume(createVolumeRequest.Name)
+		return err
+	}
+	return nil
+}
+
+func (s *spectrumSmbLocalClient) RemoveVolume(removeVolumeRequest resources.RemoveVolumeRequest) error {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: Remove-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: Remove-end")
+
+	if err := s.spectrumClient.connector.UnexportSmb(removeVolumeRequest.Name); err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: Could not unexport volume %s (error=%s)", removeVolumeRequest.Name, err.Error())
+	}
+	return s.removeUnexportedVolume(removeVolumeRequest.Name)
+}
+
+func (s *spectrumSmbLocalClient) GetVolumeConfig(getVolumeConfigRequest resources.GetVolumeConfigRequest) (map[string]interface{}, error) {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: GetVolumeConfig-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: GetVolumeConfig-end")
+
+	volumeConfig, err := s.spectrumClient.GetVolumeConfig(getVolumeConfigRequest)
+	if err != nil {
+		return volumeConfig, err
+	}
+	if _, exists := volumeConfig["mountpoint"]; exists == false {
+		return nil, fmt.Errorf("Volume :%s not found", getVolumeConfigRequest.Name)
+	}
+	smbShare := fmt.Sprintf("//%s/%s", s.config.SmbServerAddr, getVolumeConfigRequest.Name)
+	volumeConfig["smb_share"] = smbShare
+	s.spectrumClient.logger.Printf("spectrumSmbLocalClient: GetVolume: Adding smb_share %s to volume config for volume %s\n", smbShare, getVolumeConfigRequest.Name)
+	return volumeConfig, nil
+}
+
+func (s *spectrumSmbLocalClient) GetVolume(getVolumeRequest resources.GetVolumeRequest) (resources.Volume, error) {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: GetVolume start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: GetVolume finish")
+
+	return s.spectrumClient.GetVolume(getVolumeRequest)
+}
+
+func (s *spectrumSmbLocalClient) ResizeVolume(resizeVolumeRequest resources.ResizeVolumeRequest) error {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: ResizeVolume-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: ResizeVolume-end")
+
+	return s.spectrumClient.ResizeVolume(resizeVolumeRequest)
+}
+
+func (s *spectrumSmbLocalClient) GetCapacity(getCapacityRequest resources.GetCapacityRequest) ([]resources.ServiceCapacity, error) {
+	s.spectrumClient.logger.Println("spectrumSmbLocalClient: GetCapacity-start")
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: GetCapacity-end")
+
+	return s.spectrumClient.GetCapacity(getCapacityRequest)
+}
+
+// removeUnexportedVolume detaches the volume from the SMB server and removes it
+func (s *spectrumSmbLocalClient) removeUnexportedVolume(name string) error {
+	detachRequest := resources.DetachRequest{Name: name}
+	if err := s.spectrumClient.Detach(detachRequest); err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: Could not detach volume %s (error=%s)", name, err.Error())
+	}
+	removeVolumeRequest := resources.RemoveVolumeRequest{Name: name}
+	return s.spectrumClient.RemoveVolume(removeVolumeRequest)
+}
+
+func (s *spectrumSmbLocalClient) exportSmb(name string, smbOptions map[string]string) error {
+	s.spectrumClient.logger.Printf("spectrumSmbLocalClient: ExportSmb start with name=%s and smbOptions=%v\n", name, smbOptions)
+	defer s.spectrumClient.logger.Println("spectrumSmbLocalClient: ExportSmb end")
+
+	existingVolume, exists, err := s.spectrumClient.dataModel.GetVolume(name)
+	if err != nil {
+		s.spectrumClient.logger.Printf("spectrumSmbLocalClient: DbClient.GetVolume returned error %#v\n", err.Error())
+		return err
+	}
+	if exists == false {
+		return fmt.Errorf("Volume %s not found", name)
+	}
+
+	volumeMountpoint, err := s.spectrumClient.getVolumeMountPoint(existingVolume)
+	if err != nil {
+		return err
+	}
+
+	return s.spectrumClient.connector.ExportSmb(name, volumeMountpoint, smbOptions)
+}
+
+// validateSmbShareName checks that the volume name is a valid name of the SMB share of the volume
+func validateSmbShareName(name string) error {
+	if name == "" || len(name) > 80 || strings.ContainsAny(name, smbShareInvalidChars) {
+		return fmt.Errorf("Volume name %s is not a valid SMB share name", name)
+	}
+	return nil
+}
+
+// parseSmbOptions parses the key=value pairs of the smbOptions opt
+func parseSmbOptions(opt interface{}) (map[string]string, error) {
+	optString, ok := opt.(string)
+	if !ok {
+		return nil, fmt.Errorf("Invalid '%s' = %v specified", UserSpecifiedSmbOptions, opt)
+	}
+	smbOptions := make(map[string]string)
+	for _, pair := range strings.Split(optString, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		keyValue := strings.SplitN(pair, "=", 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return nil, fmt.Errorf("Invalid '%s' = %s specified, the options are key=value pairs separated by ';'", UserSpecifiedSmbOptions, optString)
+		}
+		smbOptions[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+	}
+	return smbOptions, nil
+}
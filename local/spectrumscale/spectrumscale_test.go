@@ -25,6 +25,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/IBM/ubiquity/local/spectrumscale"
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
 
 	"github.com/IBM/ubiquity/fakes"
 	"github.com/IBM/ubiquity/resources"
@@ -220,6 +221,90 @@ var _ = Describe("local-client", func() {
 				Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(1))
 			})
 
+			It("should set the quota in the largest whole unit and store the requested quota", func() {
+				opts["quota"] = "1.5Gi"
+				fakeSpectrumScaleConnector.CreateFilesetReturns(nil)
+				fakeSpectrumScaleConnector.SetFilesetQuotaReturns(nil)
+				fakeSpectrumDataModel.InsertFilesetQuotaVolumeReturns(nil)
+
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).ToNot(HaveOccurred())
+				_, _, quota := fakeSpectrumScaleConnector.SetFilesetQuotaArgsForCall(0)
+				Expect(quota).To(Equal("1536M"))
+				_, dbQuota, _, _, _, _ := fakeSpectrumDataModel.InsertFilesetQuotaVolumeArgsForCall(0)
+				Expect(dbQuota).To(Equal("1.5Gi"))
+			})
+
+			It("should fail when the quota is invalid", func() {
+				opts["quota"] = "1.5XB"
+
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+			})
+
+			Context(".WithPool", func() {
+				BeforeEach(func() {
+					opts["pool"] = "flash"
+					fakeSpectrumScaleConnector.ListFilesystemPoolsReturns([]string{"system", "flash"}, nil)
+					fakeSpectrumScaleConnector.GetPolicyReturns("RULE 'admin' SET POOL 'system'\n", nil)
+				})
+
+				It("should add a placement rule for the fileset and store the pool", func() {
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fakeSpectrumScaleConnector.ChangePolicyCallCount()).To(Equal(1))
+					filesystem, policy := fakeSpectrumScaleConnector.ChangePolicyArgsForCall(0)
+					Expect(filesystem).To(Equal(fakeSpectrumScaleConnector.ListFilesystemPoolsArgsForCall(0)))
+					Expect(policy).To(Equal("RULE 'ubiquity_fake-fileset' SET POOL 'flash' FOR FILESET ('fake-fileset')\nRULE 'admin' SET POOL 'system'\n"))
+					_, _, _, _, dbOpts := fakeSpectrumDataModel.InsertFilesetVolumeArgsForCall(0)
+					Expect(dbOpts["pool"]).To(Equal("flash"))
+				})
+
+				It("should place a fileset with quota in the pool after setting the quota", func() {
+					opts["quota"] = "1Gi"
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fakeSpectrumScaleConnector.SetFilesetQuotaCallCount()).To(Equal(1))
+					Expect(fakeSpectrumScaleConnector.ChangePolicyCallCount()).To(Equal(1))
+					Expect(fakeSpectrumDataModel.InsertFilesetQuotaVolumeCallCount()).To(Equal(1))
+				})
+
+				It("should fail without creating the fileset when the pool does not exist", func() {
+					opts["pool"] = "nl-sas"
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Pool nl-sas does not exist"))
+					Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+				})
+
+				It("should fail when the pools cannot be listed", func() {
+					fakeSpectrumScaleConnector.ListFilesystemPoolsReturns(nil, fmt.Errorf("error listing pools"))
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("error listing pools"))
+					Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+				})
+
+				It("should delete the fileset when changing the policy fails", func() {
+					fakeSpectrumScaleConnector.ChangePolicyReturns(fmt.Errorf("error changing policy"))
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("error changing policy"))
+					Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(1))
+					Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(0))
+				})
+
+				It("should fail for an existing fileset", func() {
+					opts["fileset"] = "fake-existing-fileset"
+					opts["filesystem"] = "fake-filesystem"
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("'pool' is not supported for existing volumes"))
+					Expect(fakeSpectrumScaleConnector.ListFilesystemPoolsCallCount()).To(Equal(0))
+				})
+			})
+
 		})
 
 		Context(".FilesetVolume", func() {
@@ -243,8 +328,9 @@ var _ = Describe("local-client", func() {
 					Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(0))
 				})
 				It("should fail when dbClient fails to insert Fileset quota volume", func() {
-					fakeVolume := resources.Volume{Name: "fake-fileset", Mountpoint: "fake-mountpoint"}
+					fakeVolume := resources.Volume{Name: "fake-fileset", Mountpoint: "fake-mountpoint/fake-fileset"}
 					fakeSpectrumScaleConnector.ListFilesetReturns(fakeVolume, nil)
+					fakeSpectrumScaleConnector.GetFilesystemMountpointReturns("fake-mountpoint", nil)
 					fakeSpectrumDataModel.InsertFilesetVolumeReturns(fmt.Errorf("error inserting filesetvolume"))
 					err = client.CreateVolume(createVolumeRequest)
 					Expect(err).To(HaveOccurred())
@@ -252,13 +338,32 @@ var _ = Describe("local-client", func() {
 					Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(1))
 				})
 				It("should succeed when parameters are well specified", func() {
-					fakeVolume := resources.Volume{Name: "fake-fileset", Mountpoint: "fake-mountpoint"}
+					fakeVolume := resources.Volume{Name: "fake-fileset", Mountpoint: "fake-mountpoint/fake-fileset"}
 					fakeSpectrumScaleConnector.ListFilesetReturns(fakeVolume, nil)
+					fakeSpectrumScaleConnector.GetFilesystemMountpointReturns("fake-mountpoint", nil)
 					fakeSpectrumDataModel.InsertFilesetVolumeReturns(nil)
 					err = client.CreateVolume(createVolumeRequest)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(1))
 				})
+				It("should record the junction of a fileset linked elsewhere", func() {
+					fakeVolume := resources.Volume{Name: "fake-fileset", Mountpoint: "fake-mountpoint/projects/team1/fake-fileset"}
+					fakeSpectrumScaleConnector.ListFilesetReturns(fakeVolume, nil)
+					fakeSpectrumScaleConnector.GetFilesystemMountpointReturns("fake-mountpoint", nil)
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).ToNot(HaveOccurred())
+					_, _, _, _, dbOpts := fakeSpectrumDataModel.InsertFilesetVolumeArgsForCall(0)
+					Expect(dbOpts["junctionPath"]).To(Equal("projects/team1/fake-fileset"))
+				})
+				It("should fail when the fileset is linked outside of the filesystem", func() {
+					fakeVolume := resources.Volume{Name: "fake-fileset", Mountpoint: "other-mountpoint/fake-fileset"}
+					fakeSpectrumScaleConnector.ListFilesetReturns(fakeVolume, nil)
+					fakeSpectrumScaleConnector.GetFilesystemMountpointReturns("fake-mountpoint", nil)
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Fileset fake-fileset is linked at other-mountpoint/fake-fileset outside of the mountpoint fake-mountpoint of filesystem fake-filesystem"))
+					Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(0))
+				})
 
 			})
 
@@ -353,6 +458,191 @@ var _ = Describe("local-client", func() {
 				Expect(fakeSpectrumScaleConnector.GetFilesystemMountpointCallCount()).To(Equal(1))
 				// Expect(fakeExec.StatCallCount()).To(Equal(1))
 			})
+
+			Context(".WithQuota", func() {
+				BeforeEach(func() {
+					opts["quota"] = "1Gi"
+					fakeSpectrumScaleConnector.IsFilesetLinkedReturns(true, nil)
+					fakeSpectrumScaleConnector.GetFilesystemMountpointReturns("fake-mountpoint", nil)
+				})
+				It("should link a dependent fileset with the quota at the volume directory", func() {
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(1))
+					filesystem, fileset, filesetOpts := fakeSpectrumScaleConnector.CreateFilesetArgsForCall(0)
+					Expect([]string{filesystem, fileset}).To(Equal([]string{"fake-filesystem", "fake-lightweight"}))
+					Expect(filesetOpts).To(Equal(map[string]interface{}{connectors.UserSpecifiedInodeSpace: "fake-fileset"}))
+					_, fileset, junction := fakeSpectrumScaleConnector.LinkFilesetAtArgsForCall(0)
+					Expect([]string{fileset, junction}).To(Equal([]string{"fake-lightweight", "fake-mountpoint/fake-fileset/fake-lightweight"}))
+					_, fileset, quota := fakeSpectrumScaleConnector.SetFilesetQuotaArgsForCall(0)
+					Expect([]string{fileset, quota}).To(Equal([]string{"fake-lightweight", "1G"}))
+					Expect(fakeSpectrumDataModel.InsertLightweightQuotaVolumeCallCount()).To(Equal(1))
+					parent, directory, quotaFileset, dbQuota, name, _, _, _ := fakeSpectrumDataModel.InsertLightweightQuotaVolumeArgsForCall(0)
+					Expect([]string{parent, directory, quotaFileset, dbQuota, name}).To(Equal([]string{"fake-fileset", "fake-lightweight", "fake-lightweight", "1Gi", "fake-lightweight"}))
+				})
+				It("should delete the dependent fileset when linking it fails", func() {
+					fakeSpectrumScaleConnector.LinkFilesetAtReturns(fmt.Errorf("error linking fileset"))
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("error linking fileset"))
+					Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(1))
+					Expect(fakeSpectrumDataModel.InsertLightweightQuotaVolumeCallCount()).To(Equal(0))
+				})
+				It("should unlink and delete the dependent fileset when setting the quota fails", func() {
+					fakeSpectrumScaleConnector.SetFilesetQuotaReturns(fmt.Errorf("error setting quota"))
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("error setting quota"))
+					Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(1))
+					Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(1))
+					Expect(fakeSpectrumDataModel.InsertLightweightQuotaVolumeCallCount()).To(Equal(0))
+				})
+				It("should fail when the quota is invalid", func() {
+					opts["quota"] = "1.5XB"
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+				})
+				It("should place the dependent fileset in the pool", func() {
+					opts["pool"] = "flash"
+					fakeSpectrumScaleConnector.ListFilesystemPoolsReturns([]string{"system", "flash"}, nil)
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).ToNot(HaveOccurred())
+					_, policy := fakeSpectrumScaleConnector.ChangePolicyArgsForCall(0)
+					Expect(policy).To(Equal("RULE 'ubiquity_fake-lightweight' SET POOL 'flash' FOR FILESET ('fake-lightweight')\nRULE 'ubiquity_default' SET POOL 'system'\n"))
+				})
+				It("should unlink and delete the dependent fileset when changing the policy fails", func() {
+					opts["pool"] = "flash"
+					fakeSpectrumScaleConnector.ListFilesystemPoolsReturns([]string{"system", "flash"}, nil)
+					fakeSpectrumScaleConnector.ChangePolicyReturns(fmt.Errorf("error changing policy"))
+					err = client.CreateVolume(createVolumeRequest)
+					Expect(err).To(HaveOccurred())
+					Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(1))
+					Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(1))
+					Expect(fakeSpectrumDataModel.InsertLightweightQuotaVolumeCallCount()).To(Equal(0))
+				})
+			})
+			It("should fail when a pool is requested without quota", func() {
+				opts["pool"] = "flash"
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("'pool' is supported for lightweight volumes with 'quota' only"))
+				Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+			})
+		})
+
+		Context(".AfmVolume", func() {
+			BeforeEach(func() {
+				opts = map[string]interface{}{"type": "afm", "afm-target": "nfs://home1/gpfs/datasets"}
+				createVolumeRequest = resources.CreateVolumeRequest{Name: "fake-afm", Opts: opts}
+			})
+			It("should create an afm fileset in read-only mode by default", func() {
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).ToNot(HaveOccurred())
+				_, fileset, filesetOpts := fakeSpectrumScaleConnector.CreateFilesetArgsForCall(0)
+				Expect(fileset).To(Equal("fake-afm"))
+				Expect(filesetOpts["afm-target"]).To(Equal("nfs://home1/gpfs/datasets"))
+				Expect(filesetOpts["afm-mode"]).To(Equal("ro"))
+				Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(1))
+			})
+			It("should create an afm fileset with a quota", func() {
+				opts["afm-mode"] = "iw"
+				opts["quota"] = "1Gi"
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).ToNot(HaveOccurred())
+				_, _, filesetOpts := fakeSpectrumScaleConnector.CreateFilesetArgsForCall(0)
+				Expect(filesetOpts["afm-mode"]).To(Equal("iw"))
+				Expect(fakeSpectrumScaleConnector.SetFilesetQuotaCallCount()).To(Equal(1))
+				Expect(fakeSpectrumDataModel.InsertFilesetQuotaVolumeCallCount()).To(Equal(1))
+			})
+			It("should fail without afm-target", func() {
+				delete(opts, "afm-target")
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("'afm-target' is a required opt for afm volumes"))
+				Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+			})
+			It("should fail on an unknown afm-mode", func() {
+				opts["afm-mode"] = "dr"
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Unknown 'afm-mode' = dr specified, supported modes are ro, sw, iw, lu"))
+				Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+			})
+			It("should fail on an invalid interval", func() {
+				opts["afm-async-delay"] = "1m"
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+			})
+			It("should fail with an existing fileset", func() {
+				opts["fileset"] = "fake-fileset"
+				opts["filesystem"] = "fake-filesystem"
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("'fileset' and 'directory' are not supported for afm volumes"))
+			})
+			It("should fail on afm options for other volume types", func() {
+				opts["type"] = "fileset"
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("'afm-target' is supported for afm volumes only"))
+			})
+		})
+
+		Context(".WithJunctionPathTemplate", func() {
+			BeforeEach(func() {
+				fakeConfig.JunctionPathTemplate = "projects/{tenant}/{volume}"
+				client, err = spectrumscale.NewSpectrumLocalClientWithConnectors(logger, fakeSpectrumScaleConnector, fakeExec, fakeConfig, fakeSpectrumDataModel)
+				Expect(err).ToNot(HaveOccurred())
+				opts = map[string]interface{}{"tenant": "team1"}
+				createVolumeRequest = resources.CreateVolumeRequest{Name: "fake-volume", Opts: opts}
+			})
+			It("should record the junction of a new fileset from the template", func() {
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeSpectrumDataModel.InsertFilesetVolumeCallCount()).To(Equal(1))
+				_, _, _, _, dbOpts := fakeSpectrumDataModel.InsertFilesetVolumeArgsForCall(0)
+				Expect(dbOpts["junctionPath"]).To(Equal("projects/team1/fake-volume"))
+			})
+			It("should record the junction of an unlinked existing fileset from the template", func() {
+				opts["fileset"] = "fake-fileset"
+				opts["filesystem"] = "fake-filesystem"
+				fakeSpectrumScaleConnector.ListFilesetReturns(resources.Volume{Name: "fake-fileset", Mountpoint: "--"}, nil)
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).ToNot(HaveOccurred())
+				_, _, _, _, dbOpts := fakeSpectrumDataModel.InsertFilesetVolumeArgsForCall(0)
+				Expect(dbOpts["junctionPath"]).To(Equal("projects/team1/fake-volume"))
+			})
+			It("should fail without a tenant", func() {
+				delete(opts, "tenant")
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("'tenant' is a required opt for the junction path template projects/{tenant}/{volume}"))
+				Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+			})
+			It("should fail on a tenant with a directory separator", func() {
+				opts["tenant"] = "team1/../team2"
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Invalid 'tenant' = team1/../team2 specified"))
+				Expect(fakeSpectrumScaleConnector.CreateFilesetCallCount()).To(Equal(0))
+			})
+			It("should fail on a tenant when the template has no tenant", func() {
+				fakeConfig.JunctionPathTemplate = "projects/{volume}"
+				client, err = spectrumscale.NewSpectrumLocalClientWithConnectors(logger, fakeSpectrumScaleConnector, fakeExec, fakeConfig, fakeSpectrumDataModel)
+				Expect(err).ToNot(HaveOccurred())
+				err = client.CreateVolume(createVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("'tenant' is supported with a junction path template which contains {tenant} only"))
+			})
+			It("should fail to create a client with an invalid template", func() {
+				for _, template := range []string{"/gpfs/fs1/{volume}", "../{volume}", "projects/{team}/{volume}", "projects/{tenant}"} {
+					fakeConfig.JunctionPathTemplate = template
+					_, err = spectrumscale.NewSpectrumLocalClientWithConnectors(logger, fakeSpectrumScaleConnector, fakeExec, fakeConfig, fakeSpectrumDataModel)
+					Expect(err).To(HaveOccurred())
+				}
+			})
 		})
 	})
 
@@ -397,6 +687,19 @@ var _ = Describe("local-client", func() {
 			Expect(fakeSpectrumDataModel.DeleteVolumeCallCount()).To(Equal(1))
 			Expect(fakeSpectrumScaleConnector.GetFilesystemMountpointCallCount()).To(Equal(0))
 		})
+		It("should unlink the dependent fileset when type is lightweight with quota", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.Lightweight,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset", Directory: "fake-volume", QuotaFileset: "fake-volume", Quota: "1G"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			fakeSpectrumScaleConnector.IsFilesetLinkedReturns(true, nil)
+			err = client.RemoveVolume(removeVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			_, fileset := fakeSpectrumScaleConnector.UnlinkFilesetArgsForCall(0)
+			Expect(fileset).To(Equal("fake-volume"))
+			Expect(fakeSpectrumDataModel.DeleteVolumeCallCount()).To(Equal(1))
+			Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(0))
+			Expect(fakeExec.RemoveAllCallCount()).To(Equal(0))
+		})
 		Context("When forcedelete is set to true", func() {
 			BeforeEach(func() {
 				fakeConfig = resources.SpectrumScaleConfig{ForceDelete: true}
@@ -476,6 +779,42 @@ var _ = Describe("local-client", func() {
 				Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(1))
 			})
 
+			It("should not delete a fileset which other attached volumes use", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: 0}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				fakeSpectrumScaleConnector.IsFilesetLinkedReturns(true, nil)
+				fakeSpectrumDataModel.ListFilesetAttachmentsReturns([]spectrumscale.SpectrumScaleAttachment{{VolumeName: "other-volume", Host: "host1"}}, nil)
+				err = client.RemoveVolume(removeVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Cannot delete fileset fake-fileset of volume fake-volume, it is used by other volumes"))
+				Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(0))
+				Expect(fakeSpectrumDataModel.DeleteVolumeCallCount()).To(Equal(0))
+				Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(0))
+			})
+
+			It("should remove the placement rule of a fileset in a pool before deleting it", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: 0, Pool: "flash"}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				fakeSpectrumScaleConnector.IsFilesetLinkedReturns(false, nil)
+				fakeSpectrumScaleConnector.GetPolicyReturns("RULE 'ubiquity_fake-fileset' SET POOL 'flash' FOR FILESET ('fake-fileset')\nRULE 'admin' SET POOL 'system'\n", nil)
+				err = client.RemoveVolume(removeVolumeRequest)
+				Expect(err).ToNot(HaveOccurred())
+				filesystem, policy := fakeSpectrumScaleConnector.ChangePolicyArgsForCall(0)
+				Expect(filesystem).To(Equal("fake-filesystem"))
+				Expect(policy).To(Equal("RULE 'admin' SET POOL 'system'\n"))
+				Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(1))
+			})
+
+			It("should not delete the fileset when removing its placement rule fails", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: 0, Pool: "flash"}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				fakeSpectrumScaleConnector.IsFilesetLinkedReturns(false, nil)
+				fakeSpectrumScaleConnector.GetPolicyReturns("", fmt.Errorf("error getting policy"))
+				err = client.RemoveVolume(removeVolumeRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(fakeSpectrumScaleConnector.DeleteFilesetCallCount()).To(Equal(0))
+			})
+
 		})
 		It("should succeed when type is lightweight and forcedelete is false", func() {
 			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: 1}
@@ -611,6 +950,160 @@ var _ = Describe("local-client", func() {
 			Expect(fakeSpectrumDataModel.GetVolumeCallCount()).To(Equal(1))
 		})
 
+		It("should report the fileset quota usage and the filesystem free space", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.FilesetWithQuota,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset", Quota: "1G"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			fakeSpectrumScaleConnector.ListFilesetQuotaUsageReturns(connectors.FilesetQuotaUsage{BlockUsage: 1024, BlockLimit: 1073741824, FilesUsage: 2}, nil)
+			fakeSpectrumScaleConnector.GetFilesystemCapacityReturns(connectors.FilesystemCapacity{TotalBytes: 4096, FreeBytes: 2048}, nil)
+			vol, err := client.GetVolume(getVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vol.Usage).To(Equal(&resources.VolumeUsage{UsedBytes: 1024, UsedInodes: 2, LimitBytes: 1073741824, FreeBytes: 2048}))
+			filesystem, fileset := fakeSpectrumScaleConnector.ListFilesetQuotaUsageArgsForCall(0)
+			Expect([]string{filesystem, fileset}).To(Equal([]string{"fake-filesystem", "fake-fileset"}))
+		})
+
+		It("should cache the usage", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.Fileset,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			_, err = client.GetVolume(getVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.GetVolume(getVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeSpectrumScaleConnector.ListFilesetQuotaUsageCallCount()).To(Equal(1))
+			Expect(fakeSpectrumScaleConnector.GetFilesystemCapacityCallCount()).To(Equal(1))
+		})
+
+		It("should account the usage of a lightweight volume without quota with du", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.Lightweight,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset", Directory: "fake-volume"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			fakeSpectrumScaleConnector.GetFilesystemMountpointReturns("/gpfs/fake-filesystem", nil)
+			fakeExec.ExecuteStub = func(command string, args []string) ([]byte, error) {
+				if args[1] == "--inodes" {
+					return []byte("3\t/gpfs/fake-filesystem/fake-fileset/fake-volume\n"), nil
+				}
+				return []byte("8192\t/gpfs/fake-filesystem/fake-fileset/fake-volume\n"), nil
+			}
+			vol, err := client.GetVolume(getVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vol.Usage.UsedBytes).To(Equal(int64(8192)))
+			Expect(vol.Usage.UsedInodes).To(Equal(int64(3)))
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("du"))
+			Expect(args).To(Equal([]string{"-s", "-B1", "/gpfs/fake-filesystem/fake-fileset/fake-volume"}))
+			Expect(fakeSpectrumScaleConnector.ListFilesetQuotaUsageCallCount()).To(Equal(0))
+		})
+
+		It("should return the volume without usage if the cluster does not report it", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.Fileset,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			fakeSpectrumScaleConnector.ListFilesetQuotaUsageReturns(connectors.FilesetQuotaUsage{}, fmt.Errorf("quota accounting is disabled"))
+			vol, err := client.GetVolume(getVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vol.Usage).To(BeNil())
+		})
+	})
+
+	Context(".GetCapacity", func() {
+		It("should report the capacity of the default filesystem and of the filesystems of the volumes", func() {
+			fakeConfig = resources.SpectrumScaleConfig{DefaultFilesystemName: "fake-filesystem"}
+			client, err = spectrumscale.NewSpectrumLocalClientWithConnectors(logger, fakeSpectrumScaleConnector, fakeExec, fakeConfig, fakeSpectrumDataModel)
+			Expect(err).ToNot(HaveOccurred())
+			fakeSpectrumDataModel.ListVolumesReturns([]resources.Volume{{Name: "fake-volume"}}, nil)
+			fakeSpectrumDataModel.GetVolumeReturns(spectrumscale.SpectrumScaleVolume{FileSystem: "other-filesystem"}, true, nil)
+			fakeSpectrumScaleConnector.GetFilesystemCapacityReturns(connectors.FilesystemCapacity{TotalBytes: 4096, FreeBytes: 1024}, nil)
+			capacity, err := client.(resources.CapacityReporter).GetCapacity(resources.GetCapacityRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capacity).To(Equal([]resources.ServiceCapacity{
+				{Name: "fake-filesystem", TotalCapacity: 4096, UsedCapacity: 3072, FreeCapacity: 1024, MaxFreeForProvisioning: 1024},
+				{Name: "other-filesystem", TotalCapacity: 4096, UsedCapacity: 3072, FreeCapacity: 1024, MaxFreeForProvisioning: 1024},
+			}))
+		})
+		It("should fail if the capacity of a filesystem cannot be fetched", func() {
+			fakeSpectrumScaleConnector.GetFilesystemCapacityReturns(connectors.FilesystemCapacity{}, fmt.Errorf("error getting capacity"))
+			_, err := client.(resources.CapacityReporter).GetCapacity(resources.GetCapacityRequest{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("error getting capacity"))
+		})
+	})
+
+	Context(".ResizeVolume", func() {
+		var resizeVolumeRequest resources.ResizeVolumeRequest
+		BeforeEach(func() {
+			resizeVolumeRequest = resources.ResizeVolumeRequest{Name: "fake-volume", Size: "2G"}
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.FilesetWithQuota,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset", Quota: "1G"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			fakeSpectrumScaleConnector.ListFilesetQuotaReturns("2097152K", nil)
+		})
+
+		It("should set, verify and save the new quota", func() {
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeSpectrumScaleConnector.SetFilesetQuotaCallCount()).To(Equal(1))
+			filesystem, fileset, quota := fakeSpectrumScaleConnector.SetFilesetQuotaArgsForCall(0)
+			Expect([]string{filesystem, fileset, quota}).To(Equal([]string{"fake-filesystem", "fake-fileset", "2G"}))
+			Expect(fakeSpectrumScaleConnector.ListFilesetQuotaCallCount()).To(Equal(1))
+			Expect(fakeSpectrumDataModel.UpdateVolumeQuotaCallCount()).To(Equal(1))
+			name, quota := fakeSpectrumDataModel.UpdateVolumeQuotaArgsForCall(0)
+			Expect([]string{name, quota}).To(Equal([]string{"fake-volume", "2G"}))
+		})
+		It("should set a quota on a fileset volume without quota", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.Fileset,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeSpectrumScaleConnector.SetFilesetQuotaCallCount()).To(Equal(1))
+			Expect(fakeSpectrumDataModel.UpdateVolumeQuotaCallCount()).To(Equal(1))
+		})
+		It("should fail if the volume does not exist", func() {
+			fakeSpectrumDataModel.GetVolumeReturns(spectrumscale.SpectrumScaleVolume{}, false, nil)
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).To(BeAssignableToTypeOf(&resources.VolumeNotFoundError{}))
+			Expect(fakeSpectrumScaleConnector.SetFilesetQuotaCallCount()).To(Equal(0))
+		})
+		It("should set the quota of the dependent fileset of a lightweight volume", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.Lightweight,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset", Directory: "fake-directory", QuotaFileset: "fake-quota-fileset", Quota: "1G"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).ToNot(HaveOccurred())
+			_, fileset, _ := fakeSpectrumScaleConnector.SetFilesetQuotaArgsForCall(0)
+			Expect(fileset).To(Equal("fake-quota-fileset"))
+			Expect(fakeSpectrumDataModel.UpdateVolumeQuotaCallCount()).To(Equal(1))
+		})
+		It("should fail to resize a lightweight volume without quota", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, Type: spectrumscale.Lightweight,
+				FileSystem: "fake-filesystem", Fileset: "fake-fileset", Directory: "fake-directory"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(fakeSpectrumScaleConnector.SetFilesetQuotaCallCount()).To(Equal(0))
+		})
+		It("should fail on an invalid size", func() {
+			resizeVolumeRequest.Size = "2X"
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(fakeSpectrumScaleConnector.SetFilesetQuotaCallCount()).To(Equal(0))
+		})
+		It("should fail if setting the quota fails", func() {
+			fakeSpectrumScaleConnector.SetFilesetQuotaReturns(fmt.Errorf("error setting quota"))
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("error setting quota"))
+			Expect(fakeSpectrumDataModel.UpdateVolumeQuotaCallCount()).To(Equal(0))
+		})
+		It("should fail if the listed quota does not match the new quota", func() {
+			fakeSpectrumScaleConnector.ListFilesetQuotaReturns("1G", nil)
+			err = client.(resources.VolumeResizer).ResizeVolume(resizeVolumeRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("Mismatch between requested quota 2G and listed quota 1G"))
+			Expect(fakeSpectrumDataModel.UpdateVolumeQuotaCallCount()).To(Equal(0))
+		})
 	})
 
 	Context(".Attach", func() {
@@ -706,6 +1199,22 @@ var _ = Describe("local-client", func() {
 			Expect(fakeSpectrumScaleConnector.LinkFilesetCallCount()).To(Equal(1))
 		})
 
+		It("should link the fileset of the volume at its junction", func() {
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: spectrumscale.Fileset, JunctionPath: "projects/team1/fake-volume"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			fakeSpectrumScaleConnector.GetFilesystemMountpointReturns("fake-mountpoint", nil)
+			fakeSpectrumScaleConnector.IsFilesetLinkedReturns(false, nil)
+			mountpath, err := client.Attach(attachRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mountpath).To(Equal("fake-mountpoint/projects/team1/fake-volume"))
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("mkdir"))
+			Expect(args).To(Equal([]string{"-p", "fake-mountpoint/projects/team1"}))
+			Expect(fakeSpectrumScaleConnector.LinkFilesetCallCount()).To(Equal(0))
+			_, fileset, junction := fakeSpectrumScaleConnector.LinkFilesetAtArgsForCall(0)
+			Expect([]string{fileset, junction}).To(Equal([]string{"fake-fileset", "fake-mountpoint/projects/team1/fake-volume"}))
+		})
+
 		It("should succeed when volume is fileset volume with permissions", func() {
 			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Type: spectrumscale.Fileset, UID: "fake-uid", GID: "gid"}
 			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
@@ -771,8 +1280,81 @@ var _ = Describe("local-client", func() {
 			err := client.Detach(detachRequest)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(fakeSpectrumDataModel.GetVolumeCallCount()).To(Equal(1))
+			Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(0))
+		})
+
+		It("should keep the mountpoint while the volume is attached to other hosts", func() {
+			detachRequest.Host = "host1"
+			fakeSpectrumScaleConnector.IsFilesetLinkedReturns(true, nil)
+			volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset"}
+			fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+			fakeSpectrumDataModel.ListAttachmentsReturns([]spectrumscale.SpectrumScaleAttachment{{VolumeName: "fake-volume", Host: "host2"}}, nil)
+			err := client.Detach(detachRequest)
+			Expect(err).ToNot(HaveOccurred())
+			name, host := fakeSpectrumDataModel.DeleteAttachmentArgsForCall(0)
+			Expect([]string{name, host}).To(Equal([]string{"fake-volume", "host1"}))
+			Expect(fakeSpectrumDataModel.UpdateVolumeMountpointCallCount()).To(Equal(0))
 		})
 
+		Context(".WithUnlinkUnusedPolicy", func() {
+			BeforeEach(func() {
+				fakeConfig.DetachPolicy = spectrumscale.DetachPolicyUnlinkUnused
+				client, err = spectrumscale.NewSpectrumLocalClientWithConnectors(logger, fakeSpectrumScaleConnector, fakeExec, fakeConfig, fakeSpectrumDataModel)
+				Expect(err).ToNot(HaveOccurred())
+				fakeSpectrumScaleConnector.IsFilesetLinkedReturns(true, nil)
+			})
+			It("should unlink the fileset when no host uses it", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: spectrumscale.Fileset}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				err := client.Detach(detachRequest)
+				Expect(err).ToNot(HaveOccurred())
+				filesystem, fileset := fakeSpectrumDataModel.ListFilesetAttachmentsArgsForCall(0)
+				Expect([]string{filesystem, fileset}).To(Equal([]string{"fake-filesystem", "fake-fileset"}))
+				Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(1))
+			})
+			It("should keep the fileset linked while a volume of the fileset is attached", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: spectrumscale.Fileset}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				fakeSpectrumDataModel.ListFilesetAttachmentsReturns([]spectrumscale.SpectrumScaleAttachment{{VolumeName: "other-volume", Host: "host2"}}, nil)
+				err := client.Detach(detachRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(0))
+			})
+			It("should keep the fileset linked while a volume attached before the upgrade uses it", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: spectrumscale.Fileset}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				fakeSpectrumDataModel.ListFilesetVolumesReturns([]spectrumscale.SpectrumScaleVolume{volume,
+					{Volume: resources.Volume{Name: "legacy-volume", Mountpoint: "/gpfs/fake-filesystem/fake-fileset/legacy-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: spectrumscale.Lightweight}}, nil)
+				err := client.Detach(detachRequest)
+				Expect(err).ToNot(HaveOccurred())
+				filesystem, fileset := fakeSpectrumDataModel.ListFilesetVolumesArgsForCall(0)
+				Expect([]string{filesystem, fileset}).To(Equal([]string{"fake-filesystem", "fake-fileset"}))
+				Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(0))
+			})
+			It("should keep an existing fileset linked", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: spectrumscale.Fileset, IsPreexisting: true}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				err := client.Detach(detachRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(0))
+			})
+			It("should unlink the dependent fileset of a lightweight volume only", func() {
+				volume := spectrumscale.SpectrumScaleVolume{Volume: resources.Volume{Name: "fake-volume"}, FileSystem: "fake-filesystem", Fileset: "fake-fileset", Type: spectrumscale.Lightweight, QuotaFileset: "fake-volume"}
+				fakeSpectrumDataModel.GetVolumeReturns(volume, true, nil)
+				err := client.Detach(detachRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeSpectrumScaleConnector.UnlinkFilesetCallCount()).To(Equal(1))
+				_, fileset := fakeSpectrumScaleConnector.UnlinkFilesetArgsForCall(0)
+				Expect(fileset).To(Equal("fake-volume"))
+			})
+		})
+
+		It("should fail to create a client with an unknown detach policy", func() {
+			fakeConfig.DetachPolicy = "unlink"
+			_, err = spectrumscale.NewSpectrumLocalClientWithConnectors(logger, fakeSpectrumScaleConnector, fakeExec, fakeConfig, fakeSpectrumDataModel)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Unknown detach policy unlink, supported policies are keep-linked, unlink-unused, always-unlink"))
+		})
 	})
 
 })
@@ -0,0 +1,202 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spectrumscale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ubiquity/local/spectrumscale/connectors"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+)
+
+// DefaultUsageCacheTimeout is the default number of seconds the usage of a volume or a filesystem is cached
+const DefaultUsageCacheTimeout = 30
+
+// usageCache keeps the usage the cluster reported for a while, so that frequent queries do not hammer the cluster.
+// A negative timeout disables the caching.
+type usageCache struct {
+	lock    sync.Mutex
+	timeout time.Duration
+	entries map[string]usageCacheEntry
+}
+
+type usageCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newUsageCache(timeoutSeconds int) *usageCache {
+	if timeoutSeconds == 0 {
+		timeoutSeconds = DefaultUsageCacheTimeout
+	}
+	return &usageCache{timeout: time.Duration(timeoutSeconds) * time.Second, entries: make(map[string]usageCacheEntry)}
+}
+
+// get return the cached value of the key, or loads and caches it if it is missing or expired
+func (c *usageCache) get(key string, load func() (interface{}, error)) (interface{}, error) {
+	c.lock.Lock()
+	entry, exists := c.entries[key]
+	c.lock.Unlock()
+	if exists && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if c.timeout > 0 {
+		c.lock.Lock()
+		c.entries[key] = usageCacheEntry{value: value, expires: time.Now().Add(c.timeout)}
+		c.lock.Unlock()
+	}
+	return value, nil
+}
+
+func (c *usageCache) invalidate(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, key)
+}
+
+func volumeUsageKey(name string) string {
+	return "volume:" + name
+}
+
+func filesystemUsageKey(name string) string {
+	return "filesystem:" + name
+}
+
+// getVolumeUsage return the usage and the limits of the volume, and the free space of its filesystem.
+// The usage of filesets is their fileset quota usage, the usage of lightweight volumes without quota is accounted with du.
+func (s *spectrumLocalClient) getVolumeUsage(volume SpectrumScaleVolume) (resources.VolumeUsage, error) {
+	s.logger.Println("spectrumLocalClient: getVolumeUsage start")
+	defer s.logger.Println("spectrumLocalClient: getVolumeUsage end")
+
+	value, err := s.usageCache.get(volumeUsageKey(volume.Volume.Name), func() (interface{}, error) {
+		fileset := getQuotaFileset(volume)
+		if volume.Type == Fileset {
+			fileset = volume.Fileset
+		}
+		if fileset != "" {
+			quotaUsage, err := s.connector.ListFilesetQuotaUsage(volume.FileSystem, fileset)
+			if err != nil {
+				return nil, err
+			}
+			return resources.VolumeUsage{UsedBytes: quotaUsage.BlockUsage, UsedInodes: quotaUsage.FilesUsage,
+				LimitBytes: quotaUsage.BlockLimit, LimitInodes: quotaUsage.FilesLimit}, nil
+		}
+
+		directoryPath, err := s.getVolumeMountPoint(volume)
+		if err != nil {
+			return nil, err
+		}
+		usedBytes, err := s.duTotal(directoryPath, "-B1")
+		if err != nil {
+			return nil, err
+		}
+		usedInodes, err := s.duTotal(directoryPath, "--inodes")
+		if err != nil {
+			return nil, err
+		}
+		return resources.VolumeUsage{UsedBytes: usedBytes, UsedInodes: usedInodes}, nil
+	})
+	if err != nil {
+		s.logger.Printf("Failed to get the usage of volume %s: %v\n", volume.Volume.Name, err)
+		return resources.VolumeUsage{}, err
+	}
+	usage := value.(resources.VolumeUsage)
+
+	capacity, err := s.getFilesystemCapacity(volume.FileSystem)
+	if err != nil {
+		return resources.VolumeUsage{}, err
+	}
+	usage.FreeBytes = capacity.FreeBytes
+	return usage, nil
+}
+
+func (s *spectrumLocalClient) getFilesystemCapacity(filesystem string) (connectors.FilesystemCapacity, error) {
+	value, err := s.usageCache.get(filesystemUsageKey(filesystem), func() (interface{}, error) {
+		return s.connector.GetFilesystemCapacity(filesystem)
+	})
+	if err != nil {
+		s.logger.Printf("Failed to get the capacity of filesystem %s: %v\n", filesystem, err)
+		return connectors.FilesystemCapacity{}, err
+	}
+	return value.(connectors.FilesystemCapacity), nil
+}
+
+// duTotal return the total of du -s with the given unit option for the directory
+func (s *spectrumLocalClient) duTotal(directoryPath string, unitOption string) (int64, error) {
+	output, err := s.executor.Execute("du", []string{"-s", unitOption, directoryPath})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to account the usage of directory %s: %s", directoryPath, err.Error())
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("Failed to account the usage of directory %s: empty du output", directoryPath)
+	}
+	total, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to account the usage of directory %s: invalid du output [%s]", directoryPath, strings.TrimSpace(string(output)))
+	}
+	return total, nil
+}
+
+// GetCapacity return the capacity of the default filesystem and of the filesystems of the volumes
+func (s *spectrumLocalClient) GetCapacity(getCapacityRequest resources.GetCapacityRequest) ([]resources.ServiceCapacity, error) {
+	s.logger.Println("spectrumLocalClient: GetCapacity start")
+	defer s.logger.Println("spectrumLocalClient: GetCapacity end")
+
+	volumes, err := s.dataModel.ListVolumes()
+	if err != nil {
+		s.logger.Println(err.Error())
+		return nil, err
+	}
+	filesystems := []string{s.config.DefaultFilesystemName}
+	for _, volume := range volumes {
+		existingVolume, volExists, err := s.dataModel.GetVolume(volume.Name)
+		if err != nil {
+			s.logger.Println(err.Error())
+			return nil, err
+		}
+		if volExists && !utils.StringInSlice(existingVolume.FileSystem, filesystems) {
+			filesystems = append(filesystems, existingVolume.FileSystem)
+		}
+	}
+
+	capacity := []resources.ServiceCapacity{}
+	for _, filesystem := range filesystems {
+		fsCapacity, err := s.getFilesystemCapacity(filesystem)
+		if err != nil {
+			return nil, err
+		}
+		capacity = append(capacity, resources.ServiceCapacity{
+			Name:                   filesystem,
+			TotalCapacity:          int(fsCapacity.TotalBytes),
+			UsedCapacity:           int(fsCapacity.TotalBytes - fsCapacity.FreeBytes),
+			FreeCapacity:           int(fsCapacity.FreeBytes),
+			MaxFreeForProvisioning: int(fsCapacity.FreeBytes),
+		})
+	}
+	return capacity, nil
+}
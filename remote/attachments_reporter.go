@@ -0,0 +1,173 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+	"github.com/IBM/ubiquity/utils/logs"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultAttachmentsReportInterval is well below the server HostReportTimeout, so a few failed reports do not make the
+// mappings of a live node stale
+const DefaultAttachmentsReportInterval = 2 * time.Minute
+
+const multipathListTimeout = 20 * 1000 // milliseconds
+
+// AttachmentsReporter reports periodically the block volumes mounted or mapped (multipath device) on the node to the
+// ubiquity server, for the mapping reconciliation. NewRemoteClientSecure starts it when SCBE is a backend of the plugin.
+type AttachmentsReporter interface {
+	Report() error
+	Start()
+	Stop()
+}
+
+type attachmentsReporter struct {
+	logger            logs.Logger
+	mappingReconciler resources.MappingReconciler
+	executor          utils.Executor
+	interval          time.Duration
+	stop              chan struct{}
+}
+
+// the WWN of the volumes mounted by the SCBE mounter, see resources.PathToMountUbiquityBlockDevices
+var ubiquityBlockMountRegex = regexp.MustCompile(`\son\s` + fmt.Sprintf(resources.PathToMountUbiquityBlockDevices, `([^\s/]+)`) + `\s`)
+
+// the wwid of a multipath device in multipath -ll, e.g "mpatha (36001738cfc9035eb0000000000cea5f6) dm-1 IBM,2810XIV"
+// or "36001738cfc9035eb0000000000cea5f6 dm-1 IBM,2810XIV" without friendly names. The WWN is the wwid without the
+// leading NAA type 3.
+var multipathDeviceRegex = regexp.MustCompile(`^(?:\S+\s+\()?3([0-9a-fA-F]{32})\)?\s+dm-[0-9]+\s`)
+
+func NewAttachmentsReporter(mappingReconciler resources.MappingReconciler, interval time.Duration) AttachmentsReporter {
+	return NewAttachmentsReporterWithExecutor(mappingReconciler, interval, utils.NewExecutor())
+}
+
+func NewAttachmentsReporterWithExecutor(mappingReconciler resources.MappingReconciler, interval time.Duration, executor utils.Executor) AttachmentsReporter {
+	return &attachmentsReporter{logger: logs.GetLogger(), mappingReconciler: mappingReconciler, executor: executor, interval: interval}
+}
+
+// Report sends the WWNs of the block volumes currently mounted or mapped on the node. A volume mapped but not mounted
+// yet is reported too, so it is not considered stale.
+func (r *attachmentsReporter) Report() error {
+	defer r.logger.Trace(logs.DEBUG)()
+
+	host, err := r.executor.Hostname()
+	if err != nil {
+		return r.logger.ErrorRet(err, "executor.Hostname failed")
+	}
+	wwns, err := r.getMountedWWNs()
+	if err != nil {
+		return err
+	}
+	mappedWWNs, err := r.getMappedWWNs()
+	if err != nil {
+		return err
+	}
+	for _, wwn := range mappedWWNs {
+		if !containsWWN(wwns, wwn) {
+			wwns = append(wwns, wwn)
+		}
+	}
+	reportAttachmentsRequest := resources.ReportAttachmentsRequest{Host: host, Volumes: []string{}, WWNs: wwns}
+	if err := r.mappingReconciler.ReportAttachments(reportAttachmentsRequest); err != nil {
+		return r.logger.ErrorRet(err, "mappingReconciler.ReportAttachments failed", logs.Args{{"host", host}})
+	}
+	return nil
+}
+
+// Start reports now and then every interval, until Stop
+func (r *attachmentsReporter) Start() {
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			// a failed report is retried on the next tick, the server keeps the last report until the timeout
+			r.Report()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(r.stop)
+}
+
+func (r *attachmentsReporter) Stop() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	r.stop = nil
+}
+
+func (r *attachmentsReporter) getMountedWWNs() ([]string, error) {
+	defer r.logger.Trace(logs.DEBUG)()
+
+	output, err := r.executor.Execute("mount", nil)
+	if err != nil {
+		return nil, r.logger.ErrorRet(err, "failed to list the mountpoints")
+	}
+	wwns := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if matches := ubiquityBlockMountRegex.FindStringSubmatch(scanner.Text()); len(matches) == 2 {
+			wwns = append(wwns, matches[1])
+		}
+	}
+	return wwns, nil
+}
+
+// getMappedWWNs returns the WWNs of the multipath devices on the node. Without multipath there is no mapped device to
+// report.
+func (r *attachmentsReporter) getMappedWWNs() ([]string, error) {
+	defer r.logger.Trace(logs.DEBUG)()
+
+	wwns := []string{}
+	if err := r.executor.IsExecutable("multipath"); err != nil {
+		r.logger.Debug("multipath is not available, reporting the mounted volumes only")
+		return wwns, nil
+	}
+	output, err := r.executor.ExecuteWithTimeout(multipathListTimeout, "multipath", []string{"-ll"})
+	if err != nil {
+		return nil, r.logger.ErrorRet(err, "failed to list the multipath devices")
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if matches := multipathDeviceRegex.FindStringSubmatch(scanner.Text()); len(matches) == 2 {
+			wwns = append(wwns, matches[1])
+		}
+	}
+	return wwns, nil
+}
+
+func containsWWN(wwns []string, wwn string) bool {
+	for _, w := range wwns {
+		if strings.EqualFold(w, wwn) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote_test
+
+import (
+	"errors"
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/remote"
+	"github.com/IBM/ubiquity/resources"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sync"
+	"time"
+)
+
+type fakeMappingReconciler struct {
+	resources.MappingReconciler
+	mutex    sync.Mutex
+	requests []resources.ReportAttachmentsRequest
+	err      error
+}
+
+func (f *fakeMappingReconciler) ReportAttachments(reportAttachmentsRequest resources.ReportAttachmentsRequest) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.requests = append(f.requests, reportAttachmentsRequest)
+	return f.err
+}
+
+func (f *fakeMappingReconciler) reportCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.requests)
+}
+
+var _ = Describe("AttachmentsReporter", func() {
+	var (
+		fakeExec          *fakes.FakeExecutor
+		mappingReconciler *fakeMappingReconciler
+		reporter          remote.AttachmentsReporter
+	)
+	BeforeEach(func() {
+		fakeExec = new(fakes.FakeExecutor)
+		fakeExec.HostnameReturns("node1", nil)
+		fakeExec.ExecuteReturns([]byte(
+			"/dev/sda1 on / type ext4 (rw,relatime)\n"+
+				"/dev/mapper/mpatha on /ubiquity/6001738CFC9035E8000000000091F0C0 type ext4 (rw,relatime)\n"+
+				"/dev/mapper/mpathb on /ubiquity/6001738CFC9035E8000000000091F0C1 type xfs (rw,relatime)\n"+
+				"server:/export on /ubiquity/nfs/vol1 type nfs (rw)\n"), nil)
+		mappingReconciler = &fakeMappingReconciler{}
+		reporter = remote.NewAttachmentsReporterWithExecutor(mappingReconciler, time.Millisecond, fakeExec)
+	})
+	Context(".Report", func() {
+		It("should report the WWNs of the block volumes mounted on the node", func() {
+			err := reporter.Report()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappingReconciler.requests).To(HaveLen(1))
+			Expect(mappingReconciler.requests[0].Host).To(Equal("node1"))
+			Expect(mappingReconciler.requests[0].WWNs).To(Equal([]string{"6001738CFC9035E8000000000091F0C0", "6001738CFC9035E8000000000091F0C1"}))
+			command, _ := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("mount"))
+		})
+		It("should report the WWNs of the multipath devices mapped to the node but not mounted", func() {
+			fakeExec.ExecuteWithTimeoutReturns([]byte(
+				"mpatha (36001738cfc9035e8000000000091f0c0) dm-0 IBM,2810XIV\n"+
+					"size=1.0G features='1 queue_if_no_path' hwhandler='0' wp=rw\n"+
+					"`-+- policy='service-time 0' prio=1 status=active\n"+
+					"  `- 2:0:0:1 sdb 8:16 active ready running\n"+
+					"36001738cfc9035e8000000000091f0c2 dm-2 IBM,2810XIV\n"+
+					"size=1.0G features='1 queue_if_no_path' hwhandler='0' wp=rw\n"), nil)
+			err := reporter.Report()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappingReconciler.requests[0].WWNs).To(Equal([]string{"6001738CFC9035E8000000000091F0C0", "6001738CFC9035E8000000000091F0C1", "6001738cfc9035e8000000000091f0c2"}))
+			Expect(fakeExec.IsExecutableArgsForCall(0)).To(Equal("multipath"))
+			_, command, args := fakeExec.ExecuteWithTimeoutArgsForCall(0)
+			Expect(command).To(Equal("multipath"))
+			Expect(args).To(Equal([]string{"-ll"}))
+		})
+		It("should report the mounted WWNs only if multipath is not available", func() {
+			fakeExec.IsExecutableReturns(errors.New("not found"))
+			err := reporter.Report()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappingReconciler.requests[0].WWNs).To(HaveLen(2))
+			Expect(fakeExec.ExecuteWithTimeoutCallCount()).To(Equal(0))
+		})
+		It("should fail and not report if the multipath devices are not known", func() {
+			fakeExec.ExecuteWithTimeoutReturns(nil, errors.New("multipath failed"))
+			err := reporter.Report()
+			Expect(err).To(HaveOccurred())
+			Expect(mappingReconciler.requests).To(BeEmpty())
+		})
+		It("should report no WWNs if no block volume is mounted", func() {
+			fakeExec.ExecuteReturns([]byte("/dev/sda1 on / type ext4 (rw,relatime)\n"), nil)
+			err := reporter.Report()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappingReconciler.requests[0].WWNs).To(BeEmpty())
+		})
+		It("should fail and not report if the mountpoints are not known", func() {
+			fakeExec.ExecuteReturns(nil, errors.New("mount failed"))
+			err := reporter.Report()
+			Expect(err).To(HaveOccurred())
+			Expect(mappingReconciler.requests).To(BeEmpty())
+		})
+		It("should fail if the report failed", func() {
+			mappingReconciler.err = errors.New("server error")
+			err := reporter.Report()
+			Expect(err).To(MatchError(mappingReconciler.err))
+		})
+	})
+	Context(".Start", func() {
+		It("should report periodically until stopped", func() {
+			reporter.Start()
+			Eventually(mappingReconciler.reportCount).Should(BeNumerically(">=", 2))
+			reporter.Stop()
+			count := mappingReconciler.reportCount()
+			Consistently(mappingReconciler.reportCount, 20*time.Millisecond).Should(BeNumerically("<=", count+1))
+		})
+	})
+})
@@ -34,6 +34,8 @@ type remoteClient struct {
 	httpClient    *http.Client
 	storageApiURL string
 	config        resources.UbiquityPluginConfig
+	initiators    InitiatorsDiscoverer
+	reporter      AttachmentsReporter
 }
 
 func (s *remoteClient) Activate(activateRequest resources.ActivateRequest) error {
@@ -65,7 +67,10 @@ func (s *remoteClient) CreateVolume(createVolumeRequest resources.CreateVolumeRe
 
 	createRemoteURL := utils.FormatURL(s.storageApiURL, "volumes")
 
-	if reflect.DeepEqual(s.config.SpectrumNfsRemoteConfig, resources.SpectrumNfsRemoteConfig{}) == false {
+	// the client config of the plugin is the default of the volumes which are created without their own NFS clients
+	_, nfsClientConfigSpecified := createVolumeRequest.Opts["nfsClientConfig"]
+	_, nfsClientsSpecified := createVolumeRequest.Opts["nfsClients"]
+	if reflect.DeepEqual(s.config.SpectrumNfsRemoteConfig, resources.SpectrumNfsRemoteConfig{}) == false && !nfsClientConfigSpecified && !nfsClientsSpecified {
 		createVolumeRequest.Opts["nfsClientConfig"] = s.config.SpectrumNfsRemoteConfig.ClientConfig
 	}
 
@@ -159,6 +164,14 @@ func (s *remoteClient) Attach(attachRequest resources.AttachRequest) (string, er
 
 	attachRemoteURL := utils.FormatURL(s.storageApiURL, "volumes", attachRequest.Name, "attach")
 	attachRequest.CredentialInfo = s.config.CredentialInfo
+	if attachRequest.Initiators.Iqn == "" && len(attachRequest.Initiators.Wwpns) == 0 {
+		// the server needs the initiators only to define an unknown host, so attach without them if not found
+		initiators, err := s.initiators.GetHostInitiators()
+		if err != nil {
+			s.logger.Warning("Cannot discover the host initiators", logs.Args{{"error", err}})
+		}
+		attachRequest.Initiators = initiators
+	}
 	response, err := utils.HttpExecute(s.httpClient, "PUT", attachRemoteURL, attachRequest, attachRequest.Context)
 	if err != nil {
 		return "", s.logger.ErrorRet(err, "utils.HttpExecute failed")
@@ -217,3 +230,66 @@ func (s *remoteClient) ListVolumes(listVolumesRequest resources.ListVolumesReque
 	return listResponse.Volumes, nil
 
 }
+
+func (s *remoteClient) ReportAttachments(reportAttachmentsRequest resources.ReportAttachmentsRequest) error {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	reportRemoteURL := utils.FormatURL(s.storageApiURL, "hosts", reportAttachmentsRequest.Host, "attachments")
+	reportAttachmentsRequest.CredentialInfo = s.config.CredentialInfo
+	response, err := utils.HttpExecute(s.httpClient, "PUT", reportRemoteURL, reportAttachmentsRequest, reportAttachmentsRequest.Context)
+	if err != nil {
+		return s.logger.ErrorRet(err, "utils.HttpExecute failed")
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return s.logger.ErrorRet(utils.ExtractErrorResponse(response), "failed", logs.Args{{"response", response}})
+	}
+
+	return nil
+}
+
+func (s *remoteClient) GetStaleMappings(getStaleMappingsRequest resources.GetStaleMappingsRequest) ([]resources.StaleMapping, error) {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	staleMappingsRemoteURL := utils.FormatURL(s.storageApiURL, "backends", getStaleMappingsRequest.Backend, "stale_mappings")
+	getStaleMappingsRequest.CredentialInfo = s.config.CredentialInfo
+	response, err := utils.HttpExecute(s.httpClient, "GET", staleMappingsRemoteURL, getStaleMappingsRequest, getStaleMappingsRequest.Context)
+	if err != nil {
+		return nil, s.logger.ErrorRet(err, "utils.HttpExecute failed")
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, s.logger.ErrorRet(utils.ExtractErrorResponse(response), "failed", logs.Args{{"response", response}})
+	}
+
+	staleMappingsResponse := resources.GetStaleMappingsResponse{}
+	err = utils.UnmarshalResponse(response, &staleMappingsResponse)
+	if err != nil {
+		return nil, s.logger.ErrorRet(err, "utils.UnmarshalResponse failed", logs.Args{{"response", response}})
+	}
+
+	return staleMappingsResponse.StaleMappings, nil
+}
+
+func (s *remoteClient) ForceUnmap(forceUnmapRequest resources.ForceUnmapRequest) error {
+	defer s.logger.Trace(logs.DEBUG)()
+
+	unmapRemoteURL := utils.FormatURL(s.storageApiURL, "backends", forceUnmapRequest.Backend, "stale_mappings", "unmap")
+	forceUnmapRequest.CredentialInfo = s.config.CredentialInfo
+	response, err := utils.HttpExecute(s.httpClient, "PUT", unmapRemoteURL, forceUnmapRequest, forceUnmapRequest.Context)
+	if err != nil {
+		return s.logger.ErrorRet(err, "utils.HttpExecute failed")
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return s.logger.ErrorRet(utils.ExtractErrorResponse(response), "failed", logs.Args{{"response", response}})
+	}
+
+	return nil
+}
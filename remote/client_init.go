@@ -52,8 +52,22 @@ func (e *SslModeFullVerifyWithoutCAfile) Error() string {
 		e.VerifyCaEnvName, resources.SslModeVerifyFull)
 }
 
+// NewRemoteClientSecure also starts reporting the attached SCBE volumes to the server, for the mapping reconciliation
 func NewRemoteClientSecure(logger *log.Logger, config resources.UbiquityPluginConfig) (resources.StorageClient, error) {
-	client := &remoteClient{logger: logs.GetLogger(), config: config}
+	storageClient, err := NewRemoteClientSecureWithInitiatorsDiscoverer(logger, config, NewInitiatorsDiscoverer())
+	if err != nil {
+		return nil, err
+	}
+	client := storageClient.(*remoteClient)
+	if utils.StringInSlice(resources.SCBE, config.Backends) {
+		client.reporter = NewAttachmentsReporter(client, DefaultAttachmentsReportInterval)
+		client.reporter.Start()
+	}
+	return client, nil
+}
+
+func NewRemoteClientSecureWithInitiatorsDiscoverer(logger *log.Logger, config resources.UbiquityPluginConfig, initiators InitiatorsDiscoverer) (resources.StorageClient, error) {
+	client := &remoteClient{logger: logs.GetLogger(), config: config, initiators: initiators}
 	if err := client.initialize(); err != nil {
 		return nil, err
 	}
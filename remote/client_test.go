@@ -0,0 +1,159 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/IBM/ubiquity/remote"
+	"github.com/IBM/ubiquity/resources"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+type fakeInitiatorsDiscoverer struct {
+	initiators resources.HostInitiators
+	err        error
+}
+
+func (f *fakeInitiatorsDiscoverer) GetHostInitiators() (resources.HostInitiators, error) {
+	return f.initiators, f.err
+}
+
+var _ = Describe("remoteClient", func() {
+	var (
+		server            *httptest.Server
+		method            string
+		path              string
+		body              map[string]interface{}
+		status            int
+		response          interface{}
+		client            resources.StorageClient
+		mappingReconciler resources.MappingReconciler
+		initiators        *fakeInitiatorsDiscoverer
+	)
+	BeforeEach(func() {
+		status = http.StatusOK
+		response = resources.GenericResponse{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			path = r.URL.Path
+			body = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(response)
+		}))
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		host, port, err := net.SplitHostPort(serverURL.Host)
+		Expect(err).NotTo(HaveOccurred())
+		portNumber, err := strconv.Atoi(port)
+		Expect(err).NotTo(HaveOccurred())
+
+		os.Setenv(remote.KeyUseSsl, "false")
+		os.Setenv(resources.KeySslMode, resources.SslModeRequire)
+		logger := log.New(os.Stdout, "ubiquity: ", log.Lshortfile|log.LstdFlags)
+		config := resources.UbiquityPluginConfig{UbiquityServer: resources.UbiquityServerConnectionInfo{Address: host, Port: portNumber}}
+		initiators = &fakeInitiatorsDiscoverer{initiators: resources.HostInitiators{Iqn: "iqn1", Wwpns: []string{"wwpn1"}}}
+		client, err = remote.NewRemoteClientSecureWithInitiatorsDiscoverer(logger, config, initiators)
+		Expect(err).NotTo(HaveOccurred())
+		mappingReconciler = client.(resources.MappingReconciler)
+	})
+	AfterEach(func() {
+		os.Unsetenv(remote.KeyUseSsl)
+		os.Unsetenv(resources.KeySslMode)
+		server.Close()
+	})
+	Context(".Attach", func() {
+		It("should send the initiators of the node with the attach", func() {
+			_, err := client.Attach(resources.AttachRequest{Name: "vol1", Host: "node1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(method).To(Equal("PUT"))
+			Expect(path).To(Equal("/ubiquity_storage/volumes/vol1/attach"))
+			Expect(body["Initiators"]).To(Equal(map[string]interface{}{"Iqn": "iqn1", "Wwpns": []interface{}{"wwpn1"}}))
+		})
+		It("should keep the initiators given in the attach request", func() {
+			_, err := client.Attach(resources.AttachRequest{Name: "vol1", Host: "node1", Initiators: resources.HostInitiators{Iqn: "iqn2"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body["Initiators"]).To(Equal(map[string]interface{}{"Iqn": "iqn2", "Wwpns": nil}))
+		})
+		It("should attach without initiators if the discovery failed", func() {
+			initiators.err = errors.New("discovery failed")
+			initiators.initiators = resources.HostInitiators{}
+			_, err := client.Attach(resources.AttachRequest{Name: "vol1", Host: "node1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body["Initiators"]).To(Equal(map[string]interface{}{"Iqn": "", "Wwpns": nil}))
+		})
+	})
+	Context(".ReportAttachments", func() {
+		It("should PUT the report to the host attachments", func() {
+			err := mappingReconciler.ReportAttachments(resources.ReportAttachmentsRequest{Host: "node1", WWNs: []string{"wwn1"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(method).To(Equal("PUT"))
+			Expect(path).To(Equal("/ubiquity_storage/hosts/node1/attachments"))
+			Expect(body["WWNs"]).To(Equal([]interface{}{"wwn1"}))
+		})
+		It("should fail if the server failed", func() {
+			status = http.StatusBadRequest
+			response = resources.GenericResponse{Err: "bad report"}
+			err := mappingReconciler.ReportAttachments(resources.ReportAttachmentsRequest{Host: "node1"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("bad report"))
+		})
+	})
+	Context(".GetStaleMappings", func() {
+		It("should GET the stale mappings of the backend", func() {
+			staleMapping := resources.StaleMapping{Name: "vol1", Host: "node1", Status: resources.MappingStatusStale, Reason: resources.StaleMappingReasonVolumeNotInUse}
+			response = resources.GetStaleMappingsResponse{StaleMappings: []resources.StaleMapping{staleMapping}}
+			staleMappings, err := mappingReconciler.GetStaleMappings(resources.GetStaleMappingsRequest{Backend: resources.SCBE})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(method).To(Equal("GET"))
+			Expect(path).To(Equal("/ubiquity_storage/backends/scbe/stale_mappings"))
+			Expect(staleMappings).To(Equal([]resources.StaleMapping{staleMapping}))
+		})
+		It("should fail if the server failed", func() {
+			status = http.StatusConflict
+			response = resources.GetStaleMappingsResponse{Err: "scbe error"}
+			_, err := mappingReconciler.GetStaleMappings(resources.GetStaleMappingsRequest{Backend: resources.SCBE})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context(".ForceUnmap", func() {
+		It("should PUT the unmap to the backend stale mappings", func() {
+			err := mappingReconciler.ForceUnmap(resources.ForceUnmapRequest{Backend: resources.SCBE, Name: "vol1", Host: "node1", Force: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(method).To(Equal("PUT"))
+			Expect(path).To(Equal("/ubiquity_storage/backends/scbe/stale_mappings/unmap"))
+			Expect(body["Name"]).To(Equal("vol1"))
+			Expect(body["Host"]).To(Equal("node1"))
+			Expect(body["Force"]).To(Equal(true))
+		})
+		It("should fail if the server refused the unmap", func() {
+			status = http.StatusConflict
+			response = resources.GenericResponse{Err: "mapping is unknown"}
+			err := mappingReconciler.ForceUnmap(resources.ForceUnmapRequest{Backend: resources.SCBE, Name: "vol1", Host: "node1"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
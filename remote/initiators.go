@@ -0,0 +1,106 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package remote
+
+import (
+	"bufio"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils/logs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultIscsiInitiatorFile = "/etc/iscsi/initiatorname.iscsi"
+	DefaultFcHostDir          = "/sys/class/fc_host"
+	iscsiInitiatorNamePrefix  = "InitiatorName="
+)
+
+// InitiatorsDiscoverer finds the storage initiators of the node, so the server can define the node on the storage system
+type InitiatorsDiscoverer interface {
+	GetHostInitiators() (resources.HostInitiators, error)
+}
+
+type initiatorsDiscoverer struct {
+	logger             logs.Logger
+	iscsiInitiatorFile string
+	fcHostDir          string
+}
+
+func NewInitiatorsDiscoverer() InitiatorsDiscoverer {
+	return NewInitiatorsDiscovererWithPaths(DefaultIscsiInitiatorFile, DefaultFcHostDir)
+}
+
+func NewInitiatorsDiscovererWithPaths(iscsiInitiatorFile string, fcHostDir string) InitiatorsDiscoverer {
+	return &initiatorsDiscoverer{logger: logs.GetLogger(), iscsiInitiatorFile: iscsiInitiatorFile, fcHostDir: fcHostDir}
+}
+
+// GetHostInitiators return the iSCSI IQN and the FC WWPNs of the node, a node without iSCSI or FC has empty values
+func (d *initiatorsDiscoverer) GetHostInitiators() (resources.HostInitiators, error) {
+	defer d.logger.Trace(logs.DEBUG)()
+
+	iqn, err := d.getIqn()
+	if err != nil {
+		return resources.HostInitiators{}, err
+	}
+	wwpns, err := d.getWwpns()
+	if err != nil {
+		return resources.HostInitiators{}, err
+	}
+	return resources.HostInitiators{Iqn: iqn, Wwpns: wwpns}, nil
+}
+
+func (d *initiatorsDiscoverer) getIqn() (string, error) {
+	content, err := ioutil.ReadFile(d.iscsiInitiatorFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", d.logger.ErrorRet(err, "failed to read the iSCSI initiator name", logs.Args{{"file", d.iscsiInitiatorFile}})
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, iscsiInitiatorNamePrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, iscsiInitiatorNamePrefix)), nil
+		}
+	}
+	return "", nil
+}
+
+func (d *initiatorsDiscoverer) getWwpns() ([]string, error) {
+	portNameFiles, err := filepath.Glob(filepath.Join(d.fcHostDir, "*", "port_name"))
+	if err != nil {
+		return nil, d.logger.ErrorRet(err, "failed to list the FC hosts", logs.Args{{"dir", d.fcHostDir}})
+	}
+	sort.Strings(portNameFiles)
+	wwpns := []string{}
+	for _, portNameFile := range portNameFiles {
+		content, err := ioutil.ReadFile(portNameFile)
+		if err != nil {
+			return nil, d.logger.ErrorRet(err, "failed to read the FC port name", logs.Args{{"file", portNameFile}})
+		}
+		// e.g 0x10000000c9a1b2c3
+		wwpn := strings.TrimPrefix(strings.TrimSpace(string(content)), "0x")
+		if wwpn != "" {
+			wwpns = append(wwpns, wwpn)
+		}
+	}
+	return wwpns, nil
+}
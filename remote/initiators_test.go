@@ -0,0 +1,64 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package remote_test
+
+import (
+	"github.com/IBM/ubiquity/remote"
+	"github.com/IBM/ubiquity/resources"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var _ = Describe("InitiatorsDiscoverer", func() {
+	var (
+		dir                string
+		iscsiInitiatorFile string
+		fcHostDir          string
+		discoverer         remote.InitiatorsDiscoverer
+	)
+	writeFile := func(path string, content string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "initiators")
+		Expect(err).NotTo(HaveOccurred())
+		iscsiInitiatorFile = filepath.Join(dir, "initiatorname.iscsi")
+		fcHostDir = filepath.Join(dir, "fc_host")
+		discoverer = remote.NewInitiatorsDiscovererWithPaths(iscsiInitiatorFile, fcHostDir)
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+	It("should return the IQN and the WWPNs of the node", func() {
+		writeFile(iscsiInitiatorFile, "## DO NOT EDIT\nInitiatorName=iqn.1994-05.com.redhat:node1\n")
+		writeFile(filepath.Join(fcHostDir, "host1", "port_name"), "0x10000000c9a1b2c4\n")
+		writeFile(filepath.Join(fcHostDir, "host0", "port_name"), "0x10000000c9a1b2c3\n")
+		initiators, err := discoverer.GetHostInitiators()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(initiators).To(Equal(resources.HostInitiators{Iqn: "iqn.1994-05.com.redhat:node1", Wwpns: []string{"10000000c9a1b2c3", "10000000c9a1b2c4"}}))
+	})
+	It("should return empty initiators on a node without iSCSI and FC", func() {
+		initiators, err := discoverer.GetHostInitiators()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(initiators.Iqn).To(BeEmpty())
+		Expect(initiators.Wwpns).To(BeEmpty())
+	})
+})
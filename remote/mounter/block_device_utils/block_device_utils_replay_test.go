@@ -0,0 +1,62 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package block_device_utils_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/remote/mounter/block_device_utils"
+	"github.com/IBM/ubiquity/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Regression scenarios of real multipath / sg_inq outputs, replayed from the fixtures in testdata
+var _ = Describe("block_device_utils_replay_test", func() {
+	var (
+		fakeExec *fakes.FakeExecutor
+	)
+
+	BeforeEach(func() {
+		fakeExec = new(fakes.FakeExecutor)
+	})
+
+	replayFixture := func(fixture string) (utils.ReplayExecutor, block_device_utils.BlockDeviceUtils) {
+		scenario, err := utils.LoadExecutorScenario(filepath.Join("testdata", fixture))
+		Expect(err).ToNot(HaveOccurred())
+		replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+		Expect(err).ToNot(HaveOccurred())
+		return replay, block_device_utils.NewBlockDeviceUtilsWithExecutor(replay)
+	}
+
+	It("Discover finds by sg_inq an EUI-64 device that multipath -ll lists without its WWN", func() {
+		replay, bdUtils := replayFixture("discover_by_sg_inq.json")
+		mpath, err := bdUtils.Discover("6001738cfc9035eb0000000000cea5f6", true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mpath).To(Equal("/dev/mapper/mpatha"))
+		Expect(replay.Verify()).To(Succeed())
+	})
+	It("Cleanup fails if multipath -f times out", func() {
+		replay, bdUtils := replayFixture("cleanup_multipath_timeout.json")
+		err := bdUtils.Cleanup("/dev/mapper/mpatha")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(context.DeadlineExceeded.Error()))
+		Expect(replay.Verify()).To(Succeed())
+	})
+})
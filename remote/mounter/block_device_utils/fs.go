@@ -19,10 +19,9 @@ package block_device_utils
 import (
 	"bufio"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
-	"syscall"
+	"github.com/IBM/ubiquity/utils"
 	"github.com/IBM/ubiquity/utils/logs"
 )
 
@@ -215,12 +214,8 @@ func (b *blockDeviceUtils) IsDirAMountPoint(dirPath string) (bool, []string, err
 
 func (b *blockDeviceUtils) IsExitStatusCode(err error, code int) bool {
 	defer b.logger.Trace(logs.DEBUG)()
-	isExitStatusCode := false
-	if status, ok := err.(*exec.ExitError); ok {
-		if waitStatus, ok := status.ProcessState.Sys().(syscall.WaitStatus); ok {
-			isExitStatusCode = waitStatus.ExitStatus() == code
-		}
-	}
+	exitStatusCode, ok := utils.GetExitStatusCode(err)
+	isExitStatusCode := ok && exitStatusCode == code
 	b.logger.Info("verified", logs.Args{{"isExitStatusCode", isExitStatusCode}, {"code", code}, {"error", err}})
 	return isExitStatusCode
 }
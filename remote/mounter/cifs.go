@@ -0,0 +1,164 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mounter
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils"
+)
+
+type cifsMounter struct {
+	logger   *log.Logger
+	config   resources.SpectrumSmbRemoteConfig
+	executor utils.Executor
+}
+
+func NewCifsMounter(logger *log.Logger, config resources.SpectrumSmbRemoteConfig) resources.Mounter {
+	return &cifsMounter{logger: logger, config: config, executor: utils.NewExecutor()}
+}
+
+func NewCifsMounterWithExecutor(logger *log.Logger, config resources.SpectrumSmbRemoteConfig, executor utils.Executor) resources.Mounter {
+	return &cifsMounter{logger: logger, config: config, executor: executor}
+}
+
+func (s *cifsMounter) Mount(mountRequest resources.MountRequest) (string, error) {
+	s.logger.Println("cifsMounter: Mount start")
+	defer s.logger.Println("cifsMounter: Mount end")
+
+	remoteMountpoint, err := cifsRemoteMountpoint(mountRequest.Mountpoint)
+	if err != nil {
+		return "", err
+	}
+	if s.isMounted(mountRequest.Mountpoint, remoteMountpoint) {
+		s.logger.Printf("cifsMounter: - mount: %s is already mounted at %s\n", mountRequest.Mountpoint, remoteMountpoint)
+		return remoteMountpoint, nil
+	}
+
+	s.logger.Printf("cifsMounter: mkdir -p %s\n", remoteMountpoint)
+	args := []string{"-p", remoteMountpoint}
+
+	_, err = s.executor.Execute("mkdir", args)
+	if err != nil {
+		return "", fmt.Errorf("cifsMounter: Failed to mkdir for remote mountpoint %s (share %s, error '%s')\n", remoteMountpoint, mountRequest.Mountpoint, err.Error())
+	}
+
+	return s.mount(mountRequest.Mountpoint, remoteMountpoint, s.mountOptions(mountRequest.VolumeConfig))
+}
+
+func (s *cifsMounter) Unmount(unmountRequest resources.UnmountRequest) error {
+	s.logger.Println("cifsMounter: Unmount start")
+	defer s.logger.Println("cifsMounter: Unmount end")
+
+	smbShare, ok := unmountRequest.VolumeConfig["smb_share"].(string)
+	if !ok {
+		return fmt.Errorf("cifsMounter: smb_share is missing from the volume config")
+	}
+	remoteMountpoint, err := cifsRemoteMountpoint(smbShare)
+	if err != nil {
+		return err
+	}
+
+	return s.unmount(remoteMountpoint)
+}
+
+func (s *cifsMounter) ActionAfterDetach(request resources.AfterDetachRequest) error {
+	// no action needed for SSc
+	return nil
+}
+
+// cifsRemoteMountpoint return the local mountpoint of a share //<server>/<share>, /mnt/smb/<server>/<share>
+func cifsRemoteMountpoint(smbShare string) (string, error) {
+	serverAndShare := strings.Split(strings.TrimPrefix(smbShare, "//"), "/")
+	if !strings.HasPrefix(smbShare, "//") || len(serverAndShare) != 2 || serverAndShare[0] == "" || serverAndShare[1] == "" {
+		return "", fmt.Errorf("cifsMounter: Invalid SMB share %s, expected //<server>/<share>", smbShare)
+	}
+	return path.Join("/mnt/smb", serverAndShare[0], serverAndShare[1]), nil
+}
+
+// mountOptions return the cifs mount options of the volume, the files of a volume with uid or gid are owned by the
+// uid and gid on the host, like the files of the NFS volumes, and are open to all the users otherwise
+func (s *cifsMounter) mountOptions(volumeConfig map[string]interface{}) string {
+	options := []string{}
+	if s.config.CredentialsFile != "" {
+		options = append(options, fmt.Sprintf("credentials=%s", s.config.CredentialsFile))
+	}
+	uid, uidSpecified := volumeConfig["uid"]
+	gid, gidSpecified := volumeConfig["gid"]
+	if uidSpecified || gidSpecified {
+		if uidSpecified {
+			options = append(options, fmt.Sprintf("uid=%v", uid))
+		}
+		if gidSpecified {
+			options = append(options, fmt.Sprintf("gid=%v", gid))
+		}
+		options = append(options, "file_mode=0711", "dir_mode=0711")
+	} else {
+		options = append(options, "file_mode=0777", "dir_mode=0777")
+	}
+	if s.config.MountOptions != "" {
+		options = append(options, s.config.MountOptions)
+	}
+	return strings.Join(options, ",")
+}
+
+func (s *cifsMounter) mount(smbShare, remoteMountpoint, options string) (string, error) {
+	s.logger.Printf("cifsMounter: - mount start smbShare=%s\n", smbShare)
+	defer s.logger.Printf("cifsMounter: - mount end smbShare=%s\n", smbShare)
+
+	args := []string{"-t", "cifs", smbShare, remoteMountpoint, "-o", options}
+	output, err := s.executor.Execute("mount", args)
+	if err != nil {
+		return "", fmt.Errorf("cifsMounter: Failed to mount share %s to remote mountpoint %s (error '%s', output '%s')\n", smbShare, remoteMountpoint, err.Error(), output)
+	}
+	s.logger.Printf("cifsMounter:  mount output: %s\n", string(output))
+
+	return remoteMountpoint, nil
+}
+
+func (s *cifsMounter) isMounted(smbShare, remoteMountpoint string) bool {
+	s.logger.Printf("cifsMounter: - isMounted start smbShare=%s\n", smbShare)
+	defer s.logger.Printf("cifsMounter: - isMounted end smbShare=%s\n", smbShare)
+
+	command := "grep"
+	args := []string{"-qs", fmt.Sprintf("%s\\s%s", smbShare, remoteMountpoint), "/proc/mounts"}
+	output, err := s.executor.Execute(command, args)
+	if err != nil {
+		s.logger.Printf("cifsMounter: failed to check if share %s is mounted at remote mountpoint %s (error '%s', output '%s')\n",
+			smbShare, remoteMountpoint, err.Error(), output)
+		return false
+	}
+	return true
+}
+
+func (s *cifsMounter) unmount(remoteMountpoint string) error {
+	s.logger.Printf("cifsMounter: - unmount start remoteMountpoint=%s\n", remoteMountpoint)
+	defer s.logger.Printf("cifsMounter: - unmount end remoteMountpoint=%s\n", remoteMountpoint)
+
+	args := []string{remoteMountpoint}
+	output, err := s.executor.Execute("umount", args)
+	if err != nil {
+		return fmt.Errorf("Failed to unmount remote mountpoint %s (error '%s', output '%s')\n", remoteMountpoint, err.Error(), output)
+	}
+	s.logger.Printf("cifsMounter: umount output: %s\n", string(output))
+
+	return nil
+}
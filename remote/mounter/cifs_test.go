@@ -0,0 +1,83 @@
+package mounter_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/remote/mounter"
+	"github.com/IBM/ubiquity/resources"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cifs_mounter_test", func() {
+	var (
+		fakeExec    *fakes.FakeExecutor
+		cifsMounter resources.Mounter
+	)
+
+	BeforeEach(func() {
+		fakeExec = new(fakes.FakeExecutor)
+		config := resources.SpectrumSmbRemoteConfig{CredentialsFile: "/etc/ubiquity/smb-credentials", MountOptions: "vers=3.0"}
+		cifsMounter = mounter.NewCifsMounterWithExecutor(log.New(os.Stdout, "cifs: ", log.Lshortfile|log.LstdFlags), config, fakeExec)
+	})
+
+	Context(".Mount", func() {
+		It("should mount the share below /mnt/smb with the configured credentials", func() {
+			fakeExec.ExecuteReturnsOnCall(0, nil, fmt.Errorf("not mounted"))
+			volumeConfig := map[string]interface{}{"uid": "1000", "gid": "100"}
+			mountpoint, err := cifsMounter.Mount(resources.MountRequest{Mountpoint: "//ces.example.com/vol1", VolumeConfig: volumeConfig})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mountpoint).To(Equal("/mnt/smb/ces.example.com/vol1"))
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(3))
+			command, args := fakeExec.ExecuteArgsForCall(1)
+			Expect(command).To(Equal("mkdir"))
+			Expect(args).To(Equal([]string{"-p", "/mnt/smb/ces.example.com/vol1"}))
+			command, args = fakeExec.ExecuteArgsForCall(2)
+			Expect(command).To(Equal("mount"))
+			Expect(args).To(Equal([]string{"-t", "cifs", "//ces.example.com/vol1", "/mnt/smb/ces.example.com/vol1", "-o",
+				"credentials=/etc/ubiquity/smb-credentials,uid=1000,gid=100,file_mode=0711,dir_mode=0711,vers=3.0"}))
+		})
+		It("should open the files to all the users if the volume has no uid and gid", func() {
+			fakeExec.ExecuteReturnsOnCall(0, nil, fmt.Errorf("not mounted"))
+			_, err := cifsMounter.Mount(resources.MountRequest{Mountpoint: "//ces.example.com/vol1", VolumeConfig: map[string]interface{}{}})
+			Expect(err).ToNot(HaveOccurred())
+			_, args := fakeExec.ExecuteArgsForCall(2)
+			Expect(args[len(args)-1]).To(Equal("credentials=/etc/ubiquity/smb-credentials,file_mode=0777,dir_mode=0777,vers=3.0"))
+		})
+		It("should not mount a share which is already mounted", func() {
+			mountpoint, err := cifsMounter.Mount(resources.MountRequest{Mountpoint: "//ces.example.com/vol1", VolumeConfig: map[string]interface{}{}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mountpoint).To(Equal("/mnt/smb/ces.example.com/vol1"))
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(1))
+		})
+		It("should fail if the mount fails", func() {
+			fakeExec.ExecuteReturnsOnCall(0, nil, fmt.Errorf("not mounted"))
+			fakeExec.ExecuteReturnsOnCall(2, nil, fmt.Errorf("mount error(13): Permission denied"))
+			_, err := cifsMounter.Mount(resources.MountRequest{Mountpoint: "//ces.example.com/vol1", VolumeConfig: map[string]interface{}{}})
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail for a mountpoint which is not an SMB share", func() {
+			_, err := cifsMounter.Mount(resources.MountRequest{Mountpoint: "ces.example.com:/gpfs/fs1/vol1", VolumeConfig: map[string]interface{}{}})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeExec.ExecuteCallCount()).To(Equal(0))
+		})
+	})
+
+	Context(".Unmount", func() {
+		It("should unmount the local mountpoint of the share", func() {
+			volumeConfig := map[string]interface{}{"smb_share": "//ces.example.com/vol1"}
+			err := cifsMounter.Unmount(resources.UnmountRequest{VolumeConfig: volumeConfig})
+			Expect(err).ToNot(HaveOccurred())
+			command, args := fakeExec.ExecuteArgsForCall(0)
+			Expect(command).To(Equal("umount"))
+			Expect(args).To(Equal([]string{"/mnt/smb/ces.example.com/vol1"}))
+		})
+		It("should fail if the volume config has no share", func() {
+			err := cifsMounter.Unmount(resources.UnmountRequest{VolumeConfig: map[string]interface{}{}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
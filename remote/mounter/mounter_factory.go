@@ -29,6 +29,8 @@ func (m *mounterFactory) GetMounterPerBackend(backend string, legacyLogger *log.
 		return NewSpectrumScaleMounter(legacyLogger), nil
 	} else if backend == resources.SoftlayerNFS || backend == resources.SpectrumScaleNFS {
 		return NewNfsMounter(legacyLogger), nil
+	} else if backend == resources.SpectrumScaleSMB {
+		return NewCifsMounter(legacyLogger, pluginConfig.SpectrumSmbRemoteConfig), nil
 	} else if backend == resources.SCBE {
 		return NewScbeMounter(pluginConfig.ScbeRemoteConfig), nil
 	} else {
@@ -56,6 +56,16 @@ var _ = Describe("mounter_factory_test", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(backendMounter).NotTo(Equal(nil))
 		})
+		It("should succeed get spectrum-scale-smb backend", func() {
+			backendMounter, err := mounterFactory.GetMounterPerBackend(
+				resources.SpectrumScaleSMB,
+				nil,
+				resources.UbiquityPluginConfig{},
+				resources.RequestContext{},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backendMounter).NotTo(Equal(nil))
+		})
 	})
 })
 
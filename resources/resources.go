@@ -24,6 +24,7 @@ import (
 const (
 	SpectrumScale     string = "spectrum-scale"
 	SpectrumScaleNFS  string = "spectrum-scale-nfs"
+	SpectrumScaleSMB  string = "spectrum-scale-smb"
 	SoftlayerNFS      string = "softlayer-nfs"
 	SCBE              string = "scbe"
 	ScbeInterfaceName string = "Enabler for Containers"
@@ -35,6 +36,7 @@ type UbiquityServerConfig struct {
 	ConfigPath          string
 	SpectrumScaleConfig SpectrumScaleConfig
 	ScbeConfig          ScbeConfig
+	NfsConfig           NfsConfig
 	BrokerConfig        BrokerConfig
 	DefaultBackend      string
 	LogLevel            string
@@ -44,9 +46,23 @@ type UbiquityServerConfig struct {
 type SpectrumScaleConfig struct {
 	DefaultFilesystemName string
 	NfsServerAddr         string
+	SmbServerAddr         string // CES address of the SMB shares, e.g. ces.example.com
 	SshConfig             SshConfig
 	RestConfig            RestConfig
 	ForceDelete           bool
+	UsageCacheTimeout     int    // seconds to cache the usage of the volumes and the filesystems
+	JunctionPathTemplate  string // junction of the new filesets relative to the filesystem mountpoint, e.g. projects/{tenant}/{volume}
+	DetachPolicy          string // keep-linked (default), unlink-unused or always-unlink
+}
+
+// NfsConfig is the config of the generic NFS backend, which keeps the volumes in subdirectories of an export of an NFS server
+type NfsConfig struct {
+	ServerAddr      string // NFS server that the hosts mount the volumes from
+	ExportPath      string // export root on the NFS server, e.g. /exports/ubiquity
+	MountPath       string // where the ubiquity server mounts the export root to create the volumes, e.g. /mnt/ubiquity-nfs
+	MountOptions    string // nfs mount options of the export root, e.g. vers=4.1
+	QuotaFilesystem string // XFS filesystem with project quotas that holds the export root, when the ubiquity server runs on the NFS server
+	ForceDelete     bool   // delete the directory of a volume when the volume is removed
 }
 
 type CredentialInfo struct {
@@ -68,11 +84,14 @@ type ScbeConfig struct {
 	DefaultVolumeSize    string // The default volume size in case not specified by user
 	UbiquityInstanceName string // Prefix for the volume name in the storage side (max length 15 char)
 
-	DefaultFilesystemType string // The default filesystem type to create on new provisioned volume during attachment to the host
+	DefaultFilesystemType string   // The default filesystem type to create on new provisioned volume during attachment to the host
+	AllowedServices       []string // SCBE storage services that profile=auto can choose from (empty means all delegated services)
+	CreateHosts           bool     // define unknown hosts on the storage system during attach, by the initiators the node sends
+	PlainSizeInGB         bool     // compatibility: a size without unit is in GB, as before units were supported, instead of bytes (set by default by LoadConfig)
 }
 
 const UbiquityInstanceNameMaxSize = 15
-const DefaultForScbeConfigParamDefaultVolumeSize = "1"    // if customer don't mention size, then the default is 1gb
+const DefaultForScbeConfigParamDefaultVolumeSize = "1Gi"  // if customer don't mention size, then the default is 1gb
 const DefaultForScbeConfigParamDefaultFilesystem = "ext4" // if customer don't mention fstype, then the default is ext4
 const PathToMountUbiquityBlockDevices = "/ubiquity/%s"    // %s is the WWN of the volume # TODO this should be moved to docker plugin side
 const OptionNameForVolumeFsType = "fstype"                // the option name of the fstype and also the key in the volumeConfig
@@ -85,24 +104,44 @@ const KeyScbeSslMode = "SCBE_SSL_MODE"
 const DefaultDbSslMode = SslModeVerifyFull
 const DefaultScbeSslMode = SslModeVerifyFull
 const DefaultPluginsSslMode = SslModeVerifyFull
+const DefaultSpectrumScaleRestSslMode = SslModeVerifyFull
 
 type SshConfig struct {
-	User string
-	Host string
-	Port string
+	User           string
+	Host           string
+	Port           string
+	KeyFile        string // private key file (e.g mounted from a secret), used instead of Password if set
+	Password       string
+	HostKey        string // pinned public host key(s) of Host, in authorized_keys format
+	CommandTimeout int    // seconds, per command
 }
 
 type RestConfig struct {
-	Endpoint string
-	User     string
-	Password string
-	Hostname string
+	Endpoint   string
+	User       string
+	Password   string
+	Hostname   string
+	ApiVersion string // v1 (deprecated) or v2, probed from the GUI if empty
+
+	SslMode         string // verify-full (default) or require
+	CACertFile      string // CA bundle to verify the GUI certificate with, mandatory for verify-full
+	ClientCertFile  string // client certificate (PEM) to authenticate with, together with ClientKeyFile
+	ClientKeyFile   string
+	CertFingerprint string // pinned SHA-256 fingerprint of the GUI certificate, hex (colons are ignored)
+
+	JobTimeout      int // seconds, overall timeout of an asynchronous job, the job is cancelled when it exceeds
+	JobPollInterval int // milliseconds, first interval between the job status queries, doubled after every query
 }
 
 type SpectrumNfsRemoteConfig struct {
 	ClientConfig string
 }
 
+type SpectrumSmbRemoteConfig struct {
+	CredentialsFile string // cifs credentials file of the SMB user which mounts the shares
+	MountOptions    string // additional cifs mount options, e.g. vers=3.0
+}
+
 type BrokerConfig struct {
 	ConfigPath string
 	Port       int //for CF Service broker
@@ -114,6 +153,7 @@ type UbiquityPluginConfig struct {
 	LogRotateMaxSize        int
 	UbiquityServer          UbiquityServerConnectionInfo
 	SpectrumNfsRemoteConfig SpectrumNfsRemoteConfig
+	SpectrumSmbRemoteConfig SpectrumSmbRemoteConfig
 	ScbeRemoteConfig        ScbeRemoteConfig
 	Backends                []string
 	LogLevel                string
@@ -175,6 +215,28 @@ func (e *VolAlreadyExistsError) Error() string {
 	return fmt.Sprintf("Volume [%s] already exists.", e.VolName)
 }
 
+// CapacityReporter is implemented by backends that can report the capacity of their storage services
+type CapacityReporter interface {
+	GetCapacity(getCapacityRequest GetCapacityRequest) ([]ServiceCapacity, error)
+}
+
+// VolumeResizer is implemented by backends that can change the size of existing volumes
+type VolumeResizer interface {
+	ResizeVolume(resizeVolumeRequest ResizeVolumeRequest) error
+}
+
+// NfsExportUpdater is implemented by backends that can change the NFS export options of existing volumes
+type NfsExportUpdater interface {
+	UpdateNfsExport(updateNfsExportRequest UpdateNfsExportRequest) error
+}
+
+// MappingReconciler is implemented by backends that can find host mappings left behind by dead nodes and remove them
+type MappingReconciler interface {
+	ReportAttachments(reportAttachmentsRequest ReportAttachmentsRequest) error
+	GetStaleMappings(getStaleMappingsRequest GetStaleMappingsRequest) ([]StaleMapping, error)
+	ForceUnmap(forceUnmapRequest ForceUnmapRequest) error
+}
+
 //go:generate counterfeiter -o ../fakes/fake_mounter.go . Mounter
 
 type Mounter interface {
@@ -215,9 +277,16 @@ type AttachRequest struct {
 	CredentialInfo CredentialInfo
 	Name           string
 	Host           string
+	Initiators     HostInitiators // optional, used to define the host on the storage system if needed
 	Context        RequestContext
 }
 
+// HostInitiators are the storage initiators of a node
+type HostInitiators struct {
+	Iqn   string   // iSCSI qualified name
+	Wwpns []string // Fibre Channel world wide port names
+}
+
 type DetachRequest struct {
 	CredentialInfo CredentialInfo
 	Name           string
@@ -239,6 +308,90 @@ type ActivateResponse struct {
 	Err        string
 }
 
+type ResizeVolumeRequest struct {
+	CredentialInfo CredentialInfo
+	Name           string
+	Size           string // the new size of the volume, e.g 10G
+	Context        RequestContext
+}
+
+type UpdateNfsExportRequest struct {
+	CredentialInfo CredentialInfo
+	Name           string
+	Opts           map[string]interface{} // the NFS export opts of the volume, e.g nfsClients, nfsAccessType
+	Context        RequestContext
+}
+
+type GetCapacityRequest struct {
+	CredentialInfo CredentialInfo
+	Backend        string
+	Context        RequestContext
+}
+
+// ServiceCapacity describes the capacity of a storage service (in bytes)
+type ServiceCapacity struct {
+	Name                   string
+	TotalCapacity          int
+	UsedCapacity           int
+	FreeCapacity           int
+	MaxFreeForProvisioning int
+	QosMaxIops             int
+	QosMaxMbps             int
+}
+
+type GetCapacityResponse struct {
+	Capacity []ServiceCapacity
+	Err      string
+}
+
+// ReportAttachmentsRequest is sent periodically by every node with the volumes it currently uses
+type ReportAttachmentsRequest struct {
+	CredentialInfo CredentialInfo
+	Host           string
+	Volumes        []string // names of the volumes the node uses
+	WWNs           []string // WWNs of the block volumes mounted on the node
+	Context        RequestContext
+}
+
+type GetStaleMappingsRequest struct {
+	CredentialInfo CredentialInfo
+	Backend        string
+	Context        RequestContext
+}
+
+const (
+	MappingStatusStale   = "stale"   // the host does not use the volume, the mapping can be removed
+	MappingStatusUnknown = "unknown" // the host did not report, it may still use the volume
+
+	StaleMappingReasonHostNeverReported = "host never reported its attachments"
+	StaleMappingReasonHostReportPending = "server started recently, the host did not report its attachments since"
+	StaleMappingReasonHostStopped       = "host stopped reporting its attachments"
+	StaleMappingReasonVolumeNotInUse    = "host does not report the volume as attached"
+)
+
+// StaleMapping is a volume mapping to a host that does not use the volume (stale), according to the host reports,
+// or to a host whose use of the volume is not known (unknown)
+type StaleMapping struct {
+	Name   string
+	Host   string
+	Status string
+	Reason string
+}
+
+type GetStaleMappingsResponse struct {
+	StaleMappings []StaleMapping
+	Err           string
+}
+
+type ForceUnmapRequest struct {
+	CredentialInfo CredentialInfo
+	Backend        string
+	Name           string
+	Host           string
+	Force          bool // unmap also if the host may still use the volume (unknown or used mapping)
+	Context        RequestContext
+}
+
 type GenericResponse struct {
 	Err string
 }
@@ -283,6 +436,16 @@ type Volume struct {
 	Name       string
 	Backend    string
 	Mountpoint string
+	Usage      *VolumeUsage `gorm:"-"` // reported by backends that track the usage of their volumes, not stored
+}
+
+// VolumeUsage is the usage of a volume, its limits and the free space of the storage it is on (in bytes). A 0 limit means no limit.
+type VolumeUsage struct {
+	UsedBytes   int64
+	UsedInodes  int64
+	LimitBytes  int64
+	LimitInodes int64
+	FreeBytes   int64 // free space of the filesystem or pool of the volume
 }
 
 type GetConfigResponse struct {
@@ -28,3 +28,13 @@ type NoENVKeyError struct {
 func (e *NoENVKeyError) Error() string {
 	return fmt.Sprintf("ENV Key [%s] not exist.", e.EnvKeyName)
 }
+
+// CommandExitError is a command that exited with a none zero code, without the process (e.g a replayed command)
+type CommandExitError struct {
+	Command  string
+	ExitCode int
+}
+
+func (e *CommandExitError) Error() string {
+	return fmt.Sprintf("Command [%s] exited with status [%d]", e.Command, e.ExitCode)
+}
@@ -24,6 +24,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"syscall"
 	"time"
 )
 
@@ -168,3 +169,19 @@ func (e *executor)	GetGlobFiles(file_pattern string) (matches []string, err erro
 func (e *executor) IsSameFile(file1 os.FileInfo, file2 os.FileInfo) bool{
 	return os.SameFile(file1, file2)
 }
+
+// GetExitStatusCode return the exit code of a command that exited with a none zero code
+func GetExitStatusCode(err error) (int, bool) {
+	switch exitErr := err.(type) {
+	case *exec.ExitError:
+		if exitErr.ProcessState == nil {
+			return 0, false
+		}
+		if status, ok := exitErr.ProcessState.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus(), true
+		}
+	case *CommandExitError:
+		return exitErr.ExitCode, true
+	}
+	return 0, false
+}
@@ -0,0 +1,100 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	KiB uint64 = 1024
+	MiB        = 1024 * KiB
+	GiB        = 1024 * MiB
+	TiB        = 1024 * GiB
+	PiB        = 1024 * TiB
+)
+
+// quantityUnits maps every supported unit suffix (lower case) to its size in bytes.
+// Decimal-looking suffixes (kb, mb, ...) are binary as well, to stay compatible with the existing configurations.
+var quantityUnits = map[string]uint64{
+	"": 1, "b": 1, "byte": 1, "bytes": 1,
+	"k": KiB, "kb": KiB, "ki": KiB, "kib": KiB, "kilobyte": KiB, "kilobytes": KiB,
+	"m": MiB, "mb": MiB, "mi": MiB, "mib": MiB, "megabyte": MiB, "megabytes": MiB,
+	"g": GiB, "gb": GiB, "gi": GiB, "gib": GiB, "gigabyte": GiB, "gigabytes": GiB,
+	"t": TiB, "tb": TiB, "ti": TiB, "tib": TiB, "terabyte": TiB, "terabytes": TiB,
+	"p": PiB, "pb": PiB, "pi": PiB, "pib": PiB, "petabyte": PiB, "petabytes": PiB,
+}
+
+// ParseQuantity converts a size such as 500Mi, 1.5Gi, 2T or 1048576 into bytes.
+// A number without unit is bytes. A fractional size must add up to a whole number of bytes.
+func ParseQuantity(quantity string) (uint64, error) {
+	trimmed := strings.TrimSpace(quantity)
+	numberEnd := strings.IndexFunc(trimmed, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.')
+	})
+	if numberEnd < 0 {
+		numberEnd = len(trimmed)
+	}
+	number, unit := trimmed[:numberEnd], strings.ToLower(strings.TrimSpace(trimmed[numberEnd:]))
+	if number == "" || strings.Count(number, ".") > 1 || strings.HasPrefix(number, ".") || strings.HasSuffix(number, ".") {
+		return 0, fmt.Errorf("Invalid number specified %v", quantity)
+	}
+
+	multiplier, ok := quantityUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("Invalid Unit %v supplied with %v", unit, quantity)
+	}
+
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return 0, fmt.Errorf("Invalid number specified %v", quantity)
+	}
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).SetUint64(multiplier)))
+	if !value.IsInt() {
+		return 0, fmt.Errorf("Size %v is not a whole number of bytes", quantity)
+	}
+	if !value.Num().IsUint64() {
+		return 0, fmt.Errorf("Overflow detected %v", quantity)
+	}
+	return value.Num().Uint64(), nil
+}
+
+// BestQuantityUnit returns size expressed in the largest of the given units that divides it exactly.
+// The units are suffixes known to ParseQuantity, ordered from the largest to the smallest.
+func BestQuantityUnit(size uint64, units []string) (uint64, string, error) {
+	for _, unit := range units {
+		multiplier, ok := quantityUnits[strings.ToLower(unit)]
+		if !ok {
+			return 0, "", fmt.Errorf("Invalid Unit %v", unit)
+		}
+		if size%multiplier == 0 {
+			return size / multiplier, unit, nil
+		}
+	}
+	return 0, "", fmt.Errorf("Size %v bytes cannot be expressed in any of the units %v", size, units)
+}
+
+// FormatQuantity returns size in the largest K, M, G or T unit that keeps it a whole number (e.g 1536M)
+func FormatQuantity(size uint64) string {
+	value, unit, err := BestQuantityUnit(size, []string{"T", "G", "M", "K", ""})
+	if err != nil {
+		return fmt.Sprintf("%d", size)
+	}
+	return fmt.Sprintf("%d%s", value, unit)
+}
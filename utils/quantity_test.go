@@ -0,0 +1,75 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils_test
+
+import (
+	"github.com/IBM/ubiquity/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils - quantity", func() {
+	Context(".ParseQuantity", func() {
+		It("should parse sizes with and without units", func() {
+			expected := map[string]uint64{
+				"1048576": 1048576,
+				"512b":    512,
+				"500Mi":   500 * utils.MiB,
+				"500M":    500 * utils.MiB,
+				"1.5Gi":   1536 * utils.MiB,
+				"2T":      2 * utils.TiB,
+				"10 gb":   10 * utils.GiB,
+				"1Pi":     utils.PiB,
+			}
+			for quantity, bytes := range expected {
+				size, err := utils.ParseQuantity(quantity)
+				Expect(err).NotTo(HaveOccurred(), quantity)
+				Expect(size).To(Equal(bytes), quantity)
+			}
+		})
+		It("should fail on invalid sizes", func() {
+			for _, quantity := range []string{"", "Gi", "1.2.3G", ".5G", "1.G", "10XB", "-1G", "1.5b", "99999999999P"} {
+				_, err := utils.ParseQuantity(quantity)
+				Expect(err).To(HaveOccurred(), quantity)
+			}
+		})
+	})
+	Context(".BestQuantityUnit", func() {
+		It("should return the largest unit that divides the size", func() {
+			size, unit, err := utils.BestQuantityUnit(1536*utils.MiB, []string{"tb", "gb", "mb"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(size).To(Equal(uint64(1536)))
+			Expect(unit).To(Equal("mb"))
+
+			size, unit, err = utils.BestQuantityUnit(2*utils.TiB, []string{"tb", "gb", "mb"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(size).To(Equal(uint64(2)))
+			Expect(unit).To(Equal("tb"))
+		})
+		It("should fail if no unit divides the size", func() {
+			_, _, err := utils.BestQuantityUnit(1000, []string{"gb", "mb"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context(".FormatQuantity", func() {
+		It("should format the size with the largest whole unit", func() {
+			Expect(utils.FormatQuantity(utils.GiB)).To(Equal("1G"))
+			Expect(utils.FormatQuantity(1536 * utils.MiB)).To(Equal("1536M"))
+			Expect(utils.FormatQuantity(1000)).To(Equal("1000"))
+		})
+	})
+})
@@ -0,0 +1,312 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExecutorScenario is a script of command executions, loaded from (or saved to) a JSON fixture file.
+// If Ordered the commands must be executed in the order of the steps, otherwise each command
+// is answered by the first step that matches it.
+type ExecutorScenario struct {
+	Ordered bool           `json:"ordered"`
+	Steps   []ExecutorStep `json:"steps"`
+}
+
+// ExecutorStep is one command of a scenario and its result
+type ExecutorStep struct {
+	Command  string   `json:"command,omitempty"`  // the command name, matches any command if empty
+	Args     []string `json:"args,omitempty"`     // the exact args, matches any args if nil
+	Pattern  string   `json:"pattern,omitempty"`  // regexp matched against the command line ("command arg1 arg2") instead of Command and Args
+	Stdout   string   `json:"stdout"`             // the output of the command
+	ExitCode int      `json:"exitCode,omitempty"` // a none zero exit code fails the command with a *CommandExitError
+	Error    string   `json:"error,omitempty"`    // fails the command with this error instead of an exit code (e.g the command was killed)
+	DelayMs  int      `json:"delayMs,omitempty"`  // the command run time, it times out if ExecuteWithTimeout is called with a shorter timeout
+	Times    int      `json:"times,omitempty"`    // how many times the step can be used, 0 means unlimited (once in an ordered scenario)
+}
+
+// LoadExecutorScenario reads a scenario from a JSON fixture file
+func LoadExecutorScenario(path string) (ExecutorScenario, error) {
+	scenario := ExecutorScenario{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return scenario, err
+	}
+	if err = json.Unmarshal(data, &scenario); err != nil {
+		return scenario, fmt.Errorf("Failed to parse executor scenario %s: %s", path, err.Error())
+	}
+	return scenario, nil
+}
+
+// SaveExecutorScenario writes a scenario to a JSON fixture file
+func SaveExecutorScenario(path string, scenario ExecutorScenario) error {
+	data, err := json.MarshalIndent(scenario, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// RecordingExecutor is an Executor that executes the commands with another executor and records them as a scenario
+type RecordingExecutor interface {
+	Executor
+	Scenario() ExecutorScenario
+	Save(path string) error
+}
+
+type recordingExecutor struct {
+	Executor
+	lock  sync.Mutex
+	steps []ExecutorStep
+}
+
+// NewRecordingExecutor return an executor that records the commands that executor executes
+func NewRecordingExecutor(executor Executor) RecordingExecutor {
+	return &recordingExecutor{Executor: executor}
+}
+
+func (r *recordingExecutor) Execute(command string, args []string) ([]byte, error) {
+	start := time.Now()
+	stdout, err := r.Executor.Execute(command, args)
+	r.record(command, args, stdout, err, start)
+	return stdout, err
+}
+
+func (r *recordingExecutor) ExecuteWithTimeout(mSeconds int, command string, args []string) ([]byte, error) {
+	start := time.Now()
+	stdout, err := r.Executor.ExecuteWithTimeout(mSeconds, command, args)
+	r.record(command, args, stdout, err, start)
+	return stdout, err
+}
+
+func (r *recordingExecutor) record(command string, args []string, stdout []byte, err error, start time.Time) {
+	step := ExecutorStep{Command: command, Args: append([]string{}, args...), Stdout: string(stdout)}
+	if err != nil {
+		if exitCode, ok := GetExitStatusCode(err); ok {
+			step.ExitCode = exitCode
+		} else {
+			step.Error = err.Error()
+		}
+		if err == context.DeadlineExceeded {
+			// keep the timeout in the replay
+			step.DelayMs = int(time.Since(start) / time.Millisecond)
+		}
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+func (r *recordingExecutor) Scenario() ExecutorScenario {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return ExecutorScenario{Ordered: true, Steps: append([]ExecutorStep{}, r.steps...)}
+}
+
+func (r *recordingExecutor) Save(path string) error {
+	return SaveExecutorScenario(path, r.Scenario())
+}
+
+// ReplayExecutor is an Executor that answers the commands from a scenario instead of executing them
+type ReplayExecutor interface {
+	Executor
+	// Executed return the command lines that were executed, e.g "multipath -ll"
+	Executed() []string
+	// Verify return an error if a command did not match the scenario or if steps with Times were not fully used
+	Verify() error
+}
+
+type replayExecutor struct {
+	Executor
+	scenario ExecutorScenario
+	patterns []*regexp.Regexp
+	lock     sync.Mutex
+	used     []int
+	next     int // the next step of an ordered scenario
+	executed []string
+	errors   []string
+}
+
+// NewReplayExecutor return an executor that replays the scenario.
+// All the other Executor methods (file system, host name...) are delegated to executor, a new Executor if nil.
+func NewReplayExecutor(scenario ExecutorScenario, executor Executor) (ReplayExecutor, error) {
+	if executor == nil {
+		executor = NewExecutor()
+	}
+	patterns := make([]*regexp.Regexp, len(scenario.Steps))
+	for i, step := range scenario.Steps {
+		if step.Pattern == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(step.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern in step %d of the executor scenario: %s", i, err.Error())
+		}
+		patterns[i] = pattern
+	}
+	return &replayExecutor{Executor: executor, scenario: scenario, patterns: patterns, used: make([]int, len(scenario.Steps))}, nil
+}
+
+func (r *replayExecutor) Execute(command string, args []string) ([]byte, error) {
+	step, err := r.takeStep(command, args)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(time.Duration(step.DelayMs) * time.Millisecond)
+	return r.result(step, command)
+}
+
+func (r *replayExecutor) ExecuteWithTimeout(mSeconds int, command string, args []string) ([]byte, error) {
+	step, err := r.takeStep(command, args)
+	if err != nil {
+		return nil, err
+	}
+	if step.DelayMs >= mSeconds {
+		time.Sleep(time.Duration(mSeconds) * time.Millisecond)
+		return nil, context.DeadlineExceeded
+	}
+	time.Sleep(time.Duration(step.DelayMs) * time.Millisecond)
+	return r.result(step, command)
+}
+
+// IsExecutable is true for the commands of the scenario, other commands are checked by the delegated executor
+func (r *replayExecutor) IsExecutable(path string) error {
+	for _, step := range r.scenario.Steps {
+		if step.Command == path {
+			return nil
+		}
+	}
+	return r.Executor.IsExecutable(path)
+}
+
+func (r *replayExecutor) Executed() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string{}, r.executed...)
+}
+
+func (r *replayExecutor) Verify() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	problems := append([]string{}, r.errors...)
+	for i, step := range r.scenario.Steps {
+		times := step.Times
+		if times == 0 && r.scenario.Ordered {
+			times = 1
+		}
+		if r.used[i] < times {
+			problems = append(problems, fmt.Sprintf("step %d [%s] was executed %d of %d times", i, stepString(step), r.used[i], times))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Executor scenario mismatch: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (r *replayExecutor) takeStep(command string, args []string) (ExecutorStep, error) {
+	commandLine := strings.TrimSpace(command + " " + strings.Join(args, " "))
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.executed = append(r.executed, commandLine)
+
+	if r.scenario.Ordered {
+		if r.next < len(r.scenario.Steps) && r.matches(r.next, command, args, commandLine) {
+			r.used[r.next]++
+			r.next++
+			return r.scenario.Steps[r.next-1], nil
+		}
+		expected := "end of scenario"
+		if r.next < len(r.scenario.Steps) {
+			expected = stepString(r.scenario.Steps[r.next])
+		}
+		err := &unexpectedCommandError{commandLine, expected}
+		r.errors = append(r.errors, err.Error())
+		return ExecutorStep{}, err
+	}
+
+	for i, step := range r.scenario.Steps {
+		if (step.Times == 0 || r.used[i] < step.Times) && r.matches(i, command, args, commandLine) {
+			r.used[i]++
+			return step, nil
+		}
+	}
+	err := &unexpectedCommandError{commandLine, "no matching step"}
+	r.errors = append(r.errors, err.Error())
+	return ExecutorStep{}, err
+}
+
+func (r *replayExecutor) matches(i int, command string, args []string, commandLine string) bool {
+	if r.patterns[i] != nil {
+		return r.patterns[i].MatchString(commandLine)
+	}
+	step := r.scenario.Steps[i]
+	if step.Command != "" && step.Command != command {
+		return false
+	}
+	if step.Args == nil {
+		return true
+	}
+	if len(step.Args) != len(args) {
+		return false
+	}
+	for j := range args {
+		if step.Args[j] != args[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *replayExecutor) result(step ExecutorStep, command string) ([]byte, error) {
+	stdout := []byte(step.Stdout)
+	if step.Error != "" {
+		if step.Error == context.DeadlineExceeded.Error() {
+			return nil, context.DeadlineExceeded
+		}
+		return stdout, errors.New(step.Error)
+	}
+	if step.ExitCode != 0 {
+		return stdout, &CommandExitError{Command: command, ExitCode: step.ExitCode}
+	}
+	return stdout, nil
+}
+
+type unexpectedCommandError struct {
+	commandLine string
+	expected    string
+}
+
+func (e *unexpectedCommandError) Error() string {
+	return fmt.Sprintf("Unexpected command [%s], expected [%s]", e.commandLine, e.expected)
+}
+
+func stepString(step ExecutorStep) string {
+	if step.Pattern != "" {
+		return step.Pattern
+	}
+	return strings.TrimSpace(step.Command + " " + strings.Join(step.Args, " "))
+}
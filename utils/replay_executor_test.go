@@ -0,0 +1,194 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils - replay executor", func() {
+	var (
+		fakeExec *fakes.FakeExecutor
+		tmpDir   string
+		err      error
+	)
+
+	BeforeEach(func() {
+		fakeExec = new(fakes.FakeExecutor)
+		tmpDir, err = ioutil.TempDir("", "replay-executor")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	Context(".NewReplayExecutor", func() {
+		It("should replay an ordered scenario", func() {
+			scenario := utils.ExecutorScenario{Ordered: true, Steps: []utils.ExecutorStep{
+				{Command: "multipath", Args: []string{"-ll"}, Stdout: "mpatha (36001738cfc9035eb0000000000cea5f6) dm-1 IBM"},
+				{Command: "sg_inq", Args: []string{"-p", "0x83", "/dev/mapper/mpatha"}, Stdout: "[0x6001738cfc9035eb0000000000cea5f6]"},
+			}}
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+
+			out, err := replay.ExecuteWithTimeout(1000, "multipath", []string{"-ll"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(Equal(scenario.Steps[0].Stdout))
+			out, err = replay.Execute("sg_inq", []string{"-p", "0x83", "/dev/mapper/mpatha"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(Equal(scenario.Steps[1].Stdout))
+			Expect(replay.Executed()).To(Equal([]string{"multipath -ll", "sg_inq -p 0x83 /dev/mapper/mpatha"}))
+			Expect(replay.Verify()).To(Succeed())
+		})
+		It("should fail a command that is out of order", func() {
+			scenario := utils.ExecutorScenario{Ordered: true, Steps: []utils.ExecutorStep{
+				{Command: "multipath", Args: []string{"-ll"}},
+				{Command: "sg_inq"},
+			}}
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Execute("sg_inq", []string{"-p", "0x83", "/dev/mapper/mpatha"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("Unexpected command"))
+			err = replay.Verify()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("sg_inq -p 0x83 /dev/mapper/mpatha"))
+			Expect(err.Error()).To(MatchRegexp("step 0 \\[multipath -ll\\] was executed 0 of 1 times"))
+		})
+		It("should match steps by pattern in an unordered scenario", func() {
+			scenario := utils.ExecutorScenario{Steps: []utils.ExecutorStep{
+				{Pattern: "^mmlsfileset gold fset1 ", Stdout: "fset1", Times: 1},
+				{Pattern: "^mmlsfileset gold ", Stdout: "no such fileset", ExitCode: 2},
+			}}
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+
+			out, err := replay.Execute("mmlsfileset", []string{"gold", "fset1", "-Y"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(Equal("fset1"))
+			out, err = replay.Execute("mmlsfileset", []string{"gold", "fset1", "-Y"})
+			Expect(err).To(HaveOccurred())
+			Expect(string(out)).To(Equal("no such fileset"))
+			Expect(replay.Verify()).To(Succeed())
+		})
+		It("should fail with a *CommandExitError holding the exit code", func() {
+			scenario := utils.ExecutorScenario{Steps: []utils.ExecutorStep{{Command: "blkid", ExitCode: 2}}}
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Execute("blkid", []string{"/dev/mapper/mpatha"})
+			Expect(err).To(Equal(&utils.CommandExitError{Command: "blkid", ExitCode: 2}))
+			exitCode, ok := utils.GetExitStatusCode(err)
+			Expect(ok).To(BeTrue())
+			Expect(exitCode).To(Equal(2))
+		})
+		It("should time out if the delay exceeds the timeout", func() {
+			scenario := utils.ExecutorScenario{Steps: []utils.ExecutorStep{{Command: "multipath", DelayMs: 50}}}
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.ExecuteWithTimeout(10, "multipath", []string{"-ll"})
+			Expect(err).To(Equal(context.DeadlineExceeded))
+			start := time.Now()
+			_, err = replay.ExecuteWithTimeout(1000, "multipath", []string{"-ll"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+		})
+		It("should return the error of the step", func() {
+			scenario := utils.ExecutorScenario{Steps: []utils.ExecutorStep{{Command: "iscsiadm", Error: "signal: killed"}}}
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Execute("iscsiadm", []string{"-m", "session", "--rescan"})
+			Expect(err).To(MatchError("signal: killed"))
+		})
+		It("should fail on an invalid pattern", func() {
+			scenario := utils.ExecutorScenario{Steps: []utils.ExecutorStep{{Pattern: "mmlsfileset ("}}}
+			_, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should delegate the other methods to the executor", func() {
+			scenario := utils.ExecutorScenario{Steps: []utils.ExecutorStep{{Command: "multipath"}}}
+			fakeExec.IsExecutableReturns(errors.New("not found"))
+			fakeExec.HostnameReturns("node1", nil)
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(replay.IsExecutable("multipath")).To(Succeed())
+			Expect(replay.IsExecutable("sg_inq")).ToNot(Succeed())
+			Expect(fakeExec.IsExecutableCallCount()).To(Equal(1))
+			Expect(replay.Hostname()).To(Equal("node1"))
+		})
+	})
+
+	Context(".NewRecordingExecutor", func() {
+		It("should record the commands and replay them from the fixture", func() {
+			exitErr := exec.Command("sh", "-c", "exit 3").Run()
+			fakeExec.ExecuteReturnsOnCall(0, []byte("mpatha (36001738cfc9035eb0000000000cea5f6) dm-1"), nil)
+			fakeExec.ExecuteReturnsOnCall(1, []byte("device busy"), exitErr)
+			fakeExec.ExecuteWithTimeoutReturns(nil, context.DeadlineExceeded)
+			recorder := utils.NewRecordingExecutor(fakeExec)
+
+			recorder.Execute("multipath", []string{"-ll"})
+			recorder.Execute("multipath", []string{"-f", "mpatha"})
+			recorder.ExecuteWithTimeout(10, "sg_inq", []string{"-p", "0x83", "/dev/mapper/mpatha"})
+			fixture := filepath.Join(tmpDir, "scenario.json")
+			Expect(recorder.Save(fixture)).To(Succeed())
+
+			scenario, err := utils.LoadExecutorScenario(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scenario).To(Equal(recorder.Scenario()))
+			Expect(scenario.Ordered).To(BeTrue())
+			Expect(scenario.Steps).To(HaveLen(3))
+			Expect(scenario.Steps[1].ExitCode).To(Equal(3))
+			Expect(scenario.Steps[2].Error).To(Equal(context.DeadlineExceeded.Error()))
+
+			replay, err := utils.NewReplayExecutor(scenario, fakeExec)
+			Expect(err).ToNot(HaveOccurred())
+			out, err := replay.Execute("multipath", []string{"-ll"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(Equal("mpatha (36001738cfc9035eb0000000000cea5f6) dm-1"))
+			out, err = replay.Execute("multipath", []string{"-f", "mpatha"})
+			Expect(string(out)).To(Equal("device busy"))
+			exitCode, ok := utils.GetExitStatusCode(err)
+			Expect(ok).To(BeTrue())
+			Expect(exitCode).To(Equal(3))
+			_, err = replay.ExecuteWithTimeout(10, "sg_inq", []string{"-p", "0x83", "/dev/mapper/mpatha"})
+			Expect(err).To(Equal(context.DeadlineExceeded))
+			Expect(replay.Verify()).To(Succeed())
+		})
+		It("should fail to load a corrupted fixture", func() {
+			fixture := filepath.Join(tmpDir, "scenario.json")
+			Expect(ioutil.WriteFile(fixture, []byte("{"), 0644)).To(Succeed())
+			_, err := utils.LoadExecutorScenario(fixture)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -154,60 +154,11 @@ func StringInSlice(a string, list []string) bool {
 }
 
 func ConvertToBytes(logger *log.Logger, inputStr string) (uint64, error) {
-	var Iter int
-	var byteSlice []byte
-	var retValue uint64
-	var uintMax64 uint64
-
-	byteSlice = []byte(inputStr)
-	uintMax64 = (1 << 64) - 1
-
-	for Iter = 0; Iter < len(byteSlice); Iter++ {
-		if ('0' <= byteSlice[Iter]) &&
-			(byteSlice[Iter] <= '9') {
-			continue
-		} else {
-			break
-		}
-	}
-
-	if Iter == 0 {
-		return 0, fmt.Errorf("Invalid number specified %v", inputStr)
-	}
-
-	retValue, err := strconv.ParseUint(inputStr[:Iter], 10, 64)
-
+	retValue, err := ParseQuantity(inputStr)
 	if err != nil {
-		return 0, fmt.Errorf("ParseUint Failed for %v", inputStr[:Iter])
+		return 0, err
 	}
-
-	if Iter == len(inputStr) {
-		logger.Printf("Input string has no Unit, returning %v\n", retValue)
-		return retValue, nil
-	}
-
-	unit := strings.TrimSpace(string(byteSlice[Iter:]))
-	unit = strings.ToLower(unit)
-
-	switch unit {
-	case "b", "bytes":
-		/* Nothing to do here */
-	case "k", "kb", "kilobytes", "kilobyte":
-		retValue *= 1024
-	case "m", "mb", "megabytes", "megabyte":
-		retValue *= (1024 * 1024)
-	case "g", "gb", "gigabytes", "gigabyte":
-		retValue *= (1024 * 1024 * 1024)
-	case "t", "tb", "terabytes", "terabyte":
-		retValue *= (1024 * 1024 * 1024 * 1024)
-	default:
-		return 0, fmt.Errorf("Invalid Unit %v supplied with %v", unit, inputStr)
-	}
-
-	if retValue > uintMax64 {
-		return 0, fmt.Errorf("Overflow detected %v", inputStr)
-	}
-
+	logger.Printf("Converted %v to %v bytes\n", inputStr, retValue)
 	return retValue, nil
 }
 
@@ -229,6 +180,13 @@ func LoadConfig() (resources.UbiquityServerConfig, error) {
 	sshConfig.User = os.Getenv("SSC_SSH_USER")
 	sshConfig.Host = os.Getenv("SSC_SSH_HOST")
 	sshConfig.Port = os.Getenv("SSC_SSH_PORT")
+	sshConfig.KeyFile = os.Getenv("SSC_SSH_KEY_FILE")
+	sshConfig.Password = os.Getenv("SSC_SSH_PASSWORD")
+	sshConfig.HostKey = os.Getenv("SSC_SSH_HOST_KEY")
+	commandTimeout, err := strconv.ParseInt(os.Getenv("SSC_SSH_COMMAND_TIMEOUT"), 0, 32)
+	if err == nil {
+		sshConfig.CommandTimeout = int(commandTimeout)
+	}
 	if sshConfig.User != "" && sshConfig.Host != "" && sshConfig.Port != "" {
 		sscConfig.SshConfig = sshConfig
 	}
@@ -237,24 +195,73 @@ func LoadConfig() (resources.UbiquityServerConfig, error) {
 	restConfig.User = os.Getenv("SSC_REST_USER")
 	restConfig.Password = os.Getenv("SSC_REST_PASSWORD")
 	restConfig.Hostname = os.Getenv("SSC_REST_HOSTNAME")
+	restConfig.ApiVersion = os.Getenv("SSC_REST_API_VERSION")
+	restConfig.SslMode = os.Getenv("SSC_REST_SSL_MODE")
+	restConfig.CACertFile = os.Getenv("SSC_REST_CA_FILE")
+	restConfig.ClientCertFile = os.Getenv("SSC_REST_CLIENT_CERT_FILE")
+	restConfig.ClientKeyFile = os.Getenv("SSC_REST_CLIENT_KEY_FILE")
+	restConfig.CertFingerprint = os.Getenv("SSC_REST_CERT_FINGERPRINT")
+	jobTimeout, err := strconv.ParseInt(os.Getenv("SSC_REST_JOB_TIMEOUT"), 0, 32)
+	if err == nil {
+		restConfig.JobTimeout = int(jobTimeout)
+	}
+	jobPollInterval, err := strconv.ParseInt(os.Getenv("SSC_REST_JOB_POLL_INTERVAL"), 0, 32)
+	if err == nil {
+		restConfig.JobPollInterval = int(jobPollInterval)
+	}
 	if restConfig.User != "" && restConfig.Hostname != "" && restConfig.Password != "" {
 		sscConfig.RestConfig = restConfig
 	}
 	sscConfig.DefaultFilesystemName = os.Getenv("DEFAULT_FILESYSTEM_NAME")
 	sscConfig.NfsServerAddr = os.Getenv("SSC_NFS_SERVER_ADDRESS")
+	sscConfig.SmbServerAddr = os.Getenv("SSC_SMB_SERVER_ADDRESS")
 	forceDelete, err := strconv.ParseBool(os.Getenv("FORCE_DELETE"))
 	if err != nil {
 		sscConfig.ForceDelete = false
 	} else {
 		sscConfig.ForceDelete = forceDelete
 	}
+	usageCacheTimeout, err := strconv.ParseInt(os.Getenv("SSC_USAGE_CACHE_TIMEOUT"), 0, 32)
+	if err == nil {
+		sscConfig.UsageCacheTimeout = int(usageCacheTimeout)
+	}
+	sscConfig.JunctionPathTemplate = os.Getenv("SSC_JUNCTION_PATH_TEMPLATE")
+	sscConfig.DetachPolicy = os.Getenv("SSC_DETACH_POLICY")
 	config.SpectrumScaleConfig = sscConfig
 
+	nfsConfig := resources.NfsConfig{}
+	nfsConfig.ServerAddr = os.Getenv("NFS_SERVER_ADDRESS")
+	nfsConfig.ExportPath = os.Getenv("NFS_EXPORT_PATH")
+	nfsConfig.MountPath = os.Getenv("NFS_MOUNT_PATH")
+	nfsConfig.MountOptions = os.Getenv("NFS_MOUNT_OPTIONS")
+	nfsConfig.QuotaFilesystem = os.Getenv("NFS_QUOTA_FILESYSTEM")
+	nfsForceDelete, err := strconv.ParseBool(os.Getenv("NFS_FORCE_DELETE"))
+	if err == nil {
+		nfsConfig.ForceDelete = nfsForceDelete
+	}
+	config.NfsConfig = nfsConfig
+
 	scbeConfig := resources.ScbeConfig{}
 	scbeConfig.DefaultService = os.Getenv("SCBE_DEFAULT_SERVICE")
 	scbeConfig.DefaultVolumeSize = os.Getenv("DEFAULT_VOLUME_SIZE")
 	scbeConfig.UbiquityInstanceName = os.Getenv("UBIQUITY_INSTANCE_NAME")
 	scbeConfig.DefaultFilesystemType = os.Getenv("DEFAULT_FSTYPE")
+	if allowedServices := os.Getenv("SCBE_ALLOWED_SERVICES"); allowedServices != "" {
+		scbeConfig.AllowedServices = strings.Split(allowedServices, ",")
+	}
+	createHosts, err := strconv.ParseBool(os.Getenv("SCBE_CREATE_HOSTS"))
+	if err != nil {
+		scbeConfig.CreateHosts = false
+	} else {
+		scbeConfig.CreateHosts = createHosts
+	}
+	// the sizes without unit stay in GB, as in the configs written before units were supported, unless set to false
+	plainSizeInGB, err := strconv.ParseBool(os.Getenv("SCBE_PLAIN_SIZE_IN_GB"))
+	if err != nil {
+		scbeConfig.PlainSizeInGB = true
+	} else {
+		scbeConfig.PlainSizeInGB = plainSizeInGB
+	}
 	scbeCred := resources.CredentialInfo{}
 	scbeCred.UserName = os.Getenv("SCBE_USERNAME")
 	scbeCred.Password = os.Getenv("SCBE_PASSWORD")
@@ -0,0 +1,153 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_server
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/utils/logs"
+)
+
+// metric is one gauge family of the Prometheus text exposition format
+type metric struct {
+	name    string
+	help    string
+	samples []metricSample
+}
+
+type metricSample struct {
+	labels [][2]string
+	value  int64
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (m *metric) add(value int64, labels ...[2]string) {
+	m.samples = append(m.samples, metricSample{labels: labels, value: value})
+}
+
+func (m *metric) write(buffer *bytes.Buffer) {
+	if len(m.samples) == 0 {
+		return
+	}
+	fmt.Fprintf(buffer, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+	for _, sample := range m.samples {
+		labels := make([]string, len(sample.labels))
+		for i, label := range sample.labels {
+			labels[i] = fmt.Sprintf(`%s="%s"`, label[0], labelValueEscaper.Replace(label[1]))
+		}
+		fmt.Fprintf(buffer, "%s{%s} %d\n", m.name, strings.Join(labels, ","), sample.value)
+	}
+}
+
+// collectMetrics return the usage of the volumes that report it and the capacity of the backend services,
+// a backend that fails to report is logged and skipped so the other backends are still exported
+func (h *StorageApiHandler) collectMetrics() []byte {
+	volumeUsedBytes := &metric{name: "ubiquity_volume_used_bytes", help: "Bytes used by the volume."}
+	volumeUsedInodes := &metric{name: "ubiquity_volume_used_inodes", help: "Inodes used by the volume."}
+	volumeLimitBytes := &metric{name: "ubiquity_volume_limit_bytes", help: "Quota of the volume in bytes, if it has one."}
+	volumeLimitInodes := &metric{name: "ubiquity_volume_limit_inodes", help: "Inode limit of the volume, if it has one."}
+	volumeFreeBytes := &metric{name: "ubiquity_volume_storage_free_bytes", help: "Free bytes of the filesystem or pool of the volume."}
+	serviceTotalBytes := &metric{name: "ubiquity_service_total_bytes", help: "Total capacity of the storage service in bytes."}
+	serviceFreeBytes := &metric{name: "ubiquity_service_free_bytes", help: "Free capacity of the storage service in bytes."}
+
+	backendNames := make([]string, 0, len(h.backends))
+	for backendName := range h.backends {
+		backendNames = append(backendNames, backendName)
+	}
+	sort.Strings(backendNames)
+
+	var skippedCapacities []string
+	for _, backendName := range backendNames {
+		backend := h.backends[backendName]
+		volumes, err := backend.ListVolumes(resources.ListVolumesRequest{})
+		if err != nil {
+			h.logger.Error("error-metrics-list-volumes", logs.Args{{"backend", backendName}, {"error", err}})
+			volumes = nil
+		}
+		for _, listedVolume := range volumes {
+			volume, err := h.getVolumeForMetrics(backend, listedVolume.Name)
+			if err != nil {
+				h.logger.Error("error-metrics-get-volume", logs.Args{{"backend", backendName}, {"volume", listedVolume.Name}, {"error", err}})
+				continue
+			}
+			if volume.Usage == nil {
+				continue
+			}
+			labels := [][2]string{{"backend", backendName}, {"volume", volume.Name}}
+			volumeUsedBytes.add(volume.Usage.UsedBytes, labels...)
+			volumeUsedInodes.add(volume.Usage.UsedInodes, labels...)
+			if volume.Usage.LimitBytes > 0 {
+				volumeLimitBytes.add(volume.Usage.LimitBytes, labels...)
+			}
+			if volume.Usage.LimitInodes > 0 {
+				volumeLimitInodes.add(volume.Usage.LimitInodes, labels...)
+			}
+			volumeFreeBytes.add(volume.Usage.FreeBytes, labels...)
+		}
+
+		capacityReporter, ok := backend.(resources.CapacityReporter)
+		if !ok {
+			continue
+		}
+		credentialInfo, ok := h.getMetricsCredentialInfo(backendName)
+		if !ok {
+			skippedCapacities = append(skippedCapacities, backendName)
+			continue
+		}
+		capacity, err := capacityReporter.GetCapacity(resources.GetCapacityRequest{Backend: backendName, CredentialInfo: credentialInfo})
+		if err != nil {
+			h.logger.Error("error-metrics-get-capacity", logs.Args{{"backend", backendName}, {"error", err}})
+			continue
+		}
+		for _, service := range capacity {
+			labels := [][2]string{{"backend", backendName}, {"service", service.Name}}
+			serviceTotalBytes.add(int64(service.TotalCapacity), labels...)
+			serviceFreeBytes.add(int64(service.FreeCapacity), labels...)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	for _, m := range []*metric{volumeUsedBytes, volumeUsedInodes, volumeLimitBytes, volumeLimitInodes, volumeFreeBytes, serviceTotalBytes, serviceFreeBytes} {
+		m.write(buffer)
+	}
+	for _, backendName := range skippedCapacities {
+		fmt.Fprintf(buffer, "# the service capacity of backend %s is not exported, no credentials of the backend are configured on the server\n", backendName)
+	}
+	return buffer.Bytes()
+}
+
+// getVolumeForMetrics takes the volume lock like the GetVolume handler, so the scrape does not race the volume operations
+func (h *StorageApiHandler) getVolumeForMetrics(backend resources.StorageClient, name string) (resources.Volume, error) {
+	h.locker.WriteLock(name)
+	defer h.locker.WriteUnlock(name)
+	return backend.GetVolume(resources.GetVolumeRequest{Name: name})
+}
+
+// getMetricsCredentialInfo return the credentials to query the capacity of the backend without a request of a plugin,
+// or false if the backend needs credentials and none are configured on the server (SCBE).
+func (h *StorageApiHandler) getMetricsCredentialInfo(backendName string) (resources.CredentialInfo, bool) {
+	if backendName != resources.SCBE {
+		return resources.CredentialInfo{}, true
+	}
+	credentialInfo := h.config.ScbeConfig.ConnectionInfo.CredentialInfo
+	return credentialInfo, credentialInfo.UserName != ""
+}
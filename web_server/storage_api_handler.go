@@ -357,6 +357,241 @@ func (h *StorageApiHandler) ListVolumes() http.HandlerFunc {
 	}
 }
 
+func (h *StorageApiHandler) ResizeVolume() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		resizeVolumeRequest := resources.ResizeVolumeRequest{}
+		err := utils.UnmarshalDataFromRequest(req, &resizeVolumeRequest)
+		go_id := logs.GetGoID()
+		logs.GoIdToRequestIdMap.Store(go_id, resizeVolumeRequest.Context)
+		defer logs.GetDeleteFromMapFunc(go_id)
+		defer h.logger.Trace(logs.DEBUG)()
+
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		backend, err := h.getBackend(resizeVolumeRequest.Name)
+		if err != nil {
+			h.logger.Error("error-backend-not-found-for-volume", logs.Args{{"name", resizeVolumeRequest.Name}})
+			utils.WriteResponse(w, http.StatusNotFound, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+		volumeResizer, ok := backend.(resources.VolumeResizer)
+		if !ok {
+			h.logger.Error("error-backend-does-not-resize-volumes", logs.Args{{"name", resizeVolumeRequest.Name}})
+			utils.WriteResponse(w, http.StatusNotImplemented, &resources.GenericResponse{Err: "backend-does-not-resize-volumes"})
+			return
+		}
+
+		h.locker.WriteLock(resizeVolumeRequest.Name)
+		defer h.locker.WriteUnlock(resizeVolumeRequest.Name)
+		err = volumeResizer.ResizeVolume(resizeVolumeRequest)
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		utils.WriteResponse(w, http.StatusOK, nil)
+	}
+}
+
+func (h *StorageApiHandler) UpdateNfsExport() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		updateNfsExportRequest := resources.UpdateNfsExportRequest{}
+		err := utils.UnmarshalDataFromRequest(req, &updateNfsExportRequest)
+		go_id := logs.GetGoID()
+		logs.GoIdToRequestIdMap.Store(go_id, updateNfsExportRequest.Context)
+		defer logs.GetDeleteFromMapFunc(go_id)
+		defer h.logger.Trace(logs.DEBUG)()
+
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		backend, err := h.getBackend(updateNfsExportRequest.Name)
+		if err != nil {
+			h.logger.Error("error-backend-not-found-for-volume", logs.Args{{"name", updateNfsExportRequest.Name}})
+			utils.WriteResponse(w, http.StatusNotFound, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+		nfsExportUpdater, ok := backend.(resources.NfsExportUpdater)
+		if !ok {
+			h.logger.Error("error-backend-does-not-update-nfs-exports", logs.Args{{"name", updateNfsExportRequest.Name}})
+			utils.WriteResponse(w, http.StatusNotImplemented, &resources.GenericResponse{Err: "backend-does-not-update-nfs-exports"})
+			return
+		}
+
+		h.locker.WriteLock(updateNfsExportRequest.Name)
+		defer h.locker.WriteUnlock(updateNfsExportRequest.Name)
+		err = nfsExportUpdater.UpdateNfsExport(updateNfsExportRequest)
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		utils.WriteResponse(w, http.StatusOK, nil)
+	}
+}
+
+func (h *StorageApiHandler) GetCapacity() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		getCapacityRequest := resources.GetCapacityRequest{}
+		err := utils.UnmarshalDataFromRequest(req, &getCapacityRequest)
+		go_id := logs.GetGoID()
+		logs.GoIdToRequestIdMap.Store(go_id, getCapacityRequest.Context)
+		defer logs.GetDeleteFromMapFunc(go_id)
+		defer h.logger.Trace(logs.DEBUG)()
+
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		getCapacityRequest.Backend = utils.ExtractVarsFromRequest(req, "backend")
+		backend, ok := h.backends[getCapacityRequest.Backend]
+		if !ok {
+			h.logger.Error("error-backend-not-found", logs.Args{{"backend", getCapacityRequest.Backend}})
+			utils.WriteResponse(w, http.StatusNotFound, &resources.GenericResponse{Err: "backend-not-found"})
+			return
+		}
+		capacityReporter, ok := backend.(resources.CapacityReporter)
+		if !ok {
+			h.logger.Error("error-backend-does-not-report-capacity", logs.Args{{"backend", getCapacityRequest.Backend}})
+			utils.WriteResponse(w, http.StatusNotImplemented, &resources.GenericResponse{Err: "backend-does-not-report-capacity"})
+			return
+		}
+
+		capacity, err := capacityReporter.GetCapacity(getCapacityRequest)
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GetCapacityResponse{Err: err.Error()})
+			return
+		}
+
+		utils.WriteResponse(w, http.StatusOK, resources.GetCapacityResponse{Capacity: capacity})
+	}
+}
+
+// Metrics exports the usage of the volumes and the capacity of the backends in the Prometheus text format
+func (h *StorageApiHandler) Metrics() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		defer h.logger.Trace(logs.DEBUG)()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.WriteHeader(http.StatusOK)
+		w.Write(h.collectMetrics())
+	}
+}
+
+func (h *StorageApiHandler) ReportAttachments() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		reportAttachmentsRequest := resources.ReportAttachmentsRequest{}
+		err := utils.UnmarshalDataFromRequest(req, &reportAttachmentsRequest)
+		go_id := logs.GetGoID()
+		logs.GoIdToRequestIdMap.Store(go_id, reportAttachmentsRequest.Context)
+		defer logs.GetDeleteFromMapFunc(go_id)
+		defer h.logger.Trace(logs.DEBUG)()
+
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		// the report lists the volumes of all the backends, every reconciler picks its own volumes
+		reportAttachmentsRequest.Host = utils.ExtractVarsFromRequest(req, "host")
+		for backendName, backend := range h.backends {
+			mappingReconciler, ok := backend.(resources.MappingReconciler)
+			if !ok {
+				continue
+			}
+			if err := mappingReconciler.ReportAttachments(reportAttachmentsRequest); err != nil {
+				h.logger.Error("error-report-attachments", logs.Args{{"backend", backendName}, {"error", err}})
+				utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+				return
+			}
+		}
+		utils.WriteResponse(w, http.StatusOK, nil)
+	}
+}
+
+func (h *StorageApiHandler) GetStaleMappings() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		getStaleMappingsRequest := resources.GetStaleMappingsRequest{}
+		err := utils.UnmarshalDataFromRequest(req, &getStaleMappingsRequest)
+		go_id := logs.GetGoID()
+		logs.GoIdToRequestIdMap.Store(go_id, getStaleMappingsRequest.Context)
+		defer logs.GetDeleteFromMapFunc(go_id)
+		defer h.logger.Trace(logs.DEBUG)()
+
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		getStaleMappingsRequest.Backend = utils.ExtractVarsFromRequest(req, "backend")
+		mappingReconciler, code, err := h.getMappingReconciler(getStaleMappingsRequest.Backend)
+		if err != nil {
+			utils.WriteResponse(w, code, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		staleMappings, err := mappingReconciler.GetStaleMappings(getStaleMappingsRequest)
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GetStaleMappingsResponse{Err: err.Error()})
+			return
+		}
+
+		utils.WriteResponse(w, http.StatusOK, resources.GetStaleMappingsResponse{StaleMappings: staleMappings})
+	}
+}
+
+func (h *StorageApiHandler) ForceUnmap() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		forceUnmapRequest := resources.ForceUnmapRequest{}
+		err := utils.UnmarshalDataFromRequest(req, &forceUnmapRequest)
+		go_id := logs.GetGoID()
+		logs.GoIdToRequestIdMap.Store(go_id, forceUnmapRequest.Context)
+		defer logs.GetDeleteFromMapFunc(go_id)
+		defer h.logger.Trace(logs.DEBUG)()
+
+		if err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		forceUnmapRequest.Backend = utils.ExtractVarsFromRequest(req, "backend")
+		mappingReconciler, code, err := h.getMappingReconciler(forceUnmapRequest.Backend)
+		if err != nil {
+			utils.WriteResponse(w, code, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+
+		h.locker.WriteLock(forceUnmapRequest.Name)
+		defer h.locker.WriteUnlock(forceUnmapRequest.Name)
+		if err = mappingReconciler.ForceUnmap(forceUnmapRequest); err != nil {
+			utils.WriteResponse(w, 409, &resources.GenericResponse{Err: err.Error()})
+			return
+		}
+		utils.WriteResponse(w, http.StatusOK, nil)
+	}
+}
+
+// getMappingReconciler returns the backend by name and the http status code to return if it cannot reconcile mappings
+func (h *StorageApiHandler) getMappingReconciler(backendName string) (resources.MappingReconciler, int, error) {
+	backend, ok := h.backends[backendName]
+	if !ok {
+		h.logger.Error("error-backend-not-found", logs.Args{{"backend", backendName}})
+		return nil, http.StatusNotFound, fmt.Errorf("backend-not-found")
+	}
+	mappingReconciler, ok := backend.(resources.MappingReconciler)
+	if !ok {
+		h.logger.Error("error-backend-does-not-reconcile-mappings", logs.Args{{"backend", backendName}})
+		return nil, http.StatusNotImplemented, fmt.Errorf("backend-does-not-reconcile-mappings")
+	}
+	return mappingReconciler, http.StatusOK, nil
+}
+
 func (h *StorageApiHandler) getBackend(name string) (resources.StorageClient, error) {
 	defer h.logger.Trace(logs.DEBUG)()
 	var backendName string
@@ -0,0 +1,220 @@
+/**
+ * Copyright 2017 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package web_server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/IBM/ubiquity/fakes"
+	"github.com/IBM/ubiquity/resources"
+	"github.com/IBM/ubiquity/web_server"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+)
+
+// fakeMappingReconcilerClient is a backend that reconciles mappings
+type fakeMappingReconcilerClient struct {
+	fakes.FakeStorageClient
+	reportRequests []resources.ReportAttachmentsRequest
+	staleRequests  []resources.GetStaleMappingsRequest
+	unmapRequests  []resources.ForceUnmapRequest
+	staleMappings  []resources.StaleMapping
+	err            error
+}
+
+func (f *fakeMappingReconcilerClient) ReportAttachments(reportAttachmentsRequest resources.ReportAttachmentsRequest) error {
+	f.reportRequests = append(f.reportRequests, reportAttachmentsRequest)
+	return f.err
+}
+
+func (f *fakeMappingReconcilerClient) GetStaleMappings(getStaleMappingsRequest resources.GetStaleMappingsRequest) ([]resources.StaleMapping, error) {
+	f.staleRequests = append(f.staleRequests, getStaleMappingsRequest)
+	return f.staleMappings, f.err
+}
+
+func (f *fakeMappingReconcilerClient) ForceUnmap(forceUnmapRequest resources.ForceUnmapRequest) error {
+	f.unmapRequests = append(f.unmapRequests, forceUnmapRequest)
+	return f.err
+}
+
+// fakeCapacityReporterClient is a backend that reports the capacity of its services
+type fakeCapacityReporterClient struct {
+	fakes.FakeStorageClient
+	capacity         []resources.ServiceCapacity
+	capacityRequests []resources.GetCapacityRequest
+}
+
+func (f *fakeCapacityReporterClient) GetCapacity(getCapacityRequest resources.GetCapacityRequest) ([]resources.ServiceCapacity, error) {
+	f.capacityRequests = append(f.capacityRequests, getCapacityRequest)
+	return f.capacity, nil
+}
+
+var _ = Describe("StorageApiHandler", func() {
+	var (
+		reconciler   *fakeMappingReconcilerClient
+		otherBackend *fakes.FakeStorageClient
+		handler      http.Handler
+	)
+	serve := func(method string, path string, request interface{}) *httptest.ResponseRecorder {
+		body, err := json.Marshal(request)
+		Expect(err).NotTo(HaveOccurred())
+		req, err := http.NewRequest(method, path, bytes.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+	BeforeEach(func() {
+		reconciler = &fakeMappingReconcilerClient{}
+		otherBackend = new(fakes.FakeStorageClient)
+		backends := map[string]resources.StorageClient{resources.SCBE: reconciler, resources.SpectrumScale: otherBackend}
+		server, err := web_server.NewStorageApiServer(backends, resources.UbiquityServerConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		handler = server.InitializeHandler()
+	})
+	Context(".ReportAttachments", func() {
+		It("should pass the report of the host to the backends that reconcile mappings", func() {
+			recorder := serve("PUT", "/ubiquity_storage/hosts/node1/attachments", resources.ReportAttachmentsRequest{WWNs: []string{"wwn1"}})
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(reconciler.reportRequests).To(HaveLen(1))
+			Expect(reconciler.reportRequests[0].Host).To(Equal("node1"))
+			Expect(reconciler.reportRequests[0].WWNs).To(Equal([]string{"wwn1"}))
+		})
+		It("should fail if the backend failed to save the report", func() {
+			reconciler.err = errors.New("db error")
+			recorder := serve("PUT", "/ubiquity_storage/hosts/node1/attachments", resources.ReportAttachmentsRequest{})
+			Expect(recorder.Code).To(Equal(409))
+		})
+	})
+	Context(".GetStaleMappings", func() {
+		It("should return the stale mappings of the backend", func() {
+			reconciler.staleMappings = []resources.StaleMapping{{Name: "vol1", Host: "node1", Status: resources.MappingStatusStale}}
+			recorder := serve("GET", "/ubiquity_storage/backends/scbe/stale_mappings", resources.GetStaleMappingsRequest{})
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			response := resources.GetStaleMappingsResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.StaleMappings).To(Equal(reconciler.staleMappings))
+			Expect(reconciler.staleRequests[0].Backend).To(Equal(resources.SCBE))
+		})
+		It("should return 404 for an unknown backend", func() {
+			recorder := serve("GET", "/ubiquity_storage/backends/fake/stale_mappings", resources.GetStaleMappingsRequest{})
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+		It("should return 501 for a backend that does not reconcile mappings", func() {
+			recorder := serve("GET", "/ubiquity_storage/backends/spectrum-scale/stale_mappings", resources.GetStaleMappingsRequest{})
+			Expect(recorder.Code).To(Equal(http.StatusNotImplemented))
+		})
+	})
+	Context(".ForceUnmap", func() {
+		It("should unmap the volume from the host", func() {
+			recorder := serve("PUT", "/ubiquity_storage/backends/scbe/stale_mappings/unmap", resources.ForceUnmapRequest{Name: "vol1", Host: "node1", Force: true})
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(reconciler.unmapRequests).To(HaveLen(1))
+			Expect(reconciler.unmapRequests[0]).To(Equal(resources.ForceUnmapRequest{Backend: resources.SCBE, Name: "vol1", Host: "node1", Force: true}))
+		})
+		It("should fail if the backend refused the unmap", func() {
+			reconciler.err = errors.New("mapping is unknown")
+			recorder := serve("PUT", "/ubiquity_storage/backends/scbe/stale_mappings/unmap", resources.ForceUnmapRequest{Name: "vol1", Host: "node1"})
+			Expect(recorder.Code).To(Equal(409))
+			Expect(recorder.Body.String()).To(ContainSubstring("mapping is unknown"))
+		})
+	})
+	Context(".Metrics", func() {
+		It("should export the usage of the volumes and the capacity of the backends", func() {
+			spectrumBackend := new(fakeCapacityReporterClient)
+			spectrumBackend.ListVolumesReturns([]resources.Volume{{Name: "vol1"}, {Name: "vol2"}, {Name: "vol3"}}, nil)
+			spectrumBackend.GetVolumeStub = func(getVolumeRequest resources.GetVolumeRequest) (resources.Volume, error) {
+				switch getVolumeRequest.Name {
+				case "vol1":
+					return resources.Volume{Name: "vol1", Usage: &resources.VolumeUsage{UsedBytes: 1024, UsedInodes: 10, LimitBytes: 4096, FreeBytes: 8192}}, nil
+				case "vol2":
+					return resources.Volume{Name: "vol2"}, nil
+				}
+				return resources.Volume{}, errors.New("volume not found")
+			}
+			spectrumBackend.capacity = []resources.ServiceCapacity{{Name: "gold", TotalCapacity: 16384, FreeCapacity: 8192}}
+			server, err := web_server.NewStorageApiServer(map[string]resources.StorageClient{resources.SpectrumScale: spectrumBackend}, resources.UbiquityServerConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			handler = server.InitializeHandler()
+
+			req, err := http.NewRequest("GET", "/ubiquity_storage/metrics", nil)
+			Expect(err).NotTo(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/plain"))
+			metrics := recorder.Body.String()
+			Expect(metrics).To(ContainSubstring("# TYPE ubiquity_volume_used_bytes gauge\n"))
+			Expect(metrics).To(ContainSubstring(`ubiquity_volume_used_bytes{backend="spectrum-scale",volume="vol1"} 1024` + "\n"))
+			Expect(metrics).To(ContainSubstring(`ubiquity_volume_used_inodes{backend="spectrum-scale",volume="vol1"} 10` + "\n"))
+			Expect(metrics).To(ContainSubstring(`ubiquity_volume_limit_bytes{backend="spectrum-scale",volume="vol1"} 4096` + "\n"))
+			Expect(metrics).To(ContainSubstring(`ubiquity_volume_storage_free_bytes{backend="spectrum-scale",volume="vol1"} 8192` + "\n"))
+			Expect(metrics).To(ContainSubstring(`ubiquity_service_total_bytes{backend="spectrum-scale",service="gold"} 16384` + "\n"))
+			Expect(metrics).To(ContainSubstring(`ubiquity_service_free_bytes{backend="spectrum-scale",service="gold"} 8192` + "\n"))
+			Expect(metrics).NotTo(ContainSubstring("ubiquity_volume_limit_inodes"))
+			Expect(metrics).NotTo(ContainSubstring("vol2"))
+			Expect(metrics).NotTo(ContainSubstring("vol3"))
+		})
+		It("should query the SCBE capacity with the credentials configured on the server", func() {
+			scbeBackend := new(fakeCapacityReporterClient)
+			scbeBackend.capacity = []resources.ServiceCapacity{{Name: "gold", TotalCapacity: 16384, FreeCapacity: 8192}}
+			config := resources.UbiquityServerConfig{}
+			config.ScbeConfig.ConnectionInfo.CredentialInfo = resources.CredentialInfo{UserName: "user1", Password: "password1"}
+			server, err := web_server.NewStorageApiServer(map[string]resources.StorageClient{resources.SCBE: scbeBackend}, config)
+			Expect(err).NotTo(HaveOccurred())
+			handler = server.InitializeHandler()
+
+			req, err := http.NewRequest("GET", "/ubiquity_storage/metrics", nil)
+			Expect(err).NotTo(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(scbeBackend.capacityRequests).To(HaveLen(1))
+			Expect(scbeBackend.capacityRequests[0].CredentialInfo).To(Equal(config.ScbeConfig.ConnectionInfo.CredentialInfo))
+			Expect(recorder.Body.String()).To(ContainSubstring(`ubiquity_service_total_bytes{backend="scbe",service="gold"} 16384` + "\n"))
+		})
+		It("should skip the SCBE capacity and say so if no credentials are configured on the server", func() {
+			scbeBackend := new(fakeCapacityReporterClient)
+			scbeBackend.capacity = []resources.ServiceCapacity{{Name: "gold", TotalCapacity: 16384, FreeCapacity: 8192}}
+			server, err := web_server.NewStorageApiServer(map[string]resources.StorageClient{resources.SCBE: scbeBackend}, resources.UbiquityServerConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			handler = server.InitializeHandler()
+
+			req, err := http.NewRequest("GET", "/ubiquity_storage/metrics", nil)
+			Expect(err).NotTo(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(scbeBackend.capacityRequests).To(BeEmpty())
+			Expect(recorder.Body.String()).NotTo(ContainSubstring("ubiquity_service_total_bytes"))
+			Expect(recorder.Body.String()).To(ContainSubstring("# the service capacity of backend scbe is not exported"))
+		})
+		It("should export the other backends if a backend fails to list its volumes", func() {
+			otherBackend.ListVolumesReturns(nil, errors.New("db error"))
+			reconciler.ListVolumesReturns([]resources.Volume{{Name: "vol1"}}, nil)
+			reconciler.GetVolumeReturns(resources.Volume{Name: "vol1", Usage: &resources.VolumeUsage{UsedBytes: 1}}, nil)
+			req, err := http.NewRequest("GET", "/ubiquity_storage/metrics", nil)
+			Expect(err).NotTo(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`ubiquity_volume_used_bytes{backend="scbe",volume="vol1"} 1` + "\n"))
+		})
+	})
+})
@@ -53,6 +53,13 @@ func (s *StorageApiServer) InitializeHandler() http.Handler {
 	router.HandleFunc("/ubiquity_storage/volumes/{volume}/detach", s.storageApiHandler.DetachVolume()).Methods("PUT")
 	router.HandleFunc("/ubiquity_storage/volumes/{volume}", s.storageApiHandler.GetVolume()).Methods("GET")
 	router.HandleFunc("/ubiquity_storage/volumes/{volume}/config", s.storageApiHandler.GetVolumeConfig()).Methods("GET")
+	router.HandleFunc("/ubiquity_storage/volumes/{volume}/resize", s.storageApiHandler.ResizeVolume()).Methods("PUT")
+	router.HandleFunc("/ubiquity_storage/volumes/{volume}/nfs_export", s.storageApiHandler.UpdateNfsExport()).Methods("PUT")
+	router.HandleFunc("/ubiquity_storage/backends/{backend}/capacity", s.storageApiHandler.GetCapacity()).Methods("GET")
+	router.HandleFunc("/ubiquity_storage/metrics", s.storageApiHandler.Metrics()).Methods("GET")
+	router.HandleFunc("/ubiquity_storage/hosts/{host}/attachments", s.storageApiHandler.ReportAttachments()).Methods("PUT")
+	router.HandleFunc("/ubiquity_storage/backends/{backend}/stale_mappings", s.storageApiHandler.GetStaleMappings()).Methods("GET")
+	router.HandleFunc("/ubiquity_storage/backends/{backend}/stale_mappings/unmap", s.storageApiHandler.ForceUnmap()).Methods("PUT")
 	return router
 }
 